@@ -21,6 +21,12 @@ func main() {
 		Run:   serve.Server,
 	})
 
+	cmd.AddCommand(&cobra.Command{
+		Use:   "worker",
+		Short: "Process queued upload and export tasks",
+		Run:   serve.Worker,
+	})
+
 	cmd.AddCommand(&cobra.Command{
 		Use:   "migrate up",
 		Short: "Apply pending database migrations",
@@ -16,6 +16,64 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/admin/duplicates": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Chapter pairs sharing pages by perceptual hash, most similar first. Hashes must be equal; with manga_id, pages within that manga also match when near-identical. Admins only (ADMIN__USER_IDS).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Duplicate chapter report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only pairs involving this manga",
+                        "name": "manga_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum share of the shorter chapter's pages, in percent (default DUPLICATES__WARN_PERCENT)",
+                        "name": "min_similarity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Max pairs",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.DuplicateReportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/auth/login": {
             "post": {
                 "consumes": [
@@ -225,6 +283,116 @@ const docTemplate = `{
                 }
             }
         },
+        "/chapters/latest": {
+            "get": {
+                "description": "Published chapters, newest first, grouped by manga. Paginate with the returned next_cursor.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "chapter"
+                ],
+                "summary": "Latest chapter releases",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "Filter by manga tags (AND)",
+                        "name": "tags[]",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by manga category (partial match)",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Chapters in this language (ISO 639-1) or without one",
+                        "name": "lang",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Chapters per page",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.LatestReleasesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/lists/{slug}": {
+            "get": {
+                "description": "Private lists are only visible to their owner.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "list"
+                ],
+                "summary": "Get a reading list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "List slug",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ReadingListDetailResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/mangas": {
             "get": {
                 "produces": [
@@ -284,7 +452,12 @@ const docTemplate = `{
                         "enum": [
                             "newest",
                             "oldest",
-                            "title"
+                            "title",
+                            "popular",
+                            "trending",
+                            "updated",
+                            "chapters",
+                            "rating"
                         ],
                         "type": "string",
                         "description": "Sort order",
@@ -522,6 +695,7 @@ const docTemplate = `{
         },
         "/mangas/{mangaID}/chapters": {
             "get": {
+                "description": "Without lang, signed-in users get their preferred language (all chapters if the manga has none in it).\nChapters without a language are always included. lang=all disables the filter.\nSigned-in users also get each chapter's read_state.",
                 "produces": [
                     "application/json"
                 ],
@@ -536,6 +710,12 @@ const docTemplate = `{
                         "name": "mangaID",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Language code (ISO 639-1) or all",
+                        "name": "lang",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -630,8 +810,73 @@ const docTemplate = `{
                 }
             }
         },
+        "/mangas/{mangaID}/chapters/mark-read": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Marks every published chapter numbered up to up_to read, among the chapters the list endpoint shows for language.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "chapter"
+                ],
+                "summary": "Mark chapters read up to a number",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Manga ID",
+                        "name": "mangaID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Last chapter to mark",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.MarkReadUpToRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.MarkReadUpToResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/mangas/{mangaID}/chapters/{chapterID}": {
             "get": {
+                "description": "Includes the previous and next published chapters in the chapter's language. With prefetch, the first pages of the next chapter are included too.",
                 "produces": [
                     "application/json"
                 ],
@@ -653,6 +898,12 @@ const docTemplate = `{
                         "name": "chapterID",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Pages of the next chapter to include (max 10)",
+                        "name": "prefetch",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -928,23 +1179,21 @@ const docTemplate = `{
                 }
             }
         },
-        "/mangas/{mangaID}/chapters/{chapterID}/pages": {
-            "post": {
+        "/mangas/{mangaID}/chapters/{chapterID}/export/cbz": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "consumes": [
-                    "multipart/form-data"
-                ],
+                "description": "Streams the chapter's pages as a CBZ archive with a generated ComicInfo.xml.",
                 "produces": [
-                    "application/json"
+                    "application/vnd.comicbook+zip"
                 ],
                 "tags": [
-                    "page"
+                    "export"
                 ],
-                "summary": "Upload individual pages",
+                "summary": "Download a chapter as CBZ",
                 "parameters": [
                     {
                         "type": "string",
@@ -959,23 +1208,13 @@ const docTemplate = `{
                         "name": "chapterID",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "file",
-                        "description": "Image files (jpeg/png/webp), field name: pages",
-                        "name": "pages",
-                        "in": "formData",
-                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/dto.PageUploadResponse"
-                            }
+                            "type": "file"
                         }
                     },
                     "400": {
@@ -990,8 +1229,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
-                    "403": {
-                        "description": "Forbidden",
+                    "404": {
+                        "description": "Chapter not found or has no pages",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
@@ -999,20 +1238,16 @@ const docTemplate = `{
                 }
             }
         },
-        "/mangas/{mangaID}/chapters/{chapterID}/pages/reorder": {
-            "patch": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "consumes": [
+        "/mangas/{mangaID}/chapters/{chapterID}/manifest": {
+            "get": {
+                "description": "Page dimensions and prev/next chapters without signed URLs, for laying out the reader before images load.",
+                "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "page"
+                    "chapter"
                 ],
-                "summary": "Reorder pages",
+                "summary": "Chapter reading manifest",
                 "parameters": [
                     {
                         "type": "string",
@@ -1027,21 +1262,144 @@ const docTemplate = `{
                         "name": "chapterID",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Ordered list of page IDs",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dto.ReorderPagesRequest"
-                        }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
-                    },
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ChapterManifestResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/mangas/{mangaID}/chapters/{chapterID}/pages": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "page"
+                ],
+                "summary": "Upload individual pages",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Manga ID",
+                        "name": "mangaID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Chapter ID",
+                        "name": "chapterID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Image files (jpeg/png/webp), field name: pages",
+                        "name": "pages",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.PageUploadResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/mangas/{mangaID}/chapters/{chapterID}/pages/reorder": {
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "page"
+                ],
+                "summary": "Reorder pages",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Manga ID",
+                        "name": "mangaID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Chapter ID",
+                        "name": "chapterID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Ordered list of page IDs",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ReorderPagesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
@@ -1070,7 +1428,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Enqueues a ZIP upload for async processing. Returns 202 with a task_id to poll for status.",
+                "description": "Enqueues an archive upload for async processing. Accepts zip/cbz, rar/cbr, 7z, tar(.gz) or PDF, detected from the content. Returns 202 with a task_id to poll for status.",
                 "consumes": [
                     "multipart/form-data"
                 ],
@@ -1080,7 +1438,7 @@ const docTemplate = `{
                 "tags": [
                     "page"
                 ],
-                "summary": "Upload pages from ZIP (async)",
+                "summary": "Upload pages from an archive (async)",
                 "parameters": [
                     {
                         "type": "string",
@@ -1098,10 +1456,16 @@ const docTemplate = `{
                     },
                     {
                         "type": "file",
-                        "description": "ZIP archive",
+                        "description": "Archive (zip/cbz, rar/cbr, 7z, tar, tar.gz) or PDF",
                         "name": "file",
                         "in": "formData",
                         "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Merge metadata.json / ComicInfo.xml into the manga and set the chapter number and title",
+                        "name": "apply_metadata",
+                        "in": "formData"
                     }
                 ],
                 "responses": {
@@ -1197,6 +1561,127 @@ const docTemplate = `{
                 }
             }
         },
+        "/mangas/{mangaID}/chapters/{chapterID}/read": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Progress only grows; reaching the last page (or completed) marks the chapter read.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "chapter"
+                ],
+                "summary": "Record reading progress in a chapter",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Manga ID",
+                        "name": "mangaID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Chapter ID",
+                        "name": "chapterID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Progress",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.RecordReadRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ReadStateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "chapter"
+                ],
+                "summary": "Mark a chapter unread",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Manga ID",
+                        "name": "mangaID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Chapter ID",
+                        "name": "chapterID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/mangas/{mangaID}/comments": {
             "get": {
                 "produces": [
@@ -1437,6 +1922,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
+                "description": "The image is cropped to the optional crop_* rectangle (all four or none) and stored as WebP variants\n(IMAGE__COVER_WIDTHS, never upscaled), returned as cover_srcset; cover_url is the largest.",
                 "consumes": [
                     "multipart/form-data"
                 ],
@@ -1461,12 +1947,36 @@ const docTemplate = `{
                         "name": "cover",
                         "in": "formData",
                         "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Crop left edge (px)",
+                        "name": "crop_x",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Crop top edge (px)",
+                        "name": "crop_y",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Crop width (px)",
+                        "name": "crop_width",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Crop height (px)",
+                        "name": "crop_height",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
                             "$ref": "#/definitions/dto.MangaResponse"
                         }
                     },
@@ -1497,49 +2007,51 @@ const docTemplate = `{
                 }
             }
         },
-        "/mangas/{mangaID}/oneshot/upload": {
-            "post": {
+        "/mangas/{mangaID}/cover/page": {
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "For oneshot manga only. Enqueues zip processing; Lambda creates the chapter and uploads pages. Returns 202 with a task_id to poll for status.",
+                "description": "Renders the cover variants from a page of one of the manga's chapters, optionally cropped.",
                 "consumes": [
-                    "multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "page"
+                    "manga"
                 ],
-                "summary": "Upload oneshot ZIP (async)",
+                "summary": "Use a page as the manga cover",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Manga ID (must be type=oneshot)",
+                        "description": "Manga ID",
                         "name": "mangaID",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "file",
-                        "description": "ZIP archive",
-                        "name": "file",
-                        "in": "formData",
-                        "required": true
+                        "description": "Source page and crop",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.SetCoverFromPageRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "202": {
-                        "description": "Accepted",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.EnqueueResponse"
+                            "$ref": "#/definitions/dto.MangaResponse"
                         }
                     },
                     "400": {
-                        "description": "Not a oneshot or invalid ZIP",
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
@@ -1555,36 +2067,6 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
-                    }
-                }
-            }
-        },
-        "/tasks/{taskID}": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "tags": [
-                    "upload"
-                ],
-                "summary": "Get upload task status",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Task ID",
-                        "name": "taskID",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/dto.UploadTaskResponse"
-                        }
                     },
                     "404": {
                         "description": "Not Found",
@@ -1595,13 +2077,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/me": {
-            "patch": {
+        "/mangas/{mangaID}/export": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "description": "Builds a CBZ (one folder per chapter) or a fixed-layout EPUB in the background. Returns 202 with a task_id; the task's download_url is set once done.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1609,25 +2092,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "export"
                 ],
-                "summary": "Update own profile",
+                "summary": "Export a whole manga (async)",
                 "parameters": [
                     {
-                        "description": "Profile fields to update",
+                        "type": "string",
+                        "description": "Manga ID",
+                        "name": "mangaID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Export format",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.UpdateUserRequest"
+                            "$ref": "#/definitions/dto.ExportRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "202": {
+                        "description": "Accepted",
                         "schema": {
-                            "$ref": "#/definitions/dto.UserResponse"
+                            "$ref": "#/definitions/dto.EnqueueResponse"
                         }
                     },
                     "400": {
@@ -1641,41 +2131,52 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/users/me/avatar": {
-            "put": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "consumes": [
-                    "multipart/form-data"
-                ],
+        "/mangas/{mangaID}/lists": {
+            "get": {
+                "description": "Public lists, plus the viewer's own private ones.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "list"
                 ],
-                "summary": "Upload avatar",
+                "summary": "Reading lists containing a manga",
                 "parameters": [
                     {
-                        "type": "file",
-                        "description": "Avatar image (jpeg/png/webp)",
-                        "name": "avatar",
-                        "in": "formData",
+                        "type": "string",
+                        "description": "Manga ID",
+                        "name": "mangaID",
+                        "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.UserResponse"
+                            "$ref": "#/definitions/dto.PagedReadingListResponse"
                         }
                     },
                     "400": {
@@ -1684,8 +2185,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
@@ -1693,28 +2194,57 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/me/bookmarks": {
-            "get": {
+        "/mangas/{mangaID}/oneshot/upload": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "description": "For oneshot manga only. Accepts zip/cbz, rar/cbr, 7z, tar(.gz) or PDF. Enqueues processing; Lambda creates the chapter and uploads pages. Returns 202 with a task_id to poll for status.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "bookmark"
+                    "page"
+                ],
+                "summary": "Upload oneshot archive (async)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Manga ID (must be type=oneshot)",
+                        "name": "mangaID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Archive (zip/cbz, rar/cbr, 7z, tar, tar.gz) or PDF",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Merge metadata.json / ComicInfo.xml into the manga",
+                        "name": "apply_metadata",
+                        "in": "formData"
+                    }
                 ],
-                "summary": "List bookmarks",
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "202": {
+                        "description": "Accepted",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/dto.BookmarkResponse"
-                            }
+                            "$ref": "#/definitions/dto.EnqueueResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Not a oneshot or unsupported archive",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
                     "401": {
@@ -1722,11 +2252,17 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/users/me/bookmarks/{mangaID}": {
+        "/mangas/{mangaID}/rating": {
             "get": {
                 "security": [
                     {
@@ -1737,9 +2273,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "bookmark"
+                    "rating"
                 ],
-                "summary": "Get bookmark for a manga",
+                "summary": "Get my rating of a manga",
                 "parameters": [
                     {
                         "type": "string",
@@ -1753,13 +2289,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.BookmarkResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/dto.ErrorResponse"
+                            "$ref": "#/definitions/dto.RatingResponse"
                         }
                     },
                     "401": {
@@ -1782,6 +2312,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
+                "description": "Creates or replaces my 1–10 rating, with an optional review. Changing the review text clears its helpful votes. Owners can't rate their own manga.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1789,9 +2320,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "bookmark"
+                    "rating"
                 ],
-                "summary": "Create or update bookmark",
+                "summary": "Rate a manga",
                 "parameters": [
                     {
                         "type": "string",
@@ -1801,12 +2332,12 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Bookmark data",
+                        "description": "Rating",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.UpsertBookmarkRequest"
+                            "$ref": "#/definitions/dto.RateMangaRequest"
                         }
                     }
                 ],
@@ -1814,7 +2345,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.BookmarkResponse"
+                            "$ref": "#/definitions/dto.RatingResponse"
                         }
                     },
                     "400": {
@@ -1828,6 +2359,18 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
                     }
                 }
             },
@@ -1838,9 +2381,9 @@ const docTemplate = `{
                     }
                 ],
                 "tags": [
-                    "bookmark"
+                    "rating"
                 ],
-                "summary": "Delete bookmark",
+                "summary": "Remove my rating of a manga",
                 "parameters": [
                     {
                         "type": "string",
@@ -1854,14 +2397,14 @@ const docTemplate = `{
                     "204": {
                         "description": "No Content"
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
@@ -1869,29 +2412,52 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/{userID}": {
+        "/mangas/{mangaID}/reviews": {
             "get": {
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "rating"
                 ],
-                "summary": "Get public user profile",
+                "summary": "List written reviews of a manga",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID",
-                        "name": "userID",
+                        "description": "Manga ID",
+                        "name": "mangaID",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "enum": [
+                            "helpful",
+                            "newest"
+                        ],
+                        "type": "string",
+                        "default": "helpful",
+                        "description": "Sort order",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.PublicUserResponse"
+                            "$ref": "#/definitions/dto.PagedReviewResponse"
                         }
                     },
                     "400": {
@@ -1909,36 +2475,242 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/{userID}/mangas": {
-            "get": {
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "manga"
+        "/mangas/{mangaID}/reviews/{reviewID}/helpful": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
-                "summary": "List manga by user",
+                "tags": [
+                    "rating"
+                ],
+                "summary": "Vote a review helpful",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID",
-                        "name": "userID",
+                        "description": "Manga ID",
+                        "name": "mangaID",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "integer",
-                        "default": 1,
-                        "description": "Page number",
-                        "name": "page",
-                        "in": "query"
+                        "type": "string",
+                        "description": "Review ID",
+                        "name": "reviewID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "rating"
+                ],
+                "summary": "Withdraw a helpful vote",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Manga ID",
+                        "name": "mangaID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Review ID",
+                        "name": "reviewID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/mangas/{mangaID}/series/upload": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "For series manga only. Each top-level folder (or inner archive such as a .cbz/.cbr/.pdf) becomes a chapter, numbered from its metadata.json, ComicInfo.xml or folder name (e.g. \"Chapter 12 - Title\"). Returns 202 with a task_id; per-chapter results are reported on the task.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "page"
+                ],
+                "summary": "Import a whole series from an archive (async)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Manga ID (must be type=series)",
+                        "name": "mangaID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Archive with one folder or inner archive per chapter",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Merge the chapters' metadata.json / ComicInfo.xml into the manga",
+                        "name": "apply_metadata",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/dto.EnqueueResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Not a series or unsupported archive",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{taskID}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "For finished export tasks the response includes a presigned download_url.\nOnly the task's owner can read it.",
+                "tags": [
+                    "upload"
+                ],
+                "summary": "Get upload task status",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "taskID",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.PagedMangaResponse"
+                            "$ref": "#/definitions/dto.UploadTaskResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{taskID}/cancel": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Only tasks no worker has picked up yet can be cancelled; the staged upload is deleted.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "upload"
+                ],
+                "summary": "Cancel a pending task",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "taskID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UploadTaskResponse"
                         }
                     },
                     "400": {
@@ -1946,198 +2718,2534 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Task is not pending",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{taskID}/events": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Server-Sent Events. The first \"status\" event describes the task as stored; \"progress\"\nevents follow while pages are stored ({\"processed\": 37, \"total\": 120}) and a \"status\"\nevent on every status change. The stream ends once the task is done, failed or cancelled.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "upload"
+                ],
+                "summary": "Stream upload task progress",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "taskID",
+                        "in": "path",
+                        "required": true
                     }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UploadTaskEventResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{taskID}/retry": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Queues the task again with the archive uploaded for it; its error, results and\nprogress are cleared. Poll or stream the task as after the first upload.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "upload"
+                ],
+                "summary": "Retry a failed task",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "taskID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UploadTaskResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Task has not failed",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/uploads": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "tus creation. Upload-Length is the archive size in bytes. Upload-Metadata (base64 values)\nselects the target like the multipart endpoints: type (zip, oneshot_zip or series_zip),\nmanga_id, chapter_id (zip only), apply_metadata (\"true\") and filename. The target is\nchecked before any data is sent. Send the archive with PATCH to the Location URL.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "upload"
+                ],
+                "summary": "Start a resumable archive upload",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "1.0.0",
+                        "name": "Tus-Resumable",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Archive size in bytes",
+                        "name": "Upload-Length",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "type, manga_id, chapter_id, apply_metadata, filename",
+                        "name": "Upload-Metadata",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UploadSessionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Unsupported tus version",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Larger than Tus-Max-Size",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "options": {
+                "description": "Returns the supported tus version (Tus-Version), extensions (Tus-Extension) and the\nlargest accepted archive in bytes (Tus-Max-Size).",
+                "tags": [
+                    "upload"
+                ],
+                "summary": "Discover tus upload support",
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        },
+        "/uploads/direct": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns presigned URLs to PUT the archive straight to storage, so it doesn't pass\nthrough the API: one URL for archives up to 64 MB, otherwise one per part_size slice\n(S3 multipart). The target is checked like for the multipart endpoints. Then call\nPOST /uploads/direct/{uploadID}/complete. The URLs expire with the upload\n(UPLOAD__SESSION_TTL). Browsers need a bucket CORS rule allowing PUT from the site.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "upload"
+                ],
+                "summary": "Start a direct-to-storage archive upload",
+                "parameters": [
+                    {
+                        "description": "Archive",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateDirectUploadRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.DirectUploadResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Larger than UPLOAD__MAX_SIZE",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/uploads/direct/{uploadID}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "upload"
+                ],
+                "summary": "Cancel a direct-to-storage archive upload",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Upload ID",
+                        "name": "uploadID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Being completed",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/uploads/direct/{uploadID}/complete": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Checks the uploaded archive against the declared size and SHA-256, validates it and\nenqueues the import. A missing or short upload is a 400 the client can fix by\nuploading again; a checksum mismatch or an invalid archive also ends the upload.\nCalling it again after success returns the same task.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "upload"
+                ],
+                "summary": "Finish a direct-to-storage archive upload",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Upload ID",
+                        "name": "uploadID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/dto.EnqueueResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Already being completed",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/uploads/{uploadID}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "tus termination: discards the uploaded data. After completion only the upload is\nremoved; use POST /tasks/{taskID}/cancel to stop the import.",
+                "tags": [
+                    "upload"
+                ],
+                "summary": "Cancel a resumable upload",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Upload ID",
+                        "name": "uploadID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "1.0.0",
+                        "name": "Tus-Resumable",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Chunk in progress",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "head": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "tus core: Upload-Offset is where to resume. Upload-Task-Id is set once the upload is\ncomplete; poll or stream that task for the import.",
+                "tags": [
+                    "upload"
+                ],
+                "summary": "Get a resumable upload's offset",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Upload ID",
+                        "name": "uploadID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "1.0.0",
+                        "name": "Tus-Resumable",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "tus core: the body is appended at Upload-Offset, which must be the current offset\n(409 otherwise; HEAD to resume). The response's Upload-Offset counts the bytes stored,\nalso when the connection dropped mid-chunk. The chunk that completes the upload\nvalidates the archive and returns the created task in Upload-Task-Id; an invalid\narchive returns 400 and ends the upload.",
+                "consumes": [
+                    "application/offset+octet-stream"
+                ],
+                "tags": [
+                    "upload"
+                ],
+                "summary": "Send a chunk of a resumable upload",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Upload ID",
+                        "name": "uploadID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "1.0.0",
+                        "name": "Tus-Resumable",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Offset of the chunk",
+                        "name": "Upload-Offset",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Offset mismatch, chunk in progress or upload complete",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me": {
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Update own profile",
+                "parameters": [
+                    {
+                        "description": "Profile fields to update",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UpdateUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/avatar": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Upload avatar",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Avatar image (jpeg/png/webp)",
+                        "name": "avatar",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/bookmarks": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "My library, each entry with its manga and unread_count (the manga's published chapters not yet finished).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "bookmark"
+                ],
+                "summary": "List bookmarks",
+                "parameters": [
+                    {
+                        "enum": [
+                            "reading",
+                            "plan_to_read",
+                            "completed",
+                            "dropped",
+                            "on_hold"
+                        ],
+                        "type": "string",
+                        "description": "Filter by status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "updated",
+                            "added",
+                            "title",
+                            "score",
+                            "status"
+                        ],
+                        "type": "string",
+                        "default": "updated",
+                        "description": "Sort order",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.BookmarkResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/bookmarks/{mangaID}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "bookmark"
+                ],
+                "summary": "Get bookmark for a manga",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Manga ID",
+                        "name": "mangaID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BookmarkResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "bookmark"
+                ],
+                "summary": "Create or update bookmark",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Manga ID",
+                        "name": "mangaID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Bookmark data",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UpsertBookmarkRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BookmarkResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "bookmark"
+                ],
+                "summary": "Delete bookmark",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Manga ID",
+                        "name": "mangaID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/lists": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "list"
+                ],
+                "summary": "List my reading lists",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.ReadingListResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "list"
+                ],
+                "summary": "Create a reading list",
+                "parameters": [
+                    {
+                        "description": "List",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateReadingListRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ReadingListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/lists/{listID}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "list"
+                ],
+                "summary": "Delete a reading list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "List ID",
+                        "name": "listID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "list"
+                ],
+                "summary": "Update a reading list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "List ID",
+                        "name": "listID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to update",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UpdateReadingListRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ReadingListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/lists/{listID}/items/reorder": {
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "list"
+                ],
+                "summary": "Reorder a reading list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "List ID",
+                        "name": "listID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Every manga of the list, in the new order",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ReorderReadingListRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/lists/{listID}/items/{mangaID}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "list"
+                ],
+                "summary": "Add a manga to a reading list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "List ID",
+                        "name": "listID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Manga ID",
+                        "name": "mangaID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "list"
+                ],
+                "summary": "Remove a manga from a reading list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "List ID",
+                        "name": "listID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Manga ID",
+                        "name": "mangaID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/notifications": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notification"
+                ],
+                "summary": "List my notifications",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Only unread notifications",
+                        "name": "unread",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PagedNotificationResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/notifications/preferences": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notification"
+                ],
+                "summary": "Get my notification preferences",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.NotificationPreferencesResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notification"
+                ],
+                "summary": "Turn notification types on or off",
+                "parameters": [
+                    {
+                        "description": "Types to change",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.NotificationPreferencesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.NotificationPreferencesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/notifications/read-all": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notification"
+                ],
+                "summary": "Mark all my notifications read",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.MarkAllReadResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/notifications/unread-count": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notification"
+                ],
+                "summary": "Count my unread notifications",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UnreadCountResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/notifications/{notificationID}/read": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "notification"
+                ],
+                "summary": "Mark a notification read",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Notification ID",
+                        "name": "notificationID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/tasks": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "upload"
+                ],
+                "summary": "List my upload and export tasks, newest first",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "pending | processing | done | failed | cancelled",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "zip | oneshot_zip | series_zip | export_cbz | export_epub",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Manga ID",
+                        "name": "manga_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PagedUploadTaskResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "summary": "List my webhooks",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.WebhookResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Deliveries are POSTed as JSON and signed with the secret in the X-Webhook-Signature\nheader (\"t=\u003cunix time\u003e,v1=\u003chex HMAC-SHA256 of \"\u003ct\u003e.\u003cbody\u003e\"\u003e\"). upload_task events are\nonly sent for your own tasks. The secret is only returned here and when it changes.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "summary": "Register a webhook",
+                "parameters": [
+                    {
+                        "description": "Webhook",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateWebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.WebhookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Webhook limit reached",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/webhooks/{webhookID}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "summary": "Delete a webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "webhookID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "summary": "Update a webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "webhookID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to change",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UpdateWebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.WebhookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/webhooks/{webhookID}/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "summary": "List a webhook's deliveries, newest first",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "webhookID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PagedWebhookDeliveryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/webhooks/{webhookID}/ping": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Queues a \"ping\" event for the webhook; check its deliveries for the result.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "summary": "Send a test delivery",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "webhookID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/dto.WebhookDeliveryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{userID}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Get public user profile",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PublicUserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{userID}/library": {
+            "get": {
+                "description": "Only available when the user made their library public (library_public). Notes are omitted.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "bookmark"
+                ],
+                "summary": "List a user's library",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "reading",
+                            "plan_to_read",
+                            "completed",
+                            "dropped",
+                            "on_hold"
+                        ],
+                        "type": "string",
+                        "description": "Filter by status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "updated",
+                            "added",
+                            "title",
+                            "score",
+                            "status"
+                        ],
+                        "type": "string",
+                        "default": "updated",
+                        "description": "Sort order",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.BookmarkResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{userID}/lists": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "list"
+                ],
+                "summary": "List a user's public reading lists",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.ReadingListResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{userID}/mangas": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "manga"
+                ],
+                "summary": "List manga by user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PagedMangaResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "dto.AuthResponse": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                },
+                "refresh_token": {
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/dto.UserResponse"
+                }
+            }
+        },
+        "dto.BookmarkResponse": {
+            "type": "object",
+            "properties": {
+                "chapter_id": {
+                    "description": "null until reading starts",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "description": "YYYY-MM-DD",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_page_number": {
+                    "type": "integer"
+                },
+                "manga": {
+                    "description": "set when listing",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.MangaResponse"
+                        }
+                    ]
+                },
+                "manga_id": {
+                    "type": "string"
+                },
+                "notes": {
+                    "description": "only shown to the owner",
+                    "type": "string"
+                },
+                "score": {
+                    "type": "integer"
+                },
+                "started_at": {
+                    "description": "YYYY-MM-DD",
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/model.BookmarkStatus"
+                },
+                "unread_count": {
+                    "description": "published chapters not yet finished; set when listing",
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ChapterManifestResponse": {
+            "type": "object",
+            "properties": {
+                "chapter": {
+                    "$ref": "#/definitions/dto.ChapterResponse"
+                },
+                "next": {
+                    "$ref": "#/definitions/dto.ChapterRefResponse"
+                },
+                "pages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ManifestPageResponse"
+                    }
+                },
+                "prev": {
+                    "$ref": "#/definitions/dto.ChapterRefResponse"
+                }
+            }
+        },
+        "dto.ChapterRefResponse": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "language": {
+                    "type": "string"
+                },
+                "number": {
+                    "type": "number"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "volume": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.ChapterResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "language": {
+                    "type": "string"
+                },
+                "manga_id": {
+                    "type": "string"
+                },
+                "number": {
+                    "type": "number"
+                },
+                "page_count": {
+                    "type": "integer"
+                },
+                "read_state": {
+                    "description": "ReadState is the viewer's progress; only set for signed-in chapter lists.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.ReadStateResponse"
+                        }
+                    ]
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "volume": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.ChapterWithPagesResponse": {
+            "type": "object",
+            "properties": {
+                "chapter": {
+                    "$ref": "#/definitions/dto.ChapterResponse"
+                },
+                "next": {
+                    "description": "null at the last chapter",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.ChapterRefResponse"
+                        }
+                    ]
+                },
+                "next_prefetch": {
+                    "description": "NextPrefetch holds the first pages of Next when ?prefetch= asks for them.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.PageItemResponse"
+                    }
+                },
+                "pages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.PageItemResponse"
+                    }
+                },
+                "prev": {
+                    "description": "null at the first chapter",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.ChapterRefResponse"
+                        }
+                    ]
+                }
+            }
+        },
+        "dto.CommentAuthor": {
+            "type": "object",
+            "properties": {
+                "avatar_url": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.CommentResponse": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "$ref": "#/definitions/dto.CommentAuthor"
+                },
+                "content": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "edited": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "parent_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.CreateChapterRequest": {
+            "type": "object",
+            "properties": {
+                "language": {
+                    "description": "ISO 639-1 code, e.g. \"en\"; empty when unspecified",
+                    "type": "string"
+                },
+                "number": {
+                    "type": "number"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "volume": {
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
+        "dto.CreateCommentRequest": {
+            "type": "object",
+            "required": [
+                "content"
+            ],
+            "properties": {
+                "content": {
+                    "type": "string",
+                    "maxLength": 2000,
+                    "minLength": 1
+                },
+                "parent_id": {
+                    "description": "reply to this comment in the same thread",
+                    "type": "string"
+                }
+            }
+        },
+        "dto.CreateDirectUploadRequest": {
+            "type": "object",
+            "required": [
+                "checksum_sha256",
+                "manga_id",
+                "size",
+                "type"
+            ],
+            "properties": {
+                "apply_metadata": {
+                    "type": "boolean"
+                },
+                "chapter_id": {
+                    "description": "required for zip",
+                    "type": "string"
+                },
+                "checksum_sha256": {
+                    "description": "of the whole archive",
+                    "type": "string"
+                },
+                "filename": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "manga_id": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "type": {
+                    "enum": [
+                        "zip",
+                        "oneshot_zip",
+                        "series_zip"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.UploadTaskType"
+                        }
+                    ]
+                }
+            }
+        },
+        "dto.CreateMangaRequest": {
+            "type": "object",
+            "required": [
+                "title"
+            ],
+            "properties": {
+                "artist": {
+                    "type": "string"
+                },
+                "author": {
+                    "type": "string"
+                },
+                "category": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/model.MangaStatus"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "type": "string",
+                    "minLength": 1
+                },
+                "type": {
+                    "$ref": "#/definitions/model.MangaType"
+                }
+            }
+        },
+        "dto.CreateReadingListRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 2000
+                },
+                "is_public": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 1
+                }
+            }
+        },
+        "dto.CreateWebhookRequest": {
+            "type": "object",
+            "required": [
+                "events",
+                "url"
+            ],
+            "properties": {
+                "events": {
+                    "description": "manga.created | manga.updated | chapter.published | upload_task.done | upload_task.failed",
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/model.WebhookEvent"
+                    }
+                },
+                "secret": {
+                    "description": "generated when empty",
+                    "type": "string",
+                    "maxLength": 200,
+                    "minLength": 16
+                },
+                "url": {
+                    "type": "string",
+                    "maxLength": 2000
+                }
+            }
+        },
+        "dto.CropRequest": {
+            "type": "object",
+            "properties": {
+                "height": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "width": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "x": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "y": {
+                    "type": "integer",
+                    "minimum": 0
                 }
             }
-        }
-    },
-    "definitions": {
-        "dto.AuthResponse": {
+        },
+        "dto.DirectUploadPartURL": {
             "type": "object",
             "properties": {
-                "access_token": {
-                    "type": "string"
+                "number": {
+                    "type": "integer"
                 },
-                "refresh_token": {
+                "url": {
                     "type": "string"
-                },
-                "user": {
-                    "$ref": "#/definitions/dto.UserResponse"
                 }
             }
         },
-        "dto.BookmarkResponse": {
+        "dto.DirectUploadResponse": {
             "type": "object",
             "properties": {
-                "chapter_id": {
+                "expires_at": {
                     "type": "string"
                 },
-                "created_at": {
+                "id": {
                     "type": "string"
                 },
-                "id": {
+                "method": {
+                    "description": "put or multipart",
                     "type": "string"
                 },
-                "last_page_number": {
+                "part_size": {
                     "type": "integer"
                 },
-                "manga_id": {
-                    "type": "string"
+                "parts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.DirectUploadPartURL"
+                    }
                 },
-                "updated_at": {
+                "url": {
                     "type": "string"
                 }
             }
         },
-        "dto.ChapterResponse": {
+        "dto.DuplicateChapterRef": {
             "type": "object",
             "properties": {
-                "created_at": {
+                "chapter_id": {
                     "type": "string"
                 },
-                "id": {
+                "manga_id": {
                     "type": "string"
                 },
-                "manga_id": {
+                "manga_title": {
                     "type": "string"
                 },
                 "number": {
                     "type": "number"
-                },
-                "page_count": {
-                    "type": "integer"
-                },
-                "title": {
-                    "type": "string"
-                },
-                "updated_at": {
-                    "type": "string"
                 }
             }
         },
-        "dto.ChapterWithPagesResponse": {
+        "dto.DuplicateChapterResponse": {
             "type": "object",
             "properties": {
                 "chapter": {
-                    "$ref": "#/definitions/dto.ChapterResponse"
+                    "$ref": "#/definitions/dto.DuplicateChapterRef"
                 },
-                "pages": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/dto.PageItemResponse"
-                    }
+                "cross_library": {
+                    "description": "the chapters belong to different mangas",
+                    "type": "boolean"
+                },
+                "other": {
+                    "$ref": "#/definitions/dto.DuplicateChapterRef"
+                },
+                "shared_pages": {
+                    "type": "integer"
+                },
+                "similarity": {
+                    "description": "shared pages / pages of the shorter chapter, 0–1",
+                    "type": "number"
                 }
             }
         },
-        "dto.CommentAuthor": {
+        "dto.DuplicateReportResponse": {
             "type": "object",
             "properties": {
-                "avatar_url": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "string"
-                },
-                "username": {
-                    "type": "string"
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.DuplicateChapterResponse"
+                    }
                 }
             }
         },
-        "dto.CommentResponse": {
+        "dto.EnqueueResponse": {
             "type": "object",
             "properties": {
-                "author": {
-                    "$ref": "#/definitions/dto.CommentAuthor"
-                },
-                "content": {
-                    "type": "string"
-                },
-                "created_at": {
-                    "type": "string"
-                },
-                "edited": {
-                    "type": "boolean"
-                },
-                "id": {
-                    "type": "string"
-                },
-                "updated_at": {
+                "task_id": {
                     "type": "string"
                 }
             }
         },
-        "dto.CreateChapterRequest": {
+        "dto.ErrorResponse": {
             "type": "object",
             "properties": {
-                "number": {
-                    "type": "number"
-                },
-                "title": {
+                "error": {
                     "type": "string"
                 }
             }
         },
-        "dto.CreateCommentRequest": {
+        "dto.ExportRequest": {
             "type": "object",
             "required": [
-                "content"
+                "format"
             ],
             "properties": {
-                "content": {
+                "format": {
                     "type": "string",
-                    "maxLength": 2000,
-                    "minLength": 1
+                    "enum": [
+                        "cbz",
+                        "epub"
+                    ]
                 }
             }
         },
-        "dto.CreateMangaRequest": {
+        "dto.LatestReleasesResponse": {
             "type": "object",
-            "required": [
-                "title"
-            ],
             "properties": {
-                "artist": {
-                    "type": "string"
-                },
-                "author": {
-                    "type": "string"
-                },
-                "category": {
-                    "type": "string"
-                },
-                "description": {
-                    "type": "string"
-                },
-                "status": {
-                    "$ref": "#/definitions/model.MangaStatus"
-                },
-                "tags": {
+                "items": {
                     "type": "array",
                     "items": {
-                        "type": "string"
+                        "$ref": "#/definitions/dto.ReleaseGroupResponse"
                     }
                 },
-                "title": {
-                    "type": "string",
-                    "minLength": 1
-                },
-                "type": {
-                    "$ref": "#/definitions/model.MangaType"
-                }
-            }
-        },
-        "dto.EnqueueResponse": {
-            "type": "object",
-            "properties": {
-                "task_id": {
-                    "type": "string"
-                }
-            }
-        },
-        "dto.ErrorResponse": {
-            "type": "object",
-            "properties": {
-                "error": {
+                "next_cursor": {
                     "type": "string"
                 }
             }
@@ -2149,6 +5257,9 @@ const docTemplate = `{
                 "password"
             ],
             "properties": {
+                "device_id": {
+                    "type": "string"
+                },
                 "email": {
                     "type": "string"
                 },
@@ -2180,6 +5291,10 @@ const docTemplate = `{
                 "category": {
                     "type": "string"
                 },
+                "cover_srcset": {
+                    "description": "\"\u003curl\u003e 160w, \u003curl\u003e 320w, ...\"; empty for older covers",
+                    "type": "string"
+                },
                 "cover_url": {
                     "type": "string"
                 },
@@ -2192,9 +5307,19 @@ const docTemplate = `{
                 "id": {
                     "type": "string"
                 },
+                "last_chapter_at": {
+                    "type": "string"
+                },
                 "owner_id": {
                     "type": "string"
                 },
+                "rating_avg": {
+                    "description": "mean 1–10 score, 0 when unrated",
+                    "type": "number"
+                },
+                "rating_count": {
+                    "type": "integer"
+                },
                 "slug": {
                     "type": "string"
                 },
@@ -2213,8 +5338,153 @@ const docTemplate = `{
                 "type": {
                     "$ref": "#/definitions/model.MangaType"
                 },
-                "updated_at": {
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ManifestPageResponse": {
+            "type": "object",
+            "properties": {
+                "height": {
+                    "type": "integer"
+                },
+                "number": {
+                    "type": "integer"
+                },
+                "width": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.MarkAllReadResponse": {
+            "type": "object",
+            "properties": {
+                "marked": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.MarkReadUpToRequest": {
+            "type": "object",
+            "required": [
+                "up_to"
+            ],
+            "properties": {
+                "language": {
+                    "description": "as ?lang= on the chapter list",
+                    "type": "string"
+                },
+                "up_to": {
+                    "description": "chapter number, inclusive",
+                    "type": "number"
+                }
+            }
+        },
+        "dto.MarkReadUpToResponse": {
+            "type": "object",
+            "properties": {
+                "marked": {
+                    "description": "chapters newly marked read",
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.NotificationActor": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.NotificationChapter": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "number": {
+                    "type": "number"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.NotificationPreferencesRequest": {
+            "type": "object",
+            "properties": {
+                "comment_mention": {
+                    "type": "boolean"
+                },
+                "comment_reply": {
+                    "type": "boolean"
+                },
+                "new_chapter": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "dto.NotificationPreferencesResponse": {
+            "type": "object",
+            "properties": {
+                "comment_mention": {
+                    "type": "boolean"
+                },
+                "comment_reply": {
+                    "type": "boolean"
+                },
+                "new_chapter": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "dto.NotificationResponse": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "description": "omitted once the account is deleted",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.NotificationActor"
+                        }
+                    ]
+                },
+                "chapter": {
+                    "$ref": "#/definitions/dto.NotificationChapter"
+                },
+                "comment_excerpt": {
+                    "type": "string"
+                },
+                "comment_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "manga_id": {
+                    "type": "string"
+                },
+                "manga_title": {
                     "type": "string"
+                },
+                "read": {
+                    "type": "boolean"
+                },
+                "type": {
+                    "description": "new_chapter | comment_reply | comment_mention",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.NotificationType"
+                        }
+                    ]
                 }
             }
         },
@@ -2230,6 +5500,10 @@ const docTemplate = `{
                 "number": {
                     "type": "integer"
                 },
+                "thumbnail_url": {
+                    "description": "small WebP for chapter scrubbers",
+                    "type": "string"
+                },
                 "url": {
                     "type": "string"
                 },
@@ -2292,6 +5566,106 @@ const docTemplate = `{
                 }
             }
         },
+        "dto.PagedNotificationResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.NotificationResponse"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.PagedReadingListResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ReadingListResponse"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.PagedReviewResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ReviewResponse"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.PagedUploadTaskResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.UploadTaskResponse"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.PagedWebhookDeliveryResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.WebhookDeliveryResponse"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
         "dto.PublicUserResponse": {
             "type": "object",
             "properties": {
@@ -2312,6 +5686,126 @@ const docTemplate = `{
                 }
             }
         },
+        "dto.RateMangaRequest": {
+            "type": "object",
+            "required": [
+                "score"
+            ],
+            "properties": {
+                "review": {
+                    "description": "optional; empty removes the review",
+                    "type": "string",
+                    "maxLength": 10000
+                },
+                "score": {
+                    "type": "integer",
+                    "maximum": 10,
+                    "minimum": 1
+                }
+            }
+        },
+        "dto.RatingResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "helpful_count": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "manga_id": {
+                    "type": "string"
+                },
+                "review": {
+                    "type": "string"
+                },
+                "score": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ReadStateResponse": {
+            "type": "object",
+            "properties": {
+                "completed_at": {
+                    "type": "string"
+                },
+                "last_page_number": {
+                    "type": "integer"
+                },
+                "progress": {
+                    "description": "percent of pages, 0–100",
+                    "type": "integer"
+                },
+                "read": {
+                    "description": "finished",
+                    "type": "boolean"
+                }
+            }
+        },
+        "dto.ReadingListDetailResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "$ref": "#/definitions/dto.PagedMangaResponse"
+                },
+                "list": {
+                    "$ref": "#/definitions/dto.ReadingListResponse"
+                }
+            }
+        },
+        "dto.ReadingListResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "is_public": {
+                    "type": "boolean"
+                },
+                "item_count": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "owner_id": {
+                    "type": "string"
+                },
+                "slug": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.RecordReadRequest": {
+            "type": "object",
+            "properties": {
+                "completed": {
+                    "description": "mark finished regardless of page_number",
+                    "type": "boolean"
+                },
+                "page_number": {
+                    "description": "last page reached",
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
         "dto.RefreshRequest": {
             "type": "object",
             "required": [
@@ -2331,6 +5825,9 @@ const docTemplate = `{
                 "username"
             ],
             "properties": {
+                "device_id": {
+                    "type": "string"
+                },
                 "email": {
                     "type": "string"
                 },
@@ -2345,6 +5842,20 @@ const docTemplate = `{
                 }
             }
         },
+        "dto.ReleaseGroupResponse": {
+            "type": "object",
+            "properties": {
+                "chapters": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ChapterResponse"
+                    }
+                },
+                "manga": {
+                    "$ref": "#/definitions/dto.MangaResponse"
+                }
+            }
+        },
         "dto.ReorderPagesRequest": {
             "type": "object",
             "required": [
@@ -2360,6 +5871,70 @@ const docTemplate = `{
                 }
             }
         },
+        "dto.ReorderReadingListRequest": {
+            "type": "object",
+            "required": [
+                "manga_ids"
+            ],
+            "properties": {
+                "manga_ids": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "dto.ReviewResponse": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "$ref": "#/definitions/dto.CommentAuthor"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "helpful_count": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "review": {
+                    "type": "string"
+                },
+                "score": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "voted_helpful": {
+                    "description": "by the viewer",
+                    "type": "boolean"
+                }
+            }
+        },
+        "dto.SetCoverFromPageRequest": {
+            "type": "object",
+            "required": [
+                "chapter_id",
+                "page_number"
+            ],
+            "properties": {
+                "chapter_id": {
+                    "type": "string"
+                },
+                "crop": {
+                    "$ref": "#/definitions/dto.CropRequest"
+                },
+                "page_number": {
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
         "dto.TokenPairResponse": {
             "type": "object",
             "properties": {
@@ -2371,14 +5946,30 @@ const docTemplate = `{
                 }
             }
         },
+        "dto.UnreadCountResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                }
+            }
+        },
         "dto.UpdateChapterRequest": {
             "type": "object",
             "properties": {
+                "language": {
+                    "type": "string"
+                },
                 "number": {
                     "type": "number"
                 },
                 "title": {
                     "type": "string"
+                },
+                "volume": {
+                    "description": "0 clears the volume",
+                    "type": "integer",
+                    "minimum": 0
                 }
             }
         },
@@ -2427,26 +6018,142 @@ const docTemplate = `{
                 }
             }
         },
+        "dto.UpdateReadingListRequest": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 2000
+                },
+                "is_public": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 1
+                }
+            }
+        },
         "dto.UpdateUserRequest": {
             "type": "object",
             "properties": {
                 "bio": {
                     "type": "string"
                 },
+                "library_public": {
+                    "description": "let others see GET /users/:id/library",
+                    "type": "boolean"
+                },
+                "preferred_language": {
+                    "description": "ISO 639-1 code; \"\" shows chapters in all languages",
+                    "type": "string"
+                },
                 "username": {
                     "type": "string"
                 }
             }
         },
+        "dto.UpdateWebhookRequest": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.WebhookEvent"
+                    }
+                },
+                "secret": {
+                    "description": "\"\" generates a new one",
+                    "type": "string",
+                    "maxLength": 200
+                },
+                "url": {
+                    "type": "string",
+                    "maxLength": 2000
+                }
+            }
+        },
+        "dto.UploadSessionResponse": {
+            "type": "object",
+            "properties": {
+                "apply_metadata": {
+                    "type": "boolean"
+                },
+                "chapter_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "filename": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "manga_id": {
+                    "type": "string"
+                },
+                "offset": {
+                    "description": "bytes received so far",
+                    "type": "integer"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "task_id": {
+                    "description": "once the upload is complete",
+                    "type": "string"
+                },
+                "type": {
+                    "$ref": "#/definitions/model.UploadTaskType"
+                }
+            }
+        },
+        "dto.UploadTaskEventResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "processed": {
+                    "type": "integer"
+                },
+                "status": {
+                    "$ref": "#/definitions/model.UploadTaskStatus"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
         "dto.UploadTaskResponse": {
             "type": "object",
             "properties": {
+                "apply_metadata": {
+                    "type": "boolean"
+                },
+                "attempts": {
+                    "description": "times a worker picked the task up",
+                    "type": "integer"
+                },
                 "chapter_id": {
                     "type": "string"
                 },
                 "created_at": {
                     "type": "string"
                 },
+                "download_url": {
+                    "description": "presigned; export tasks once done",
+                    "type": "string"
+                },
                 "error": {
                     "type": "string"
                 },
@@ -2456,30 +6163,82 @@ const docTemplate = `{
                 "manga_id": {
                     "type": "string"
                 },
+                "metadata_report": {
+                    "description": "apply_metadata tasks once processed",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.MetadataReport"
+                        }
+                    ]
+                },
+                "processed": {
+                    "description": "archive pages stored so far",
+                    "type": "integer"
+                },
+                "results": {
+                    "description": "series_zip only",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ChapterResult"
+                    }
+                },
                 "status": {
                     "$ref": "#/definitions/model.UploadTaskStatus"
                 },
+                "total": {
+                    "description": "archive pages; 0 until the worker has read the archive",
+                    "type": "integer"
+                },
                 "type": {
                     "$ref": "#/definitions/model.UploadTaskType"
                 },
                 "updated_at": {
                     "type": "string"
+                },
+                "warnings": {
+                    "description": "skipped files, ambiguous page order",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
         "dto.UpsertBookmarkRequest": {
             "type": "object",
-            "required": [
-                "chapter_id",
-                "last_page_number"
-            ],
             "properties": {
                 "chapter_id": {
                     "type": "string"
                 },
+                "finished_at": {
+                    "description": "YYYY-MM-DD; \"\" clears it",
+                    "type": "string"
+                },
                 "last_page_number": {
                     "type": "integer",
                     "minimum": 1
+                },
+                "notes": {
+                    "type": "string",
+                    "maxLength": 5000
+                },
+                "score": {
+                    "description": "0 clears it",
+                    "type": "integer",
+                    "maximum": 10,
+                    "minimum": 0
+                },
+                "started_at": {
+                    "description": "YYYY-MM-DD; \"\" clears it",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "reading | plan_to_read | completed | dropped | on_hold",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.BookmarkStatus"
+                        }
+                    ]
                 }
             }
         },
@@ -2501,6 +6260,12 @@ const docTemplate = `{
                 "id": {
                     "type": "string"
                 },
+                "library_public": {
+                    "type": "boolean"
+                },
+                "preferred_language": {
+                    "type": "string"
+                },
                 "updated_at": {
                     "type": "string"
                 },
@@ -2509,6 +6274,133 @@ const docTemplate = `{
                 }
             }
         },
+        "dto.WebhookDeliveryResponse": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "delivered_at": {
+                    "type": "string"
+                },
+                "event": {
+                    "$ref": "#/definitions/model.WebhookEvent"
+                },
+                "event_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "next_attempt_at": {
+                    "description": "set while pending",
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "response_status": {
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "pending | succeeded | failed",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.WebhookDeliveryStatus"
+                        }
+                    ]
+                }
+            }
+        },
+        "dto.WebhookResponse": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.WebhookEvent"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "secret": {
+                    "description": "only returned when created or changed",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.BookmarkStatus": {
+            "type": "string",
+            "enum": [
+                "reading",
+                "plan_to_read",
+                "completed",
+                "dropped",
+                "on_hold"
+            ],
+            "x-enum-varnames": [
+                "BookmarkReading",
+                "BookmarkPlanToRead",
+                "BookmarkCompleted",
+                "BookmarkDropped",
+                "BookmarkOnHold"
+            ]
+        },
+        "model.ChapterResult": {
+            "type": "object",
+            "properties": {
+                "chapter_id": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "folder": {
+                    "type": "string"
+                },
+                "language": {
+                    "type": "string"
+                },
+                "number": {
+                    "type": "number"
+                },
+                "page_count": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "volume": {
+                    "type": "integer"
+                },
+                "warnings": {
+                    "description": "skipped files, ambiguous page order",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
         "model.MangaStatus": {
             "type": "string",
             "enum": [
@@ -2533,43 +6425,150 @@ const docTemplate = `{
                 "TypeOneshot"
             ]
         },
+        "model.MetadataChange": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "new": {},
+                "old": {},
+                "reason": {
+                    "description": "conflicts only",
+                    "type": "string"
+                },
+                "target": {
+                    "description": "\"manga\" or \"chapter\"",
+                    "type": "string"
+                }
+            }
+        },
+        "model.MetadataReport": {
+            "type": "object",
+            "properties": {
+                "applied": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.MetadataChange"
+                    }
+                },
+                "conflicts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.MetadataChange"
+                    }
+                }
+            }
+        },
+        "model.NotificationType": {
+            "type": "string",
+            "enum": [
+                "new_chapter",
+                "comment_reply",
+                "comment_mention"
+            ],
+            "x-enum-comments": {
+                "NotificationCommentMention": "someone @mentioned the user in a comment",
+                "NotificationCommentReply": "someone replied to the user's comment",
+                "NotificationNewChapter": "a bookmarked manga published a chapter"
+            },
+            "x-enum-varnames": [
+                "NotificationNewChapter",
+                "NotificationCommentReply",
+                "NotificationCommentMention"
+            ]
+        },
         "model.UploadTaskStatus": {
             "type": "string",
             "enum": [
                 "pending",
                 "processing",
                 "done",
-                "failed"
+                "failed",
+                "cancelled"
             ],
+            "x-enum-comments": {
+                "UploadTaskStatusCancelled": "by the owner, before a worker claimed it"
+            },
             "x-enum-varnames": [
                 "UploadTaskStatusPending",
                 "UploadTaskStatusProcessing",
                 "UploadTaskStatusDone",
-                "UploadTaskStatusFailed"
+                "UploadTaskStatusFailed",
+                "UploadTaskStatusCancelled"
             ]
         },
         "model.UploadTaskType": {
             "type": "string",
             "enum": [
                 "zip",
-                "oneshot_zip"
+                "oneshot_zip",
+                "series_zip",
+                "export_cbz",
+                "export_epub"
             ],
             "x-enum-varnames": [
                 "UploadTaskTypeZip",
-                "UploadTaskTypeOneshotZip"
+                "UploadTaskTypeOneshotZip",
+                "UploadTaskTypeSeriesZip",
+                "UploadTaskTypeExportCBZ",
+                "UploadTaskTypeExportEPUB"
+            ]
+        },
+        "model.WebhookDeliveryStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "succeeded",
+                "failed"
+            ],
+            "x-enum-comments": {
+                "WebhookDeliveryFailed": "gave up after the last retry"
+            },
+            "x-enum-varnames": [
+                "WebhookDeliveryPending",
+                "WebhookDeliverySucceeded",
+                "WebhookDeliveryFailed"
             ]
+        },
+        "model.WebhookEvent": {
+            "type": "string",
+            "enum": [
+                "manga.created",
+                "manga.updated",
+                "chapter.published",
+                "upload_task.done",
+                "upload_task.failed",
+                "ping"
+            ],
+            "x-enum-varnames": [
+                "WebhookMangaCreated",
+                "WebhookMangaUpdated",
+                "WebhookChapterPublished",
+                "WebhookUploadTaskDone",
+                "WebhookUploadTaskFailed",
+                "WebhookPing"
+            ]
+        }
+    },
+    "securityDefinitions": {
+        "BearerAuth": {
+            "description": "Type \"Bearer\" followed by a space and JWT access token.",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     }
 }`
 
 // SwaggerInfo holds exported Swagger Info so clients can modify it
 var SwaggerInfo = &swag.Spec{
-	Version:          "",
-	Host:             "",
-	BasePath:         "",
+	Version:          "1.0.0",
+	Host:             "localhost:8080",
+	BasePath:         "/api/v1",
 	Schemes:          []string{},
-	Title:            "",
-	Description:      "",
+	Title:            "Sherry Archive API",
+	Description:      "Manga reading platform — browse, upload, and track manga.",
 	InfoInstanceName: "swagger",
 	SwaggerTemplate:  docTemplate,
 	LeftDelim:        "{{",
@@ -8,6 +8,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/yumikokawaii/sherry-archive/internal/dto"
 	"github.com/yumikokawaii/sherry-archive/internal/metrics"
+	"github.com/yumikokawaii/sherry-archive/internal/model"
 	"github.com/yumikokawaii/sherry-archive/pkg/urlcache"
 )
 
@@ -43,20 +44,16 @@ func (h *Handler) Trending(c *gin.Context) {
 		TrendingScore float64 `json:"trending_score"`
 	}
 
-	keys := make([]string, len(results))
+	mangas := make([]*model.Manga, len(results))
 	for i, r := range results {
-		keys[i] = r.Manga.CoverKey
+		mangas[i] = r.Manga
 	}
-	urls, _ := h.urlCache.ResolveMany(c.Request.Context(), keys)
+	responses := dto.NewMangaResponses(c.Request.Context(), h.urlCache, mangas)
 
 	out := make([]trendingItem, 0, len(results))
 	for i, r := range results {
-		url := ""
-		if i < len(urls) {
-			url = urls[i]
-		}
 		out = append(out, trendingItem{
-			MangaResponse: dto.NewMangaResponse(r.Manga, url),
+			MangaResponse: responses[i],
 			TrendingScore: r.Score,
 		})
 	}
@@ -94,20 +91,7 @@ func (h *Handler) Suggestions(c *gin.Context) {
 		return
 	}
 
-	keys := make([]string, len(mangas))
-	for i, m := range mangas {
-		keys[i] = m.CoverKey
-	}
-	urls, _ := h.urlCache.ResolveMany(c.Request.Context(), keys)
-
-	out := make([]dto.MangaResponse, 0, len(mangas))
-	for i, m := range mangas {
-		url := ""
-		if i < len(urls) {
-			url = urls[i]
-		}
-		out = append(out, dto.NewMangaResponse(m, url))
-	}
+	out := dto.NewMangaResponses(c.Request.Context(), h.urlCache, mangas)
 
 	c.JSON(http.StatusOK, gin.H{"data": out})
 }
@@ -128,20 +112,7 @@ func (h *Handler) Similar(c *gin.Context) {
 		return
 	}
 
-	keys := make([]string, len(mangas))
-	for i, m := range mangas {
-		keys[i] = m.CoverKey
-	}
-	urls, _ := h.urlCache.ResolveMany(c.Request.Context(), keys)
-
-	out := make([]dto.MangaResponse, 0, len(mangas))
-	for i, m := range mangas {
-		url := ""
-		if i < len(urls) {
-			url = urls[i]
-		}
-		out = append(out, dto.NewMangaResponse(m, url))
-	}
+	out := dto.NewMangaResponses(c.Request.Context(), h.urlCache, mangas)
 
 	c.JSON(http.StatusOK, gin.H{"data": out})
 }
@@ -10,6 +10,11 @@ var interestPoints = map[string]float64{
 	"comment_post":     4,
 	"bookmark_add":     8,
 	"bookmark_remove":  -3,
+	"list_add":         6,
+	"list_remove":      -2,
+	// Ratings are sent by score: 7–10 positive, 1–4 negative, 5–6 not at all.
+	"rating_positive": 10,
+	"rating_negative": -5,
 }
 
 // trendingPoints maps event names to the score increment applied to the
@@ -0,0 +1,62 @@
+package analytics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yumikokawaii/sherry-archive/internal/tracking"
+)
+
+type nopStore struct{}
+
+func (nopStore) Insert(context.Context, []tracking.EventRow) error { return nil }
+
+// enricherFunc hands recorded events to the test.
+type enricherFunc func([]tracking.EventRow)
+
+func (f enricherFunc) ProcessEvents(_ context.Context, events []tracking.EventRow) { f(events) }
+
+func TestRatingEventPoints(t *testing.T) {
+	rows := make(chan tracking.EventRow, 1)
+	recorder := tracking.NewRecorder(nopStore{}, enricherFunc(func(events []tracking.EventRow) {
+		for _, e := range events {
+			rows <- e
+		}
+	}))
+	userID := uuid.New()
+
+	for score := 1; score <= 10; score++ {
+		event := tracking.RatingEvent(score)
+		if score == 5 || score == 6 {
+			if event != "" {
+				t.Errorf("RatingEvent(%d) = %q, want none for a neutral score", score, event)
+			}
+			continue
+		}
+
+		recorder.TrackUser(userID, event, map[string]any{"manga_id": uuid.NewString(), "score": score})
+		var row tracking.EventRow
+		select {
+		case row = <-rows:
+		case <-time.After(time.Second):
+			t.Fatalf("score %d: event never reached the enricher", score)
+		}
+		if row.DeviceID != userID || row.UserID == nil || *row.UserID != userID {
+			t.Errorf("score %d: event recorded for device %s, user %v; want the rating user", score, row.DeviceID, row.UserID)
+		}
+		if extractMangaID(row) == "" {
+			t.Errorf("score %d: event has no manga_id", score)
+		}
+		pts, ok := interestPoints[row.Event]
+		switch {
+		case !ok:
+			t.Errorf("score %d: %q has no interest points", score, row.Event)
+		case score >= 7 && pts <= 0:
+			t.Errorf("score %d: %q = %v points, want positive", score, row.Event, pts)
+		case score <= 4 && pts >= 0:
+			t.Errorf("score %d: %q = %v points, want negative", score, row.Event, pts)
+		}
+	}
+}
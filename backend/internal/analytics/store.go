@@ -219,6 +219,22 @@ func (s *Store) GetTrending(ctx context.Context, limit int) ([]*TrendingResult,
 	return results, nil
 }
 
+// TrendingIDs returns the ids of the top trending manga, highest score first.
+// Used to rank the manga list (sort=trending) in SQL.
+func (s *Store) TrendingIDs(ctx context.Context, limit int) ([]uuid.UUID, error) {
+	members, err := s.rdb.ZRevRange(ctx, trendingKey, 0, int64(limit-1)).Result()
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uuid.UUID, 0, len(members))
+	for _, m := range members {
+		if id, err := uuid.Parse(m); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
 // --- Suggestions ---
 
 const interestCacheTTL = 24 * time.Hour
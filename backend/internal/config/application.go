@@ -3,20 +3,30 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 )
 
 // Application holds all runtime configuration for the server.
 type Application struct {
-	Server     *ServerConfig     `json:"server" mapstructure:"server" yaml:"server"`
-	DB         *DBConfig         `json:"db"     mapstructure:"db"     yaml:"db"`
-	JWT        *JWTConfig        `json:"jwt"    mapstructure:"jwt"    yaml:"jwt"`
-	S3         *S3Config         `json:"s3"     mapstructure:"s3"     yaml:"s3"`
-	Redis      *RedisConfig      `json:"redis"  mapstructure:"redis"  yaml:"redis"`
-	SQS        *SQSConfig        `json:"sqs"        mapstructure:"sqs"        yaml:"sqs"`
-	Analytics  *AnalyticsConfig  `json:"analytics"  mapstructure:"analytics"  yaml:"analytics"`
-	CloudFront *CloudFrontConfig `json:"cloudfront" mapstructure:"cloudfront" yaml:"cloudfront"`
-	Tracing    *TracingConfig    `json:"tracing"    mapstructure:"tracing"    yaml:"tracing"`
-	Metrics    *MetricsConfig    `json:"metrics"    mapstructure:"metrics"    yaml:"metrics"`
+	Server        *ServerConfig        `json:"server" mapstructure:"server" yaml:"server"`
+	DB            *DBConfig            `json:"db"     mapstructure:"db"     yaml:"db"`
+	JWT           *JWTConfig           `json:"jwt"    mapstructure:"jwt"    yaml:"jwt"`
+	S3            *S3Config            `json:"s3"     mapstructure:"s3"     yaml:"s3"`
+	Redis         *RedisConfig         `json:"redis"  mapstructure:"redis"  yaml:"redis"`
+	SQS           *SQSConfig           `json:"sqs"        mapstructure:"sqs"        yaml:"sqs"`
+	Queue         *QueueConfig         `json:"queue"      mapstructure:"queue"      yaml:"queue"`
+	Worker        *WorkerConfig        `json:"worker"     mapstructure:"worker"     yaml:"worker"`
+	Analytics     *AnalyticsConfig     `json:"analytics"  mapstructure:"analytics"  yaml:"analytics"`
+	CloudFront    *CloudFrontConfig    `json:"cloudfront" mapstructure:"cloudfront" yaml:"cloudfront"`
+	Tracing       *TracingConfig       `json:"tracing"    mapstructure:"tracing"    yaml:"tracing"`
+	Metrics       *MetricsConfig       `json:"metrics"    mapstructure:"metrics"    yaml:"metrics"`
+	Upload        *UploadConfig        `json:"upload"     mapstructure:"upload"     yaml:"upload"`
+	Image         *ImageConfig         `json:"image"      mapstructure:"image"      yaml:"image"`
+	Duplicates    *DuplicatesConfig    `json:"duplicates" mapstructure:"duplicates" yaml:"duplicates"`
+	Admin         *AdminConfig         `json:"admin"      mapstructure:"admin"      yaml:"admin"`
+	Notifications *NotificationsConfig `json:"notifications" mapstructure:"notifications" yaml:"notifications"`
+	Webhooks      *WebhooksConfig      `json:"webhooks"      mapstructure:"webhooks"      yaml:"webhooks"`
 }
 
 type ServerConfig struct {
@@ -80,6 +90,124 @@ type SQSConfig struct {
 	QueueURL string `json:"queue_url" mapstructure:"queue_url" yaml:"queue_url"`
 }
 
+// QueueConfig selects where upload and export tasks are queued.
+// Env vars: QUEUE__BACKEND, QUEUE__MAX_RECEIVES, QUEUE__POLL_INTERVAL
+type QueueConfig struct {
+	// Backend is "sqs" (sqs.queue_url) or "postgres" (the queue_messages table,
+	// for running without AWS; tasks are then only processed by the worker
+	// subcommand). Default: "sqs".
+	Backend string `json:"backend" mapstructure:"backend" yaml:"backend"`
+	// MaxReceives is how often a postgres queue message is received before it
+	// is dead-lettered and its pending task failed. SQS uses the queue's
+	// redrive policy instead. Default: 5.
+	MaxReceives int `json:"max_receives" mapstructure:"max_receives" yaml:"max_receives"`
+	// PollInterval is how often an idle worker polls the postgres queue (e.g. "1s"). Default: "1s".
+	PollInterval string `json:"poll_interval" mapstructure:"poll_interval" yaml:"poll_interval"`
+}
+
+// WorkerConfig holds settings for the worker subcommand.
+// Env vars: WORKER__CONCURRENCY, WORKER__VISIBILITY_TIMEOUT
+type WorkerConfig struct {
+	// Concurrency is how many tasks one worker processes at once. Default: 2.
+	Concurrency int `json:"concurrency" mapstructure:"concurrency" yaml:"concurrency"`
+	// VisibilityTimeout hides a received message from other workers; it is
+	// extended while the task runs, so it only bounds how long a crashed
+	// worker's task waits before it is retried (e.g. "5m"; at least "1s"). Default: "5m".
+	VisibilityTimeout string `json:"visibility_timeout" mapstructure:"visibility_timeout" yaml:"visibility_timeout"`
+}
+
+// UploadConfig holds settings for archive ingestion.
+// Env vars: UPLOAD__PDF_RASTERIZER, UPLOAD__PDF_DPI, UPLOAD__STUCK_AFTER, UPLOAD__REAP_INTERVAL, UPLOAD__MAX_ATTEMPTS,
+// UPLOAD__MAX_SIZE, UPLOAD__SESSION_TTL
+type UploadConfig struct {
+	// PDFRasterizer is the poppler pdftoppm binary used to render PDF uploads. Default: "pdftoppm" (from PATH).
+	PDFRasterizer string `json:"pdf_rasterizer" mapstructure:"pdf_rasterizer" yaml:"pdf_rasterizer"`
+	// PDFDPI is the render resolution for PDF pages. Default: 150.
+	PDFDPI int `json:"pdf_dpi" mapstructure:"pdf_dpi" yaml:"pdf_dpi"`
+	// StuckAfter is how long a processing task may go without an update before the
+	// API's reaper releases it (e.g. "30m"). Workers touch running tasks every
+	// minute, so keep it well above that. Default: "30m".
+	StuckAfter string `json:"stuck_after" mapstructure:"stuck_after" yaml:"stuck_after"`
+	// ReapInterval is how often the reaper looks for stuck tasks, and for expired
+	// resumable upload sessions (e.g. "1m"). Default: "1m".
+	ReapInterval string `json:"reap_interval" mapstructure:"reap_interval" yaml:"reap_interval"`
+	// MaxAttempts is how often a task may be claimed before a stuck task is failed
+	// rather than queued again. Default: 3.
+	MaxAttempts int `json:"max_attempts" mapstructure:"max_attempts" yaml:"max_attempts"`
+	// MaxSize is the largest archive a resumable (tus) upload accepts, in bytes. Default: 4294967296 (4 GiB).
+	MaxSize int64 `json:"max_size" mapstructure:"max_size" yaml:"max_size"`
+	// SessionTTL is how long a resumable upload may take before it expires and
+	// its data is discarded (e.g. "24h"). Default: "24h".
+	SessionTTL string `json:"session_ttl" mapstructure:"session_ttl" yaml:"session_ttl"`
+}
+
+// ImageConfig holds settings for the page image pipeline (WebP transcoding and thumbnails).
+// Env vars: IMAGE__ENCODER, IMAGE__QUALITY, IMAGE__THUMBNAIL_WIDTH, IMAGE__THUMBNAIL_QUALITY, IMAGE__WORKERS,
+// IMAGE__COVER_WIDTHS, IMAGE__COVER_QUALITY
+type ImageConfig struct {
+	// Encoder is libwebp's cwebp binary, needed by the API and the worker. Default: "cwebp" (from PATH).
+	Encoder string `json:"encoder" mapstructure:"encoder" yaml:"encoder"`
+	// Quality is the WebP quality of stored pages, 0–100. Default: 80.
+	Quality int `json:"quality" mapstructure:"quality" yaml:"quality"`
+	// ThumbnailWidth is the width of page thumbnails in pixels. Default: 160.
+	ThumbnailWidth int `json:"thumbnail_width" mapstructure:"thumbnail_width" yaml:"thumbnail_width"`
+	// ThumbnailQuality is the WebP quality of page thumbnails. Default: 50.
+	ThumbnailQuality int `json:"thumbnail_quality" mapstructure:"thumbnail_quality" yaml:"thumbnail_quality"`
+	// Workers bounds how many pages of one upload are transcoded at once. Default: 4.
+	Workers int `json:"workers" mapstructure:"workers" yaml:"workers"`
+	// CoverWidths is a comma-separated list of manga cover variant widths in pixels. Default: "160,320,640".
+	CoverWidths string `json:"cover_widths" mapstructure:"cover_widths" yaml:"cover_widths"`
+	// CoverQuality is the WebP quality of cover variants. Default: 80.
+	CoverQuality int `json:"cover_quality" mapstructure:"cover_quality" yaml:"cover_quality"`
+}
+
+// CoverWidthList parses CoverWidths, skipping invalid entries.
+func (c *ImageConfig) CoverWidthList() []int {
+	var widths []int
+	for _, v := range strings.Split(c.CoverWidths, ",") {
+		if w, err := strconv.Atoi(strings.TrimSpace(v)); err == nil && w > 0 {
+			widths = append(widths, w)
+		}
+	}
+	return widths
+}
+
+// DuplicatesConfig holds the duplicate chapter detection thresholds for archive imports.
+// Env vars: DUPLICATES__WARN_PERCENT, DUPLICATES__REJECT_PERCENT, DUPLICATES__MAX_DISTANCE
+type DuplicatesConfig struct {
+	// WarnPercent adds an upload warning when this share of the pages matches an existing chapter. 0 disables. Default: 60.
+	WarnPercent int `json:"warn_percent" mapstructure:"warn_percent" yaml:"warn_percent"`
+	// RejectPercent fails the upload at this share of matching pages. 0 disables. Default: 0.
+	RejectPercent int `json:"reject_percent" mapstructure:"reject_percent" yaml:"reject_percent"`
+	// MaxDistance is the perceptual hash distance (0–64 bits) under which pages of the same manga match. Default: 4.
+	MaxDistance int `json:"max_distance" mapstructure:"max_distance" yaml:"max_distance"`
+}
+
+// AdminConfig lists the users allowed on /api/v1/admin routes.
+// Env vars: ADMIN__USER_IDS (comma-separated user UUIDs)
+type AdminConfig struct {
+	UserIDs string `json:"user_ids" mapstructure:"user_ids" yaml:"user_ids"`
+}
+
+// NotificationsConfig holds settings for the notification fan-out dispatcher run by the API.
+// Env vars: NOTIFICATIONS__POLL_INTERVAL
+type NotificationsConfig struct {
+	// PollInterval is how often the dispatcher looks for queued fan-out jobs (e.g. "5s"). Default: "5s".
+	PollInterval string `json:"poll_interval" mapstructure:"poll_interval" yaml:"poll_interval"`
+}
+
+// WebhooksConfig holds settings for the webhook delivery dispatcher run by the API.
+// Env vars: WEBHOOKS__POLL_INTERVAL, WEBHOOKS__TIMEOUT, WEBHOOKS__ALLOW_PRIVATE
+type WebhooksConfig struct {
+	// PollInterval is how often the dispatcher looks for due deliveries (e.g. "5s"). Default: "5s".
+	PollInterval string `json:"poll_interval" mapstructure:"poll_interval" yaml:"poll_interval"`
+	// Timeout bounds one delivery attempt, including the response (e.g. "10s"). Default: "10s".
+	Timeout string `json:"timeout"       mapstructure:"timeout"       yaml:"timeout"`
+	// AllowPrivate lets webhooks reach loopback and private network addresses.
+	// Off by default so users cannot probe internal services; enable for local development.
+	AllowPrivate bool `json:"allow_private" mapstructure:"allow_private" yaml:"allow_private"`
+}
+
 // CloudFrontConfig holds CloudFront signing credentials for CDN URL generation.
 // When Domain is set, the app generates CloudFront signed URLs instead of S3 presigned URLs.
 // Env vars: CLOUDFRONT__DOMAIN, CLOUDFRONT__KEY_PAIR_ID, CLOUDFRONT__PRIVATE_KEY (PEM string)
@@ -154,6 +282,15 @@ func loadDefault() *Application {
 		SQS: &SQSConfig{
 			QueueURL: "",
 		},
+		Queue: &QueueConfig{
+			Backend:      "sqs",
+			MaxReceives:  5,
+			PollInterval: "1s",
+		},
+		Worker: &WorkerConfig{
+			Concurrency:       2,
+			VisibilityTimeout: "5m",
+		},
 		Analytics: &AnalyticsConfig{
 			ContributionCap: 15,
 			DecayInterval:   "1h",
@@ -167,6 +304,37 @@ func loadDefault() *Application {
 		Metrics: &MetricsConfig{
 			Enabled: false,
 		},
+		Upload: &UploadConfig{
+			PDFRasterizer: "pdftoppm",
+			PDFDPI:        150,
+			StuckAfter:    "30m",
+			ReapInterval:  "1m",
+			MaxAttempts:   3,
+			MaxSize:       4 << 30,
+			SessionTTL:    "24h",
+		},
+		Image: &ImageConfig{
+			Encoder:          "cwebp",
+			Quality:          80,
+			ThumbnailWidth:   160,
+			ThumbnailQuality: 50,
+			Workers:          4,
+			CoverWidths:      "160,320,640",
+			CoverQuality:     80,
+		},
+		Duplicates: &DuplicatesConfig{
+			WarnPercent:   60,
+			RejectPercent: 0,
+			MaxDistance:   4,
+		},
+		Admin: &AdminConfig{},
+		Notifications: &NotificationsConfig{
+			PollInterval: "5s",
+		},
+		Webhooks: &WebhooksConfig{
+			PollInterval: "5s",
+			Timeout:      "10s",
+		},
 	}
 }
 
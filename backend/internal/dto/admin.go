@@ -0,0 +1,40 @@
+package dto
+
+import (
+	"github.com/google/uuid"
+	"github.com/yumikokawaii/sherry-archive/internal/model"
+)
+
+type DuplicateChapterRef struct {
+	MangaID    uuid.UUID `json:"manga_id"`
+	MangaTitle string    `json:"manga_title"`
+	ChapterID  uuid.UUID `json:"chapter_id"`
+	Number     float64   `json:"number"`
+}
+
+// DuplicateChapterResponse is a pair of chapters sharing pages.
+type DuplicateChapterResponse struct {
+	Chapter      DuplicateChapterRef `json:"chapter"`
+	Other        DuplicateChapterRef `json:"other"`
+	SharedPages  int                 `json:"shared_pages"`
+	Similarity   float64             `json:"similarity"`    // shared pages / pages of the shorter chapter, 0–1
+	CrossLibrary bool                `json:"cross_library"` // the chapters belong to different mangas
+}
+
+type DuplicateReportResponse struct {
+	Data []DuplicateChapterResponse `json:"data"`
+}
+
+func NewDuplicateChapterResponse(p *model.DuplicateChapterPair) DuplicateChapterResponse {
+	return DuplicateChapterResponse{
+		Chapter: DuplicateChapterRef{
+			MangaID: p.MangaID, MangaTitle: p.MangaTitle, ChapterID: p.ChapterID, Number: p.ChapterNumber,
+		},
+		Other: DuplicateChapterRef{
+			MangaID: p.OtherMangaID, MangaTitle: p.OtherMangaTitle, ChapterID: p.OtherChapterID, Number: p.OtherChapterNumber,
+		},
+		SharedPages:  p.SharedPages,
+		Similarity:   p.Similarity,
+		CrossLibrary: p.MangaID != p.OtherMangaID,
+	}
+}
@@ -9,20 +9,33 @@ import (
 
 // --- Requests ---
 
+// UpsertBookmarkRequest changes the fields that are set.
 type UpsertBookmarkRequest struct {
-	ChapterID      uuid.UUID `json:"chapter_id"       binding:"required"`
-	LastPageNumber int       `json:"last_page_number" binding:"required,min=1"`
+	ChapterID      *uuid.UUID            `json:"chapter_id"`
+	LastPageNumber *int                  `json:"last_page_number" binding:"omitempty,min=1"`
+	Status         *model.BookmarkStatus `json:"status"`                                       // reading | plan_to_read | completed | dropped | on_hold
+	Score          *int                  `json:"score"       binding:"omitempty,min=0,max=10"` // 0 clears it
+	StartedAt      *string               `json:"started_at"`                                   // YYYY-MM-DD; "" clears it
+	FinishedAt     *string               `json:"finished_at"`                                  // YYYY-MM-DD; "" clears it
+	Notes          *string               `json:"notes"       binding:"omitempty,max=5000"`
 }
 
 // --- Responses ---
 
 type BookmarkResponse struct {
-	ID             uuid.UUID `json:"id"`
-	MangaID        uuid.UUID `json:"manga_id"`
-	ChapterID      uuid.UUID `json:"chapter_id"`
-	LastPageNumber int       `json:"last_page_number"`
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
+	ID             uuid.UUID            `json:"id"`
+	MangaID        uuid.UUID            `json:"manga_id"`
+	ChapterID      *uuid.UUID           `json:"chapter_id"` // null until reading starts
+	LastPageNumber int                  `json:"last_page_number"`
+	Status         model.BookmarkStatus `json:"status"`
+	Score          *int                 `json:"score"`
+	StartedAt      string               `json:"started_at,omitempty"`   // YYYY-MM-DD
+	FinishedAt     string               `json:"finished_at,omitempty"`  // YYYY-MM-DD
+	Notes          string               `json:"notes,omitempty"`        // only shown to the owner
+	UnreadCount    *int                 `json:"unread_count,omitempty"` // published chapters not yet finished; set when listing
+	Manga          *MangaResponse       `json:"manga,omitempty"`        // set when listing
+	CreatedAt      time.Time            `json:"created_at"`
+	UpdatedAt      time.Time            `json:"updated_at"`
 }
 
 func NewBookmarkResponse(b *model.Bookmark) BookmarkResponse {
@@ -31,6 +44,11 @@ func NewBookmarkResponse(b *model.Bookmark) BookmarkResponse {
 		MangaID:        b.MangaID,
 		ChapterID:      b.ChapterID,
 		LastPageNumber: b.LastPageNumber,
+		Status:         b.Status,
+		Score:          b.Score,
+		StartedAt:      formatDate(b.StartedAt),
+		FinishedAt:     formatDate(b.FinishedAt),
+		Notes:          b.Notes,
 		CreatedAt:      b.CreatedAt,
 		UpdatedAt:      b.UpdatedAt,
 	}
@@ -43,3 +61,10 @@ func NewBookmarkResponseList(bs []*model.Bookmark) []BookmarkResponse {
 	}
 	return out
 }
+
+func formatDate(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
@@ -10,13 +10,31 @@ import (
 // --- Requests ---
 
 type CreateChapterRequest struct {
-	Number *float64 `json:"number"`
-	Title  string   `json:"title"`
+	Number   *float64 `json:"number"`
+	Title    string   `json:"title"`
+	Volume   *int     `json:"volume"   binding:"omitempty,min=1"`
+	Language string   `json:"language"` // ISO 639-1 code, e.g. "en"; empty when unspecified
+}
+
+type RecordReadRequest struct {
+	PageNumber int  `json:"page_number" binding:"min=0"` // last page reached
+	Completed  bool `json:"completed"`                   // mark finished regardless of page_number
+}
+
+type MarkReadUpToRequest struct {
+	UpTo     *float64 `json:"up_to"    binding:"required"` // chapter number, inclusive
+	Language string   `json:"language"`                    // as ?lang= on the chapter list
+}
+
+type MarkReadUpToResponse struct {
+	Marked int `json:"marked"` // chapters newly marked read
 }
 
 type UpdateChapterRequest struct {
-	Number *float64 `json:"number"`
-	Title  *string  `json:"title"`
+	Number   *float64 `json:"number"`
+	Title    *string  `json:"title"`
+	Volume   *int     `json:"volume"   binding:"omitempty,min=0"` // 0 clears the volume
+	Language *string  `json:"language"`
 }
 
 // --- Responses ---
@@ -26,22 +44,88 @@ type ChapterResponse struct {
 	MangaID   uuid.UUID `json:"manga_id"`
 	Number    float64   `json:"number"`
 	Title     string    `json:"title"`
+	Volume    *int      `json:"volume,omitempty"`
+	Language  string    `json:"language"`
 	PageCount int       `json:"page_count"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	// ReadState is the viewer's progress; only set for signed-in chapter lists.
+	ReadState *ReadStateResponse `json:"read_state,omitempty"`
+}
+
+type ReadStateResponse struct {
+	Read           bool       `json:"read"`     // finished
+	Progress       int        `json:"progress"` // percent of pages, 0–100
+	LastPageNumber int        `json:"last_page_number"`
+	CompletedAt    *time.Time `json:"completed_at,omitempty"`
+}
+
+// NewReadStateResponse returns the unread state for a nil r.
+func NewReadStateResponse(r *model.ChapterRead) *ReadStateResponse {
+	if r == nil {
+		return &ReadStateResponse{}
+	}
+	return &ReadStateResponse{
+		Read:           r.CompletedAt != nil,
+		Progress:       r.Progress,
+		LastPageNumber: r.LastPageNumber,
+		CompletedAt:    r.CompletedAt,
+	}
 }
 
 type PageItemResponse struct {
-	ID     uuid.UUID `json:"id"`
-	Number int       `json:"number"`
-	URL    string    `json:"url"`
-	Width  int       `json:"width"`
-	Height int       `json:"height"`
+	ID           uuid.UUID `json:"id"`
+	Number       int       `json:"number"`
+	URL          string    `json:"url"`
+	ThumbnailURL string    `json:"thumbnail_url,omitempty"` // small WebP for chapter scrubbers
+	Width        int       `json:"width"`
+	Height       int       `json:"height"`
 }
 
 type ChapterWithPagesResponse struct {
-	Chapter ChapterResponse    `json:"chapter"`
-	Pages   []PageItemResponse `json:"pages"`
+	Chapter ChapterResponse     `json:"chapter"`
+	Pages   []PageItemResponse  `json:"pages"`
+	Prev    *ChapterRefResponse `json:"prev"` // null at the first chapter
+	Next    *ChapterRefResponse `json:"next"` // null at the last chapter
+	// NextPrefetch holds the first pages of Next when ?prefetch= asks for them.
+	NextPrefetch []PageItemResponse `json:"next_prefetch,omitempty"`
+}
+
+// ChapterRefResponse identifies a neighboring chapter for reader navigation.
+type ChapterRefResponse struct {
+	ID       uuid.UUID `json:"id"`
+	Number   float64   `json:"number"`
+	Title    string    `json:"title"`
+	Volume   *int      `json:"volume,omitempty"`
+	Language string    `json:"language"`
+}
+
+// ManifestPageResponse is a page's layout without its URL.
+type ManifestPageResponse struct {
+	Number int `json:"number"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+type ChapterManifestResponse struct {
+	Chapter ChapterResponse        `json:"chapter"`
+	Pages   []ManifestPageResponse `json:"pages"`
+	Prev    *ChapterRefResponse    `json:"prev"`
+	Next    *ChapterRefResponse    `json:"next"`
+}
+
+// NewChapterRefResponse returns nil for a nil chapter.
+func NewChapterRefResponse(ch *model.Chapter) *ChapterRefResponse {
+	if ch == nil {
+		return nil
+	}
+	return &ChapterRefResponse{
+		ID:       ch.ID,
+		Number:   ch.Number,
+		Title:    ch.Title,
+		Volume:   ch.Volume,
+		Language: ch.Language,
+	}
 }
 
 func NewChapterResponse(ch *model.Chapter) ChapterResponse {
@@ -50,6 +134,8 @@ func NewChapterResponse(ch *model.Chapter) ChapterResponse {
 		MangaID:   ch.MangaID,
 		Number:    ch.Number,
 		Title:     ch.Title,
+		Volume:    ch.Volume,
+		Language:  ch.Language,
 		PageCount: ch.PageCount,
 		CreatedAt: ch.CreatedAt,
 		UpdatedAt: ch.UpdatedAt,
@@ -63,3 +149,16 @@ func NewChapterResponseList(chs []*model.Chapter) []ChapterResponse {
 	}
 	return out
 }
+
+// ReleaseGroupResponse is a manga with its newly released chapters.
+type ReleaseGroupResponse struct {
+	Manga    MangaResponse     `json:"manga"`
+	Chapters []ChapterResponse `json:"chapters"`
+}
+
+// LatestReleasesResponse is one page of the latest releases feed.
+// Pass next_cursor back as ?cursor= to get the next page; it is omitted on the last page.
+type LatestReleasesResponse struct {
+	Items      []ReleaseGroupResponse `json:"items"`
+	NextCursor string                 `json:"next_cursor,omitempty"`
+}
@@ -3,11 +3,13 @@ package dto
 import (
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/yumikokawaii/sherry-archive/internal/model"
 )
 
 type CreateCommentRequest struct {
-	Content string `json:"content" binding:"required,min=1,max=2000"`
+	Content  string     `json:"content"   binding:"required,min=1,max=2000"`
+	ParentID *uuid.UUID `json:"parent_id"` // reply to this comment in the same thread
 }
 
 type UpdateCommentRequest struct {
@@ -22,6 +24,7 @@ type CommentAuthor struct {
 
 type CommentResponse struct {
 	ID        string        `json:"id"`
+	ParentID  *uuid.UUID    `json:"parent_id,omitempty"`
 	Content   string        `json:"content"`
 	Author    CommentAuthor `json:"author"`
 	Edited    bool          `json:"edited"`
@@ -31,8 +34,9 @@ type CommentResponse struct {
 
 func NewCommentResponse(c *model.CommentWithAuthor) CommentResponse {
 	return CommentResponse{
-		ID:      c.ID.String(),
-		Content: c.Content,
+		ID:       c.ID.String(),
+		ParentID: c.ParentID,
+		Content:  c.Content,
 		Author: CommentAuthor{
 			ID:        c.UserID.String(),
 			Username:  c.AuthorUsername,
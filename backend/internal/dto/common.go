@@ -28,3 +28,38 @@ type PagedCommentResponse struct {
 	Page  int               `json:"page"`
 	Limit int               `json:"limit"`
 }
+
+type PagedReviewResponse struct {
+	Items []ReviewResponse `json:"items"`
+	Total int              `json:"total"`
+	Page  int              `json:"page"`
+	Limit int              `json:"limit"`
+}
+
+type PagedReadingListResponse struct {
+	Items []ReadingListResponse `json:"items"`
+	Total int                   `json:"total"`
+	Page  int                   `json:"page"`
+	Limit int                   `json:"limit"`
+}
+
+type PagedNotificationResponse struct {
+	Items []NotificationResponse `json:"items"`
+	Total int                    `json:"total"`
+	Page  int                    `json:"page"`
+	Limit int                    `json:"limit"`
+}
+
+type PagedWebhookDeliveryResponse struct {
+	Items []WebhookDeliveryResponse `json:"items"`
+	Total int                       `json:"total"`
+	Page  int                       `json:"page"`
+	Limit int                       `json:"limit"`
+}
+
+type PagedUploadTaskResponse struct {
+	Items []UploadTaskResponse `json:"items"`
+	Total int                  `json:"total"`
+	Page  int                  `json:"page"`
+	Limit int                  `json:"limit"`
+}
@@ -1,6 +1,10 @@
 package dto
 
 import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -31,46 +35,119 @@ type UpdateMangaRequest struct {
 	Category    *string            `json:"category"`
 }
 
+// CropRequest is a rectangle of the source image, in pixels from its top-left corner.
+type CropRequest struct {
+	X      int `json:"x"      binding:"min=0"`
+	Y      int `json:"y"      binding:"min=0"`
+	Width  int `json:"width"  binding:"min=1"`
+	Height int `json:"height" binding:"min=1"`
+}
+
+type SetCoverFromPageRequest struct {
+	ChapterID  uuid.UUID    `json:"chapter_id"  binding:"required"`
+	PageNumber int          `json:"page_number" binding:"required,min=1"`
+	Crop       *CropRequest `json:"crop"`
+}
+
 // --- Responses ---
 
 type MangaResponse struct {
-	ID          uuid.UUID         `json:"id"`
-	OwnerID     uuid.UUID         `json:"owner_id"`
-	Title       string            `json:"title"`
-	Slug        string            `json:"slug"`
-	Description string            `json:"description"`
-	CoverURL    string            `json:"cover_url"`
-	Status      model.MangaStatus `json:"status"`
-	Type        model.MangaType   `json:"type"`
-	Tags        []string          `json:"tags"`
-	Author      string            `json:"author"`
-	Artist      string            `json:"artist"`
-	Category    string            `json:"category"`
-	CreatedAt   time.Time         `json:"created_at"`
-	UpdatedAt   time.Time         `json:"updated_at"`
+	ID            uuid.UUID         `json:"id"`
+	OwnerID       uuid.UUID         `json:"owner_id"`
+	Title         string            `json:"title"`
+	Slug          string            `json:"slug"`
+	Description   string            `json:"description"`
+	CoverURL      string            `json:"cover_url"`
+	CoverSrcset   string            `json:"cover_srcset"` // "<url> 160w, <url> 320w, ..."; empty for older covers
+	Status        model.MangaStatus `json:"status"`
+	Type          model.MangaType   `json:"type"`
+	Tags          []string          `json:"tags"`
+	Author        string            `json:"author"`
+	Artist        string            `json:"artist"`
+	Category      string            `json:"category"`
+	LastChapterAt *time.Time        `json:"last_chapter_at,omitempty"`
+	RatingAvg     float64           `json:"rating_avg"` // mean 1–10 score, 0 when unrated
+	RatingCount   int               `json:"rating_count"`
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at"`
 }
 
-// NewMangaResponse builds a MangaResponse. coverURL is passed separately because
-// it is generated on-demand from the stored CoverKey (presigned URL, local crypto op).
-func NewMangaResponse(m *model.Manga, coverURL string) MangaResponse {
+// NewMangaResponse builds a MangaResponse. Cover URLs are passed separately because
+// they are generated on-demand from the stored keys (presigned URL, local crypto op):
+// coverURLs are the resolved CoverKeys(m), in order. Prefer NewMangaResponses.
+func NewMangaResponse(m *model.Manga, coverURLs []string) MangaResponse {
+	url := func(i int) string {
+		if i < len(coverURLs) {
+			return coverURLs[i]
+		}
+		return ""
+	}
+	var srcset []string
+	for i, v := range m.CoverVariants {
+		if u := url(i + 1); u != "" {
+			srcset = append(srcset, fmt.Sprintf("%s %dw", u, v.Width))
+		}
+	}
 	tags := []string(m.Tags)
 	if tags == nil {
 		tags = []string{}
 	}
 	return MangaResponse{
-		ID:          m.ID,
-		OwnerID:     m.OwnerID,
-		Title:       m.Title,
-		Slug:        m.Slug,
-		Description: m.Description,
-		CoverURL:    coverURL,
-		Status:      m.Status,
-		Type:        m.Type,
-		Tags:        tags,
-		Author:      m.Author,
-		Artist:      m.Artist,
-		Category:    m.Category,
-		CreatedAt:   m.CreatedAt,
-		UpdatedAt:   m.UpdatedAt,
+		ID:            m.ID,
+		OwnerID:       m.OwnerID,
+		Title:         m.Title,
+		Slug:          m.Slug,
+		Description:   m.Description,
+		CoverURL:      url(0),
+		CoverSrcset:   strings.Join(srcset, ", "),
+		Status:        m.Status,
+		Type:          m.Type,
+		Tags:          tags,
+		Author:        m.Author,
+		Artist:        m.Artist,
+		Category:      m.Category,
+		LastChapterAt: m.LastChapterAt,
+		RatingAvg:     math.Round(m.RatingAvg*100) / 100,
+		RatingCount:   m.RatingCount,
+		CreatedAt:     m.CreatedAt,
+		UpdatedAt:     m.UpdatedAt,
+	}
+}
+
+// CoverKeys lists the object keys a MangaResponse resolves: the cover, then its variants.
+func CoverKeys(m *model.Manga) []string {
+	keys := make([]string, 0, 1+len(m.CoverVariants))
+	keys = append(keys, m.CoverKey)
+	for _, v := range m.CoverVariants {
+		keys = append(keys, v.Key)
+	}
+	return keys
+}
+
+// CoverResolver resolves object keys to URLs, in order; implemented by urlcache.URLCache.
+type CoverResolver interface {
+	ResolveMany(ctx context.Context, keys []string) ([]string, error)
+}
+
+// NewMangaResponses builds the responses of ms, resolving every cover and cover
+// variant in one ResolveMany call. Keys that fail to resolve get empty URLs.
+func NewMangaResponses(ctx context.Context, r CoverResolver, ms []*model.Manga) []MangaResponse {
+	var keys []string
+	for _, m := range ms {
+		keys = append(keys, CoverKeys(m)...)
+	}
+	urls, _ := r.ResolveMany(ctx, keys)
+
+	out := make([]MangaResponse, len(ms))
+	offset := 0
+	for i, m := range ms {
+		n := 1 + len(m.CoverVariants)
+		var mine []string
+		if offset < len(urls) {
+			mine = urls[offset:min(offset+n, len(urls))]
+		}
+		out[i] = NewMangaResponse(m, mine)
+		offset += n
 	}
+	return out
 }
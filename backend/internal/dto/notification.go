@@ -0,0 +1,122 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yumikokawaii/sherry-archive/internal/model"
+)
+
+// NotificationPreferencesRequest turns notification types on or off; omitted fields
+// are left as they are.
+type NotificationPreferencesRequest struct {
+	NewChapter     *bool `json:"new_chapter"`
+	CommentReply   *bool `json:"comment_reply"`
+	CommentMention *bool `json:"comment_mention"`
+}
+
+// Changes returns the preferences the request sets.
+func (r NotificationPreferencesRequest) Changes() map[model.NotificationType]bool {
+	prefs := make(map[model.NotificationType]bool)
+	for t, v := range map[model.NotificationType]*bool{
+		model.NotificationNewChapter:     r.NewChapter,
+		model.NotificationCommentReply:   r.CommentReply,
+		model.NotificationCommentMention: r.CommentMention,
+	} {
+		if v != nil {
+			prefs[t] = *v
+		}
+	}
+	return prefs
+}
+
+type NotificationPreferencesResponse struct {
+	NewChapter     bool `json:"new_chapter"`
+	CommentReply   bool `json:"comment_reply"`
+	CommentMention bool `json:"comment_mention"`
+}
+
+func NewNotificationPreferencesResponse(prefs map[model.NotificationType]bool) NotificationPreferencesResponse {
+	return NotificationPreferencesResponse{
+		NewChapter:     prefs[model.NotificationNewChapter],
+		CommentReply:   prefs[model.NotificationCommentReply],
+		CommentMention: prefs[model.NotificationCommentMention],
+	}
+}
+
+type NotificationActor struct {
+	ID       uuid.UUID `json:"id"`
+	Username string    `json:"username"`
+}
+
+type NotificationChapter struct {
+	ID     uuid.UUID `json:"id"`
+	Number float64   `json:"number"`
+	Title  string    `json:"title"`
+}
+
+type NotificationResponse struct {
+	ID             uuid.UUID              `json:"id"`
+	Type           model.NotificationType `json:"type"`            // new_chapter | comment_reply | comment_mention
+	Actor          *NotificationActor     `json:"actor,omitempty"` // omitted once the account is deleted
+	MangaID        uuid.UUID              `json:"manga_id"`
+	MangaTitle     string                 `json:"manga_title"`
+	Chapter        *NotificationChapter   `json:"chapter,omitempty"`
+	CommentID      *uuid.UUID             `json:"comment_id,omitempty"`
+	CommentExcerpt string                 `json:"comment_excerpt,omitempty"`
+	Read           bool                   `json:"read"`
+	CreatedAt      time.Time              `json:"created_at"`
+}
+
+// notificationExcerptLen is how many characters of a comment a notification shows.
+const notificationExcerptLen = 140
+
+func NewNotificationResponse(n *model.NotificationWithContext) NotificationResponse {
+	resp := NotificationResponse{
+		ID:         n.ID,
+		Type:       n.Type,
+		MangaID:    n.MangaID,
+		MangaTitle: n.MangaTitle,
+		CommentID:  n.CommentID,
+		Read:       n.ReadAt != nil,
+		CreatedAt:  n.CreatedAt,
+	}
+	if n.ActorID != nil && n.ActorUsername != nil {
+		resp.Actor = &NotificationActor{ID: *n.ActorID, Username: *n.ActorUsername}
+	}
+	if n.ChapterID != nil && n.ChapterNumber != nil {
+		resp.Chapter = &NotificationChapter{ID: *n.ChapterID, Number: *n.ChapterNumber}
+		if n.ChapterTitle != nil {
+			resp.Chapter.Title = *n.ChapterTitle
+		}
+	}
+	if n.CommentContent != nil {
+		resp.CommentExcerpt = excerpt(*n.CommentContent, notificationExcerptLen)
+	}
+	return resp
+}
+
+func NewNotificationResponses(rows []*model.NotificationWithContext) []NotificationResponse {
+	out := make([]NotificationResponse, len(rows))
+	for i, n := range rows {
+		out[i] = NewNotificationResponse(n)
+	}
+	return out
+}
+
+// excerpt cuts s to at most n characters, marking the cut with an ellipsis.
+func excerpt(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
+
+type UnreadCountResponse struct {
+	Count int `json:"count"`
+}
+
+type MarkAllReadResponse struct {
+	Marked int `json:"marked"`
+}
@@ -19,6 +19,7 @@ type PageUploadResponse struct {
 type ZipMetadataSuggestions struct {
 	ChapterNumber *float64 `json:"chapter_number,omitempty"`
 	ChapterTitle  string   `json:"chapter_title,omitempty"`
+	Volume        *int     `json:"volume,omitempty"`
 	Author        string   `json:"author,omitempty"`
 	Artist        string   `json:"artist,omitempty"`
 	Tags          []string `json:"tags,omitempty"`
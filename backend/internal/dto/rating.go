@@ -0,0 +1,68 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yumikokawaii/sherry-archive/internal/model"
+)
+
+type RateMangaRequest struct {
+	Score  int    `json:"score"  binding:"required,min=1,max=10"`
+	Review string `json:"review" binding:"max=10000"` // optional; empty removes the review
+}
+
+// RatingResponse is the viewer's own rating.
+type RatingResponse struct {
+	ID           uuid.UUID `json:"id"`
+	MangaID      uuid.UUID `json:"manga_id"`
+	Score        int       `json:"score"`
+	Review       string    `json:"review"`
+	HelpfulCount int       `json:"helpful_count"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type ReviewResponse struct {
+	ID           uuid.UUID     `json:"id"`
+	Score        int           `json:"score"`
+	Review       string        `json:"review"`
+	Author       CommentAuthor `json:"author"`
+	HelpfulCount int           `json:"helpful_count"`
+	VotedHelpful bool          `json:"voted_helpful"` // by the viewer
+	CreatedAt    time.Time     `json:"created_at"`
+	UpdatedAt    time.Time     `json:"updated_at"`
+}
+
+func NewRatingResponse(r *model.Rating) RatingResponse {
+	return RatingResponse{
+		ID:           r.ID,
+		MangaID:      r.MangaID,
+		Score:        r.Score,
+		Review:       r.Review,
+		HelpfulCount: r.HelpfulCount,
+		CreatedAt:    r.CreatedAt,
+		UpdatedAt:    r.UpdatedAt,
+	}
+}
+
+func NewReviewResponses(rows []*model.RatingWithAuthor) []ReviewResponse {
+	out := make([]ReviewResponse, len(rows))
+	for i, r := range rows {
+		out[i] = ReviewResponse{
+			ID:     r.ID,
+			Score:  r.Score,
+			Review: r.Review,
+			Author: CommentAuthor{
+				ID:        r.UserID.String(),
+				Username:  r.AuthorUsername,
+				AvatarURL: r.AuthorAvatarURL,
+			},
+			HelpfulCount: r.HelpfulCount,
+			VotedHelpful: r.VotedHelpful,
+			CreatedAt:    r.CreatedAt,
+			UpdatedAt:    r.UpdatedAt,
+		}
+	}
+	return out
+}
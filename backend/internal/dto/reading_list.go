@@ -0,0 +1,68 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yumikokawaii/sherry-archive/internal/model"
+)
+
+// --- Requests ---
+
+type CreateReadingListRequest struct {
+	Name        string `json:"name"        binding:"required,min=1,max=100"`
+	Description string `json:"description" binding:"max=2000"`
+	IsPublic    bool   `json:"is_public"`
+}
+
+type UpdateReadingListRequest struct {
+	Name        *string `json:"name"        binding:"omitempty,min=1,max=100"`
+	Description *string `json:"description" binding:"omitempty,max=2000"`
+	IsPublic    *bool   `json:"is_public"`
+}
+
+type ReorderReadingListRequest struct {
+	MangaIDs []uuid.UUID `json:"manga_ids" binding:"required,min=1"`
+}
+
+// --- Responses ---
+
+type ReadingListResponse struct {
+	ID          uuid.UUID `json:"id"`
+	OwnerID     uuid.UUID `json:"owner_id"`
+	Name        string    `json:"name"`
+	Slug        string    `json:"slug"`
+	Description string    `json:"description"`
+	IsPublic    bool      `json:"is_public"`
+	ItemCount   int       `json:"item_count"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ReadingListDetailResponse is a list with one page of its manga, in list order.
+type ReadingListDetailResponse struct {
+	List  ReadingListResponse `json:"list"`
+	Items PagedMangaResponse  `json:"items"`
+}
+
+func NewReadingListResponse(l *model.ReadingList) ReadingListResponse {
+	return ReadingListResponse{
+		ID:          l.ID,
+		OwnerID:     l.UserID,
+		Name:        l.Name,
+		Slug:        l.Slug,
+		Description: l.Description,
+		IsPublic:    l.IsPublic,
+		ItemCount:   l.ItemCount,
+		CreatedAt:   l.CreatedAt,
+		UpdatedAt:   l.UpdatedAt,
+	}
+}
+
+func NewReadingListResponses(ls []*model.ReadingList) []ReadingListResponse {
+	out := make([]ReadingListResponse, len(ls))
+	for i, l := range ls {
+		out[i] = NewReadingListResponse(l)
+	}
+	return out
+}
@@ -0,0 +1,73 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yumikokawaii/sherry-archive/internal/model"
+)
+
+// UploadSessionResponse is the body of a tus creation response; tus clients
+// only read the Location header.
+type UploadSessionResponse struct {
+	ID            uuid.UUID            `json:"id"`
+	Type          model.UploadTaskType `json:"type"`
+	MangaID       uuid.UUID            `json:"manga_id"`
+	ChapterID     *uuid.UUID           `json:"chapter_id,omitempty"`
+	ApplyMetadata bool                 `json:"apply_metadata"`
+	Filename      string               `json:"filename,omitempty"`
+	Size          int64                `json:"size"`
+	Offset        int64                `json:"offset"`            // bytes received so far
+	TaskID        *uuid.UUID           `json:"task_id,omitempty"` // once the upload is complete
+	ExpiresAt     time.Time            `json:"expires_at"`
+	CreatedAt     time.Time            `json:"created_at"`
+}
+
+func NewUploadSessionResponse(s *model.UploadSession) UploadSessionResponse {
+	resp := UploadSessionResponse{
+		ID:            s.ID,
+		Type:          s.TaskType,
+		MangaID:       s.MangaID,
+		ApplyMetadata: s.ApplyMetadata,
+		Filename:      s.Filename,
+		Size:          s.Size,
+		Offset:        s.Offset,
+		ExpiresAt:     s.ExpiresAt,
+		CreatedAt:     s.CreatedAt,
+	}
+	if s.ChapterID.Valid {
+		resp.ChapterID = &s.ChapterID.UUID
+	}
+	if s.TaskID.Valid {
+		resp.TaskID = &s.TaskID.UUID
+	}
+	return resp
+}
+
+// CreateDirectUploadRequest starts an upload sent straight to storage.
+type CreateDirectUploadRequest struct {
+	Type           model.UploadTaskType `json:"type"            binding:"required,oneof=zip oneshot_zip series_zip"`
+	MangaID        uuid.UUID            `json:"manga_id"        binding:"required"`
+	ChapterID      *uuid.UUID           `json:"chapter_id"` // required for zip
+	ApplyMetadata  bool                 `json:"apply_metadata"`
+	Filename       string               `json:"filename"        binding:"max=255"`
+	Size           int64                `json:"size"            binding:"required,min=1"`
+	ChecksumSHA256 string               `json:"checksum_sha256" binding:"required,len=64,hexadecimal"` // of the whole archive
+}
+
+// DirectUploadResponse tells the client where to PUT the archive. method "put":
+// the whole archive to url. method "multipart": each part_size slice of it, in
+// order, to parts[i].url; the last part holds the rest. Then call complete.
+type DirectUploadResponse struct {
+	ID        uuid.UUID             `json:"id"`
+	Method    string                `json:"method"` // put or multipart
+	URL       string                `json:"url,omitempty"`
+	PartSize  int64                 `json:"part_size,omitempty"`
+	Parts     []DirectUploadPartURL `json:"parts,omitempty"`
+	ExpiresAt time.Time             `json:"expires_at"`
+}
+
+type DirectUploadPartURL struct {
+	Number int    `json:"number"`
+	URL    string `json:"url"`
+}
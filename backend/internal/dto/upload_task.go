@@ -5,36 +5,87 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/yumikokawaii/sherry-archive/internal/model"
+	"github.com/yumikokawaii/sherry-archive/pkg/taskevents"
 )
 
+// ExportRequest selects the archive format of a whole-manga export.
+type ExportRequest struct {
+	Format string `json:"format" binding:"required,oneof=cbz epub"`
+}
+
 // EnqueueResponse is returned immediately on a zip upload — the client polls TaskID for status.
 type EnqueueResponse struct {
 	TaskID uuid.UUID `json:"task_id"`
 }
 
 type UploadTaskResponse struct {
-	ID        uuid.UUID              `json:"id"`
-	Type      model.UploadTaskType   `json:"type"`
-	Status    model.UploadTaskStatus `json:"status"`
-	MangaID   uuid.UUID              `json:"manga_id"`
-	ChapterID *uuid.UUID             `json:"chapter_id,omitempty"`
-	Error     string                 `json:"error,omitempty"`
-	CreatedAt time.Time              `json:"created_at"`
-	UpdatedAt time.Time              `json:"updated_at"`
+	ID             uuid.UUID              `json:"id"`
+	Type           model.UploadTaskType   `json:"type"`
+	Status         model.UploadTaskStatus `json:"status"`
+	MangaID        uuid.UUID              `json:"manga_id"`
+	ChapterID      *uuid.UUID             `json:"chapter_id,omitempty"`
+	Error          string                 `json:"error,omitempty"`
+	Results        []model.ChapterResult  `json:"results,omitempty"`      // series_zip only
+	DownloadURL    string                 `json:"download_url,omitempty"` // presigned; export tasks once done
+	ApplyMetadata  bool                   `json:"apply_metadata"`
+	MetadataReport *model.MetadataReport  `json:"metadata_report,omitempty"` // apply_metadata tasks once processed
+	Warnings       []string               `json:"warnings,omitempty"`        // skipped files, ambiguous page order
+	Processed      int                    `json:"processed"`                 // archive pages stored so far
+	Total          int                    `json:"total"`                     // archive pages; 0 until the worker has read the archive
+	Attempts       int                    `json:"attempts"`                  // times a worker picked the task up
+	CreatedAt      time.Time              `json:"created_at"`
+	UpdatedAt      time.Time              `json:"updated_at"`
 }
 
 func NewUploadTaskResponse(t *model.UploadTask) UploadTaskResponse {
 	resp := UploadTaskResponse{
-		ID:        t.ID,
-		Type:      t.Type,
-		Status:    t.Status,
-		MangaID:   t.MangaID,
-		Error:     t.Error,
-		CreatedAt: t.CreatedAt,
-		UpdatedAt: t.UpdatedAt,
+		ID:             t.ID,
+		Type:           t.Type,
+		Status:         t.Status,
+		MangaID:        t.MangaID,
+		Error:          t.Error,
+		Results:        t.Results,
+		ApplyMetadata:  t.ApplyMetadata,
+		MetadataReport: t.MetadataReport,
+		Warnings:       t.Warnings,
+		Processed:      t.Processed,
+		Total:          t.Total,
+		Attempts:       t.Attempts,
+		CreatedAt:      t.CreatedAt,
+		UpdatedAt:      t.UpdatedAt,
 	}
 	if t.ChapterID.Valid {
 		resp.ChapterID = &t.ChapterID.UUID
 	}
 	return resp
 }
+
+func NewUploadTaskResponseList(ts []*model.UploadTask) []UploadTaskResponse {
+	out := make([]UploadTaskResponse, len(ts))
+	for i, t := range ts {
+		out[i] = NewUploadTaskResponse(t)
+	}
+	return out
+}
+
+// UploadTaskEventResponse is the data of a GET /tasks/:id/events message.
+type UploadTaskEventResponse struct {
+	Status    model.UploadTaskStatus `json:"status"`
+	Processed int                    `json:"processed"`
+	Total     int                    `json:"total"`
+	Error     string                 `json:"error,omitempty"`
+}
+
+func NewUploadTaskEventResponse(e taskevents.Event) UploadTaskEventResponse {
+	return UploadTaskEventResponse{
+		Status:    model.UploadTaskStatus(e.Status),
+		Processed: e.Processed,
+		Total:     e.Total,
+		Error:     e.Error,
+	}
+}
+
+// NewUploadTaskEventResponseFromTask describes the task as stored.
+func NewUploadTaskEventResponseFromTask(t *model.UploadTask) UploadTaskEventResponse {
+	return UploadTaskEventResponse{Status: t.Status, Processed: t.Processed, Total: t.Total, Error: t.Error}
+}
@@ -10,21 +10,25 @@ import (
 // --- Requests ---
 
 type UpdateUserRequest struct {
-	Username *string `json:"username"`
-	Bio      *string `json:"bio"`
+	Username          *string `json:"username"`
+	Bio               *string `json:"bio"`
+	PreferredLanguage *string `json:"preferred_language"` // ISO 639-1 code; "" shows chapters in all languages
+	LibraryPublic     *bool   `json:"library_public"`     // let others see GET /users/:id/library
 }
 
 // --- Responses ---
 
 // UserResponse is the full profile returned to the authenticated user themselves.
 type UserResponse struct {
-	ID        uuid.UUID `json:"id"`
-	Username  string    `json:"username"`
-	Email     string    `json:"email"`
-	AvatarURL string    `json:"avatar_url"`
-	Bio       string    `json:"bio"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID                uuid.UUID `json:"id"`
+	Username          string    `json:"username"`
+	Email             string    `json:"email"`
+	AvatarURL         string    `json:"avatar_url"`
+	Bio               string    `json:"bio"`
+	PreferredLanguage string    `json:"preferred_language"`
+	LibraryPublic     bool      `json:"library_public"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
 }
 
 // PublicUserResponse omits private fields (email) for public profile endpoints.
@@ -38,13 +42,15 @@ type PublicUserResponse struct {
 
 func NewUserResponse(u *model.User) UserResponse {
 	return UserResponse{
-		ID:        u.ID,
-		Username:  u.Username,
-		Email:     u.Email,
-		AvatarURL: u.AvatarURL,
-		Bio:       u.Bio,
-		CreatedAt: u.CreatedAt,
-		UpdatedAt: u.UpdatedAt,
+		ID:                u.ID,
+		Username:          u.Username,
+		Email:             u.Email,
+		AvatarURL:         u.AvatarURL,
+		Bio:               u.Bio,
+		PreferredLanguage: u.PreferredLanguage,
+		LibraryPublic:     u.LibraryPublic,
+		CreatedAt:         u.CreatedAt,
+		UpdatedAt:         u.UpdatedAt,
 	}
 }
 
@@ -0,0 +1,103 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yumikokawaii/sherry-archive/internal/model"
+)
+
+// --- Requests ---
+
+type CreateWebhookRequest struct {
+	URL    string               `json:"url"    binding:"required,max=2000"`
+	Secret string               `json:"secret" binding:"omitempty,min=16,max=200"` // generated when empty
+	Events []model.WebhookEvent `json:"events" binding:"required,min=1"`           // manga.created | manga.updated | chapter.published | upload_task.done | upload_task.failed
+}
+
+// UpdateWebhookRequest changes the fields that are set.
+type UpdateWebhookRequest struct {
+	URL    *string              `json:"url"    binding:"omitempty,max=2000"`
+	Secret *string              `json:"secret" binding:"omitempty,max=200"` // "" generates a new one
+	Events []model.WebhookEvent `json:"events"`
+	Active *bool                `json:"active"`
+}
+
+// --- Responses ---
+
+type WebhookResponse struct {
+	ID        uuid.UUID            `json:"id"`
+	URL       string               `json:"url"`
+	Secret    string               `json:"secret,omitempty"` // only returned when created or changed
+	Events    []model.WebhookEvent `json:"events"`
+	Active    bool                 `json:"active"`
+	CreatedAt time.Time            `json:"created_at"`
+	UpdatedAt time.Time            `json:"updated_at"`
+}
+
+// NewWebhookResponse leaves out the secret; set it on responses to requests
+// that chose or generated one.
+func NewWebhookResponse(w *model.Webhook) WebhookResponse {
+	events := make([]model.WebhookEvent, len(w.Events))
+	for i, e := range w.Events {
+		events[i] = model.WebhookEvent(e)
+	}
+	return WebhookResponse{
+		ID:        w.ID,
+		URL:       w.URL,
+		Events:    events,
+		Active:    w.Active,
+		CreatedAt: w.CreatedAt,
+		UpdatedAt: w.UpdatedAt,
+	}
+}
+
+func NewWebhookResponseList(ws []*model.Webhook) []WebhookResponse {
+	out := make([]WebhookResponse, len(ws))
+	for i, w := range ws {
+		out[i] = NewWebhookResponse(w)
+	}
+	return out
+}
+
+type WebhookDeliveryResponse struct {
+	ID             uuid.UUID                   `json:"id"`
+	EventID        uuid.UUID                   `json:"event_id"`
+	Event          model.WebhookEvent          `json:"event"`
+	Payload        json.RawMessage             `json:"payload" swaggertype:"object"`
+	Status         model.WebhookDeliveryStatus `json:"status"` // pending | succeeded | failed
+	Attempts       int                         `json:"attempts"`
+	ResponseStatus *int                        `json:"response_status"`
+	LastError      string                      `json:"last_error,omitempty"`
+	NextAttemptAt  *time.Time                  `json:"next_attempt_at,omitempty"` // set while pending
+	DeliveredAt    *time.Time                  `json:"delivered_at"`
+	CreatedAt      time.Time                   `json:"created_at"`
+}
+
+func NewWebhookDeliveryResponse(d *model.WebhookDelivery) WebhookDeliveryResponse {
+	r := WebhookDeliveryResponse{
+		ID:             d.ID,
+		EventID:        d.EventID,
+		Event:          d.Event,
+		Payload:        d.Payload,
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		ResponseStatus: d.ResponseStatus,
+		LastError:      d.LastError,
+		DeliveredAt:    d.DeliveredAt,
+		CreatedAt:      d.CreatedAt,
+	}
+	if d.Status == model.WebhookDeliveryPending {
+		r.NextAttemptAt = &d.NextAttemptAt
+	}
+	return r
+}
+
+func NewWebhookDeliveryResponseList(ds []*model.WebhookDelivery) []WebhookDeliveryResponse {
+	out := make([]WebhookDeliveryResponse, len(ds))
+	for i, d := range ds {
+		out[i] = NewWebhookDeliveryResponse(d)
+	}
+	return out
+}
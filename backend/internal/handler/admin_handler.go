@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/yumikokawaii/sherry-archive/internal/dto"
+	"github.com/yumikokawaii/sherry-archive/internal/service"
+	"github.com/yumikokawaii/sherry-archive/pkg/pagination"
+)
+
+type AdminHandler struct {
+	duplicateSvc *service.DuplicateService
+}
+
+func NewAdminHandler(duplicateSvc *service.DuplicateService) *AdminHandler {
+	return &AdminHandler{duplicateSvc: duplicateSvc}
+}
+
+// Duplicates godoc
+//
+//	@Summary		Duplicate chapter report
+//	@Description	Chapter pairs sharing pages by perceptual hash, most similar first. Hashes must be equal; with manga_id, pages within that manga also match when near-identical. Admins only (ADMIN__USER_IDS).
+//	@Tags			admin
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Param			manga_id		query		string	false	"Only pairs involving this manga"
+//	@Param			min_similarity	query		int		false	"Minimum share of the shorter chapter's pages, in percent (default DUPLICATES__WARN_PERCENT)"
+//	@Param			limit			query		int		false	"Max pairs"	default(20)
+//	@Success		200				{object}	dto.DuplicateReportResponse
+//	@Failure		400				{object}	dto.ErrorResponse
+//	@Failure		403				{object}	dto.ErrorResponse
+//	@Router			/admin/duplicates [get]
+func (h *AdminHandler) Duplicates(c *gin.Context) {
+	in := service.DuplicateReportInput{Limit: pagination.FromQuery(c).Limit}
+	if v := c.Query("manga_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid manga id"})
+			return
+		}
+		in.MangaID = &id
+	}
+	if v := c.Query("min_similarity"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid min_similarity"})
+			return
+		}
+		in.MinPercent = n
+	}
+
+	pairs, err := h.duplicateSvc.Report(c.Request.Context(), in)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	items := make([]dto.DuplicateChapterResponse, len(pairs))
+	for i, p := range pairs {
+		items[i] = dto.NewDuplicateChapterResponse(p)
+	}
+	c.JSON(http.StatusOK, dto.DuplicateReportResponse{Data: items})
+}
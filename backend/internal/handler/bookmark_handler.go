@@ -7,34 +7,129 @@ import (
 	"github.com/google/uuid"
 	"github.com/yumikokawaii/sherry-archive/internal/dto"
 	"github.com/yumikokawaii/sherry-archive/internal/middleware"
+	"github.com/yumikokawaii/sherry-archive/internal/model"
+	"github.com/yumikokawaii/sherry-archive/internal/repository"
 	"github.com/yumikokawaii/sherry-archive/internal/service"
+	"github.com/yumikokawaii/sherry-archive/pkg/urlcache"
 )
 
 type BookmarkHandler struct {
 	bookmarkSvc *service.BookmarkService
+	urlCache    *urlcache.URLCache
 }
 
-func NewBookmarkHandler(bookmarkSvc *service.BookmarkService) *BookmarkHandler {
-	return &BookmarkHandler{bookmarkSvc: bookmarkSvc}
+func NewBookmarkHandler(bookmarkSvc *service.BookmarkService, urlCache *urlcache.URLCache) *BookmarkHandler {
+	return &BookmarkHandler{bookmarkSvc: bookmarkSvc, urlCache: urlCache}
 }
 
 // List godoc
 //
-//	@Summary	List bookmarks
-//	@Tags		bookmark
-//	@Produce	json
-//	@Security	BearerAuth
-//	@Success	200	{array}		dto.BookmarkResponse
-//	@Failure	401	{object}	dto.ErrorResponse
-//	@Router		/users/me/bookmarks [get]
+//	@Summary		List bookmarks
+//	@Description	My library, each entry with its manga and unread_count (the manga's published chapters not yet finished).
+//	@Tags			bookmark
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			status	query		string	false	"Filter by status"	Enums(reading, plan_to_read, completed, dropped, on_hold)
+//	@Param			sort	query		string	false	"Sort order"		Enums(updated, added, title, score, status)	default(updated)
+//	@Success		200		{array}		dto.BookmarkResponse
+//	@Failure		400		{object}	dto.ErrorResponse
+//	@Failure		401		{object}	dto.ErrorResponse
+//	@Router			/users/me/bookmarks [get]
 func (h *BookmarkHandler) List(c *gin.Context) {
+	ctx := c.Request.Context()
 	userID := middleware.MustUserID(c)
-	bookmarks, err := h.bookmarkSvc.List(c.Request.Context(), userID)
+	bookmarks, err := h.bookmarkSvc.List(ctx, userID, bookmarkFilter(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	unread, err := h.bookmarkSvc.UnreadCounts(ctx, userID, bookmarks)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	items, err := h.libraryItems(c, bookmarks)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	for i := range items {
+		n := unread[items[i].MangaID]
+		items[i].UnreadCount = &n
+	}
+	respondOK(c, items)
+}
+
+// Library godoc
+//
+//	@Summary		List a user's library
+//	@Description	Only available when the user made their library public (library_public). Notes are omitted.
+//	@Tags			bookmark
+//	@Produce		json
+//	@Param			userID	path		string	true	"User ID"
+//	@Param			status	query		string	false	"Filter by status"	Enums(reading, plan_to_read, completed, dropped, on_hold)
+//	@Param			sort	query		string	false	"Sort order"		Enums(updated, added, title, score, status)	default(updated)
+//	@Success		200		{array}		dto.BookmarkResponse
+//	@Failure		400		{object}	dto.ErrorResponse
+//	@Failure		403		{object}	dto.ErrorResponse
+//	@Failure		404		{object}	dto.ErrorResponse
+//	@Router			/users/{userID}/library [get]
+func (h *BookmarkHandler) Library(c *gin.Context) {
+	ownerID, err := uuid.Parse(c.Param("userID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+	viewerID := middleware.MustUserID(c)
+	bookmarks, err := h.bookmarkSvc.Library(c.Request.Context(), ownerID, viewerID, bookmarkFilter(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	items, err := h.libraryItems(c, bookmarks)
 	if err != nil {
 		respondError(c, err)
 		return
 	}
-	respondOK(c, dto.NewBookmarkResponseList(bookmarks))
+	if ownerID != viewerID {
+		for i := range items {
+			items[i].Notes = ""
+		}
+	}
+	respondOK(c, items)
+}
+
+func bookmarkFilter(c *gin.Context) repository.BookmarkFilter {
+	return repository.BookmarkFilter{
+		Status: model.BookmarkStatus(c.Query("status")),
+		Sort:   c.Query("sort"),
+	}
+}
+
+// libraryItems builds bookmark responses with their manga embedded.
+func (h *BookmarkHandler) libraryItems(c *gin.Context, bookmarks []*model.Bookmark) ([]dto.BookmarkResponse, error) {
+	ctx := c.Request.Context()
+	byID, err := h.bookmarkSvc.Mangas(ctx, bookmarks)
+	if err != nil {
+		return nil, err
+	}
+	mangas := make([]*model.Manga, 0, len(bookmarks))
+	for _, b := range bookmarks {
+		if m, ok := byID[b.MangaID]; ok {
+			mangas = append(mangas, m)
+		}
+	}
+	responses := dto.NewMangaResponses(ctx, h.urlCache, mangas)
+	embedded := make(map[uuid.UUID]*dto.MangaResponse, len(responses))
+	for i := range responses {
+		embedded[responses[i].ID] = &responses[i]
+	}
+
+	items := dto.NewBookmarkResponseList(bookmarks)
+	for i := range items {
+		items[i].Manga = embedded[items[i].MangaID]
+	}
+	return items, nil
 }
 
 // Get godoc
@@ -94,6 +189,11 @@ func (h *BookmarkHandler) Upsert(c *gin.Context) {
 	b, err := h.bookmarkSvc.Upsert(c.Request.Context(), userID, mangaID, service.UpsertBookmarkInput{
 		ChapterID:      req.ChapterID,
 		LastPageNumber: req.LastPageNumber,
+		Status:         req.Status,
+		Score:          req.Score,
+		StartedAt:      req.StartedAt,
+		FinishedAt:     req.FinishedAt,
+		Notes:          req.Notes,
 	})
 	if err != nil {
 		respondError(c, err)
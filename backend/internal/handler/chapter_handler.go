@@ -2,103 +2,367 @@ package handler
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/yumikokawaii/sherry-archive/internal/dto"
 	"github.com/yumikokawaii/sherry-archive/internal/middleware"
+	"github.com/yumikokawaii/sherry-archive/internal/model"
 	"github.com/yumikokawaii/sherry-archive/internal/service"
+	"github.com/yumikokawaii/sherry-archive/pkg/pagination"
+	"github.com/yumikokawaii/sherry-archive/pkg/urlcache"
 )
 
 type ChapterHandler struct {
 	chapterSvc *service.ChapterService
 	pageSvc    *service.PageService
+	urlCache   *urlcache.URLCache
 }
 
-func NewChapterHandler(chapterSvc *service.ChapterService, pageSvc *service.PageService) *ChapterHandler {
-	return &ChapterHandler{chapterSvc: chapterSvc, pageSvc: pageSvc}
+func NewChapterHandler(chapterSvc *service.ChapterService, pageSvc *service.PageService, urlCache *urlcache.URLCache) *ChapterHandler {
+	return &ChapterHandler{chapterSvc: chapterSvc, pageSvc: pageSvc, urlCache: urlCache}
+}
+
+// Latest godoc
+//
+//	@Summary		Latest chapter releases
+//	@Description	Published chapters, newest first, grouped by manga. Paginate with the returned next_cursor.
+//	@Tags			chapter
+//	@Produce		json
+//	@Param			tags[]		query		[]string	false	"Filter by manga tags (AND)"
+//	@Param			category	query		string		false	"Filter by manga category (partial match)"
+//	@Param			lang		query		string		false	"Chapters in this language (ISO 639-1) or without one"
+//	@Param			cursor		query		string		false	"next_cursor from the previous page"
+//	@Param			limit		query		int			false	"Chapters per page"	default(20)
+//	@Success		200			{object}	dto.LatestReleasesResponse
+//	@Failure		400			{object}	dto.ErrorResponse
+//	@Router			/chapters/latest [get]
+func (h *ChapterHandler) Latest(c *gin.Context) {
+	ctx := c.Request.Context()
+	releases, err := h.chapterSvc.ListLatest(ctx, service.LatestReleasesInput{
+		Tags:     c.QueryArray("tags[]"),
+		Category: c.Query("category"),
+		Language: c.Query("lang"),
+		Cursor:   c.Query("cursor"),
+		Limit:    pagination.FromQuery(c).Limit,
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	mangas := make([]*model.Manga, len(releases.Groups))
+	for i, g := range releases.Groups {
+		mangas[i] = g.Manga
+	}
+	mangaResponses := dto.NewMangaResponses(ctx, h.urlCache, mangas)
+
+	items := make([]dto.ReleaseGroupResponse, len(releases.Groups))
+	for i, g := range releases.Groups {
+		items[i] = dto.ReleaseGroupResponse{
+			Manga:    mangaResponses[i],
+			Chapters: dto.NewChapterResponseList(g.Chapters),
+		}
+	}
+	respondOK(c, dto.LatestReleasesResponse{Items: items, NextCursor: releases.NextCursor})
 }
 
 // List godoc
 //
-//	@Summary	List chapters for a manga
-//	@Tags		chapter
-//	@Produce	json
-//	@Param		mangaID	path		string	true	"Manga ID"
-//	@Success	200		{array}		dto.ChapterResponse
-//	@Failure	400		{object}	dto.ErrorResponse
-//	@Failure	404		{object}	dto.ErrorResponse
-//	@Router		/mangas/{mangaID}/chapters [get]
+//	@Summary		List chapters for a manga
+//	@Description	Without lang, signed-in users get their preferred language (all chapters if the manga has none in it).
+//	@Description	Chapters without a language are always included. lang=all disables the filter.
+//	@Tags			chapter
+//	@Produce		json
+//	@Param			mangaID	path		string	true	"Manga ID"
+//	@Description	Signed-in users also get each chapter's read_state.
+//	@Param			lang	query		string	false	"Language code (ISO 639-1) or all"
+//	@Success		200		{array}		dto.ChapterResponse
+//	@Failure		400		{object}	dto.ErrorResponse
+//	@Failure		404		{object}	dto.ErrorResponse
+//	@Router			/mangas/{mangaID}/chapters [get]
 func (h *ChapterHandler) List(c *gin.Context) {
 	mangaID, err := uuid.Parse(c.Param("mangaID"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid manga id"})
 		return
 	}
-	chapters, err := h.chapterSvc.ListByManga(c.Request.Context(), mangaID)
+	viewerID := middleware.MustUserID(c)
+	chapters, err := h.chapterSvc.ListByManga(c.Request.Context(), mangaID, service.ListChaptersInput{
+		Language: c.Query("lang"),
+		ViewerID: viewerID,
+	})
 	if err != nil {
 		respondError(c, err)
 		return
 	}
-	respondOK(c, dto.NewChapterResponseList(chapters))
+	items := dto.NewChapterResponseList(chapters)
+	if viewerID != uuid.Nil {
+		states, err := h.chapterSvc.ReadStates(c.Request.Context(), viewerID, mangaID)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		for i := range items {
+			items[i].ReadState = dto.NewReadStateResponse(states[items[i].ID])
+		}
+	}
+	respondOK(c, items)
 }
 
-// Get godoc
+// RecordRead godoc
 //
-//	@Summary	Get chapter with pages
+//	@Summary		Record reading progress in a chapter
+//	@Description	Progress only grows; reaching the last page (or completed) marks the chapter read.
+//	@Tags			chapter
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			mangaID		path		string					true	"Manga ID"
+//	@Param			chapterID	path		string					true	"Chapter ID"
+//	@Param			body		body		dto.RecordReadRequest	true	"Progress"
+//	@Success		200			{object}	dto.ReadStateResponse
+//	@Failure		400			{object}	dto.ErrorResponse
+//	@Failure		401			{object}	dto.ErrorResponse
+//	@Failure		404			{object}	dto.ErrorResponse
+//	@Router			/mangas/{mangaID}/chapters/{chapterID}/read [put]
+func (h *ChapterHandler) RecordRead(c *gin.Context) {
+	mangaID, chapterID, ok := parseChapterPath(c)
+	if !ok {
+		return
+	}
+	var req dto.RecordReadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	r, err := h.chapterSvc.RecordRead(c.Request.Context(), middleware.MustUserID(c), mangaID, chapterID, service.RecordReadInput{
+		PageNumber: req.PageNumber,
+		Completed:  req.Completed,
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondOK(c, dto.NewReadStateResponse(r))
+}
+
+// UnmarkRead godoc
+//
+//	@Summary	Mark a chapter unread
 //	@Tags		chapter
-//	@Produce	json
-//	@Param		mangaID		path		string	true	"Manga ID"
-//	@Param		chapterID	path		string	true	"Chapter ID"
-//	@Success	200			{object}	dto.ChapterWithPagesResponse
-//	@Failure	400			{object}	dto.ErrorResponse
-//	@Failure	404			{object}	dto.ErrorResponse
-//	@Router		/mangas/{mangaID}/chapters/{chapterID} [get]
-func (h *ChapterHandler) Get(c *gin.Context) {
+//	@Security	BearerAuth
+//	@Param		mangaID		path	string	true	"Manga ID"
+//	@Param		chapterID	path	string	true	"Chapter ID"
+//	@Success	204
+//	@Failure	400	{object}	dto.ErrorResponse
+//	@Failure	401	{object}	dto.ErrorResponse
+//	@Failure	404	{object}	dto.ErrorResponse
+//	@Router		/mangas/{mangaID}/chapters/{chapterID}/read [delete]
+func (h *ChapterHandler) UnmarkRead(c *gin.Context) {
+	mangaID, chapterID, ok := parseChapterPath(c)
+	if !ok {
+		return
+	}
+	if err := h.chapterSvc.UnmarkRead(c.Request.Context(), middleware.MustUserID(c), mangaID, chapterID); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// MarkReadUpTo godoc
+//
+//	@Summary		Mark chapters read up to a number
+//	@Description	Marks every published chapter numbered up to up_to read, among the chapters the list endpoint shows for language.
+//	@Tags			chapter
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			mangaID	path		string					true	"Manga ID"
+//	@Param			body	body		dto.MarkReadUpToRequest	true	"Last chapter to mark"
+//	@Success		200		{object}	dto.MarkReadUpToResponse
+//	@Failure		400		{object}	dto.ErrorResponse
+//	@Failure		401		{object}	dto.ErrorResponse
+//	@Failure		404		{object}	dto.ErrorResponse
+//	@Router			/mangas/{mangaID}/chapters/mark-read [post]
+func (h *ChapterHandler) MarkReadUpTo(c *gin.Context) {
 	mangaID, err := uuid.Parse(c.Param("mangaID"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid manga id"})
 		return
 	}
-	chapterID, err := uuid.Parse(c.Param("chapterID"))
+	var req dto.MarkReadUpToRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	n, err := h.chapterSvc.MarkReadUpTo(c.Request.Context(), middleware.MustUserID(c), mangaID, service.MarkReadUpToInput{
+		Number:   *req.UpTo,
+		Language: req.Language,
+	})
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid chapter id"})
+		respondError(c, err)
 		return
 	}
+	respondOK(c, dto.MarkReadUpToResponse{Marked: n})
+}
 
-	ch, err := h.chapterSvc.GetByID(c.Request.Context(), chapterID)
+// maxPrefetchPages caps ?prefetch= on the chapter endpoint.
+const maxPrefetchPages = 10
+
+// Get godoc
+//
+//	@Summary		Get chapter with pages
+//	@Description	Includes the previous and next published chapters in the chapter's language. With prefetch, the first pages of the next chapter are included too.
+//	@Tags			chapter
+//	@Produce		json
+//	@Param			mangaID		path		string	true	"Manga ID"
+//	@Param			chapterID	path		string	true	"Chapter ID"
+//	@Param			prefetch	query		int		false	"Pages of the next chapter to include (max 10)"
+//	@Success		200			{object}	dto.ChapterWithPagesResponse
+//	@Failure		400			{object}	dto.ErrorResponse
+//	@Failure		404			{object}	dto.ErrorResponse
+//	@Router			/mangas/{mangaID}/chapters/{chapterID} [get]
+func (h *ChapterHandler) Get(c *gin.Context) {
+	prefetch := 0
+	if v := c.Query("prefetch"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid prefetch"})
+			return
+		}
+		prefetch = min(n, maxPrefetchPages)
+	}
+
+	ch, ok := h.loadChapter(c)
+	if !ok {
+		return
+	}
+	ctx := c.Request.Context()
+
+	pages, urls, err := h.pageSvc.GetPagesWithURLs(ctx, ch.ID)
 	if err != nil {
 		respondError(c, err)
 		return
 	}
-	if ch.MangaID != mangaID {
-		c.JSON(http.StatusNotFound, gin.H{"error": "chapter not found"})
+	nav, err := h.chapterSvc.Neighbors(ctx, ch, middleware.MustUserID(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	resp := dto.ChapterWithPagesResponse{
+		Chapter: dto.NewChapterResponse(ch),
+		Pages:   pageItems(pages, urls),
+		Prev:    dto.NewChapterRefResponse(nav.Prev),
+		Next:    dto.NewChapterRefResponse(nav.Next),
+	}
+	if prefetch > 0 && nav.Next != nil {
+		nextPages, nextURLs, err := h.pageSvc.PrefetchPages(ctx, nav.Next.ID, prefetch)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		resp.NextPrefetch = pageItems(nextPages, nextURLs)
+	}
+	respondOK(c, resp)
+}
+
+// Manifest godoc
+//
+//	@Summary		Chapter reading manifest
+//	@Description	Page dimensions and prev/next chapters without signed URLs, for laying out the reader before images load.
+//	@Tags			chapter
+//	@Produce		json
+//	@Param			mangaID		path		string	true	"Manga ID"
+//	@Param			chapterID	path		string	true	"Chapter ID"
+//	@Success		200			{object}	dto.ChapterManifestResponse
+//	@Failure		400			{object}	dto.ErrorResponse
+//	@Failure		404			{object}	dto.ErrorResponse
+//	@Router			/mangas/{mangaID}/chapters/{chapterID}/manifest [get]
+func (h *ChapterHandler) Manifest(c *gin.Context) {
+	ch, ok := h.loadChapter(c)
+	if !ok {
 		return
 	}
+	ctx := c.Request.Context()
 
-	pages, urls, err := h.pageSvc.GetPagesWithURLs(c.Request.Context(), chapterID)
+	pages, err := h.pageSvc.GetPages(ctx, ch.ID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	nav, err := h.chapterSvc.Neighbors(ctx, ch, middleware.MustUserID(c))
 	if err != nil {
 		respondError(c, err)
 		return
 	}
 
-	pageItems := make([]dto.PageItemResponse, len(pages))
+	items := make([]dto.ManifestPageResponse, len(pages))
 	for i, p := range pages {
-		pageItems[i] = dto.PageItemResponse{
-			ID:     p.ID,
-			Number: p.Number,
-			URL:    urls[i],
-			Width:  p.Width,
-			Height: p.Height,
-		}
+		items[i] = dto.ManifestPageResponse{Number: p.Number, Width: p.Width, Height: p.Height}
 	}
-
-	respondOK(c, dto.ChapterWithPagesResponse{
+	respondOK(c, dto.ChapterManifestResponse{
 		Chapter: dto.NewChapterResponse(ch),
-		Pages:   pageItems,
+		Pages:   items,
+		Prev:    dto.NewChapterRefResponse(nav.Prev),
+		Next:    dto.NewChapterRefResponse(nav.Next),
 	})
 }
 
+// parseChapterPath parses :mangaID and :chapterID, writing the error response
+// when either is malformed.
+func parseChapterPath(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	mangaID, err := uuid.Parse(c.Param("mangaID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid manga id"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	chapterID, err := uuid.Parse(c.Param("chapterID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid chapter id"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return mangaID, chapterID, true
+}
+
+// loadChapter resolves the :mangaID/:chapterID path, writing the error response
+// when it doesn't name a chapter of that manga.
+func (h *ChapterHandler) loadChapter(c *gin.Context) (*model.Chapter, bool) {
+	mangaID, chapterID, ok := parseChapterPath(c)
+	if !ok {
+		return nil, false
+	}
+
+	ch, err := h.chapterSvc.GetByID(c.Request.Context(), chapterID)
+	if err != nil {
+		respondError(c, err)
+		return nil, false
+	}
+	if ch.MangaID != mangaID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "chapter not found"})
+		return nil, false
+	}
+	return ch, true
+}
+
+func pageItems(pages []*model.Page, urls []service.PageURLs) []dto.PageItemResponse {
+	items := make([]dto.PageItemResponse, len(pages))
+	for i, p := range pages {
+		items[i] = dto.PageItemResponse{
+			ID:           p.ID,
+			Number:       p.Number,
+			URL:          urls[i].URL,
+			ThumbnailURL: urls[i].ThumbnailURL,
+			Width:        p.Width,
+			Height:       p.Height,
+		}
+	}
+	return items
+}
+
 // Create godoc
 //
 //	@Summary	Create chapter
@@ -129,9 +393,11 @@ func (h *ChapterHandler) Create(c *gin.Context) {
 	}
 
 	ch, err := h.chapterSvc.Create(c.Request.Context(), userID, service.CreateChapterInput{
-		MangaID: mangaID,
-		Number:  req.Number,
-		Title:   req.Title,
+		MangaID:  mangaID,
+		Number:   req.Number,
+		Title:    req.Title,
+		Volume:   req.Volume,
+		Language: req.Language,
 	})
 	if err != nil {
 		respondError(c, err)
@@ -171,8 +437,10 @@ func (h *ChapterHandler) Update(c *gin.Context) {
 	}
 
 	ch, err := h.chapterSvc.Update(c.Request.Context(), userID, chapterID, service.UpdateChapterInput{
-		Number: req.Number,
-		Title:  req.Title,
+		Number:   req.Number,
+		Title:    req.Title,
+		Volume:   req.Volume,
+		Language: req.Language,
 	})
 	if err != nil {
 		respondError(c, err)
@@ -76,7 +76,7 @@ func (h *CommentHandler) CreateManga(c *gin.Context) {
 		return
 	}
 	userID := middleware.MustUserID(c)
-	comment, err := h.commentSvc.CreateMangaComment(c.Request.Context(), userID, mangaID, req.Content)
+	comment, err := h.commentSvc.CreateMangaComment(c.Request.Context(), userID, mangaID, req.Content, req.ParentID)
 	if err != nil {
 		respondError(c, err)
 		return
@@ -153,7 +153,7 @@ func (h *CommentHandler) CreateChapter(c *gin.Context) {
 		return
 	}
 	userID := middleware.MustUserID(c)
-	comment, err := h.commentSvc.CreateChapterComment(c.Request.Context(), userID, mangaID, chapterID, req.Content)
+	comment, err := h.commentSvc.CreateChapterComment(c.Request.Context(), userID, mangaID, chapterID, req.Content, req.ParentID)
 	if err != nil {
 		respondError(c, err)
 		return
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/yumikokawaii/sherry-archive/internal/dto"
+	"github.com/yumikokawaii/sherry-archive/internal/middleware"
+	"github.com/yumikokawaii/sherry-archive/internal/service"
+	"go.uber.org/zap"
+)
+
+type ExportHandler struct {
+	exportSvc     *service.ExportService
+	uploadTaskSvc *service.UploadTaskService
+}
+
+func NewExportHandler(exportSvc *service.ExportService, uploadTaskSvc *service.UploadTaskService) *ExportHandler {
+	return &ExportHandler{exportSvc: exportSvc, uploadTaskSvc: uploadTaskSvc}
+}
+
+// ChapterCBZ godoc
+//
+//	@Summary	Download a chapter as CBZ
+//	@Description	Streams the chapter's pages as a CBZ archive with a generated ComicInfo.xml.
+//	@Tags		export
+//	@Produce	application/vnd.comicbook+zip
+//	@Security	BearerAuth
+//	@Param		mangaID		path		string	true	"Manga ID"
+//	@Param		chapterID	path		string	true	"Chapter ID"
+//	@Success	200			{file}		binary
+//	@Failure	400			{object}	dto.ErrorResponse
+//	@Failure	401			{object}	dto.ErrorResponse
+//	@Failure	404			{object}	dto.ErrorResponse	"Chapter not found or has no pages"
+//	@Router		/mangas/{mangaID}/chapters/{chapterID}/export/cbz [get]
+func (h *ExportHandler) ChapterCBZ(c *gin.Context) {
+	mangaID, chapterID, ok := parseMangaChapter(c)
+	if !ok {
+		return
+	}
+
+	export, err := h.exportSvc.GetChapterExport(c.Request.Context(), mangaID, chapterID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Header("Content-Type", "application/vnd.comicbook+zip")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", export.Filename()))
+	c.Status(http.StatusOK)
+	// Headers are already sent — a failure here can only be logged and the stream cut short.
+	if err := h.exportSvc.WriteChapterCBZ(c.Request.Context(), c.Writer, export); err != nil {
+		zap.L().Error("export: chapter cbz", zap.String("chapter_id", chapterID.String()), zap.Error(err))
+		c.Abort()
+	}
+}
+
+// ExportManga godoc
+//
+//	@Summary	Export a whole manga (async)
+//	@Description	Builds a CBZ (one folder per chapter) or a fixed-layout EPUB in the background. Returns 202 with a task_id; the task's download_url is set once done.
+//	@Tags		export
+//	@Accept		json
+//	@Produce	json
+//	@Security	BearerAuth
+//	@Param		mangaID	path		string				true	"Manga ID"
+//	@Param		body	body		dto.ExportRequest	true	"Export format"
+//	@Success	202		{object}	dto.EnqueueResponse
+//	@Failure	400		{object}	dto.ErrorResponse
+//	@Failure	401		{object}	dto.ErrorResponse
+//	@Failure	404		{object}	dto.ErrorResponse
+//	@Router		/mangas/{mangaID}/export [post]
+func (h *ExportHandler) ExportManga(c *gin.Context) {
+	userID := middleware.MustUserID(c)
+	mangaID, err := uuid.Parse(c.Param("mangaID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid manga id"})
+		return
+	}
+
+	var req dto.ExportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	task, err := h.uploadTaskSvc.EnqueueExport(c.Request.Context(), userID, mangaID, req.Format)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"data": dto.EnqueueResponse{TaskID: task.ID}})
+}
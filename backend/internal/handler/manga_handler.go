@@ -3,7 +3,9 @@ package handler
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -12,48 +14,29 @@ import (
 	"github.com/yumikokawaii/sherry-archive/internal/model"
 	"github.com/yumikokawaii/sherry-archive/internal/repository"
 	"github.com/yumikokawaii/sherry-archive/internal/service"
+	"github.com/yumikokawaii/sherry-archive/pkg/imageproc"
 	"github.com/yumikokawaii/sherry-archive/pkg/pagination"
-	"github.com/yumikokawaii/sherry-archive/pkg/storage"
 	"github.com/yumikokawaii/sherry-archive/pkg/urlcache"
 )
 
 type MangaHandler struct {
 	mangaSvc *service.MangaService
-	storage  *storage.Client
+	coverSvc *service.CoverService
 	urlCache *urlcache.URLCache
 }
 
-func NewMangaHandler(mangaSvc *service.MangaService, storage *storage.Client, urlCache *urlcache.URLCache) *MangaHandler {
-	return &MangaHandler{mangaSvc: mangaSvc, storage: storage, urlCache: urlCache}
-}
-
-// resolveCoverURL converts a stored object key to a cached presigned URL.
-// Returns empty string if key is empty or signing fails.
-func (h *MangaHandler) resolveCoverURL(ctx context.Context, key string) string {
-	url, _ := h.urlCache.Resolve(ctx, key)
-	return url
+func NewMangaHandler(mangaSvc *service.MangaService, coverSvc *service.CoverService, urlCache *urlcache.URLCache) *MangaHandler {
+	return &MangaHandler{mangaSvc: mangaSvc, coverSvc: coverSvc, urlCache: urlCache}
 }
 
+// toResponse resolves the cover keys to cached presigned URLs; keys that fail to
+// sign get empty URLs.
 func (h *MangaHandler) toResponse(ctx context.Context, m *model.Manga) dto.MangaResponse {
-	return dto.NewMangaResponse(m, h.resolveCoverURL(ctx, m.CoverKey))
+	return dto.NewMangaResponses(ctx, h.urlCache, []*model.Manga{m})[0]
 }
 
 func (h *MangaHandler) toResponseList(ctx context.Context, ms []*model.Manga) []dto.MangaResponse {
-	keys := make([]string, len(ms))
-	for i, m := range ms {
-		keys[i] = m.CoverKey
-	}
-	urls, _ := h.urlCache.ResolveMany(ctx, keys)
-
-	out := make([]dto.MangaResponse, len(ms))
-	for i, m := range ms {
-		url := ""
-		if i < len(urls) {
-			url = urls[i]
-		}
-		out[i] = dto.NewMangaResponse(m, url)
-	}
-	return out
+	return dto.NewMangaResponses(ctx, h.urlCache, ms)
 }
 
 // List godoc
@@ -67,7 +50,7 @@ func (h *MangaHandler) toResponseList(ctx context.Context, ms []*model.Manga) []
 //	@Param		author		query		string		false	"Filter by author (partial match)"
 //	@Param		artist		query		string		false	"Filter by artist (partial match)"
 //	@Param		category	query		string		false	"Filter by category (partial match)"
-//	@Param		sort		query		string		false	"Sort order"	Enums(newest, oldest, title)
+//	@Param		sort		query		string		false	"Sort order"	Enums(newest, oldest, title, popular, trending, updated, chapters, rating)
 //	@Param		page		query		int			false	"Page number"	default(1)
 //	@Param		limit		query		int			false	"Items per page"	default(24)
 //	@Success	200			{object}	dto.PagedMangaResponse
@@ -240,19 +223,25 @@ func (h *MangaHandler) Delete(c *gin.Context) {
 
 // UpdateCover godoc
 //
-//	@Summary	Upload manga cover
-//	@Tags		manga
-//	@Accept		mpfd
-//	@Produce	json
-//	@Security	BearerAuth
-//	@Param		mangaID	path		string	true	"Manga ID"
-//	@Param		cover	formData	file	true	"Cover image (jpeg/png/webp)"
-//	@Success	200		{object}	dto.MangaResponse
-//	@Failure	400		{object}	dto.ErrorResponse
-//	@Failure	401		{object}	dto.ErrorResponse
-//	@Failure	403		{object}	dto.ErrorResponse
-//	@Failure	404		{object}	dto.ErrorResponse
-//	@Router		/mangas/{mangaID}/cover [put]
+//	@Summary		Upload manga cover
+//	@Description	The image is cropped to the optional crop_* rectangle (all four or none) and stored as WebP variants
+//	@Description	(IMAGE__COVER_WIDTHS, never upscaled), returned as cover_srcset; cover_url is the largest.
+//	@Tags			manga
+//	@Accept			mpfd
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			mangaID		path		string	true	"Manga ID"
+//	@Param			cover		formData	file	true	"Cover image (jpeg/png/webp)"
+//	@Param			crop_x		formData	int		false	"Crop left edge (px)"
+//	@Param			crop_y		formData	int		false	"Crop top edge (px)"
+//	@Param			crop_width	formData	int		false	"Crop width (px)"
+//	@Param			crop_height	formData	int		false	"Crop height (px)"
+//	@Success		200			{object}	dto.MangaResponse
+//	@Failure		400			{object}	dto.ErrorResponse
+//	@Failure		401			{object}	dto.ErrorResponse
+//	@Failure		403			{object}	dto.ErrorResponse
+//	@Failure		404			{object}	dto.ErrorResponse
+//	@Router			/mangas/{mangaID}/cover [put]
 func (h *MangaHandler) UpdateCover(c *gin.Context) {
 	userID := middleware.MustUserID(c)
 	mangaID, err := uuid.Parse(c.Param("mangaID"))
@@ -266,8 +255,13 @@ func (h *MangaHandler) UpdateCover(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "cover file is required"})
 		return
 	}
+	crop, err := cropForm(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	f, mime, size, err := openUpload(fileHeader)
+	f, mime, _, err := openUpload(fileHeader)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -278,15 +272,54 @@ func (h *MangaHandler) UpdateCover(c *gin.Context) {
 		respondError(c, err)
 		return
 	}
+	src, err := io.ReadAll(f)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
 
-	// Store by object key — presigned URL is resolved at read time
-	objectKey := fmt.Sprintf("covers/%s/%s", mangaID, uuid.Must(uuid.NewV7()))
-	if err := h.storage.PutObject(c.Request.Context(), objectKey, mime, f, size); err != nil {
+	m, err := h.coverSvc.Upload(c.Request.Context(), userID, mangaID, src, crop)
+	if err != nil {
 		respondError(c, err)
 		return
 	}
+	respondOK(c, h.toResponse(c.Request.Context(), m))
+}
+
+// SetCoverFromPage godoc
+//
+//	@Summary		Use a page as the manga cover
+//	@Description	Renders the cover variants from a page of one of the manga's chapters, optionally cropped.
+//	@Tags			manga
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			mangaID	path		string						true	"Manga ID"
+//	@Param			body	body		dto.SetCoverFromPageRequest	true	"Source page and crop"
+//	@Success		200		{object}	dto.MangaResponse
+//	@Failure		400		{object}	dto.ErrorResponse
+//	@Failure		401		{object}	dto.ErrorResponse
+//	@Failure		403		{object}	dto.ErrorResponse
+//	@Failure		404		{object}	dto.ErrorResponse
+//	@Router			/mangas/{mangaID}/cover/page [put]
+func (h *MangaHandler) SetCoverFromPage(c *gin.Context) {
+	userID := middleware.MustUserID(c)
+	mangaID, err := uuid.Parse(c.Param("mangaID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid manga id"})
+		return
+	}
+	var req dto.SetCoverFromPageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	m, err := h.mangaSvc.UpdateCover(c.Request.Context(), userID, mangaID, objectKey)
+	var crop *imageproc.Crop
+	if req.Crop != nil {
+		crop = &imageproc.Crop{X: req.Crop.X, Y: req.Crop.Y, Width: req.Crop.Width, Height: req.Crop.Height}
+	}
+	m, err := h.coverSvc.FromPage(c.Request.Context(), userID, mangaID, req.ChapterID, req.PageNumber, crop)
 	if err != nil {
 		respondError(c, err)
 		return
@@ -294,6 +327,33 @@ func (h *MangaHandler) UpdateCover(c *gin.Context) {
 	respondOK(c, h.toResponse(c.Request.Context(), m))
 }
 
+// cropForm reads the optional crop_x, crop_y, crop_width and crop_height form fields.
+func cropForm(c *gin.Context) (*imageproc.Crop, error) {
+	fields := []string{"crop_x", "crop_y", "crop_width", "crop_height"}
+	values := make([]int, len(fields))
+	set := 0
+	for i, name := range fields {
+		v := c.PostForm(name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s", name)
+		}
+		values[i] = n
+		set++
+	}
+	switch set {
+	case 0:
+		return nil, nil
+	case len(fields):
+		return &imageproc.Crop{X: values[0], Y: values[1], Width: values[2], Height: values[3]}, nil
+	default:
+		return nil, fmt.Errorf("crop_x, crop_y, crop_width and crop_height must be given together")
+	}
+}
+
 // ListByUser godoc
 //
 //	@Summary	List manga by user
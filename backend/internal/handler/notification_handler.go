@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/yumikokawaii/sherry-archive/internal/dto"
+	"github.com/yumikokawaii/sherry-archive/internal/middleware"
+	"github.com/yumikokawaii/sherry-archive/internal/service"
+	"github.com/yumikokawaii/sherry-archive/pkg/pagination"
+)
+
+type NotificationHandler struct {
+	notificationSvc *service.NotificationService
+}
+
+func NewNotificationHandler(notificationSvc *service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{notificationSvc: notificationSvc}
+}
+
+// List godoc
+//
+//	@Summary	List my notifications
+//	@Tags		notification
+//	@Produce	json
+//	@Security	BearerAuth
+//	@Param		unread	query		bool	false	"Only unread notifications"
+//	@Param		page	query		int		false	"Page"
+//	@Param		limit	query		int		false	"Limit"
+//	@Success	200		{object}	dto.PagedNotificationResponse
+//	@Failure	401		{object}	dto.ErrorResponse
+//	@Router		/users/me/notifications [get]
+func (h *NotificationHandler) List(c *gin.Context) {
+	userID := middleware.MustUserID(c)
+	p := pagination.FromQuery(c)
+	rows, total, err := h.notificationSvc.List(c.Request.Context(), userID, c.Query("unread") == "true", p)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondOK(c, dto.PagedNotificationResponse{
+		Items: dto.NewNotificationResponses(rows),
+		Total: total,
+		Page:  p.Page,
+		Limit: p.Limit,
+	})
+}
+
+// UnreadCount godoc
+//
+//	@Summary	Count my unread notifications
+//	@Tags		notification
+//	@Produce	json
+//	@Security	BearerAuth
+//	@Success	200	{object}	dto.UnreadCountResponse
+//	@Failure	401	{object}	dto.ErrorResponse
+//	@Router		/users/me/notifications/unread-count [get]
+func (h *NotificationHandler) UnreadCount(c *gin.Context) {
+	n, err := h.notificationSvc.UnreadCount(c.Request.Context(), middleware.MustUserID(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondOK(c, dto.UnreadCountResponse{Count: n})
+}
+
+// MarkRead godoc
+//
+//	@Summary	Mark a notification read
+//	@Tags		notification
+//	@Security	BearerAuth
+//	@Param		notificationID	path	string	true	"Notification ID"
+//	@Success	204				"No Content"
+//	@Failure	400				{object}	dto.ErrorResponse
+//	@Failure	401				{object}	dto.ErrorResponse
+//	@Failure	404				{object}	dto.ErrorResponse
+//	@Router		/users/me/notifications/{notificationID}/read [put]
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("notificationID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid notification id"})
+		return
+	}
+	if err := h.notificationSvc.MarkRead(c.Request.Context(), middleware.MustUserID(c), id); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// MarkAllRead godoc
+//
+//	@Summary	Mark all my notifications read
+//	@Tags		notification
+//	@Produce	json
+//	@Security	BearerAuth
+//	@Success	200	{object}	dto.MarkAllReadResponse
+//	@Failure	401	{object}	dto.ErrorResponse
+//	@Router		/users/me/notifications/read-all [post]
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	n, err := h.notificationSvc.MarkAllRead(c.Request.Context(), middleware.MustUserID(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondOK(c, dto.MarkAllReadResponse{Marked: n})
+}
+
+// GetPreferences godoc
+//
+//	@Summary	Get my notification preferences
+//	@Tags		notification
+//	@Produce	json
+//	@Security	BearerAuth
+//	@Success	200	{object}	dto.NotificationPreferencesResponse
+//	@Failure	401	{object}	dto.ErrorResponse
+//	@Router		/users/me/notifications/preferences [get]
+func (h *NotificationHandler) GetPreferences(c *gin.Context) {
+	prefs, err := h.notificationSvc.Preferences(c.Request.Context(), middleware.MustUserID(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondOK(c, dto.NewNotificationPreferencesResponse(prefs))
+}
+
+// UpdatePreferences godoc
+//
+//	@Summary	Turn notification types on or off
+//	@Tags		notification
+//	@Accept		json
+//	@Produce	json
+//	@Security	BearerAuth
+//	@Param		body	body		dto.NotificationPreferencesRequest	true	"Types to change"
+//	@Success	200		{object}	dto.NotificationPreferencesResponse
+//	@Failure	400		{object}	dto.ErrorResponse
+//	@Failure	401		{object}	dto.ErrorResponse
+//	@Router		/users/me/notifications/preferences [patch]
+func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
+	var req dto.NotificationPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	prefs, err := h.notificationSvc.UpdatePreferences(c.Request.Context(), middleware.MustUserID(c), req.Changes())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondOK(c, dto.NewNotificationPreferencesResponse(prefs))
+}
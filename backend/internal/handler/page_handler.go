@@ -92,16 +92,17 @@ func (h *PageHandler) Upload(c *gin.Context) {
 
 // UploadZip godoc
 //
-//	@Summary	Upload pages from ZIP (async)
-//	@Description	Enqueues a ZIP upload for async processing. Returns 202 with a task_id to poll for status.
+//	@Summary	Upload pages from an archive (async)
+//	@Description	Enqueues an archive upload for async processing. Accepts zip/cbz, rar/cbr, 7z, tar(.gz) or PDF, detected from the content. Returns 202 with a task_id to poll for status.
 //	@Tags		page
 //	@Accept		mpfd
 //	@Produce	json
 //	@Security	BearerAuth
 //	@Param		mangaID		path		string	true	"Manga ID"
 //	@Param		chapterID	path		string	true	"Chapter ID"
-//	@Param		file		formData	file	true	"ZIP archive"
-//	@Success	202			{object}	dto.EnqueueResponse
+//	@Param		file			formData	file	true	"Archive (zip/cbz, rar/cbr, 7z, tar, tar.gz) or PDF"
+//	@Param		apply_metadata	formData	bool	false	"Merge metadata.json / ComicInfo.xml into the manga and set the chapter number and title"
+//	@Success	202				{object}	dto.EnqueueResponse
 //	@Failure	400			{object}	dto.ErrorResponse
 //	@Failure	401			{object}	dto.ErrorResponse
 //	@Failure	403			{object}	dto.ErrorResponse
@@ -115,7 +116,7 @@ func (h *PageHandler) UploadZip(c *gin.Context) {
 
 	fileHeader, err := c.FormFile("file")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "zip file is required (field: file)"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "archive file is required (field: file)"})
 		return
 	}
 
@@ -126,7 +127,7 @@ func (h *PageHandler) UploadZip(c *gin.Context) {
 	}
 	defer f.Close()
 
-	task, err := h.uploadTaskSvc.EnqueueZipUpload(c.Request.Context(), userID, mangaID, chapterID, f)
+	task, err := h.uploadTaskSvc.EnqueueZipUpload(c.Request.Context(), userID, mangaID, chapterID, f, applyMetadataForm(c))
 	if err != nil {
 		respondError(c, err)
 		return
@@ -136,16 +137,17 @@ func (h *PageHandler) UploadZip(c *gin.Context) {
 
 // UploadOneshotZip godoc
 //
-//	@Summary	Upload oneshot ZIP (async)
-//	@Description	For oneshot manga only. Enqueues zip processing; Lambda creates the chapter and uploads pages. Returns 202 with a task_id to poll for status.
+//	@Summary	Upload oneshot archive (async)
+//	@Description	For oneshot manga only. Accepts zip/cbz, rar/cbr, 7z, tar(.gz) or PDF. Enqueues processing; Lambda creates the chapter and uploads pages. Returns 202 with a task_id to poll for status.
 //	@Tags		page
 //	@Accept		mpfd
 //	@Produce	json
 //	@Security	BearerAuth
 //	@Param		mangaID	path		string	true	"Manga ID (must be type=oneshot)"
-//	@Param		file	formData	file	true	"ZIP archive"
-//	@Success	202		{object}	dto.EnqueueResponse
-//	@Failure	400		{object}	dto.ErrorResponse	"Not a oneshot or invalid ZIP"
+//	@Param		file			formData	file	true	"Archive (zip/cbz, rar/cbr, 7z, tar, tar.gz) or PDF"
+//	@Param		apply_metadata	formData	bool	false	"Merge metadata.json / ComicInfo.xml into the manga"
+//	@Success	202				{object}	dto.EnqueueResponse
+//	@Failure	400		{object}	dto.ErrorResponse	"Not a oneshot or unsupported archive"
 //	@Failure	401		{object}	dto.ErrorResponse
 //	@Failure	403		{object}	dto.ErrorResponse
 //	@Router		/mangas/{mangaID}/oneshot/upload [post]
@@ -159,7 +161,7 @@ func (h *PageHandler) UploadOneshotZip(c *gin.Context) {
 
 	fileHeader, err := c.FormFile("file")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "zip file is required (field: file)"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "archive file is required (field: file)"})
 		return
 	}
 
@@ -170,7 +172,52 @@ func (h *PageHandler) UploadOneshotZip(c *gin.Context) {
 	}
 	defer f.Close()
 
-	task, err := h.uploadTaskSvc.EnqueueOneshotZipUpload(c.Request.Context(), userID, mangaID, f)
+	task, err := h.uploadTaskSvc.EnqueueOneshotZipUpload(c.Request.Context(), userID, mangaID, f, applyMetadataForm(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"data": dto.EnqueueResponse{TaskID: task.ID}})
+}
+
+// UploadSeriesZip godoc
+//
+//	@Summary	Import a whole series from an archive (async)
+//	@Description	For series manga only. Each top-level folder (or inner archive such as a .cbz/.cbr/.pdf) becomes a chapter, numbered from its metadata.json, ComicInfo.xml or folder name (e.g. "Chapter 12 - Title"). Returns 202 with a task_id; per-chapter results are reported on the task.
+//	@Tags		page
+//	@Accept		mpfd
+//	@Produce	json
+//	@Security	BearerAuth
+//	@Param		mangaID	path		string	true	"Manga ID (must be type=series)"
+//	@Param		file			formData	file	true	"Archive with one folder or inner archive per chapter"
+//	@Param		apply_metadata	formData	bool	false	"Merge the chapters' metadata.json / ComicInfo.xml into the manga"
+//	@Success	202				{object}	dto.EnqueueResponse
+//	@Failure	400		{object}	dto.ErrorResponse	"Not a series or unsupported archive"
+//	@Failure	401		{object}	dto.ErrorResponse
+//	@Failure	403		{object}	dto.ErrorResponse
+//	@Router		/mangas/{mangaID}/series/upload [post]
+func (h *PageHandler) UploadSeriesZip(c *gin.Context) {
+	userID := middleware.MustUserID(c)
+	mangaID, err := uuid.Parse(c.Param("mangaID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid manga id"})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "archive file is required (field: file)"})
+		return
+	}
+
+	f, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot open uploaded file"})
+		return
+	}
+	defer f.Close()
+
+	task, err := h.uploadTaskSvc.EnqueueSeriesZipUpload(c.Request.Context(), userID, mangaID, f, applyMetadataForm(c))
 	if err != nil {
 		respondError(c, err)
 		return
@@ -261,6 +308,12 @@ func parseMangaChapter(c *gin.Context) (mangaID, chapterID uuid.UUID, ok bool) {
 	return mangaID, chapterID, true
 }
 
+// applyMetadataForm reads the optional apply_metadata form field ("true", "1", …).
+func applyMetadataForm(c *gin.Context) bool {
+	apply, _ := strconv.ParseBool(c.PostForm("apply_metadata"))
+	return apply
+}
+
 func toPageUploadResponseList(pages []*model.Page) []dto.PageUploadResponse {
 	out := make([]dto.PageUploadResponse, len(pages))
 	for i, p := range pages {
@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/yumikokawaii/sherry-archive/internal/dto"
+	"github.com/yumikokawaii/sherry-archive/internal/middleware"
+	"github.com/yumikokawaii/sherry-archive/internal/repository"
+	"github.com/yumikokawaii/sherry-archive/internal/service"
+	"github.com/yumikokawaii/sherry-archive/pkg/pagination"
+)
+
+type RatingHandler struct {
+	ratingSvc *service.RatingService
+}
+
+func NewRatingHandler(ratingSvc *service.RatingService) *RatingHandler {
+	return &RatingHandler{ratingSvc: ratingSvc}
+}
+
+// GetRating godoc
+//
+//	@Summary	Get my rating of a manga
+//	@Tags		rating
+//	@Produce	json
+//	@Security	BearerAuth
+//	@Param		mangaID	path		string	true	"Manga ID"
+//	@Success	200		{object}	dto.RatingResponse
+//	@Failure	401		{object}	dto.ErrorResponse
+//	@Failure	404		{object}	dto.ErrorResponse
+//	@Router		/mangas/{mangaID}/rating [get]
+func (h *RatingHandler) Get(c *gin.Context) {
+	mangaID, err := uuid.Parse(c.Param("mangaID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid manga id"})
+		return
+	}
+	r, err := h.ratingSvc.Get(c.Request.Context(), middleware.MustUserID(c), mangaID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondOK(c, dto.NewRatingResponse(r))
+}
+
+// RateManga godoc
+//
+//	@Summary		Rate a manga
+//	@Description	Creates or replaces my 1–10 rating, with an optional review. Changing the review text clears its helpful votes. Owners can't rate their own manga.
+//	@Tags			rating
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			mangaID	path		string					true	"Manga ID"
+//	@Param			body	body		dto.RateMangaRequest	true	"Rating"
+//	@Success		200		{object}	dto.RatingResponse
+//	@Failure		400		{object}	dto.ErrorResponse
+//	@Failure		401		{object}	dto.ErrorResponse
+//	@Failure		403		{object}	dto.ErrorResponse
+//	@Failure		404		{object}	dto.ErrorResponse
+//	@Router			/mangas/{mangaID}/rating [put]
+func (h *RatingHandler) Rate(c *gin.Context) {
+	mangaID, err := uuid.Parse(c.Param("mangaID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid manga id"})
+		return
+	}
+	var req dto.RateMangaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	r, err := h.ratingSvc.Rate(c.Request.Context(), middleware.MustUserID(c), mangaID, service.RateInput{
+		Score:  req.Score,
+		Review: req.Review,
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondOK(c, dto.NewRatingResponse(r))
+}
+
+// DeleteRating godoc
+//
+//	@Summary	Remove my rating of a manga
+//	@Tags		rating
+//	@Security	BearerAuth
+//	@Param		mangaID	path	string	true	"Manga ID"
+//	@Success	204
+//	@Failure	401	{object}	dto.ErrorResponse
+//	@Failure	404	{object}	dto.ErrorResponse
+//	@Router		/mangas/{mangaID}/rating [delete]
+func (h *RatingHandler) Delete(c *gin.Context) {
+	mangaID, err := uuid.Parse(c.Param("mangaID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid manga id"})
+		return
+	}
+	if err := h.ratingSvc.Delete(c.Request.Context(), middleware.MustUserID(c), mangaID); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListReviews godoc
+//
+//	@Summary	List written reviews of a manga
+//	@Tags		rating
+//	@Produce	json
+//	@Param		mangaID	path		string	true	"Manga ID"
+//	@Param		sort	query		string	false	"Sort order"	Enums(helpful, newest)	default(helpful)
+//	@Param		page	query		int		false	"Page"
+//	@Param		limit	query		int		false	"Limit"
+//	@Success	200		{object}	dto.PagedReviewResponse
+//	@Failure	400		{object}	dto.ErrorResponse
+//	@Failure	404		{object}	dto.ErrorResponse
+//	@Router		/mangas/{mangaID}/reviews [get]
+func (h *RatingHandler) ListReviews(c *gin.Context) {
+	mangaID, err := uuid.Parse(c.Param("mangaID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid manga id"})
+		return
+	}
+	p := pagination.FromQuery(c)
+	rows, total, err := h.ratingSvc.ListReviews(c.Request.Context(), repository.ReviewFilter{
+		MangaID:  mangaID,
+		ViewerID: middleware.MustUserID(c),
+		Sort:     c.Query("sort"),
+	}, p)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondOK(c, dto.PagedReviewResponse{
+		Items: dto.NewReviewResponses(rows),
+		Total: total,
+		Page:  p.Page,
+		Limit: p.Limit,
+	})
+}
+
+// MarkReviewHelpful godoc
+//
+//	@Summary	Vote a review helpful
+//	@Tags		rating
+//	@Security	BearerAuth
+//	@Param		mangaID		path	string	true	"Manga ID"
+//	@Param		reviewID	path	string	true	"Review ID"
+//	@Success	204
+//	@Failure	401	{object}	dto.ErrorResponse
+//	@Failure	403	{object}	dto.ErrorResponse
+//	@Failure	404	{object}	dto.ErrorResponse
+//	@Router		/mangas/{mangaID}/reviews/{reviewID}/helpful [put]
+func (h *RatingHandler) MarkHelpful(c *gin.Context) {
+	h.setHelpful(c, true)
+}
+
+// UnmarkReviewHelpful godoc
+//
+//	@Summary	Withdraw a helpful vote
+//	@Tags		rating
+//	@Security	BearerAuth
+//	@Param		mangaID		path	string	true	"Manga ID"
+//	@Param		reviewID	path	string	true	"Review ID"
+//	@Success	204
+//	@Failure	401	{object}	dto.ErrorResponse
+//	@Failure	403	{object}	dto.ErrorResponse
+//	@Failure	404	{object}	dto.ErrorResponse
+//	@Router		/mangas/{mangaID}/reviews/{reviewID}/helpful [delete]
+func (h *RatingHandler) UnmarkHelpful(c *gin.Context) {
+	h.setHelpful(c, false)
+}
+
+func (h *RatingHandler) setHelpful(c *gin.Context, helpful bool) {
+	mangaID, err := uuid.Parse(c.Param("mangaID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid manga id"})
+		return
+	}
+	reviewID, err := uuid.Parse(c.Param("reviewID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid review id"})
+		return
+	}
+	if err := h.ratingSvc.SetHelpful(c.Request.Context(), middleware.MustUserID(c), mangaID, reviewID, helpful); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,315 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/yumikokawaii/sherry-archive/internal/dto"
+	"github.com/yumikokawaii/sherry-archive/internal/middleware"
+	"github.com/yumikokawaii/sherry-archive/internal/service"
+	"github.com/yumikokawaii/sherry-archive/pkg/pagination"
+	"github.com/yumikokawaii/sherry-archive/pkg/urlcache"
+)
+
+type ReadingListHandler struct {
+	listSvc  *service.ReadingListService
+	urlCache *urlcache.URLCache
+}
+
+func NewReadingListHandler(listSvc *service.ReadingListService, urlCache *urlcache.URLCache) *ReadingListHandler {
+	return &ReadingListHandler{listSvc: listSvc, urlCache: urlCache}
+}
+
+// ListMine godoc
+//
+//	@Summary	List my reading lists
+//	@Tags		list
+//	@Produce	json
+//	@Security	BearerAuth
+//	@Success	200	{array}		dto.ReadingListResponse
+//	@Failure	401	{object}	dto.ErrorResponse
+//	@Router		/users/me/lists [get]
+func (h *ReadingListHandler) ListMine(c *gin.Context) {
+	userID := middleware.MustUserID(c)
+	lists, err := h.listSvc.ListByUser(c.Request.Context(), userID, userID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondOK(c, dto.NewReadingListResponses(lists))
+}
+
+// ListByUser godoc
+//
+//	@Summary	List a user's public reading lists
+//	@Tags		list
+//	@Produce	json
+//	@Param		userID	path		string	true	"User ID"
+//	@Success	200		{array}		dto.ReadingListResponse
+//	@Failure	400		{object}	dto.ErrorResponse
+//	@Router		/users/{userID}/lists [get]
+func (h *ReadingListHandler) ListByUser(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("userID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+	lists, err := h.listSvc.ListByUser(c.Request.Context(), userID, middleware.MustUserID(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondOK(c, dto.NewReadingListResponses(lists))
+}
+
+// GetBySlug godoc
+//
+//	@Summary		Get a reading list
+//	@Description	Private lists are only visible to their owner.
+//	@Tags			list
+//	@Produce		json
+//	@Param			slug	path		string	true	"List slug"
+//	@Param			page	query		int		false	"Page"
+//	@Param			limit	query		int		false	"Limit"
+//	@Success		200		{object}	dto.ReadingListDetailResponse
+//	@Failure		404		{object}	dto.ErrorResponse
+//	@Router			/lists/{slug} [get]
+func (h *ReadingListHandler) GetBySlug(c *gin.Context) {
+	ctx := c.Request.Context()
+	p := pagination.FromQuery(c)
+	l, items, total, err := h.listSvc.GetBySlug(ctx, c.Param("slug"), middleware.MustUserID(c), p)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondOK(c, dto.ReadingListDetailResponse{
+		List: dto.NewReadingListResponse(l),
+		Items: dto.PagedMangaResponse{
+			Items: dto.NewMangaResponses(ctx, h.urlCache, items),
+			Total: total,
+			Page:  p.Page,
+			Limit: p.Limit,
+		},
+	})
+}
+
+// ListContainingManga godoc
+//
+//	@Summary		Reading lists containing a manga
+//	@Description	Public lists, plus the viewer's own private ones.
+//	@Tags			list
+//	@Produce		json
+//	@Param			mangaID	path		string	true	"Manga ID"
+//	@Param			page	query		int		false	"Page"
+//	@Param			limit	query		int		false	"Limit"
+//	@Success		200		{object}	dto.PagedReadingListResponse
+//	@Failure		400		{object}	dto.ErrorResponse
+//	@Failure		404		{object}	dto.ErrorResponse
+//	@Router			/mangas/{mangaID}/lists [get]
+func (h *ReadingListHandler) ListContainingManga(c *gin.Context) {
+	mangaID, err := uuid.Parse(c.Param("mangaID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid manga id"})
+		return
+	}
+	p := pagination.FromQuery(c)
+	lists, total, err := h.listSvc.ListContainingManga(c.Request.Context(), mangaID, middleware.MustUserID(c), p)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondOK(c, dto.PagedReadingListResponse{
+		Items: dto.NewReadingListResponses(lists),
+		Total: total,
+		Page:  p.Page,
+		Limit: p.Limit,
+	})
+}
+
+// Create godoc
+//
+//	@Summary	Create a reading list
+//	@Tags		list
+//	@Accept		json
+//	@Produce	json
+//	@Security	BearerAuth
+//	@Param		body	body		dto.CreateReadingListRequest	true	"List"
+//	@Success	201		{object}	dto.ReadingListResponse
+//	@Failure	400		{object}	dto.ErrorResponse
+//	@Failure	401		{object}	dto.ErrorResponse
+//	@Router		/users/me/lists [post]
+func (h *ReadingListHandler) Create(c *gin.Context) {
+	var req dto.CreateReadingListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	l, err := h.listSvc.Create(c.Request.Context(), middleware.MustUserID(c), service.CreateReadingListInput{
+		Name:        req.Name,
+		Description: req.Description,
+		IsPublic:    req.IsPublic,
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondCreated(c, dto.NewReadingListResponse(l))
+}
+
+// Update godoc
+//
+//	@Summary	Update a reading list
+//	@Tags		list
+//	@Accept		json
+//	@Produce	json
+//	@Security	BearerAuth
+//	@Param		listID	path		string							true	"List ID"
+//	@Param		body	body		dto.UpdateReadingListRequest	true	"Fields to update"
+//	@Success	200		{object}	dto.ReadingListResponse
+//	@Failure	400		{object}	dto.ErrorResponse
+//	@Failure	401		{object}	dto.ErrorResponse
+//	@Failure	403		{object}	dto.ErrorResponse
+//	@Failure	404		{object}	dto.ErrorResponse
+//	@Router		/users/me/lists/{listID} [patch]
+func (h *ReadingListHandler) Update(c *gin.Context) {
+	listID, err := uuid.Parse(c.Param("listID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid list id"})
+		return
+	}
+	var req dto.UpdateReadingListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	l, err := h.listSvc.Update(c.Request.Context(), middleware.MustUserID(c), listID, service.UpdateReadingListInput{
+		Name:        req.Name,
+		Description: req.Description,
+		IsPublic:    req.IsPublic,
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondOK(c, dto.NewReadingListResponse(l))
+}
+
+// Delete godoc
+//
+//	@Summary	Delete a reading list
+//	@Tags		list
+//	@Security	BearerAuth
+//	@Param		listID	path	string	true	"List ID"
+//	@Success	204
+//	@Failure	401	{object}	dto.ErrorResponse
+//	@Failure	403	{object}	dto.ErrorResponse
+//	@Failure	404	{object}	dto.ErrorResponse
+//	@Router		/users/me/lists/{listID} [delete]
+func (h *ReadingListHandler) Delete(c *gin.Context) {
+	listID, err := uuid.Parse(c.Param("listID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid list id"})
+		return
+	}
+	if err := h.listSvc.Delete(c.Request.Context(), middleware.MustUserID(c), listID); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// AddItem godoc
+//
+//	@Summary	Add a manga to a reading list
+//	@Tags		list
+//	@Security	BearerAuth
+//	@Param		listID	path	string	true	"List ID"
+//	@Param		mangaID	path	string	true	"Manga ID"
+//	@Success	204
+//	@Failure	400	{object}	dto.ErrorResponse
+//	@Failure	401	{object}	dto.ErrorResponse
+//	@Failure	403	{object}	dto.ErrorResponse
+//	@Failure	404	{object}	dto.ErrorResponse
+//	@Router		/users/me/lists/{listID}/items/{mangaID} [put]
+func (h *ReadingListHandler) AddItem(c *gin.Context) {
+	listID, mangaID, ok := parseListItem(c)
+	if !ok {
+		return
+	}
+	if err := h.listSvc.AddItem(c.Request.Context(), middleware.MustUserID(c), listID, mangaID); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// RemoveItem godoc
+//
+//	@Summary	Remove a manga from a reading list
+//	@Tags		list
+//	@Security	BearerAuth
+//	@Param		listID	path	string	true	"List ID"
+//	@Param		mangaID	path	string	true	"Manga ID"
+//	@Success	204
+//	@Failure	401	{object}	dto.ErrorResponse
+//	@Failure	403	{object}	dto.ErrorResponse
+//	@Failure	404	{object}	dto.ErrorResponse
+//	@Router		/users/me/lists/{listID}/items/{mangaID} [delete]
+func (h *ReadingListHandler) RemoveItem(c *gin.Context) {
+	listID, mangaID, ok := parseListItem(c)
+	if !ok {
+		return
+	}
+	if err := h.listSvc.RemoveItem(c.Request.Context(), middleware.MustUserID(c), listID, mangaID); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Reorder godoc
+//
+//	@Summary	Reorder a reading list
+//	@Tags		list
+//	@Accept		json
+//	@Security	BearerAuth
+//	@Param		listID	path	string							true	"List ID"
+//	@Param		body	body	dto.ReorderReadingListRequest	true	"Every manga of the list, in the new order"
+//	@Success	204
+//	@Failure	400	{object}	dto.ErrorResponse
+//	@Failure	401	{object}	dto.ErrorResponse
+//	@Failure	403	{object}	dto.ErrorResponse
+//	@Failure	404	{object}	dto.ErrorResponse
+//	@Router		/users/me/lists/{listID}/items/reorder [patch]
+func (h *ReadingListHandler) Reorder(c *gin.Context) {
+	listID, err := uuid.Parse(c.Param("listID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid list id"})
+		return
+	}
+	var req dto.ReorderReadingListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.listSvc.ReorderItems(c.Request.Context(), middleware.MustUserID(c), listID, req.MangaIDs); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func parseListItem(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	listID, err := uuid.Parse(c.Param("listID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid list id"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	mangaID, err := uuid.Parse(c.Param("mangaID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid manga id"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return listID, mangaID, true
+}
@@ -4,24 +4,33 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/yumikokawaii/sherry-archive/internal/metrics"
 	"github.com/yumikokawaii/sherry-archive/internal/middleware"
 	"github.com/yumikokawaii/sherry-archive/pkg/token"
 )
 
 type Handlers struct {
-	Auth       *AuthHandler
-	Manga      *MangaHandler
-	Chapter    *ChapterHandler
-	Page       *PageHandler
-	Bookmark   *BookmarkHandler
-	User       *UserHandler
-	Comment    *CommentHandler
-	UploadTask *UploadTaskHandler
-	Sitemap    *SitemapHandler
+	Auth         *AuthHandler
+	Manga        *MangaHandler
+	Chapter      *ChapterHandler
+	Page         *PageHandler
+	Bookmark     *BookmarkHandler
+	Notification *NotificationHandler
+	Webhook      *WebhookHandler
+	User         *UserHandler
+	Comment      *CommentHandler
+	Rating       *RatingHandler
+	ReadingList  *ReadingListHandler
+	UploadTask   *UploadTaskHandler
+	Upload       *UploadSessionHandler
+	Export       *ExportHandler
+	Sitemap      *SitemapHandler
+	Admin        *AdminHandler
 }
 
-func SetupRouter(h Handlers, tokenMgr *token.Manager) *gin.Engine {
+// SetupRouter mounts the API. adminIDs are the users allowed on /api/v1/admin.
+func SetupRouter(h Handlers, tokenMgr *token.Manager, adminIDs map[uuid.UUID]struct{}) *gin.Engine {
 	r := gin.New()
 	r.Use(gin.Recovery(), middleware.XRay("sherry-archive"), middleware.Logger(), metrics.Middleware())
 
@@ -45,6 +54,7 @@ func SetupRouter(h Handlers, tokenMgr *token.Manager) *gin.Engine {
 	})
 
 	authMW := middleware.Auth(tokenMgr)
+	optionalAuthMW := middleware.OptionalAuth(tokenMgr)
 
 	v1 := r.Group("/api/v1")
 
@@ -67,17 +77,31 @@ func SetupRouter(h Handlers, tokenMgr *token.Manager) *gin.Engine {
 		mangas.PATCH("/:mangaID", authMW, h.Manga.Update)
 		mangas.DELETE("/:mangaID", authMW, h.Manga.Delete)
 		mangas.PUT("/:mangaID/cover", authMW, h.Manga.UpdateCover)
+		mangas.PUT("/:mangaID/cover/page", authMW, h.Manga.SetCoverFromPage)
 
 		// Oneshot direct upload
 		mangas.POST("/:mangaID/oneshot/upload", authMW, h.Page.UploadOneshotZip)
 
+		// Whole-series import
+		mangas.POST("/:mangaID/series/upload", authMW, h.Page.UploadSeriesZip)
+
+		// Export routes
+		mangas.POST("/:mangaID/export", authMW, h.Export.ExportManga)
+		mangas.GET("/:mangaID/chapters/:chapterID/export/cbz", authMW, h.Export.ChapterCBZ)
+
 		// Chapter routes
-		mangas.GET("/:mangaID/chapters", h.Chapter.List)
+		mangas.GET("/:mangaID/chapters", optionalAuthMW, h.Chapter.List)
 		mangas.POST("/:mangaID/chapters", authMW, h.Chapter.Create)
-		mangas.GET("/:mangaID/chapters/:chapterID", h.Chapter.Get)
+		mangas.GET("/:mangaID/chapters/:chapterID", optionalAuthMW, h.Chapter.Get)
+		mangas.GET("/:mangaID/chapters/:chapterID/manifest", optionalAuthMW, h.Chapter.Manifest)
 		mangas.PATCH("/:mangaID/chapters/:chapterID", authMW, h.Chapter.Update)
 		mangas.DELETE("/:mangaID/chapters/:chapterID", authMW, h.Chapter.Delete)
 
+		// Read history
+		mangas.PUT("/:mangaID/chapters/:chapterID/read", authMW, h.Chapter.RecordRead)
+		mangas.DELETE("/:mangaID/chapters/:chapterID/read", authMW, h.Chapter.UnmarkRead)
+		mangas.POST("/:mangaID/chapters/mark-read", authMW, h.Chapter.MarkReadUpTo)
+
 		// Page routes
 		mangas.POST("/:mangaID/chapters/:chapterID/pages", authMW, h.Page.Upload)
 		mangas.POST("/:mangaID/chapters/:chapterID/pages/zip", authMW, h.Page.UploadZip)
@@ -92,13 +116,29 @@ func SetupRouter(h Handlers, tokenMgr *token.Manager) *gin.Engine {
 
 		mangas.GET("/:mangaID/chapters/:chapterID/comments", h.Comment.ListChapter)
 		mangas.POST("/:mangaID/chapters/:chapterID/comments", authMW, h.Comment.CreateChapter)
+
+		// Rating and review routes
+		mangas.GET("/:mangaID/rating", authMW, h.Rating.Get)
+		mangas.PUT("/:mangaID/rating", authMW, h.Rating.Rate)
+		mangas.DELETE("/:mangaID/rating", authMW, h.Rating.Delete)
+		mangas.GET("/:mangaID/reviews", optionalAuthMW, h.Rating.ListReviews)
+		mangas.PUT("/:mangaID/reviews/:reviewID/helpful", authMW, h.Rating.MarkHelpful)
+		mangas.DELETE("/:mangaID/reviews/:reviewID/helpful", authMW, h.Rating.UnmarkHelpful)
+
+		// Reading lists containing the manga
+		mangas.GET("/:mangaID/lists", optionalAuthMW, h.ReadingList.ListContainingManga)
 	}
 
+	// Latest releases feed
+	v1.GET("/chapters/latest", h.Chapter.Latest)
+
 	// User routes
 	users := v1.Group("/users")
 	{
 		users.GET("/:userID", h.User.GetUser)
 		users.GET("/:userID/mangas", h.Manga.ListByUser)
+		users.GET("/:userID/lists", optionalAuthMW, h.ReadingList.ListByUser)
+		users.GET("/:userID/library", optionalAuthMW, h.Bookmark.Library)
 		users.PATCH("/me", authMW, h.User.UpdateMe)
 		users.PUT("/me/avatar", authMW, h.User.UpdateAvatar)
 	}
@@ -112,8 +152,67 @@ func SetupRouter(h Handlers, tokenMgr *token.Manager) *gin.Engine {
 		bookmarks.DELETE("/:mangaID", h.Bookmark.Delete)
 	}
 
-	// Upload task status polling
+	// Reading list routes
+	lists := v1.Group("/users/me/lists", authMW)
+	{
+		lists.GET("", h.ReadingList.ListMine)
+		lists.POST("", h.ReadingList.Create)
+		lists.PATCH("/:listID", h.ReadingList.Update)
+		lists.DELETE("/:listID", h.ReadingList.Delete)
+		lists.PUT("/:listID/items/:mangaID", h.ReadingList.AddItem)
+		lists.DELETE("/:listID/items/:mangaID", h.ReadingList.RemoveItem)
+		lists.PATCH("/:listID/items/reorder", h.ReadingList.Reorder)
+	}
+	v1.GET("/lists/:slug", optionalAuthMW, h.ReadingList.GetBySlug)
+
+	// Notification routes
+	notifications := v1.Group("/users/me/notifications", authMW)
+	{
+		notifications.GET("", h.Notification.List)
+		notifications.GET("/unread-count", h.Notification.UnreadCount)
+		notifications.PUT("/:notificationID/read", h.Notification.MarkRead)
+		notifications.POST("/read-all", h.Notification.MarkAllRead)
+		notifications.GET("/preferences", h.Notification.GetPreferences)
+		notifications.PATCH("/preferences", h.Notification.UpdatePreferences)
+	}
+
+	// Webhook routes
+	webhooks := v1.Group("/users/me/webhooks", authMW)
+	{
+		webhooks.GET("", h.Webhook.List)
+		webhooks.POST("", h.Webhook.Create)
+		webhooks.PATCH("/:webhookID", h.Webhook.Update)
+		webhooks.DELETE("/:webhookID", h.Webhook.Delete)
+		webhooks.GET("/:webhookID/deliveries", h.Webhook.ListDeliveries)
+		webhooks.POST("/:webhookID/ping", h.Webhook.Ping)
+	}
+
+	// Resumable archive uploads (tus); completion creates an upload task
+	uploads := v1.Group("/uploads", tusResumable())
+	{
+		uploads.OPTIONS("", h.Upload.Options)
+		uploads.POST("", authMW, h.Upload.Create)
+		uploads.HEAD("/:uploadID", authMW, h.Upload.Head)
+		uploads.PATCH("/:uploadID", authMW, h.Upload.Patch)
+		uploads.DELETE("/:uploadID", authMW, h.Upload.Delete)
+	}
+	// Direct uploads: the client PUTs the archive to presigned storage URLs
+	v1.POST("/uploads/direct", authMW, h.Upload.CreateDirect)
+	v1.POST("/uploads/direct/:uploadID/complete", authMW, h.Upload.CompleteDirect)
+	v1.DELETE("/uploads/direct/:uploadID", authMW, h.Upload.DeleteDirect)
+
+	// Upload tasks: status (polling or a live event stream), cancel and retry
 	v1.GET("/tasks/:taskID", authMW, h.UploadTask.GetTask)
+	v1.GET("/tasks/:taskID/events", authMW, h.UploadTask.Events)
+	v1.POST("/tasks/:taskID/cancel", authMW, h.UploadTask.Cancel)
+	v1.POST("/tasks/:taskID/retry", authMW, h.UploadTask.Retry)
+	v1.GET("/users/me/tasks", authMW, h.UploadTask.List)
+
+	// Admin routes
+	admin := v1.Group("/admin", authMW, middleware.RequireAdmin(adminIDs))
+	{
+		admin.GET("/duplicates", h.Admin.Duplicates)
+	}
 
 	return r
 }
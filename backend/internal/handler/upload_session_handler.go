@@ -0,0 +1,351 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/yumikokawaii/sherry-archive/internal/dto"
+	"github.com/yumikokawaii/sherry-archive/internal/middleware"
+	"github.com/yumikokawaii/sherry-archive/internal/model"
+	"github.com/yumikokawaii/sherry-archive/internal/service"
+	"github.com/yumikokawaii/sherry-archive/pkg/tus"
+)
+
+// uploadTaskIDHeader carries the created task's ID once a resumable upload is complete.
+const uploadTaskIDHeader = "Upload-Task-Id"
+
+// UploadSessionHandler serves resumable archive uploads over the tus 1.0
+// protocol (core, creation, termination and expiration) and direct uploads to
+// presigned storage URLs, alternatives to the multipart upload endpoints for
+// large archives and unreliable connections.
+type UploadSessionHandler struct {
+	sessionSvc *service.UploadSessionService
+}
+
+func NewUploadSessionHandler(sessionSvc *service.UploadSessionService) *UploadSessionHandler {
+	return &UploadSessionHandler{sessionSvc: sessionSvc}
+}
+
+// tusResumable answers every upload request with the protocol version and
+// rejects requests for another version. OPTIONS (discovery) needs no version.
+func tusResumable() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header(tus.HeaderResumable, tus.Version)
+		if c.Request.Method != http.MethodOptions && c.GetHeader(tus.HeaderResumable) != tus.Version {
+			c.Header(tus.HeaderVersion, tus.Version)
+			c.AbortWithStatusJSON(http.StatusPreconditionFailed, gin.H{"error": "unsupported tus version"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// Options godoc
+//
+//	@Summary		Discover tus upload support
+//	@Description	Returns the supported tus version (Tus-Version), extensions (Tus-Extension) and the
+//	@Description	largest accepted archive in bytes (Tus-Max-Size).
+//	@Tags			upload
+//	@Success		204
+//	@Router			/uploads [options]
+func (h *UploadSessionHandler) Options(c *gin.Context) {
+	c.Header(tus.HeaderVersion, tus.Version)
+	c.Header(tus.HeaderExtension, tus.Extensions)
+	c.Header(tus.HeaderMaxSize, strconv.FormatInt(h.sessionSvc.MaxSize(), 10))
+	c.Status(http.StatusNoContent)
+}
+
+// Create godoc
+//
+//	@Summary		Start a resumable archive upload
+//	@Description	tus creation. Upload-Length is the archive size in bytes. Upload-Metadata (base64 values)
+//	@Description	selects the target like the multipart endpoints: type (zip, oneshot_zip or series_zip),
+//	@Description	manga_id, chapter_id (zip only), apply_metadata ("true") and filename. The target is
+//	@Description	checked before any data is sent. Send the archive with PATCH to the Location URL.
+//	@Tags			upload
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			Tus-Resumable	header		string	true	"1.0.0"
+//	@Param			Upload-Length	header		int		true	"Archive size in bytes"
+//	@Param			Upload-Metadata	header		string	true	"type, manga_id, chapter_id, apply_metadata, filename"
+//	@Success		201				{object}	dto.UploadSessionResponse
+//	@Failure		400				{object}	dto.ErrorResponse
+//	@Failure		403				{object}	dto.ErrorResponse
+//	@Failure		404				{object}	dto.ErrorResponse
+//	@Failure		412				{object}	dto.ErrorResponse	"Unsupported tus version"
+//	@Failure		413				{object}	dto.ErrorResponse	"Larger than Tus-Max-Size"
+//	@Router			/uploads [post]
+func (h *UploadSessionHandler) Create(c *gin.Context) {
+	if c.GetHeader(tus.HeaderDeferLength) != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Upload-Defer-Length is not supported"})
+		return
+	}
+	size, err := strconv.ParseInt(c.GetHeader(tus.HeaderLength), 10, 64)
+	if err != nil || size <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Upload-Length must be a positive integer"})
+		return
+	}
+	if size > h.sessionSvc.MaxSize() {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "archive is larger than Tus-Max-Size"})
+		return
+	}
+	md, err := tus.ParseMetadata(c.GetHeader(tus.HeaderMetadata))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid Upload-Metadata"})
+		return
+	}
+
+	in := service.CreateUploadSessionInput{
+		Type:     model.UploadTaskType(md["type"]),
+		Filename: md["filename"],
+		Size:     size,
+	}
+	switch in.Type {
+	case model.UploadTaskTypeZip, model.UploadTaskTypeOneshotZip, model.UploadTaskTypeSeriesZip:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "type must be zip, oneshot_zip or series_zip"})
+		return
+	}
+	if in.MangaID, err = uuid.Parse(md["manga_id"]); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid manga id"})
+		return
+	}
+	if in.Type == model.UploadTaskTypeZip {
+		chapterID, err := uuid.Parse(md["chapter_id"])
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid chapter id"})
+			return
+		}
+		in.ChapterID = &chapterID
+	}
+	in.ApplyMetadata, _ = strconv.ParseBool(md["apply_metadata"])
+
+	sess, err := h.sessionSvc.Create(c.Request.Context(), middleware.MustUserID(c), in)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Header("Location", "/api/v1/uploads/"+sess.ID.String())
+	c.Header(tus.HeaderExpires, sess.ExpiresAt.UTC().Format(http.TimeFormat))
+	respondCreated(c, dto.NewUploadSessionResponse(sess))
+}
+
+// Head godoc
+//
+//	@Summary		Get a resumable upload's offset
+//	@Description	tus core: Upload-Offset is where to resume. Upload-Task-Id is set once the upload is
+//	@Description	complete; poll or stream that task for the import.
+//	@Tags			upload
+//	@Security		BearerAuth
+//	@Param			uploadID		path	string	true	"Upload ID"
+//	@Param			Tus-Resumable	header	string	true	"1.0.0"
+//	@Success		200
+//	@Failure		404
+//	@Router			/uploads/{uploadID} [head]
+func (h *UploadSessionHandler) Head(c *gin.Context) {
+	id, ok := parseUploadID(c)
+	if !ok {
+		return
+	}
+	sess, err := h.sessionSvc.Get(c.Request.Context(), middleware.MustUserID(c), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	setUploadHeaders(c, sess)
+	c.Header(tus.HeaderLength, strconv.FormatInt(sess.Size, 10))
+	c.Header("Cache-Control", "no-store")
+	c.Status(http.StatusOK)
+}
+
+// Patch godoc
+//
+//	@Summary		Send a chunk of a resumable upload
+//	@Description	tus core: the body is appended at Upload-Offset, which must be the current offset
+//	@Description	(409 otherwise; HEAD to resume). The response's Upload-Offset counts the bytes stored,
+//	@Description	also when the connection dropped mid-chunk. The chunk that completes the upload
+//	@Description	validates the archive and returns the created task in Upload-Task-Id; an invalid
+//	@Description	archive returns 400 and ends the upload.
+//	@Tags			upload
+//	@Accept			application/offset+octet-stream
+//	@Security		BearerAuth
+//	@Param			uploadID		path	string	true	"Upload ID"
+//	@Param			Tus-Resumable	header	string	true	"1.0.0"
+//	@Param			Upload-Offset	header	int		true	"Offset of the chunk"
+//	@Success		204
+//	@Failure		400	{object}	dto.ErrorResponse
+//	@Failure		404	{object}	dto.ErrorResponse
+//	@Failure		409	{object}	dto.ErrorResponse	"Offset mismatch, chunk in progress or upload complete"
+//	@Failure		415	{object}	dto.ErrorResponse
+//	@Router			/uploads/{uploadID} [patch]
+func (h *UploadSessionHandler) Patch(c *gin.Context) {
+	id, ok := parseUploadID(c)
+	if !ok {
+		return
+	}
+	if c.ContentType() != tus.ContentType {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Content-Type must be " + tus.ContentType})
+		return
+	}
+	offset, err := strconv.ParseInt(c.GetHeader(tus.HeaderOffset), 10, 64)
+	if err != nil || offset < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid Upload-Offset"})
+		return
+	}
+	sess, err := h.sessionSvc.Write(c.Request.Context(), middleware.MustUserID(c), id, offset, c.Request.Body)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	setUploadHeaders(c, sess)
+	c.Status(http.StatusNoContent)
+}
+
+// Delete godoc
+//
+//	@Summary		Cancel a resumable upload
+//	@Description	tus termination: discards the uploaded data. After completion only the upload is
+//	@Description	removed; use POST /tasks/{taskID}/cancel to stop the import.
+//	@Tags			upload
+//	@Security		BearerAuth
+//	@Param			uploadID		path	string	true	"Upload ID"
+//	@Param			Tus-Resumable	header	string	true	"1.0.0"
+//	@Success		204
+//	@Failure		404	{object}	dto.ErrorResponse
+//	@Failure		409	{object}	dto.ErrorResponse	"Chunk in progress"
+//	@Router			/uploads/{uploadID} [delete]
+func (h *UploadSessionHandler) Delete(c *gin.Context) {
+	id, ok := parseUploadID(c)
+	if !ok {
+		return
+	}
+	if err := h.sessionSvc.Delete(c.Request.Context(), middleware.MustUserID(c), id); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// CreateDirect godoc
+//
+//	@Summary		Start a direct-to-storage archive upload
+//	@Description	Returns presigned URLs to PUT the archive straight to storage, so it doesn't pass
+//	@Description	through the API: one URL for archives up to 64 MB, otherwise one per part_size slice
+//	@Description	(S3 multipart). The target is checked like for the multipart endpoints. Then call
+//	@Description	POST /uploads/direct/{uploadID}/complete. The URLs expire with the upload
+//	@Description	(UPLOAD__SESSION_TTL). Browsers need a bucket CORS rule allowing PUT from the site.
+//	@Tags			upload
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			body	body		dto.CreateDirectUploadRequest	true	"Archive"
+//	@Success		201		{object}	dto.DirectUploadResponse
+//	@Failure		400		{object}	dto.ErrorResponse
+//	@Failure		403		{object}	dto.ErrorResponse
+//	@Failure		404		{object}	dto.ErrorResponse
+//	@Failure		413		{object}	dto.ErrorResponse	"Larger than UPLOAD__MAX_SIZE"
+//	@Router			/uploads/direct [post]
+func (h *UploadSessionHandler) CreateDirect(c *gin.Context) {
+	var req dto.CreateDirectUploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Size > h.sessionSvc.MaxSize() {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "archive is larger than the upload limit"})
+		return
+	}
+	if req.Type == model.UploadTaskTypeZip && req.ChapterID == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "chapter_id is required for zip uploads"})
+		return
+	}
+	d, err := h.sessionSvc.CreateDirect(c.Request.Context(), middleware.MustUserID(c), service.CreateUploadSessionInput{
+		Type:          req.Type,
+		MangaID:       req.MangaID,
+		ChapterID:     req.ChapterID,
+		ApplyMetadata: req.ApplyMetadata,
+		Filename:      req.Filename,
+		Size:          req.Size,
+		Checksum:      req.ChecksumSHA256,
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	resp := dto.DirectUploadResponse{
+		ID:        d.Session.ID,
+		Method:    "put",
+		URL:       d.URL,
+		ExpiresAt: d.Session.ExpiresAt,
+	}
+	if len(d.PartURLs) > 0 {
+		resp.Method, resp.PartSize = "multipart", d.PartSize
+		resp.Parts = make([]dto.DirectUploadPartURL, len(d.PartURLs))
+		for i, u := range d.PartURLs {
+			resp.Parts[i] = dto.DirectUploadPartURL{Number: i + 1, URL: u}
+		}
+	}
+	respondCreated(c, resp)
+}
+
+// CompleteDirect godoc
+//
+//	@Summary		Finish a direct-to-storage archive upload
+//	@Description	Checks the uploaded archive against the declared size and SHA-256, validates it and
+//	@Description	enqueues the import. A missing or short upload is a 400 the client can fix by
+//	@Description	uploading again; a checksum mismatch or an invalid archive also ends the upload.
+//	@Description	Calling it again after success returns the same task.
+//	@Tags			upload
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			uploadID	path		string	true	"Upload ID"
+//	@Success		202			{object}	dto.EnqueueResponse
+//	@Failure		400			{object}	dto.ErrorResponse
+//	@Failure		404			{object}	dto.ErrorResponse
+//	@Failure		409			{object}	dto.ErrorResponse	"Already being completed"
+//	@Router			/uploads/direct/{uploadID}/complete [post]
+func (h *UploadSessionHandler) CompleteDirect(c *gin.Context) {
+	id, ok := parseUploadID(c)
+	if !ok {
+		return
+	}
+	sess, err := h.sessionSvc.CompleteDirect(c.Request.Context(), middleware.MustUserID(c), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"data": dto.EnqueueResponse{TaskID: sess.TaskID.UUID}})
+}
+
+// DeleteDirect godoc
+//
+//	@Summary	Cancel a direct-to-storage archive upload
+//	@Tags		upload
+//	@Security	BearerAuth
+//	@Param		uploadID	path	string	true	"Upload ID"
+//	@Success	204
+//	@Failure	404	{object}	dto.ErrorResponse
+//	@Failure	409	{object}	dto.ErrorResponse	"Being completed"
+//	@Router		/uploads/direct/{uploadID} [delete]
+func (h *UploadSessionHandler) DeleteDirect(c *gin.Context) {
+	h.Delete(c)
+}
+
+func parseUploadID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("uploadID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid upload id"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func setUploadHeaders(c *gin.Context, sess *model.UploadSession) {
+	c.Header(tus.HeaderOffset, strconv.FormatInt(sess.Offset, 10))
+	c.Header(tus.HeaderExpires, sess.ExpiresAt.UTC().Format(http.TimeFormat))
+	if sess.TaskID.Valid {
+		c.Header(uploadTaskIDHeader, sess.TaskID.UUID.String())
+	}
+}
@@ -2,13 +2,24 @@ package handler
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/yumikokawaii/sherry-archive/internal/dto"
+	"github.com/yumikokawaii/sherry-archive/internal/middleware"
+	"github.com/yumikokawaii/sherry-archive/internal/model"
+	"github.com/yumikokawaii/sherry-archive/internal/repository"
 	"github.com/yumikokawaii/sherry-archive/internal/service"
+	"github.com/yumikokawaii/sherry-archive/pkg/pagination"
+	"github.com/yumikokawaii/sherry-archive/pkg/taskevents"
 )
 
+// taskEventsRefresh is how often an open task event stream reloads the task,
+// in case a pub/sub message was lost; it also keeps proxies from closing an
+// idle stream.
+const taskEventsRefresh = 15 * time.Second
+
 type UploadTaskHandler struct {
 	uploadTaskSvc *service.UploadTaskService
 }
@@ -19,23 +30,212 @@ func NewUploadTaskHandler(uploadTaskSvc *service.UploadTaskService) *UploadTaskH
 
 // GetTask godoc
 //
-//	@Summary	Get upload task status
+//	@Summary		Get upload task status
+//	@Description	For finished export tasks the response includes a presigned download_url.
+//	@Description	Only the task's owner can read it.
+//	@Tags			upload
+//	@Security		BearerAuth
+//	@Param			taskID	path		string	true	"Task ID"
+//	@Success		200		{object}	dto.UploadTaskResponse
+//	@Failure		404		{object}	dto.ErrorResponse
+//	@Router			/tasks/{taskID} [get]
+func (h *UploadTaskHandler) GetTask(c *gin.Context) {
+	taskID, err := uuid.Parse(c.Param("taskID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task id"})
+		return
+	}
+	task, err := h.uploadTaskSvc.GetTask(c.Request.Context(), middleware.MustUserID(c), taskID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	resp := dto.NewUploadTaskResponse(task)
+	if resp.DownloadURL, err = h.uploadTaskSvc.DownloadURL(c.Request.Context(), task); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": resp})
+}
+
+// Events godoc
+//
+//	@Summary		Stream upload task progress
+//	@Description	Server-Sent Events. The first "status" event describes the task as stored; "progress"
+//	@Description	events follow while pages are stored ({"processed": 37, "total": 120}) and a "status"
+//	@Description	event on every status change. The stream ends once the task is done, failed or cancelled.
+//	@Tags			upload
+//	@Produce		text/event-stream
+//	@Security		BearerAuth
+//	@Param			taskID	path		string	true	"Task ID"
+//	@Success		200		{object}	dto.UploadTaskEventResponse
+//	@Failure		400		{object}	dto.ErrorResponse
+//	@Failure		404		{object}	dto.ErrorResponse
+//	@Router			/tasks/{taskID}/events [get]
+func (h *UploadTaskHandler) Events(c *gin.Context) {
+	taskID, err := uuid.Parse(c.Param("taskID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task id"})
+		return
+	}
+	ctx := c.Request.Context()
+	task, sub, err := h.uploadTaskSvc.Watch(ctx, middleware.MustUserID(c), taskID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	defer sub.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // nginx: don't buffer the stream
+
+	send := func(event string, data dto.UploadTaskEventResponse) {
+		c.SSEvent(event, data)
+		c.Writer.Flush()
+	}
+	send(taskevents.TypeStatus, dto.NewUploadTaskEventResponseFromTask(task))
+
+	refresh := time.NewTicker(taskEventsRefresh)
+	defer refresh.Stop()
+	for !task.Status.Finished() {
+		select {
+		case e, ok := <-sub.Events():
+			if !ok {
+				// Redis went away; the client reconnects and starts from the stored task.
+				return
+			}
+			task.Status, task.Processed, task.Total, task.Error =
+				model.UploadTaskStatus(e.Status), e.Processed, e.Total, e.Error
+			send(e.Type, dto.NewUploadTaskEventResponse(e))
+		case <-refresh.C:
+			stored, err := h.uploadTaskSvc.GetTask(ctx, middleware.MustUserID(c), taskID)
+			if err != nil {
+				return
+			}
+			if stored.Status != task.Status {
+				task = stored
+				send(taskevents.TypeStatus, dto.NewUploadTaskEventResponseFromTask(task))
+				continue
+			}
+			// An SSE comment keeps the connection alive.
+			_, _ = c.Writer.WriteString(": keep-alive\n\n")
+			c.Writer.Flush()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// List godoc
+//
+//	@Summary	List my upload and export tasks, newest first
 //	@Tags		upload
+//	@Produce	json
 //	@Security	BearerAuth
-//	@Param		taskID	path		string	true	"Task ID"
-//	@Success	200		{object}	dto.UploadTaskResponse
-//	@Failure	404		{object}	dto.ErrorResponse
-//	@Router		/tasks/{taskID} [get]
-func (h *UploadTaskHandler) GetTask(c *gin.Context) {
+//	@Param		status		query		string	false	"pending | processing | done | failed | cancelled"
+//	@Param		type		query		string	false	"zip | oneshot_zip | series_zip | export_cbz | export_epub"
+//	@Param		manga_id	query		string	false	"Manga ID"
+//	@Param		page		query		int		false	"Page"
+//	@Param		limit		query		int		false	"Limit"
+//	@Success	200			{object}	dto.PagedUploadTaskResponse
+//	@Failure	400			{object}	dto.ErrorResponse
+//	@Failure	401			{object}	dto.ErrorResponse
+//	@Router		/users/me/tasks [get]
+func (h *UploadTaskHandler) List(c *gin.Context) {
+	f := repository.UploadTaskFilter{
+		Status: model.UploadTaskStatus(c.Query("status")),
+		Type:   model.UploadTaskType(c.Query("type")),
+	}
+	switch f.Status {
+	case "", model.UploadTaskStatusPending, model.UploadTaskStatusProcessing, model.UploadTaskStatusDone,
+		model.UploadTaskStatusFailed, model.UploadTaskStatusCancelled:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status"})
+		return
+	}
+	switch f.Type {
+	case "", model.UploadTaskTypeZip, model.UploadTaskTypeOneshotZip, model.UploadTaskTypeSeriesZip,
+		model.UploadTaskTypeExportCBZ, model.UploadTaskTypeExportEPUB:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid type"})
+		return
+	}
+	if raw := c.Query("manga_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid manga id"})
+			return
+		}
+		f.MangaID = id
+	}
+
+	p := pagination.FromQuery(c)
+	tasks, total, err := h.uploadTaskSvc.List(c.Request.Context(), middleware.MustUserID(c), f, p)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondOK(c, dto.PagedUploadTaskResponse{
+		Items: dto.NewUploadTaskResponseList(tasks),
+		Total: total,
+		Page:  p.Page,
+		Limit: p.Limit,
+	})
+}
+
+// Cancel godoc
+//
+//	@Summary		Cancel a pending task
+//	@Description	Only tasks no worker has picked up yet can be cancelled; the staged upload is deleted.
+//	@Tags			upload
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			taskID	path		string	true	"Task ID"
+//	@Success		200		{object}	dto.UploadTaskResponse
+//	@Failure		400		{object}	dto.ErrorResponse
+//	@Failure		404		{object}	dto.ErrorResponse
+//	@Failure		409		{object}	dto.ErrorResponse	"Task is not pending"
+//	@Router			/tasks/{taskID}/cancel [post]
+func (h *UploadTaskHandler) Cancel(c *gin.Context) {
+	taskID, err := uuid.Parse(c.Param("taskID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task id"})
+		return
+	}
+	task, err := h.uploadTaskSvc.Cancel(c.Request.Context(), middleware.MustUserID(c), taskID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondOK(c, dto.NewUploadTaskResponse(task))
+}
+
+// Retry godoc
+//
+//	@Summary		Retry a failed task
+//	@Description	Queues the task again with the archive uploaded for it; its error, results and
+//	@Description	progress are cleared. Poll or stream the task as after the first upload.
+//	@Tags			upload
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			taskID	path		string	true	"Task ID"
+//	@Success		202		{object}	dto.UploadTaskResponse
+//	@Failure		400		{object}	dto.ErrorResponse
+//	@Failure		404		{object}	dto.ErrorResponse
+//	@Failure		409		{object}	dto.ErrorResponse	"Task has not failed"
+//	@Router			/tasks/{taskID}/retry [post]
+func (h *UploadTaskHandler) Retry(c *gin.Context) {
 	taskID, err := uuid.Parse(c.Param("taskID"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task id"})
 		return
 	}
-	task, err := h.uploadTaskSvc.GetTask(c.Request.Context(), taskID)
+	task, err := h.uploadTaskSvc.Retry(c.Request.Context(), middleware.MustUserID(c), taskID)
 	if err != nil {
 		respondError(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"data": dto.NewUploadTaskResponse(task)})
+	c.JSON(http.StatusAccepted, gin.H{"data": dto.NewUploadTaskResponse(task)})
 }
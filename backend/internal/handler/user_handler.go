@@ -68,8 +68,10 @@ func (h *UserHandler) UpdateMe(c *gin.Context) {
 	}
 
 	user, err := h.userSvc.Update(c.Request.Context(), userID, service.UpdateUserInput{
-		Username: req.Username,
-		Bio:      req.Bio,
+		Username:          req.Username,
+		Bio:               req.Bio,
+		PreferredLanguage: req.PreferredLanguage,
+		LibraryPublic:     req.LibraryPublic,
 	})
 	if err != nil {
 		respondError(c, err)
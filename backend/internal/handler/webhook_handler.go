@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/yumikokawaii/sherry-archive/internal/dto"
+	"github.com/yumikokawaii/sherry-archive/internal/middleware"
+	"github.com/yumikokawaii/sherry-archive/internal/service"
+	"github.com/yumikokawaii/sherry-archive/pkg/pagination"
+)
+
+type WebhookHandler struct {
+	webhookSvc *service.WebhookService
+}
+
+func NewWebhookHandler(webhookSvc *service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{webhookSvc: webhookSvc}
+}
+
+// List godoc
+//
+//	@Summary	List my webhooks
+//	@Tags		webhook
+//	@Produce	json
+//	@Security	BearerAuth
+//	@Success	200	{array}		dto.WebhookResponse
+//	@Failure	401	{object}	dto.ErrorResponse
+//	@Router		/users/me/webhooks [get]
+func (h *WebhookHandler) List(c *gin.Context) {
+	ws, err := h.webhookSvc.List(c.Request.Context(), middleware.MustUserID(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondOK(c, dto.NewWebhookResponseList(ws))
+}
+
+// Create godoc
+//
+//	@Summary		Register a webhook
+//	@Description	Deliveries are POSTed as JSON and signed with the secret in the X-Webhook-Signature
+//	@Description	header ("t=<unix time>,v1=<hex HMAC-SHA256 of "<t>.<body>">"). upload_task events are
+//	@Description	only sent for your own tasks. The secret is only returned here and when it changes.
+//	@Tags			webhook
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			body	body		dto.CreateWebhookRequest	true	"Webhook"
+//	@Success		201		{object}	dto.WebhookResponse
+//	@Failure		400		{object}	dto.ErrorResponse
+//	@Failure		401		{object}	dto.ErrorResponse
+//	@Failure		409		{object}	dto.ErrorResponse	"Webhook limit reached"
+//	@Router			/users/me/webhooks [post]
+func (h *WebhookHandler) Create(c *gin.Context) {
+	var req dto.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	w, err := h.webhookSvc.Create(c.Request.Context(), middleware.MustUserID(c), service.CreateWebhookInput{
+		URL:    req.URL,
+		Secret: req.Secret,
+		Events: req.Events,
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	resp := dto.NewWebhookResponse(w)
+	resp.Secret = w.Secret
+	respondCreated(c, resp)
+}
+
+// Update godoc
+//
+//	@Summary	Update a webhook
+//	@Tags		webhook
+//	@Accept		json
+//	@Produce	json
+//	@Security	BearerAuth
+//	@Param		webhookID	path		string						true	"Webhook ID"
+//	@Param		body		body		dto.UpdateWebhookRequest	true	"Fields to change"
+//	@Success	200			{object}	dto.WebhookResponse
+//	@Failure	400			{object}	dto.ErrorResponse
+//	@Failure	401			{object}	dto.ErrorResponse
+//	@Failure	404			{object}	dto.ErrorResponse
+//	@Router		/users/me/webhooks/{webhookID} [patch]
+func (h *WebhookHandler) Update(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("webhookID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid webhook id"})
+		return
+	}
+	var req dto.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	w, err := h.webhookSvc.Update(c.Request.Context(), middleware.MustUserID(c), id, service.UpdateWebhookInput{
+		URL:    req.URL,
+		Secret: req.Secret,
+		Events: req.Events,
+		Active: req.Active,
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	resp := dto.NewWebhookResponse(w)
+	if req.Secret != nil {
+		resp.Secret = w.Secret
+	}
+	respondOK(c, resp)
+}
+
+// Delete godoc
+//
+//	@Summary	Delete a webhook
+//	@Tags		webhook
+//	@Security	BearerAuth
+//	@Param		webhookID	path	string	true	"Webhook ID"
+//	@Success	204			"No Content"
+//	@Failure	400			{object}	dto.ErrorResponse
+//	@Failure	401			{object}	dto.ErrorResponse
+//	@Failure	404			{object}	dto.ErrorResponse
+//	@Router		/users/me/webhooks/{webhookID} [delete]
+func (h *WebhookHandler) Delete(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("webhookID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid webhook id"})
+		return
+	}
+	if err := h.webhookSvc.Delete(c.Request.Context(), middleware.MustUserID(c), id); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListDeliveries godoc
+//
+//	@Summary	List a webhook's deliveries, newest first
+//	@Tags		webhook
+//	@Produce	json
+//	@Security	BearerAuth
+//	@Param		webhookID	path		string	true	"Webhook ID"
+//	@Param		page		query		int		false	"Page"
+//	@Param		limit		query		int		false	"Limit"
+//	@Success	200			{object}	dto.PagedWebhookDeliveryResponse
+//	@Failure	400			{object}	dto.ErrorResponse
+//	@Failure	401			{object}	dto.ErrorResponse
+//	@Failure	404			{object}	dto.ErrorResponse
+//	@Router		/users/me/webhooks/{webhookID}/deliveries [get]
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("webhookID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid webhook id"})
+		return
+	}
+	p := pagination.FromQuery(c)
+	ds, total, err := h.webhookSvc.ListDeliveries(c.Request.Context(), middleware.MustUserID(c), id, p)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondOK(c, dto.PagedWebhookDeliveryResponse{
+		Items: dto.NewWebhookDeliveryResponseList(ds),
+		Total: total,
+		Page:  p.Page,
+		Limit: p.Limit,
+	})
+}
+
+// Ping godoc
+//
+//	@Summary		Send a test delivery
+//	@Description	Queues a "ping" event for the webhook; check its deliveries for the result.
+//	@Tags			webhook
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			webhookID	path		string	true	"Webhook ID"
+//	@Success		202			{object}	dto.WebhookDeliveryResponse
+//	@Failure		400			{object}	dto.ErrorResponse
+//	@Failure		401			{object}	dto.ErrorResponse
+//	@Failure		404			{object}	dto.ErrorResponse
+//	@Router			/users/me/webhooks/{webhookID}/ping [post]
+func (h *WebhookHandler) Ping(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("webhookID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid webhook id"})
+		return
+	}
+	d, err := h.webhookSvc.Ping(c.Request.Context(), middleware.MustUserID(c), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"data": dto.NewWebhookDeliveryResponse(d)})
+}
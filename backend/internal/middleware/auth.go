@@ -55,3 +55,14 @@ func MustUserID(c *gin.Context) uuid.UUID {
 	id, _ := v.(uuid.UUID)
 	return id
 }
+
+// RequireAdmin allows only the given users; it must run after Auth.
+func RequireAdmin(adminIDs map[uuid.UUID]struct{}) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := adminIDs[MustUserID(c)]; !ok {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": apperror.ErrForbidden.Error()})
+			return
+		}
+		c.Next()
+	}
+}
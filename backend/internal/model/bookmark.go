@@ -6,12 +6,37 @@ import (
 	"github.com/google/uuid"
 )
 
+// BookmarkStatus is where a manga stands in the user's library.
+type BookmarkStatus string
+
+const (
+	BookmarkReading    BookmarkStatus = "reading"
+	BookmarkPlanToRead BookmarkStatus = "plan_to_read"
+	BookmarkCompleted  BookmarkStatus = "completed"
+	BookmarkDropped    BookmarkStatus = "dropped"
+	BookmarkOnHold     BookmarkStatus = "on_hold"
+)
+
+// Valid reports whether s is a known status.
+func (s BookmarkStatus) Valid() bool {
+	switch s {
+	case BookmarkReading, BookmarkPlanToRead, BookmarkCompleted, BookmarkDropped, BookmarkOnHold:
+		return true
+	}
+	return false
+}
+
 type Bookmark struct {
-	ID             uuid.UUID `db:"id"`
-	UserID         uuid.UUID `db:"user_id"`
-	MangaID        uuid.UUID `db:"manga_id"`
-	ChapterID      uuid.UUID `db:"chapter_id"`
-	LastPageNumber int       `db:"last_page_number"`
-	CreatedAt      time.Time `db:"created_at"`
-	UpdatedAt      time.Time `db:"updated_at"`
+	ID             uuid.UUID      `db:"id"`
+	UserID         uuid.UUID      `db:"user_id"`
+	MangaID        uuid.UUID      `db:"manga_id"`
+	ChapterID      *uuid.UUID     `db:"chapter_id"` // nil until reading starts
+	LastPageNumber int            `db:"last_page_number"`
+	Status         BookmarkStatus `db:"status"`
+	Score          *int           `db:"score"`       // private 1–10 score
+	StartedAt      *time.Time     `db:"started_at"`  // date only
+	FinishedAt     *time.Time     `db:"finished_at"` // date only
+	Notes          string         `db:"notes"`       // never shown to others
+	CreatedAt      time.Time      `db:"created_at"`
+	UpdatedAt      time.Time      `db:"updated_at"`
 }
@@ -11,6 +11,8 @@ type Chapter struct {
 	MangaID   uuid.UUID `db:"manga_id"`
 	Number    float64   `db:"number"`
 	Title     string    `db:"title"`
+	Volume    *int      `db:"volume"`
+	Language  string    `db:"language"` // ISO 639-1 code, e.g. "en"; "" when unspecified
 	PageCount int       `db:"page_count"`
 	CreatedAt time.Time `db:"created_at"`
 	UpdatedAt time.Time `db:"updated_at"`
@@ -0,0 +1,18 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ChapterRead is a user's progress through one chapter.
+type ChapterRead struct {
+	UserID         uuid.UUID  `db:"user_id"`
+	ChapterID      uuid.UUID  `db:"chapter_id"`
+	MangaID        uuid.UUID  `db:"manga_id"`
+	Progress       int        `db:"progress"` // percent of pages, 0–100
+	LastPageNumber int        `db:"last_page_number"`
+	CompletedAt    *time.Time `db:"completed_at"` // nil until the chapter is finished
+	UpdatedAt      time.Time  `db:"updated_at"`
+}
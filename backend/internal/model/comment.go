@@ -11,6 +11,7 @@ type Comment struct {
 	UserID    uuid.UUID  `db:"user_id"`
 	MangaID   uuid.UUID  `db:"manga_id"`
 	ChapterID *uuid.UUID `db:"chapter_id"`
+	ParentID  *uuid.UUID `db:"parent_id"` // the comment this one replies to
 	Content   string     `db:"content"`
 	Edited    bool       `db:"edited"`
 	CreatedAt time.Time  `db:"created_at"`
@@ -0,0 +1,18 @@
+package model
+
+import "github.com/google/uuid"
+
+// DuplicateChapterPair is two chapters sharing many pages by perceptual hash.
+// Similarity is SharedPages relative to the shorter chapter, from 0 to 1.
+type DuplicateChapterPair struct {
+	MangaID            uuid.UUID `db:"manga_id"`
+	MangaTitle         string    `db:"manga_title"`
+	ChapterID          uuid.UUID `db:"chapter_id"`
+	ChapterNumber      float64   `db:"chapter_number"`
+	OtherMangaID       uuid.UUID `db:"other_manga_id"`
+	OtherMangaTitle    string    `db:"other_manga_title"`
+	OtherChapterID     uuid.UUID `db:"other_chapter_id"`
+	OtherChapterNumber float64   `db:"other_chapter_number"`
+	SharedPages        int       `db:"shared_pages"`
+	Similarity         float64   `db:"similarity"`
+}
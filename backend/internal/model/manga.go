@@ -1,6 +1,9 @@
 package model
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -23,18 +26,52 @@ const (
 )
 
 type Manga struct {
-	ID          uuid.UUID      `db:"id"`
-	OwnerID     uuid.UUID      `db:"owner_id"`
-	Title       string         `db:"title"`
-	Slug        string         `db:"slug"`
-	Description string         `db:"description"`
-	CoverKey    string         `db:"cover_key"`
-	Status      MangaStatus    `db:"status"`
-	Type        MangaType      `db:"type"`
-	Tags        pq.StringArray `db:"tags"`
-	Author      string         `db:"author"`
-	Artist      string         `db:"artist"`
-	Category    string         `db:"category"`
-	CreatedAt   time.Time      `db:"created_at"`
-	UpdatedAt   time.Time      `db:"updated_at"`
+	ID            uuid.UUID      `db:"id"`
+	OwnerID       uuid.UUID      `db:"owner_id"`
+	Title         string         `db:"title"`
+	Slug          string         `db:"slug"`
+	Description   string         `db:"description"`
+	CoverKey      string         `db:"cover_key"`      // largest cover variant; older covers are the uploaded image
+	CoverVariants CoverVariants  `db:"cover_variants"` // empty for covers stored before variants existed
+	Status        MangaStatus    `db:"status"`
+	Type          MangaType      `db:"type"`
+	Tags          pq.StringArray `db:"tags"`
+	Author        string         `db:"author"`
+	Artist        string         `db:"artist"`
+	Category      string         `db:"category"`
+	LastChapterAt *time.Time     `db:"last_chapter_at"` // nil until a chapter has pages
+	RatingAvg     float64        `db:"rating_avg"`      // mean score, 0 when unrated
+	RatingCount   int            `db:"rating_count"`
+	CreatedAt     time.Time      `db:"created_at"`
+	UpdatedAt     time.Time      `db:"updated_at"`
+}
+
+// CoverVariant is a WebP rendition of the manga cover, Width pixels wide.
+type CoverVariant struct {
+	Width int    `json:"width"`
+	Key   string `json:"key"`
+}
+
+// CoverVariants is stored as JSONB, narrowest first.
+type CoverVariants []CoverVariant
+
+func (v CoverVariants) Value() (driver.Value, error) {
+	if v == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(v)
+}
+
+func (v *CoverVariants) Scan(src any) error {
+	switch s := src.(type) {
+	case nil:
+		*v = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(s, v)
+	case string:
+		return json.Unmarshal([]byte(s), v)
+	default:
+		return fmt.Errorf("cover variants: unsupported type %T", src)
+	}
 }
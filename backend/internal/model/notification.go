@@ -0,0 +1,60 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NotificationType is what a notification is about; users can turn each off.
+type NotificationType string
+
+const (
+	NotificationNewChapter     NotificationType = "new_chapter"     // a bookmarked manga published a chapter
+	NotificationCommentReply   NotificationType = "comment_reply"   // someone replied to the user's comment
+	NotificationCommentMention NotificationType = "comment_mention" // someone @mentioned the user in a comment
+)
+
+// NotificationTypes lists every notification type.
+var NotificationTypes = []NotificationType{NotificationNewChapter, NotificationCommentReply, NotificationCommentMention}
+
+type Notification struct {
+	ID        uuid.UUID        `db:"id"`
+	UserID    uuid.UUID        `db:"user_id"` // recipient
+	Type      NotificationType `db:"type"`
+	ActorID   *uuid.UUID       `db:"actor_id"` // who caused it; nil once the account is deleted
+	MangaID   uuid.UUID        `db:"manga_id"`
+	ChapterID *uuid.UUID       `db:"chapter_id"`
+	CommentID *uuid.UUID       `db:"comment_id"`
+	ReadAt    *time.Time       `db:"read_at"`
+	CreatedAt time.Time        `db:"created_at"`
+}
+
+// NotificationWithContext is the flat struct returned by JOIN queries.
+type NotificationWithContext struct {
+	Notification
+	ActorUsername  *string  `db:"actor_username"`
+	MangaTitle     string   `db:"manga_title"`
+	ChapterNumber  *float64 `db:"chapter_number"`
+	ChapterTitle   *string  `db:"chapter_title"`
+	CommentContent *string  `db:"comment_content"`
+}
+
+// NotificationJobType is the event a fan-out job turns into notifications.
+type NotificationJobType string
+
+const (
+	NotificationJobChapterPublished NotificationJobType = "chapter_published" // subject: chapter ID
+	NotificationJobCommentCreated   NotificationJobType = "comment_created"   // subject: comment ID
+)
+
+// NotificationJob is queued fan-out work, run by the API's dispatcher.
+type NotificationJob struct {
+	ID        uuid.UUID           `db:"id"`
+	Type      NotificationJobType `db:"type"`
+	SubjectID uuid.UUID           `db:"subject_id"`
+	Attempts  int                 `db:"attempts"`
+	LastError string              `db:"last_error"`
+	RunAt     time.Time           `db:"run_at"`
+	CreatedAt time.Time           `db:"created_at"`
+}
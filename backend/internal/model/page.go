@@ -7,11 +7,23 @@ import (
 )
 
 type Page struct {
-	ID        uuid.UUID `db:"id"`
-	ChapterID uuid.UUID `db:"chapter_id"`
-	Number    int       `db:"number"`
-	ObjectKey string    `db:"object_key"`
-	Width     int       `db:"width"`
-	Height    int       `db:"height"`
+	ID           uuid.UUID `db:"id"`
+	ChapterID    uuid.UUID `db:"chapter_id"`
+	Number       int       `db:"number"`
+	ObjectKey    string    `db:"object_key"`
+	ThumbnailKey string    `db:"thumbnail_key"` // empty for pages stored before thumbnails existed
+	Width        int       `db:"width"`
+	Height       int       `db:"height"`
+	// SHA256 of the uploaded image bytes and its dHash perceptual hash (bit pattern
+	// stored as BIGINT); empty / NULL for pages stored before hashing existed.
+	SHA256    string    `db:"sha256"`
+	PHash     *int64    `db:"phash"`
 	CreatedAt time.Time `db:"created_at"`
 }
+
+// PageHash is a page's perceptual hash with the chapter and manga it belongs to.
+type PageHash struct {
+	ChapterID uuid.UUID `db:"chapter_id"`
+	MangaID   uuid.UUID `db:"manga_id"`
+	PHash     int64     `db:"phash"`
+}
@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// PageObject is a stored page image with its thumbnail, shared by every page
+// with the same content (see PageStore in the service package).
+type PageObject struct {
+	SHA256       string    `db:"sha256"`
+	ObjectKey    string    `db:"object_key"`
+	ThumbnailKey string    `db:"thumbnail_key"`
+	Width        int       `db:"width"`
+	Height       int       `db:"height"`
+	RefCount     int       `db:"ref_count"`
+	CreatedAt    time.Time `db:"created_at"`
+}
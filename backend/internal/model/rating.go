@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Rating is a user's 1–10 score of a manga. Review is optional long-form text.
+type Rating struct {
+	ID           uuid.UUID `db:"id"`
+	UserID       uuid.UUID `db:"user_id"`
+	MangaID      uuid.UUID `db:"manga_id"`
+	Score        int       `db:"score"`
+	Review       string    `db:"review"`
+	HelpfulCount int       `db:"helpful_count"`
+	CreatedAt    time.Time `db:"created_at"`
+	UpdatedAt    time.Time `db:"updated_at"`
+}
+
+// RatingWithAuthor is the flat struct returned by review listings.
+type RatingWithAuthor struct {
+	Rating
+	AuthorUsername  string `db:"author_username"`
+	AuthorAvatarURL string `db:"author_avatar_url"`
+	VotedHelpful    bool   `db:"voted_helpful"` // by the viewer; false when anonymous
+}
@@ -0,0 +1,80 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UploadSession is a resumable (tus) or direct-to-storage archive upload. Once
+// all Size bytes are received the archive is validated and TaskID is set to the
+// upload task that imports it.
+type UploadSession struct {
+	ID            uuid.UUID      `db:"id"`
+	OwnerID       uuid.UUID      `db:"owner_id"`
+	TaskType      UploadTaskType `db:"task_type"` // zip, oneshot_zip or series_zip
+	MangaID       uuid.UUID      `db:"manga_id"`
+	ChapterID     uuid.NullUUID  `db:"chapter_id"` // zip only
+	ApplyMetadata bool           `db:"apply_metadata"`
+	Filename      string         `db:"filename"`
+	Size          int64          `db:"size"`
+	Offset        int64          `db:"upload_offset"` // bytes received so far
+	S3Key         string         `db:"s3_key"`
+	// S3UploadID is the S3 multipart upload the chunks are stored in; "" once
+	// it was completed into S3Key.
+	S3UploadID string      `db:"s3_upload_id"`
+	Parts      UploadParts `db:"parts"`
+	// PartialSize bytes after the last part are kept in PartialKey until
+	// enough arrive for another part.
+	PartialSize int64 `db:"partial_size"`
+	// Direct sessions are uploaded straight to S3 through presigned URLs: a
+	// single PUT, or parts of PartSize bytes when S3UploadID is set. Checksum
+	// is the hex SHA-256 the client declared.
+	Direct      bool          `db:"direct"`
+	Checksum    string        `db:"checksum"`
+	PartSize    int64         `db:"part_size"`
+	TaskID      uuid.NullUUID `db:"task_id"`
+	LockedUntil *time.Time    `db:"locked_until"`
+	ExpiresAt   time.Time     `db:"expires_at"`
+	CreatedAt   time.Time     `db:"created_at"`
+	UpdatedAt   time.Time     `db:"updated_at"`
+}
+
+// PartialKey is the object holding the received bytes not yet in a part.
+func (s *UploadSession) PartialKey() string {
+	return s.S3Key + ".partial"
+}
+
+// UploadPart is a completed part of the session's S3 multipart upload.
+type UploadPart struct {
+	Number int32  `json:"number"`
+	ETag   string `json:"etag"`
+	Size   int64  `json:"size"`
+}
+
+// UploadParts is stored as a JSONB array in upload_sessions.parts.
+type UploadParts []UploadPart
+
+func (p UploadParts) Value() (driver.Value, error) {
+	if p == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(p)
+}
+
+func (p *UploadParts) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*p = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, p)
+	case string:
+		return json.Unmarshal([]byte(v), p)
+	default:
+		return fmt.Errorf("upload parts: unsupported type %T", src)
+	}
+}
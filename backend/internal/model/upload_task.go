@@ -1,9 +1,13 @@
 package model
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 type UploadTaskType string
@@ -12,11 +16,15 @@ type UploadTaskStatus string
 const (
 	UploadTaskTypeZip        UploadTaskType = "zip"
 	UploadTaskTypeOneshotZip UploadTaskType = "oneshot_zip"
+	UploadTaskTypeSeriesZip  UploadTaskType = "series_zip"
+	UploadTaskTypeExportCBZ  UploadTaskType = "export_cbz"
+	UploadTaskTypeExportEPUB UploadTaskType = "export_epub"
 
 	UploadTaskStatusPending    UploadTaskStatus = "pending"
 	UploadTaskStatusProcessing UploadTaskStatus = "processing"
 	UploadTaskStatusDone       UploadTaskStatus = "done"
 	UploadTaskStatusFailed     UploadTaskStatus = "failed"
+	UploadTaskStatusCancelled  UploadTaskStatus = "cancelled" // by the owner, before a worker claimed it
 )
 
 type UploadTask struct {
@@ -26,8 +34,103 @@ type UploadTask struct {
 	OwnerID   uuid.UUID        `db:"owner_id"`
 	MangaID   uuid.UUID        `db:"manga_id"`
 	ChapterID uuid.NullUUID    `db:"chapter_id"` // NULL for oneshot_zip until Lambda creates the chapter
-	S3Key     string           `db:"s3_key"`
+	S3Key     string           `db:"s3_key"`     // staged upload, or the output object for exports
 	Error     string           `db:"error"`
-	CreatedAt time.Time        `db:"created_at"`
-	UpdatedAt time.Time        `db:"updated_at"`
+	Results   ChapterResults   `db:"results"` // series_zip only
+	// ApplyMetadata asks the worker to merge the archive metadata into the manga
+	// and chapter; what it did is stored in MetadataReport.
+	ApplyMetadata  bool            `db:"apply_metadata"`
+	MetadataReport *MetadataReport `db:"metadata_report"`
+	// Warnings about the uploaded archive (skipped files, ambiguous page order);
+	// for series_zip, chapter-level warnings are in Results.
+	Warnings pq.StringArray `db:"warnings"`
+	// Processed of Total archive pages are stored (series_zip: across all
+	// chapters); saved every few seconds while the worker imports.
+	Processed int `db:"processed"`
+	Total     int `db:"total"`
+	// Attempts counts worker claims; see the reaper in UploadTaskService.
+	Attempts  int       `db:"attempts"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// Finished reports whether the task reached a final status.
+func (s UploadTaskStatus) Finished() bool {
+	return s == UploadTaskStatusDone || s == UploadTaskStatusFailed || s == UploadTaskStatusCancelled
+}
+
+// IsExport reports whether the task produces a download rather than consuming an upload.
+func (t UploadTaskType) IsExport() bool {
+	return t == UploadTaskTypeExportCBZ || t == UploadTaskTypeExportEPUB
+}
+
+// ChapterResult is the outcome of importing one chapter folder of a series_zip task.
+// Error is set (and ChapterID is nil) when that chapter was skipped.
+type ChapterResult struct {
+	Folder    string     `json:"folder"`
+	Number    float64    `json:"number"`
+	Title     string     `json:"title"`
+	Volume    *int       `json:"volume,omitempty"`
+	Language  string     `json:"language,omitempty"`
+	ChapterID *uuid.UUID `json:"chapter_id,omitempty"`
+	PageCount int        `json:"page_count"`
+	Error     string     `json:"error,omitempty"`
+	Warnings  []string   `json:"warnings,omitempty"` // skipped files, ambiguous page order
+}
+
+// ChapterResults is stored as a JSONB array in upload_tasks.results.
+type ChapterResults []ChapterResult
+
+func (r ChapterResults) Value() (driver.Value, error) {
+	if r == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(r)
+}
+
+func (r *ChapterResults) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*r = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, r)
+	case string:
+		return json.Unmarshal([]byte(v), r)
+	default:
+		return fmt.Errorf("chapter results: unsupported type %T", src)
+	}
+}
+
+// MetadataReport records the changes an apply_metadata task made, and the
+// metadata values it did not apply because they conflicted with existing data.
+type MetadataReport struct {
+	Applied   []MetadataChange `json:"applied"`
+	Conflicts []MetadataChange `json:"conflicts"`
+}
+
+// MetadataChange is one field of the manga or chapter. New is the archive's
+// value; for a conflict, Old is the value that was kept.
+type MetadataChange struct {
+	Target string `json:"target"` // "manga" or "chapter"
+	Field  string `json:"field"`
+	Old    any    `json:"old"`
+	New    any    `json:"new"`
+	Reason string `json:"reason,omitempty"` // conflicts only
+}
+
+// MetadataReport is stored as JSONB in upload_tasks.metadata_report.
+func (r MetadataReport) Value() (driver.Value, error) {
+	return json.Marshal(r)
+}
+
+func (r *MetadataReport) Scan(src any) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, r)
+	case string:
+		return json.Unmarshal([]byte(v), r)
+	default:
+		return fmt.Errorf("metadata report: unsupported type %T", src)
+	}
 }
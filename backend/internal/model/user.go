@@ -7,12 +7,14 @@ import (
 )
 
 type User struct {
-	ID           uuid.UUID `db:"id"`
-	Username     string    `db:"username"`
-	Email        string    `db:"email"`
-	PasswordHash string    `db:"password_hash"`
-	AvatarURL    string    `db:"avatar_url"`
-	Bio          string    `db:"bio"`
-	CreatedAt    time.Time `db:"created_at"`
-	UpdatedAt    time.Time `db:"updated_at"`
+	ID                uuid.UUID `db:"id"`
+	Username          string    `db:"username"`
+	Email             string    `db:"email"`
+	PasswordHash      string    `db:"password_hash"`
+	AvatarURL         string    `db:"avatar_url"`
+	Bio               string    `db:"bio"`
+	PreferredLanguage string    `db:"preferred_language"` // default chapter language filter; "" shows all
+	LibraryPublic     bool      `db:"library_public"`     // others may view the bookmarks library
+	CreatedAt         time.Time `db:"created_at"`
+	UpdatedAt         time.Time `db:"updated_at"`
 }
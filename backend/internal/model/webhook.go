@@ -0,0 +1,85 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// WebhookEvent is an event webhooks can subscribe to.
+type WebhookEvent string
+
+const (
+	WebhookMangaCreated     WebhookEvent = "manga.created"
+	WebhookMangaUpdated     WebhookEvent = "manga.updated"
+	WebhookChapterPublished WebhookEvent = "chapter.published"
+	WebhookUploadTaskDone   WebhookEvent = "upload_task.done"
+	WebhookUploadTaskFailed WebhookEvent = "upload_task.failed"
+	// WebhookPing is only sent on request, to test an endpoint.
+	WebhookPing WebhookEvent = "ping"
+)
+
+// WebhookEvents lists the events webhooks can subscribe to.
+var WebhookEvents = []WebhookEvent{
+	WebhookMangaCreated, WebhookMangaUpdated, WebhookChapterPublished,
+	WebhookUploadTaskDone, WebhookUploadTaskFailed,
+}
+
+// Valid reports whether e is an event webhooks can subscribe to.
+func (e WebhookEvent) Valid() bool {
+	for _, v := range WebhookEvents {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Private reports whether the event only goes to its owner's webhooks. Other
+// events are about public content and go to every subscriber.
+func (e WebhookEvent) Private() bool {
+	return e == WebhookUploadTaskDone || e == WebhookUploadTaskFailed
+}
+
+type Webhook struct {
+	ID        uuid.UUID      `db:"id"`
+	UserID    uuid.UUID      `db:"user_id"`
+	URL       string         `db:"url"`
+	Secret    string         `db:"secret"` // HMAC key for the signature header
+	Events    pq.StringArray `db:"events"`
+	Active    bool           `db:"active"`
+	CreatedAt time.Time      `db:"created_at"`
+	UpdatedAt time.Time      `db:"updated_at"`
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed" // gave up after the last retry
+)
+
+type WebhookDelivery struct {
+	ID             uuid.UUID             `db:"id"`
+	WebhookID      uuid.UUID             `db:"webhook_id"`
+	EventID        uuid.UUID             `db:"event_id"` // the same for every webhook receiving the event
+	Event          WebhookEvent          `db:"event"`
+	Payload        json.RawMessage       `db:"payload"`
+	Status         WebhookDeliveryStatus `db:"status"`
+	Attempts       int                   `db:"attempts"`
+	ResponseStatus *int                  `db:"response_status"` // of the last attempt; nil without a response
+	LastError      string                `db:"last_error"`
+	NextAttemptAt  time.Time             `db:"next_attempt_at"`
+	DeliveredAt    *time.Time            `db:"delivered_at"`
+	CreatedAt      time.Time             `db:"created_at"`
+}
+
+// WebhookDeliveryJob is a claimed delivery with its webhook's endpoint.
+type WebhookDeliveryJob struct {
+	WebhookDelivery
+	URL    string `db:"url"`
+	Secret string `db:"secret"`
+}
@@ -14,6 +14,8 @@ type UserRepository interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*model.User, error)
 	GetByEmail(ctx context.Context, email string) (*model.User, error)
 	GetByUsername(ctx context.Context, username string) (*model.User, error)
+	// ListByUsernames returns the users with these usernames; unknown ones are skipped.
+	ListByUsernames(ctx context.Context, usernames []string) ([]*model.User, error)
 	Update(ctx context.Context, u *model.User) error
 }
 
@@ -21,10 +23,14 @@ type MangaFilter struct {
 	Query    string
 	Status   string
 	Tags     []string
-	Sort     string // "newest" | "oldest" | "title"
+	Sort     string // "newest" | "oldest" | "title" | "popular" | "trending" | "updated" | "chapters" | "rating"
 	Author   string
 	Artist   string
 	Category string
+
+	// TrendingIDs ranks manga for sort=trending, highest score first.
+	// Manga not in the list sort after it, newest first.
+	TrendingIDs []uuid.UUID
 }
 
 type MangaRepository interface {
@@ -33,17 +39,32 @@ type MangaRepository interface {
 	GetBySlug(ctx context.Context, slug string) (*model.Manga, error)
 	List(ctx context.Context, filter MangaFilter, p pagination.Params) ([]*model.Manga, int, error)
 	ListByOwner(ctx context.Context, ownerID uuid.UUID, p pagination.Params) ([]*model.Manga, int, error)
+	ListByIDs(ctx context.Context, ids []uuid.UUID) ([]*model.Manga, error)
 	ListAllForSitemap(ctx context.Context) ([]*model.Manga, error)
 	Update(ctx context.Context, m *model.Manga) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	SlugExists(ctx context.Context, slug string) (bool, error)
+	// RefreshLastChapterAt recomputes last_chapter_at from the manga's chapters that have pages.
+	RefreshLastChapterAt(ctx context.Context, id uuid.UUID) error
+}
+
+// LatestChapterFilter selects chapters for the latest releases feed.
+type LatestChapterFilter struct {
+	Tags     []string // manga must have all of them
+	Category string   // partial match on the manga category
+	Language string   // chapters in this language or with none; "" for all
+	// Chapters created strictly before (BeforeTime, BeforeID) in feed order; zero for the first page.
+	BeforeTime time.Time
+	BeforeID   uuid.UUID
 }
 
 type ChapterRepository interface {
 	Create(ctx context.Context, ch *model.Chapter) error
 	GetByID(ctx context.Context, id uuid.UUID) (*model.Chapter, error)
-	GetByMangaAndNumber(ctx context.Context, mangaID uuid.UUID, number float64) (*model.Chapter, error)
+	GetByMangaAndNumber(ctx context.Context, mangaID uuid.UUID, number float64, language string) (*model.Chapter, error)
 	ListByManga(ctx context.Context, mangaID uuid.UUID) ([]*model.Chapter, error)
+	// ListLatest returns published chapters (page_count > 0), newest first.
+	ListLatest(ctx context.Context, filter LatestChapterFilter, limit int) ([]*model.Chapter, error)
 	ListAllForSitemap(ctx context.Context) ([]*model.Chapter, error)
 	Update(ctx context.Context, ch *model.Chapter) error
 	Delete(ctx context.Context, id uuid.UUID) error
@@ -57,15 +78,58 @@ type PageRepository interface {
 	Delete(ctx context.Context, id uuid.UUID) error
 	UpdateNumbers(ctx context.Context, chapterID uuid.UUID, pageIDs []uuid.UUID) error
 	CountByChapter(ctx context.Context, chapterID uuid.UUID) (int, error)
+	ListByManga(ctx context.Context, mangaID uuid.UUID) ([]*model.Page, error)
+	ListHashCandidates(ctx context.Context, mangaID uuid.UUID, phashes []int64) ([]*model.PageHash, error)
+	ListDuplicateChapters(ctx context.Context, f DuplicateFilter) ([]*model.DuplicateChapterPair, error)
+}
+
+// PageObjectRepository reference-counts content-addressed page images.
+type PageObjectRepository interface {
+	Acquire(ctx context.Context, sha256 string) (*model.PageObject, error)
+	Create(ctx context.Context, o *model.PageObject) (*model.PageObject, error)
+	Release(ctx context.Context, shas []string, remove func(*model.PageObject) error) error
+}
+
+// DuplicateFilter selects chapter pairs for the duplicate report.
+type DuplicateFilter struct {
+	MangaID       *uuid.UUID // pairs involving this manga; nil for the whole library
+	MinSimilarity float64    // 0–1
+	MaxDistance   int        // Hamming distance for near-identical pages within MangaID; unused without it
+	Limit         int
+}
+
+// BookmarkFilter selects and orders a user's library.
+type BookmarkFilter struct {
+	Status model.BookmarkStatus // "" for every status
+	Sort   string               // "updated" (default) | "added" | "title" | "score" | "status"
 }
 
 type BookmarkRepository interface {
 	Upsert(ctx context.Context, b *model.Bookmark) error
 	GetByUserAndManga(ctx context.Context, userID, mangaID uuid.UUID) (*model.Bookmark, error)
-	ListByUser(ctx context.Context, userID uuid.UUID) ([]*model.Bookmark, error)
+	ListByUser(ctx context.Context, userID uuid.UUID, f BookmarkFilter) ([]*model.Bookmark, error)
+	// ListReaderIDs returns the users who bookmarked the manga and read the
+	// language: their preferred one, any when they have none, and untagged
+	// chapters (language "") for everyone.
+	ListReaderIDs(ctx context.Context, mangaID uuid.UUID, language string) ([]uuid.UUID, error)
 	Delete(ctx context.Context, userID, mangaID uuid.UUID) error
 }
 
+type ChapterReadRepository interface {
+	// Upsert records progress. Progress never decreases and CompletedAt, once
+	// set, is kept; r is updated with the stored row.
+	Upsert(ctx context.Context, r *model.ChapterRead) error
+	// MarkCompleted marks the chapters read in full, returning how many were
+	// not completed before.
+	MarkCompleted(ctx context.Context, userID uuid.UUID, chapterIDs []uuid.UUID, at time.Time) (int, error)
+	Delete(ctx context.Context, userID, chapterID uuid.UUID) error
+	ListByManga(ctx context.Context, userID, mangaID uuid.UUID) ([]*model.ChapterRead, error)
+	// UnreadCounts counts, per manga, the published chapters the user hasn't
+	// completed, in the user's preferred language as ChapterService.ListByManga
+	// filters it. Manga with nothing unread are absent from the map.
+	UnreadCounts(ctx context.Context, userID uuid.UUID, mangaIDs []uuid.UUID) (map[uuid.UUID]int, error)
+}
+
 type RefreshTokenRepository interface {
 	Create(ctx context.Context, rt *model.RefreshToken) error
 	GetByHash(ctx context.Context, hash string) (*model.RefreshToken, error)
@@ -73,14 +137,64 @@ type RefreshTokenRepository interface {
 	DeleteByUserID(ctx context.Context, userID uuid.UUID) error
 }
 
+// UploadTaskFilter selects a user's tasks; zero fields match everything.
+type UploadTaskFilter struct {
+	Status  model.UploadTaskStatus
+	Type    model.UploadTaskType
+	MangaID uuid.UUID
+}
+
 type UploadTaskRepository interface {
 	Create(ctx context.Context, t *model.UploadTask) error
 	GetByID(ctx context.Context, id uuid.UUID) (*model.UploadTask, error)
-	// ClaimProcessing atomically transitions a task from pending → processing.
+	ListByOwner(ctx context.Context, ownerID uuid.UUID, f UploadTaskFilter, p pagination.Params) ([]*model.UploadTask, int, error)
+	// ClaimProcessing atomically transitions a task from pending → processing
+	// and counts the attempt.
 	// Returns false if the task was already claimed or completed (duplicate delivery guard).
 	ClaimProcessing(ctx context.Context, id uuid.UUID) (claimed bool, err error)
+	// Cancel transitions a pending task to cancelled; false if it was not pending.
+	Cancel(ctx context.Context, id uuid.UUID) (bool, error)
+	// FailPending transitions a pending task to failed with errMsg; false if it
+	// was not pending.
+	FailPending(ctx context.Context, id uuid.UUID, errMsg string) (bool, error)
+	// Requeue transitions a failed task back to pending with its outcome
+	// (error, warnings, report, progress, attempts) cleared; false if it was not
+	// failed. Results of imported chapters are kept, so the retry skips them.
+	Requeue(ctx context.Context, id uuid.UUID) (bool, error)
+	// ListStuck returns up to limit tasks in processing not updated since before.
+	ListStuck(ctx context.Context, before time.Time, limit int) ([]*model.UploadTask, error)
+	// ReleaseStuck moves a task found by ListStuck to status (pending or failed)
+	// with errMsg; false if it was updated or left processing meanwhile.
+	ReleaseStuck(ctx context.Context, id uuid.UUID, before time.Time, status model.UploadTaskStatus, errMsg string) (bool, error)
 	UpdateStatus(ctx context.Context, id uuid.UUID, status model.UploadTaskStatus, errMsg string) error
 	SetChapterAndDone(ctx context.Context, id uuid.UUID, chapterID uuid.UUID) error
+	SetResults(ctx context.Context, id uuid.UUID, results model.ChapterResults) error
+	SetMetadataReport(ctx context.Context, id uuid.UUID, report *model.MetadataReport) error
+	SetWarnings(ctx context.Context, id uuid.UUID, warnings []string) error
+	SetProgress(ctx context.Context, id uuid.UUID, processed, total int) error
+	// Touch marks a processing task as alive for the reaper.
+	Touch(ctx context.Context, id uuid.UUID) error
+}
+
+type UploadSessionRepository interface {
+	Create(ctx context.Context, s *model.UploadSession) error
+	GetByID(ctx context.Context, id uuid.UUID) (*model.UploadSession, error)
+	// Lock takes the session for writing until until, provided it has no task
+	// yet, nobody else holds it and offset is still its offset.
+	Lock(ctx context.Context, id uuid.UUID, offset int64, until time.Time) (bool, error)
+	ExtendLock(ctx context.Context, id uuid.UUID, until time.Time) error
+	Unlock(ctx context.Context, id uuid.UUID) error
+	// AddPart records a stored part, which consumed the partial bytes, and the
+	// new offset.
+	AddPart(ctx context.Context, id uuid.UUID, part model.UploadPart, offset int64) error
+	// SavePartial records the partial bytes stored after the last part and the new offset.
+	SavePartial(ctx context.Context, id uuid.UUID, partialSize, offset int64) error
+	// MarkAssembled records that the multipart upload was completed.
+	MarkAssembled(ctx context.Context, id uuid.UUID) error
+	SetTask(ctx context.Context, id, taskID uuid.UUID) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	// ListExpired returns up to limit unlocked sessions that expired before before.
+	ListExpired(ctx context.Context, before time.Time, limit int) ([]*model.UploadSession, error)
 }
 
 type DeviceUserMappingRepository interface {
@@ -105,6 +219,51 @@ type InterestSyncWatermarkRepository interface {
 	Upsert(ctx context.Context, identityID uuid.UUID, lastSyncedAt time.Time) error
 }
 
+// ReviewFilter selects the written reviews of a manga.
+type ReviewFilter struct {
+	MangaID  uuid.UUID
+	ViewerID uuid.UUID // sets RatingWithAuthor.VotedHelpful; uuid.Nil when anonymous
+	Sort     string    // "helpful" (default) | "newest"
+}
+
+type RatingRepository interface {
+	// Upsert stores the user's rating of the manga, replacing an earlier one, and
+	// refreshes the manga's rating_avg and rating_count. Changing the review text
+	// clears its helpful votes. r is updated with the stored row.
+	Upsert(ctx context.Context, r *model.Rating) error
+	GetByID(ctx context.Context, id uuid.UUID) (*model.Rating, error)
+	GetByUserAndManga(ctx context.Context, userID, mangaID uuid.UUID) (*model.Rating, error)
+	// Delete removes the user's rating and refreshes the manga's aggregate.
+	Delete(ctx context.Context, userID, mangaID uuid.UUID) error
+	ListReviews(ctx context.Context, f ReviewFilter, p pagination.Params) ([]*model.RatingWithAuthor, int, error)
+	// AddVote and RemoveVote are idempotent and keep helpful_count in step.
+	AddVote(ctx context.Context, ratingID, userID uuid.UUID) error
+	RemoveVote(ctx context.Context, ratingID, userID uuid.UUID) error
+}
+
+type ReadingListRepository interface {
+	Create(ctx context.Context, l *model.ReadingList) error
+	GetByID(ctx context.Context, id uuid.UUID) (*model.ReadingList, error)
+	GetBySlug(ctx context.Context, slug string) (*model.ReadingList, error)
+	// ListByUser lists a user's lists, oldest first; publicOnly hides private ones.
+	ListByUser(ctx context.Context, userID uuid.UUID, publicOnly bool) ([]*model.ReadingList, error)
+	// ListContainingManga lists the public lists holding a manga, plus the
+	// viewer's own private ones, most recently updated first.
+	ListContainingManga(ctx context.Context, mangaID, viewerID uuid.UUID, p pagination.Params) ([]*model.ReadingList, int, error)
+	Update(ctx context.Context, l *model.ReadingList) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	SlugExists(ctx context.Context, slug string) (bool, error)
+
+	// AddItem appends a manga to the list; adding one already there is a no-op.
+	AddItem(ctx context.Context, listID, mangaID uuid.UUID) error
+	RemoveItem(ctx context.Context, listID, mangaID uuid.UUID) error
+	// ItemIDs returns the list's manga IDs in list order.
+	ItemIDs(ctx context.Context, listID uuid.UUID) ([]uuid.UUID, error)
+	// ReorderItems sets the list order to mangaIDs, which must be the list's items.
+	ReorderItems(ctx context.Context, listID uuid.UUID, mangaIDs []uuid.UUID) error
+	ListItems(ctx context.Context, listID uuid.UUID, p pagination.Params) ([]*model.Manga, int, error)
+}
+
 type CommentRepository interface {
 	Create(ctx context.Context, c *model.Comment) error
 	GetByID(ctx context.Context, id uuid.UUID) (*model.CommentWithAuthor, error)
@@ -113,3 +272,51 @@ type CommentRepository interface {
 	Update(ctx context.Context, c *model.Comment) error
 	Delete(ctx context.Context, id uuid.UUID) error
 }
+
+type NotificationRepository interface {
+	// CreateMany stores notifications, skipping recipients who turned the type
+	// off and ones already notified about the same subject. It returns how
+	// many were stored.
+	CreateMany(ctx context.Context, ns []*model.Notification) (int, error)
+	List(ctx context.Context, userID uuid.UUID, unreadOnly bool, p pagination.Params) ([]*model.NotificationWithContext, int, error)
+	CountUnread(ctx context.Context, userID uuid.UUID) (int, error)
+	MarkRead(ctx context.Context, userID, id uuid.UUID) error
+	// MarkAllRead marks every unread notification read and returns how many there were.
+	MarkAllRead(ctx context.Context, userID uuid.UUID) (int, error)
+	// Preferences returns the user's settings; missing types are enabled.
+	Preferences(ctx context.Context, userID uuid.UUID) (map[model.NotificationType]bool, error)
+	SetPreferences(ctx context.Context, userID uuid.UUID, prefs map[model.NotificationType]bool) error
+}
+
+type NotificationJobRepository interface {
+	Enqueue(ctx context.Context, j *model.NotificationJob) error
+	// Claim takes up to limit due jobs, skipping ones other workers hold, and
+	// postpones them by lease so a crashed worker's jobs run again later.
+	// Attempts is incremented.
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]*model.NotificationJob, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	// Retry records the failure and schedules the job for runAt.
+	Retry(ctx context.Context, id uuid.UUID, lastError string, runAt time.Time) error
+}
+
+type WebhookRepository interface {
+	Create(ctx context.Context, w *model.Webhook) error
+	GetByID(ctx context.Context, id uuid.UUID) (*model.Webhook, error)
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*model.Webhook, error)
+	CountByUser(ctx context.Context, userID uuid.UUID) (int, error)
+	Update(ctx context.Context, w *model.Webhook) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	// ListSubscribed returns the active webhooks subscribed to event, only
+	// ownerID's unless ownerID is uuid.Nil.
+	ListSubscribed(ctx context.Context, event model.WebhookEvent, ownerID uuid.UUID) ([]*model.Webhook, error)
+
+	CreateDeliveries(ctx context.Context, ds []*model.WebhookDelivery) error
+	GetDelivery(ctx context.Context, id uuid.UUID) (*model.WebhookDelivery, error)
+	ListDeliveries(ctx context.Context, webhookID uuid.UUID, p pagination.Params) ([]*model.WebhookDelivery, int, error)
+	// ClaimDeliveries takes up to limit due pending deliveries of active
+	// webhooks, skipping ones other workers hold, and postpones them by lease
+	// so a crashed worker's deliveries are retried. Attempts is incremented.
+	ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*model.WebhookDeliveryJob, error)
+	// UpdateDelivery stores the outcome of an attempt.
+	UpdateDelivery(ctx context.Context, d *model.WebhookDelivery) error
+}
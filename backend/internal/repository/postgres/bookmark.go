@@ -9,6 +9,7 @@ import (
 	"github.com/jmoiron/sqlx"
 	"github.com/yumikokawaii/sherry-archive/internal/apperror"
 	"github.com/yumikokawaii/sherry-archive/internal/model"
+	"github.com/yumikokawaii/sherry-archive/internal/repository"
 )
 
 type BookmarkRepo struct{ db *sqlx.DB }
@@ -17,11 +18,18 @@ func NewBookmarkRepo(db *sqlx.DB) *BookmarkRepo { return &BookmarkRepo{db: db} }
 
 func (r *BookmarkRepo) Upsert(ctx context.Context, b *model.Bookmark) error {
 	const q = `
-		INSERT INTO bookmarks (id, user_id, manga_id, chapter_id, last_page_number, created_at, updated_at)
-		VALUES (:id, :user_id, :manga_id, :chapter_id, :last_page_number, :created_at, :updated_at)
+		INSERT INTO bookmarks (id, user_id, manga_id, chapter_id, last_page_number, status, score,
+			started_at, finished_at, notes, created_at, updated_at)
+		VALUES (:id, :user_id, :manga_id, :chapter_id, :last_page_number, :status, :score,
+			:started_at, :finished_at, :notes, :created_at, :updated_at)
 		ON CONFLICT (user_id, manga_id) DO UPDATE SET
 			chapter_id = EXCLUDED.chapter_id,
 			last_page_number = EXCLUDED.last_page_number,
+			status = EXCLUDED.status,
+			score = EXCLUDED.score,
+			started_at = EXCLUDED.started_at,
+			finished_at = EXCLUDED.finished_at,
+			notes = EXCLUDED.notes,
 			updated_at = EXCLUDED.updated_at`
 	_, err := r.db.NamedExecContext(ctx, q, b)
 	return err
@@ -37,13 +45,44 @@ func (r *BookmarkRepo) GetByUserAndManga(ctx context.Context, userID, mangaID uu
 	return &b, err
 }
 
-func (r *BookmarkRepo) ListByUser(ctx context.Context, userID uuid.UUID) ([]*model.Bookmark, error) {
+func (r *BookmarkRepo) ListByUser(ctx context.Context, userID uuid.UUID, f repository.BookmarkFilter) ([]*model.Bookmark, error) {
+	order := "b.updated_at DESC"
+	switch f.Sort {
+	case "added":
+		order = "b.created_at DESC"
+	case "title":
+		order = "m.title ASC"
+	case "score":
+		order = "b.score DESC NULLS LAST, b.updated_at DESC"
+	case "status":
+		order = `CASE b.status WHEN 'reading' THEN 0 WHEN 'plan_to_read' THEN 1 WHEN 'on_hold' THEN 2
+			WHEN 'completed' THEN 3 ELSE 4 END, b.updated_at DESC`
+	}
 	var rows []*model.Bookmark
-	err := r.db.SelectContext(ctx, &rows,
-		`SELECT * FROM bookmarks WHERE user_id = $1 ORDER BY updated_at DESC`, userID)
+	err := r.db.SelectContext(ctx, &rows, `
+		SELECT b.* FROM bookmarks b
+		JOIN mangas m ON m.id = b.manga_id
+		WHERE b.user_id = $1 AND ($2 = '' OR b.status = $2)
+		ORDER BY `+order+`, b.id`, userID, string(f.Status))
 	return rows, err
 }
 
+func (r *BookmarkRepo) ListReaderIDs(ctx context.Context, mangaID uuid.UUID, language string) ([]uuid.UUID, error) {
+	// The language rule mirrors ChapterService.ListByManga, as in
+	// ChapterReadRepo.UnreadCounts.
+	var ids []uuid.UUID
+	err := r.db.SelectContext(ctx, &ids, `
+		SELECT b.user_id FROM bookmarks b
+		JOIN users u ON u.id = b.user_id
+		WHERE b.manga_id = $1
+		AND (
+			$2 = ''
+			OR u.preferred_language IN ('', $2)
+			OR NOT EXISTS (SELECT 1 FROM chapters l WHERE l.manga_id = b.manga_id AND l.language = u.preferred_language)
+		)`, mangaID, language)
+	return ids, err
+}
+
 func (r *BookmarkRepo) Delete(ctx context.Context, userID, mangaID uuid.UUID) error {
 	_, err := r.db.ExecContext(ctx,
 		`DELETE FROM bookmarks WHERE user_id = $1 AND manga_id = $2`, userID, mangaID)
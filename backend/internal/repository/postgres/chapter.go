@@ -4,11 +4,15 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/yumikokawaii/sherry-archive/internal/apperror"
 	"github.com/yumikokawaii/sherry-archive/internal/model"
+	"github.com/yumikokawaii/sherry-archive/internal/repository"
 )
 
 type ChapterRepo struct{ db *sqlx.DB }
@@ -17,8 +21,8 @@ func NewChapterRepo(db *sqlx.DB) *ChapterRepo { return &ChapterRepo{db: db} }
 
 func (r *ChapterRepo) Create(ctx context.Context, ch *model.Chapter) error {
 	const q = `
-		INSERT INTO chapters (id, manga_id, number, title, page_count, created_at, updated_at)
-		VALUES (:id, :manga_id, :number, :title, :page_count, :created_at, :updated_at)`
+		INSERT INTO chapters (id, manga_id, number, title, volume, language, page_count, created_at, updated_at)
+		VALUES (:id, :manga_id, :number, :title, :volume, :language, :page_count, :created_at, :updated_at)`
 	_, err := r.db.NamedExecContext(ctx, q, ch)
 	return err
 }
@@ -32,9 +36,10 @@ func (r *ChapterRepo) GetByID(ctx context.Context, id uuid.UUID) (*model.Chapter
 	return &ch, err
 }
 
-func (r *ChapterRepo) GetByMangaAndNumber(ctx context.Context, mangaID uuid.UUID, number float64) (*model.Chapter, error) {
+func (r *ChapterRepo) GetByMangaAndNumber(ctx context.Context, mangaID uuid.UUID, number float64, language string) (*model.Chapter, error) {
 	var ch model.Chapter
-	err := r.db.GetContext(ctx, &ch, `SELECT * FROM chapters WHERE manga_id = $1 AND number = $2`, mangaID, number)
+	err := r.db.GetContext(ctx, &ch,
+		`SELECT * FROM chapters WHERE manga_id = $1 AND number = $2 AND language = $3`, mangaID, number, language)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, apperror.ErrNotFound
 	}
@@ -43,7 +48,40 @@ func (r *ChapterRepo) GetByMangaAndNumber(ctx context.Context, mangaID uuid.UUID
 
 func (r *ChapterRepo) ListByManga(ctx context.Context, mangaID uuid.UUID) ([]*model.Chapter, error) {
 	var rows []*model.Chapter
-	err := r.db.SelectContext(ctx, &rows, `SELECT * FROM chapters WHERE manga_id = $1 ORDER BY number ASC`, mangaID)
+	err := r.db.SelectContext(ctx, &rows, `SELECT * FROM chapters WHERE manga_id = $1 ORDER BY number ASC, language ASC`, mangaID)
+	return rows, err
+}
+
+func (r *ChapterRepo) ListLatest(ctx context.Context, f repository.LatestChapterFilter, limit int) ([]*model.Chapter, error) {
+	clauses := []string{"c.page_count > 0"}
+	var args []any
+	if len(f.Tags) > 0 {
+		args = append(args, pq.Array(f.Tags))
+		clauses = append(clauses, fmt.Sprintf(`m.tags @> $%d`, len(args)))
+	}
+	if f.Category != "" {
+		args = append(args, "%"+f.Category+"%")
+		clauses = append(clauses, fmt.Sprintf(`m.category ILIKE $%d`, len(args)))
+	}
+	if f.Language != "" {
+		args = append(args, f.Language)
+		clauses = append(clauses, fmt.Sprintf(`c.language IN ($%d, '')`, len(args)))
+	}
+	if !f.BeforeTime.IsZero() {
+		args = append(args, f.BeforeTime, f.BeforeID)
+		clauses = append(clauses, fmt.Sprintf(`(c.created_at, c.id) < ($%d, $%d)`, len(args)-1, len(args)))
+	}
+	args = append(args, limit)
+
+	q := fmt.Sprintf(`
+		SELECT c.* FROM chapters c
+		JOIN mangas m ON m.id = c.manga_id
+		WHERE %s
+		ORDER BY c.created_at DESC, c.id DESC
+		LIMIT $%d`, strings.Join(clauses, " AND "), len(args))
+
+	var rows []*model.Chapter
+	err := r.db.SelectContext(ctx, &rows, q, args...)
 	return rows, err
 }
 
@@ -54,7 +92,9 @@ func (r *ChapterRepo) ListAllForSitemap(ctx context.Context) ([]*model.Chapter,
 }
 
 func (r *ChapterRepo) Update(ctx context.Context, ch *model.Chapter) error {
-	const q = `UPDATE chapters SET number=:number, title=:title, updated_at=:updated_at WHERE id=:id`
+	const q = `
+		UPDATE chapters SET number=:number, title=:title, volume=:volume, language=:language,
+		updated_at=:updated_at WHERE id=:id`
 	_, err := r.db.NamedExecContext(ctx, q, ch)
 	return err
 }
@@ -0,0 +1,109 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/yumikokawaii/sherry-archive/internal/model"
+)
+
+type ChapterReadRepo struct{ db *sqlx.DB }
+
+func NewChapterReadRepo(db *sqlx.DB) *ChapterReadRepo { return &ChapterReadRepo{db: db} }
+
+func (r *ChapterReadRepo) Upsert(ctx context.Context, cr *model.ChapterRead) error {
+	const q = `
+		INSERT INTO chapter_reads (user_id, chapter_id, manga_id, progress, last_page_number, completed_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (user_id, chapter_id) DO UPDATE SET
+			progress = GREATEST(chapter_reads.progress, EXCLUDED.progress),
+			last_page_number = EXCLUDED.last_page_number,
+			completed_at = COALESCE(chapter_reads.completed_at, EXCLUDED.completed_at),
+			updated_at = EXCLUDED.updated_at
+		RETURNING *`
+	return r.db.GetContext(ctx, cr, q,
+		cr.UserID, cr.ChapterID, cr.MangaID, cr.Progress, cr.LastPageNumber, cr.CompletedAt, cr.UpdatedAt)
+}
+
+func (r *ChapterReadRepo) MarkCompleted(ctx context.Context, userID uuid.UUID, chapterIDs []uuid.UUID, at time.Time) (int, error) {
+	if len(chapterIDs) == 0 {
+		return 0, nil
+	}
+	strs := make([]string, len(chapterIDs))
+	for i, id := range chapterIDs {
+		strs[i] = id.String()
+	}
+	// Chapters already complete are skipped by the conflict clause's WHERE, so
+	// only newly completed ones are returned and counted.
+	var n int
+	err := r.db.GetContext(ctx, &n, `
+		WITH marked AS (
+			INSERT INTO chapter_reads (user_id, chapter_id, manga_id, progress, last_page_number, completed_at, updated_at)
+			SELECT $1, c.id, c.manga_id, 100, c.page_count, $3, $3
+			FROM chapters c WHERE c.id = ANY($2::uuid[])
+			ON CONFLICT (user_id, chapter_id) DO UPDATE SET
+				progress = 100,
+				completed_at = $3,
+				updated_at = $3
+			WHERE chapter_reads.completed_at IS NULL
+			RETURNING 1
+		)
+		SELECT COUNT(*) FROM marked`, userID, pq.Array(strs), at)
+	return n, err
+}
+
+func (r *ChapterReadRepo) Delete(ctx context.Context, userID, chapterID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx,
+		`DELETE FROM chapter_reads WHERE user_id = $1 AND chapter_id = $2`, userID, chapterID)
+	return err
+}
+
+func (r *ChapterReadRepo) ListByManga(ctx context.Context, userID, mangaID uuid.UUID) ([]*model.ChapterRead, error) {
+	var rows []*model.ChapterRead
+	err := r.db.SelectContext(ctx, &rows,
+		`SELECT * FROM chapter_reads WHERE user_id = $1 AND manga_id = $2`, userID, mangaID)
+	return rows, err
+}
+
+func (r *ChapterReadRepo) UnreadCounts(ctx context.Context, userID uuid.UUID, mangaIDs []uuid.UUID) (map[uuid.UUID]int, error) {
+	counts := make(map[uuid.UUID]int)
+	if len(mangaIDs) == 0 {
+		return counts, nil
+	}
+	strs := make([]string, len(mangaIDs))
+	for i, id := range mangaIDs {
+		strs[i] = id.String()
+	}
+	// The language rule mirrors ChapterService.ListByManga: the preferred
+	// language plus untagged chapters, or everything when the user has no
+	// preference or the manga has no chapter in it.
+	var rows []struct {
+		MangaID uuid.UUID `db:"manga_id"`
+		Unread  int       `db:"unread"`
+	}
+	err := r.db.SelectContext(ctx, &rows, `
+		SELECT c.manga_id, COUNT(*) AS unread
+		FROM chapters c
+		JOIN users u ON u.id = $1
+		WHERE c.manga_id = ANY($2::uuid[]) AND c.page_count > 0
+		AND (
+			u.preferred_language = ''
+			OR c.language IN ('', u.preferred_language)
+			OR NOT EXISTS (SELECT 1 FROM chapters l WHERE l.manga_id = c.manga_id AND l.language = u.preferred_language)
+		)
+		AND NOT EXISTS (
+			SELECT 1 FROM chapter_reads r
+			WHERE r.user_id = $1 AND r.chapter_id = c.id AND r.completed_at IS NOT NULL
+		)
+		GROUP BY c.manga_id`, userID, pq.Array(strs))
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		counts[row.MangaID] = row.Unread
+	}
+	return counts, nil
+}
@@ -23,8 +23,8 @@ const commentJoin = `
 
 func (r *CommentRepo) Create(ctx context.Context, c *model.Comment) error {
 	const q = `
-		INSERT INTO comments (id, user_id, manga_id, chapter_id, content, edited, created_at, updated_at)
-		VALUES (:id, :user_id, :manga_id, :chapter_id, :content, :edited, :created_at, :updated_at)`
+		INSERT INTO comments (id, user_id, manga_id, chapter_id, parent_id, content, edited, created_at, updated_at)
+		VALUES (:id, :user_id, :manga_id, :chapter_id, :parent_id, :content, :edited, :created_at, :updated_at)`
 	_, err := r.db.NamedExecContext(ctx, q, c)
 	return err
 }
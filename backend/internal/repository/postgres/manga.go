@@ -22,8 +22,8 @@ func NewMangaRepo(db *sqlx.DB) *MangaRepo { return &MangaRepo{db: db} }
 
 func (r *MangaRepo) Create(ctx context.Context, m *model.Manga) error {
 	const q = `
-		INSERT INTO mangas (id, owner_id, title, slug, description, cover_key, status, type, tags, author, artist, category, created_at, updated_at)
-		VALUES (:id, :owner_id, :title, :slug, :description, :cover_key, :status, :type, :tags, :author, :artist, :category, :created_at, :updated_at)`
+		INSERT INTO mangas (id, owner_id, title, slug, description, cover_key, cover_variants, status, type, tags, author, artist, category, created_at, updated_at)
+		VALUES (:id, :owner_id, :title, :slug, :description, :cover_key, :cover_variants, :status, :type, :tags, :author, :artist, :category, :created_at, :updated_at)`
 	_, err := r.db.NamedExecContext(ctx, q, m)
 	return err
 }
@@ -54,9 +54,10 @@ func (r *MangaRepo) List(ctx context.Context, filter repository.MangaFilter, p p
 		return nil, 0, err
 	}
 
-	order := mangaOrderBy(filter.Sort)
-	dataQ := fmt.Sprintf(`SELECT * FROM mangas %s ORDER BY %s LIMIT $%d OFFSET $%d`,
-		where, order, len(args)+1, len(args)+2)
+	join, order, joinArgs := mangaOrderBy(filter, len(args)+1)
+	args = append(args, joinArgs...)
+	dataQ := fmt.Sprintf(`SELECT mangas.* FROM mangas %s %s ORDER BY %s LIMIT $%d OFFSET $%d`,
+		join, where, order, len(args)+1, len(args)+2)
 	args = append(args, p.Limit, p.Offset)
 
 	var rows []*model.Manga
@@ -66,6 +67,19 @@ func (r *MangaRepo) List(ctx context.Context, filter repository.MangaFilter, p p
 	return rows, total, nil
 }
 
+func (r *MangaRepo) ListByIDs(ctx context.Context, ids []uuid.UUID) ([]*model.Manga, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	strs := make([]string, len(ids))
+	for i, id := range ids {
+		strs[i] = id.String()
+	}
+	var rows []*model.Manga
+	err := r.db.SelectContext(ctx, &rows, `SELECT * FROM mangas WHERE id = ANY($1::uuid[])`, pq.Array(strs))
+	return rows, err
+}
+
 func (r *MangaRepo) ListByOwner(ctx context.Context, ownerID uuid.UUID, p pagination.Params) ([]*model.Manga, int, error) {
 	var total int
 	if err := r.db.GetContext(ctx, &total, `SELECT COUNT(*) FROM mangas WHERE owner_id = $1`, ownerID); err != nil {
@@ -80,7 +94,7 @@ func (r *MangaRepo) ListByOwner(ctx context.Context, ownerID uuid.UUID, p pagina
 
 func (r *MangaRepo) Update(ctx context.Context, m *model.Manga) error {
 	const q = `
-		UPDATE mangas SET title=:title, slug=:slug, description=:description, cover_key=:cover_key,
+		UPDATE mangas SET title=:title, slug=:slug, description=:description, cover_key=:cover_key, cover_variants=:cover_variants,
 		status=:status, type=:type, tags=:tags, author=:author, artist=:artist, category=:category,
 		updated_at=:updated_at WHERE id=:id`
 	_, err := r.db.NamedExecContext(ctx, q, m)
@@ -98,6 +112,14 @@ func (r *MangaRepo) ListAllForSitemap(ctx context.Context) ([]*model.Manga, erro
 	return rows, err
 }
 
+func (r *MangaRepo) RefreshLastChapterAt(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE mangas SET last_chapter_at = (
+			SELECT MAX(created_at) FROM chapters WHERE manga_id = $1 AND page_count > 0
+		) WHERE id = $1`, id)
+	return err
+}
+
 func (r *MangaRepo) SlugExists(ctx context.Context, slug string) (bool, error) {
 	var exists bool
 	err := r.db.GetContext(ctx, &exists, `SELECT EXISTS(SELECT 1 FROM mangas WHERE slug = $1)`, slug)
@@ -147,13 +169,40 @@ func buildMangaWhere(f repository.MangaFilter) (string, []any) {
 	return "WHERE " + strings.Join(clauses, " AND "), args
 }
 
-func mangaOrderBy(sort string) string {
-	switch sort {
+// ratingPriorWeight is how many ratings at the library-wide mean sort=rating
+// adds to every manga.
+const ratingPriorWeight = 10
+
+// mangaOrderBy returns the join (if any), ORDER BY clause and join args for a sort.
+// argIdx is the placeholder number of the first join arg. Every order ends with
+// mangas.id so pages stay stable when the leading key ties.
+func mangaOrderBy(f repository.MangaFilter, argIdx int) (string, string, []any) {
+	switch f.Sort {
 	case "oldest":
-		return "created_at ASC"
+		return "", "mangas.created_at ASC, mangas.id ASC", nil
 	case "title":
-		return "title ASC"
+		return "", "mangas.title ASC, mangas.id ASC", nil
+	case "popular":
+		return "LEFT JOIN manga_popularity p ON p.manga_id = mangas.id",
+			"COALESCE(p.score, 0) DESC, mangas.created_at DESC, mangas.id DESC", nil
+	case "trending":
+		ids := make([]string, len(f.TrendingIDs))
+		for i, id := range f.TrendingIDs {
+			ids[i] = id.String()
+		}
+		join := fmt.Sprintf("LEFT JOIN unnest($%d::uuid[]) WITH ORDINALITY tr(manga_id, rank) ON tr.manga_id = mangas.id", argIdx)
+		return join, "tr.rank ASC NULLS LAST, mangas.created_at DESC, mangas.id DESC", []any{pq.Array(ids)}
+	case "updated":
+		return "", "mangas.last_chapter_at DESC NULLS LAST, mangas.created_at DESC, mangas.id DESC", nil
+	case "rating":
+		// Bayesian average: each manga's mean pulled toward the library-wide mean
+		// by ratingPriorWeight phantom ratings, so a single 10 doesn't top the list.
+		return "CROSS JOIN (SELECT COALESCE(AVG(score), 0) AS mean FROM ratings) rg",
+			fmt.Sprintf("(mangas.rating_avg * mangas.rating_count + rg.mean * %d) / (mangas.rating_count + %d) DESC, mangas.rating_count DESC, mangas.created_at DESC, mangas.id DESC",
+				ratingPriorWeight, ratingPriorWeight), nil
+	case "chapters":
+		return "", "(SELECT COUNT(*) FROM chapters c WHERE c.manga_id = mangas.id AND c.page_count > 0) DESC, mangas.created_at DESC, mangas.id DESC", nil
 	default:
-		return "created_at DESC"
+		return "", "mangas.created_at DESC, mangas.id DESC", nil
 	}
 }
@@ -0,0 +1,148 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/yumikokawaii/sherry-archive/internal/apperror"
+	"github.com/yumikokawaii/sherry-archive/internal/model"
+	"github.com/yumikokawaii/sherry-archive/pkg/pagination"
+)
+
+type NotificationRepo struct{ db *sqlx.DB }
+
+func NewNotificationRepo(db *sqlx.DB) *NotificationRepo { return &NotificationRepo{db: db} }
+
+const notificationJoin = `
+	SELECT n.*, a.username AS actor_username, m.title AS manga_title,
+		ch.number AS chapter_number, ch.title AS chapter_title, cm.content AS comment_content
+	FROM notifications n
+	JOIN mangas m ON m.id = n.manga_id
+	LEFT JOIN users a ON a.id = n.actor_id
+	LEFT JOIN chapters ch ON ch.id = n.chapter_id
+	LEFT JOIN comments cm ON cm.id = n.comment_id`
+
+func (r *NotificationRepo) CreateMany(ctx context.Context, ns []*model.Notification) (int, error) {
+	if len(ns) == 0 {
+		return 0, nil
+	}
+	// Nullable IDs travel as "" and become NULL.
+	var ids, userIDs, types, actorIDs, mangaIDs, chapterIDs, commentIDs []string
+	for _, n := range ns {
+		ids = append(ids, n.ID.String())
+		userIDs = append(userIDs, n.UserID.String())
+		types = append(types, string(n.Type))
+		actorIDs = append(actorIDs, optionalUUID(n.ActorID))
+		mangaIDs = append(mangaIDs, n.MangaID.String())
+		chapterIDs = append(chapterIDs, optionalUUID(n.ChapterID))
+		commentIDs = append(commentIDs, optionalUUID(n.CommentID))
+	}
+	res, err := r.db.ExecContext(ctx, `
+		INSERT INTO notifications (id, user_id, type, actor_id, manga_id, chapter_id, comment_id, created_at)
+		SELECT n.id::uuid, n.user_id::uuid, n.type, NULLIF(n.actor_id, '')::uuid, n.manga_id::uuid,
+			NULLIF(n.chapter_id, '')::uuid, NULLIF(n.comment_id, '')::uuid, $8::timestamptz
+		FROM unnest($1::text[], $2::text[], $3::text[], $4::text[], $5::text[], $6::text[], $7::text[])
+			AS n(id, user_id, type, actor_id, manga_id, chapter_id, comment_id)
+		WHERE NOT EXISTS (
+			SELECT 1 FROM notification_preferences p
+			WHERE p.user_id = n.user_id::uuid AND p.type = n.type AND NOT p.enabled
+		)
+		ON CONFLICT (user_id, type, (COALESCE(comment_id, chapter_id))) DO NOTHING`,
+		pq.Array(ids), pq.Array(userIDs), pq.Array(types), pq.Array(actorIDs),
+		pq.Array(mangaIDs), pq.Array(chapterIDs), pq.Array(commentIDs), ns[0].CreatedAt)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+func optionalUUID(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	return id.String()
+}
+
+func (r *NotificationRepo) List(ctx context.Context, userID uuid.UUID, unreadOnly bool, p pagination.Params) ([]*model.NotificationWithContext, int, error) {
+	const where = ` WHERE n.user_id = $1 AND (n.read_at IS NULL OR NOT $2)`
+	var total int
+	if err := r.db.GetContext(ctx, &total, `SELECT COUNT(*) FROM notifications n`+where, userID, unreadOnly); err != nil {
+		return nil, 0, err
+	}
+	var rows []*model.NotificationWithContext
+	err := r.db.SelectContext(ctx, &rows,
+		notificationJoin+where+` ORDER BY n.created_at DESC, n.id DESC LIMIT $3 OFFSET $4`,
+		userID, unreadOnly, p.Limit, p.Offset)
+	return rows, total, err
+}
+
+func (r *NotificationRepo) CountUnread(ctx context.Context, userID uuid.UUID) (int, error) {
+	var n int
+	err := r.db.GetContext(ctx, &n,
+		`SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL`, userID)
+	return n, err
+}
+
+func (r *NotificationRepo) MarkRead(ctx context.Context, userID, id uuid.UUID) error {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE notifications SET read_at = COALESCE(read_at, $3) WHERE id = $1 AND user_id = $2`,
+		id, userID, time.Now())
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return apperror.ErrNotFound
+	}
+	return nil
+}
+
+func (r *NotificationRepo) MarkAllRead(ctx context.Context, userID uuid.UUID) (int, error) {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE notifications SET read_at = $2 WHERE user_id = $1 AND read_at IS NULL`, userID, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+func (r *NotificationRepo) Preferences(ctx context.Context, userID uuid.UUID) (map[model.NotificationType]bool, error) {
+	var rows []struct {
+		Type    model.NotificationType `db:"type"`
+		Enabled bool                   `db:"enabled"`
+	}
+	if err := r.db.SelectContext(ctx, &rows,
+		`SELECT type, enabled FROM notification_preferences WHERE user_id = $1`, userID); err != nil {
+		return nil, err
+	}
+	prefs := make(map[model.NotificationType]bool, len(model.NotificationTypes))
+	for _, t := range model.NotificationTypes {
+		prefs[t] = true
+	}
+	for _, row := range rows {
+		prefs[row.Type] = row.Enabled
+	}
+	return prefs, nil
+}
+
+func (r *NotificationRepo) SetPreferences(ctx context.Context, userID uuid.UUID, prefs map[model.NotificationType]bool) error {
+	if len(prefs) == 0 {
+		return nil
+	}
+	types := make([]string, 0, len(prefs))
+	enabled := make([]bool, 0, len(prefs))
+	for t, on := range prefs {
+		types = append(types, string(t))
+		enabled = append(enabled, on)
+	}
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO notification_preferences (user_id, type, enabled)
+		SELECT $1::uuid, p.type, p.enabled FROM unnest($2::text[], $3::bool[]) AS p(type, enabled)
+		ON CONFLICT (user_id, type) DO UPDATE SET enabled = EXCLUDED.enabled`,
+		userID, pq.Array(types), pq.Array(enabled))
+	return err
+}
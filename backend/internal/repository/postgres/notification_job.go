@@ -0,0 +1,50 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/yumikokawaii/sherry-archive/internal/model"
+)
+
+type NotificationJobRepo struct{ db *sqlx.DB }
+
+func NewNotificationJobRepo(db *sqlx.DB) *NotificationJobRepo { return &NotificationJobRepo{db: db} }
+
+func (r *NotificationJobRepo) Enqueue(ctx context.Context, j *model.NotificationJob) error {
+	const q = `
+		INSERT INTO notification_jobs (id, type, subject_id, attempts, last_error, run_at, created_at)
+		VALUES (:id, :type, :subject_id, :attempts, :last_error, :run_at, :created_at)`
+	_, err := r.db.NamedExecContext(ctx, q, j)
+	return err
+}
+
+func (r *NotificationJobRepo) Claim(ctx context.Context, limit int, lease time.Duration) ([]*model.NotificationJob, error) {
+	// SKIP LOCKED lets several API instances poll the table without taking
+	// the same jobs; the lease keeps them claimed after the statement commits.
+	now := time.Now()
+	var jobs []*model.NotificationJob
+	err := r.db.SelectContext(ctx, &jobs, `
+		UPDATE notification_jobs j SET attempts = j.attempts + 1, run_at = $3
+		FROM (
+			SELECT id FROM notification_jobs WHERE run_at <= $1
+			ORDER BY run_at LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		) due
+		WHERE j.id = due.id
+		RETURNING j.*`, now, limit, now.Add(lease))
+	return jobs, err
+}
+
+func (r *NotificationJobRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM notification_jobs WHERE id = $1`, id)
+	return err
+}
+
+func (r *NotificationJobRepo) Retry(ctx context.Context, id uuid.UUID, lastError string, runAt time.Time) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE notification_jobs SET last_error = $2, run_at = $3 WHERE id = $1`, id, lastError, runAt)
+	return err
+}
@@ -9,8 +9,10 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/yumikokawaii/sherry-archive/internal/apperror"
 	"github.com/yumikokawaii/sherry-archive/internal/model"
+	"github.com/yumikokawaii/sherry-archive/internal/repository"
 )
 
 type PageRepo struct{ db *sqlx.DB }
@@ -21,16 +23,16 @@ func (r *PageRepo) CreateBatch(ctx context.Context, pages []*model.Page) error {
 	if len(pages) == 0 {
 		return nil
 	}
-	const cols = 7
+	const cols = 10
 	placeholders := make([]string, len(pages))
 	args := make([]any, 0, len(pages)*cols)
 	for i, p := range pages {
 		base := i * cols
-		placeholders[i] = fmt.Sprintf("($%d,$%d,$%d,$%d,$%d,$%d,$%d)",
-			base+1, base+2, base+3, base+4, base+5, base+6, base+7)
-		args = append(args, p.ID, p.ChapterID, p.Number, p.ObjectKey, p.Width, p.Height, p.CreatedAt)
+		placeholders[i] = fmt.Sprintf("($%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d)",
+			base+1, base+2, base+3, base+4, base+5, base+6, base+7, base+8, base+9, base+10)
+		args = append(args, p.ID, p.ChapterID, p.Number, p.ObjectKey, p.ThumbnailKey, p.Width, p.Height, p.SHA256, p.PHash, p.CreatedAt)
 	}
-	q := fmt.Sprintf(`INSERT INTO pages (id, chapter_id, number, object_key, width, height, created_at) VALUES %s`,
+	q := fmt.Sprintf(`INSERT INTO pages (id, chapter_id, number, object_key, thumbnail_key, width, height, sha256, phash, created_at) VALUES %s`,
 		strings.Join(placeholders, ","))
 	_, err := r.db.ExecContext(ctx, q, args...)
 	return err
@@ -75,8 +77,83 @@ func (r *PageRepo) UpdateNumbers(ctx context.Context, chapterID uuid.UUID, pageI
 	return tx.Commit()
 }
 
+// ListByManga returns the pages of every chapter of a manga.
+func (r *PageRepo) ListByManga(ctx context.Context, mangaID uuid.UUID) ([]*model.Page, error) {
+	var rows []*model.Page
+	err := r.db.SelectContext(ctx, &rows, `
+		SELECT p.* FROM pages p
+		JOIN chapters c ON c.id = p.chapter_id
+		WHERE c.manga_id = $1`, mangaID)
+	return rows, err
+}
+
 func (r *PageRepo) CountByChapter(ctx context.Context, chapterID uuid.UUID) (int, error) {
 	var count int
 	err := r.db.GetContext(ctx, &count, `SELECT COUNT(*) FROM pages WHERE chapter_id = $1`, chapterID)
 	return count, err
 }
+
+// ListHashCandidates returns the perceptual hashes a new chapter of mangaID must
+// be compared with: every hashed page of the manga, and pages anywhere in the
+// library whose hash equals one of phashes. Blank pages (hash 0) are skipped.
+func (r *PageRepo) ListHashCandidates(ctx context.Context, mangaID uuid.UUID, phashes []int64) ([]*model.PageHash, error) {
+	const q = `
+		SELECT p.chapter_id, c.manga_id, p.phash
+		FROM pages p JOIN chapters c ON c.id = p.chapter_id
+		WHERE c.manga_id = $1 AND p.phash IS NOT NULL AND p.phash <> 0
+		UNION ALL
+		SELECT p.chapter_id, c.manga_id, p.phash
+		FROM pages p JOIN chapters c ON c.id = p.chapter_id
+		WHERE c.manga_id <> $1 AND p.phash = ANY($2) AND p.phash <> 0`
+	var rows []*model.PageHash
+	err := r.db.SelectContext(ctx, &rows, q, mangaID, pq.Int64Array(phashes))
+	return rows, err
+}
+
+// ListDuplicateChapters pairs chapters that share pages. Across mangas pages must
+// have equal perceptual hashes, so the library-wide report is an index join.
+// Scoped to f.MangaID, pages of that manga also match within a Hamming distance
+// of f.MaxDistance, which catches re-encoded re-uploads.
+func (r *PageRepo) ListDuplicateChapters(ctx context.Context, f repository.DuplicateFilter) ([]*model.DuplicateChapterPair, error) {
+	args := []any{f.MinSimilarity, f.Limit}
+	scope := ""
+	near := ""
+	if f.MangaID != nil {
+		args = append(args, *f.MangaID, f.MaxDistance)
+		scope = "AND (c1.manga_id = $3 OR c2.manga_id = $3)"
+		near = `
+			UNION
+			SELECT a.chapter_id, b.chapter_id, a.phash
+			FROM h a JOIN h b ON b.manga_id = a.manga_id AND b.chapter_id > a.chapter_id
+			WHERE a.manga_id = $3 AND bit_count((a.phash # b.phash)::bit(64)) <= $4`
+	}
+	q := `
+		WITH h AS (
+			SELECT DISTINCT p.chapter_id, c.manga_id, p.phash
+			FROM pages p JOIN chapters c ON c.id = p.chapter_id
+			WHERE p.phash IS NOT NULL AND p.phash <> 0
+		), pairs AS (
+			SELECT a.chapter_id, b.chapter_id AS other_id, a.phash
+			FROM h a JOIN h b ON b.phash = a.phash AND b.chapter_id > a.chapter_id` + near + `
+		), counts AS (
+			SELECT chapter_id, other_id, COUNT(DISTINCT phash) AS shared
+			FROM pairs GROUP BY chapter_id, other_id
+		), scored AS (
+			SELECT counts.*, LEAST(1.0, counts.shared::float8 / GREATEST(LEAST(c1.page_count, c2.page_count), 1)) AS similarity
+			FROM counts
+			JOIN chapters c1 ON c1.id = counts.chapter_id
+			JOIN chapters c2 ON c2.id = counts.other_id
+		)
+		SELECT c1.manga_id, m1.title AS manga_title, c1.id AS chapter_id, c1.number AS chapter_number,
+		       c2.manga_id AS other_manga_id, m2.title AS other_manga_title, c2.id AS other_chapter_id, c2.number AS other_chapter_number,
+		       s.shared AS shared_pages, s.similarity
+		FROM scored s
+		JOIN chapters c1 ON c1.id = s.chapter_id JOIN mangas m1 ON m1.id = c1.manga_id
+		JOIN chapters c2 ON c2.id = s.other_id JOIN mangas m2 ON m2.id = c2.manga_id
+		WHERE s.similarity >= $1 ` + scope + `
+		ORDER BY s.similarity DESC, s.shared DESC, c1.id, c2.id
+		LIMIT $2`
+	var rows []*model.DuplicateChapterPair
+	err := r.db.SelectContext(ctx, &rows, q, args...)
+	return rows, err
+}
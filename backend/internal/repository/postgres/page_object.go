@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/yumikokawaii/sherry-archive/internal/apperror"
+	"github.com/yumikokawaii/sherry-archive/internal/model"
+)
+
+type PageObjectRepo struct{ db *sqlx.DB }
+
+func NewPageObjectRepo(db *sqlx.DB) *PageObjectRepo { return &PageObjectRepo{db: db} }
+
+// Acquire adds a reference to the object with this content, if it is stored.
+// It waits for a concurrent Release of the same object, so an object being
+// deleted is never handed out. Rows without references (left by failed
+// deletes before Release committed first) are skipped, their images may be gone.
+func (r *PageObjectRepo) Acquire(ctx context.Context, sha256 string) (*model.PageObject, error) {
+	var o model.PageObject
+	err := r.db.GetContext(ctx, &o,
+		`UPDATE page_objects SET ref_count = ref_count + 1 WHERE sha256 = $1 AND ref_count > 0 RETURNING *`, sha256)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperror.ErrNotFound
+	}
+	return &o, err
+}
+
+// Create records a newly uploaded object with one reference. If the same content
+// was stored concurrently, that row gets the reference and is returned instead;
+// a row without references is replaced.
+func (r *PageObjectRepo) Create(ctx context.Context, o *model.PageObject) (*model.PageObject, error) {
+	const q = `
+		INSERT INTO page_objects (sha256, object_key, thumbnail_key, width, height, ref_count, created_at)
+		VALUES ($1, $2, $3, $4, $5, 1, $6)
+		ON CONFLICT (sha256) DO UPDATE SET
+			object_key    = CASE WHEN page_objects.ref_count = 0 THEN EXCLUDED.object_key    ELSE page_objects.object_key    END,
+			thumbnail_key = CASE WHEN page_objects.ref_count = 0 THEN EXCLUDED.thumbnail_key ELSE page_objects.thumbnail_key END,
+			width         = CASE WHEN page_objects.ref_count = 0 THEN EXCLUDED.width         ELSE page_objects.width         END,
+			height        = CASE WHEN page_objects.ref_count = 0 THEN EXCLUDED.height        ELSE page_objects.height        END,
+			ref_count     = page_objects.ref_count + 1
+		RETURNING *`
+	var out model.PageObject
+	err := r.db.GetContext(ctx, &out, q, o.SHA256, o.ObjectKey, o.ThumbnailKey, o.Width, o.Height, o.CreatedAt)
+	return &out, err
+}
+
+// Release drops one reference per entry of shas (an object may be listed more
+// than once) and deletes the rows left without references. remove is called for
+// each deleted object once that is committed, so a failed remove only leaves
+// unused images behind: new uploads of the same content get new keys. The first
+// remove error is returned after the rest are processed.
+func (r *PageObjectRepo) Release(ctx context.Context, shas []string, remove func(*model.PageObject) error) error {
+	if len(shas) == 0 {
+		return nil
+	}
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Lock in a fixed order so concurrent releases can't deadlock.
+	if _, err := tx.ExecContext(ctx,
+		`SELECT 1 FROM page_objects WHERE sha256 = ANY($1) ORDER BY sha256 FOR UPDATE`, pq.StringArray(shas)); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE page_objects o SET ref_count = GREATEST(o.ref_count - r.n, 0)
+		FROM (SELECT sha256, COUNT(*) AS n FROM UNNEST($1::text[]) AS sha256 GROUP BY sha256) r
+		WHERE o.sha256 = r.sha256`, pq.StringArray(shas)); err != nil {
+		return err
+	}
+	var released []*model.PageObject
+	if err := tx.SelectContext(ctx, &released,
+		`DELETE FROM page_objects WHERE sha256 = ANY($1) AND ref_count = 0 RETURNING *`, pq.StringArray(shas)); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	var removeErr error
+	for _, o := range released {
+		if err := remove(o); err != nil && removeErr == nil {
+			removeErr = err
+		}
+	}
+	return removeErr
+}
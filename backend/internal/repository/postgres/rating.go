@@ -0,0 +1,161 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/yumikokawaii/sherry-archive/internal/apperror"
+	"github.com/yumikokawaii/sherry-archive/internal/model"
+	"github.com/yumikokawaii/sherry-archive/internal/repository"
+	"github.com/yumikokawaii/sherry-archive/pkg/pagination"
+)
+
+type RatingRepo struct{ db *sqlx.DB }
+
+func NewRatingRepo(db *sqlx.DB) *RatingRepo { return &RatingRepo{db: db} }
+
+func (r *RatingRepo) Upsert(ctx context.Context, rt *model.Rating) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockMangaRating(ctx, tx, rt.MangaID); err != nil {
+		return err
+	}
+	// Helpful votes were cast for the old text; a rewritten review starts over.
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM review_votes v USING ratings r
+		WHERE v.rating_id = r.id AND r.user_id = $1 AND r.manga_id = $2 AND r.review <> $3`,
+		rt.UserID, rt.MangaID, rt.Review); err != nil {
+		return err
+	}
+	const q = `
+		INSERT INTO ratings (id, user_id, manga_id, score, review, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (manga_id, user_id) DO UPDATE
+		SET score = EXCLUDED.score, review = EXCLUDED.review, updated_at = EXCLUDED.updated_at,
+			helpful_count = CASE WHEN ratings.review = EXCLUDED.review THEN ratings.helpful_count ELSE 0 END
+		RETURNING *`
+	if err := tx.GetContext(ctx, rt, q,
+		rt.ID, rt.UserID, rt.MangaID, rt.Score, rt.Review, rt.CreatedAt, rt.UpdatedAt); err != nil {
+		return err
+	}
+	if err := refreshMangaRating(ctx, tx, rt.MangaID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *RatingRepo) GetByID(ctx context.Context, id uuid.UUID) (*model.Rating, error) {
+	var rt model.Rating
+	err := r.db.GetContext(ctx, &rt, `SELECT * FROM ratings WHERE id = $1`, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperror.ErrNotFound
+	}
+	return &rt, err
+}
+
+func (r *RatingRepo) GetByUserAndManga(ctx context.Context, userID, mangaID uuid.UUID) (*model.Rating, error) {
+	var rt model.Rating
+	err := r.db.GetContext(ctx, &rt, `SELECT * FROM ratings WHERE user_id = $1 AND manga_id = $2`, userID, mangaID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperror.ErrNotFound
+	}
+	return &rt, err
+}
+
+func (r *RatingRepo) Delete(ctx context.Context, userID, mangaID uuid.UUID) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockMangaRating(ctx, tx, mangaID); err != nil {
+		return err
+	}
+	res, err := tx.ExecContext(ctx, `DELETE FROM ratings WHERE user_id = $1 AND manga_id = $2`, userID, mangaID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return apperror.ErrNotFound
+	}
+	if err := refreshMangaRating(ctx, tx, mangaID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// lockMangaRating serializes rating writes per manga, so concurrent ratings
+// can't overwrite each other's aggregate. NO KEY UPDATE doesn't conflict with
+// the key-share lock the ratings foreign key takes.
+func lockMangaRating(ctx context.Context, tx *sqlx.Tx, mangaID uuid.UUID) error {
+	var id uuid.UUID
+	err := tx.GetContext(ctx, &id, `SELECT id FROM mangas WHERE id = $1 FOR NO KEY UPDATE`, mangaID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return apperror.ErrNotFound
+	}
+	return err
+}
+
+// refreshMangaRating recomputes the manga's aggregate; the caller holds lockMangaRating.
+func refreshMangaRating(ctx context.Context, tx *sqlx.Tx, mangaID uuid.UUID) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE mangas SET (rating_avg, rating_count) = (
+			SELECT COALESCE(AVG(score), 0), COUNT(*) FROM ratings WHERE manga_id = $1
+		) WHERE id = $1`, mangaID)
+	return err
+}
+
+func (r *RatingRepo) ListReviews(ctx context.Context, f repository.ReviewFilter, p pagination.Params) ([]*model.RatingWithAuthor, int, error) {
+	var total int
+	err := r.db.GetContext(ctx, &total,
+		`SELECT COUNT(*) FROM ratings WHERE manga_id = $1 AND review <> ''`, f.MangaID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	order := "r.helpful_count DESC, r.created_at DESC, r.id DESC"
+	if f.Sort == "newest" {
+		order = "r.created_at DESC, r.id DESC"
+	}
+	var rows []*model.RatingWithAuthor
+	err = r.db.SelectContext(ctx, &rows, `
+		SELECT r.*, u.username AS author_username, u.avatar_url AS author_avatar_url,
+			EXISTS(SELECT 1 FROM review_votes v WHERE v.rating_id = r.id AND v.user_id = $2) AS voted_helpful
+		FROM ratings r
+		JOIN users u ON u.id = r.user_id
+		WHERE r.manga_id = $1 AND r.review <> ''
+		ORDER BY `+order+` LIMIT $3 OFFSET $4`,
+		f.MangaID, f.ViewerID, p.Limit, p.Offset)
+	return rows, total, err
+}
+
+func (r *RatingRepo) AddVote(ctx context.Context, ratingID, userID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `
+		WITH v AS (
+			INSERT INTO review_votes (rating_id, user_id) VALUES ($1, $2)
+			ON CONFLICT DO NOTHING
+			RETURNING rating_id
+		)
+		UPDATE ratings SET helpful_count = helpful_count + 1 WHERE id IN (SELECT rating_id FROM v)`,
+		ratingID, userID)
+	return err
+}
+
+func (r *RatingRepo) RemoveVote(ctx context.Context, ratingID, userID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `
+		WITH v AS (
+			DELETE FROM review_votes WHERE rating_id = $1 AND user_id = $2
+			RETURNING rating_id
+		)
+		UPDATE ratings SET helpful_count = helpful_count - 1 WHERE id IN (SELECT rating_id FROM v)`,
+		ratingID, userID)
+	return err
+}
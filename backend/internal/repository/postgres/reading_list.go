@@ -0,0 +1,164 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/yumikokawaii/sherry-archive/internal/apperror"
+	"github.com/yumikokawaii/sherry-archive/internal/model"
+	"github.com/yumikokawaii/sherry-archive/pkg/pagination"
+)
+
+type ReadingListRepo struct{ db *sqlx.DB }
+
+func NewReadingListRepo(db *sqlx.DB) *ReadingListRepo { return &ReadingListRepo{db: db} }
+
+const readingListSelect = `
+	SELECT l.*, (SELECT COUNT(*) FROM reading_list_items i WHERE i.list_id = l.id) AS item_count
+	FROM reading_lists l`
+
+func (r *ReadingListRepo) Create(ctx context.Context, l *model.ReadingList) error {
+	const q = `
+		INSERT INTO reading_lists (id, user_id, name, slug, description, is_public, created_at, updated_at)
+		VALUES (:id, :user_id, :name, :slug, :description, :is_public, :created_at, :updated_at)`
+	_, err := r.db.NamedExecContext(ctx, q, l)
+	return err
+}
+
+func (r *ReadingListRepo) GetByID(ctx context.Context, id uuid.UUID) (*model.ReadingList, error) {
+	var l model.ReadingList
+	err := r.db.GetContext(ctx, &l, readingListSelect+` WHERE l.id = $1`, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperror.ErrNotFound
+	}
+	return &l, err
+}
+
+func (r *ReadingListRepo) GetBySlug(ctx context.Context, slug string) (*model.ReadingList, error) {
+	var l model.ReadingList
+	err := r.db.GetContext(ctx, &l, readingListSelect+` WHERE l.slug = $1`, slug)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperror.ErrNotFound
+	}
+	return &l, err
+}
+
+func (r *ReadingListRepo) ListByUser(ctx context.Context, userID uuid.UUID, publicOnly bool) ([]*model.ReadingList, error) {
+	var rows []*model.ReadingList
+	err := r.db.SelectContext(ctx, &rows,
+		readingListSelect+` WHERE l.user_id = $1 AND (l.is_public OR NOT $2) ORDER BY l.created_at, l.id`,
+		userID, publicOnly)
+	return rows, err
+}
+
+func (r *ReadingListRepo) ListContainingManga(ctx context.Context, mangaID, viewerID uuid.UUID, p pagination.Params) ([]*model.ReadingList, int, error) {
+	const where = `
+		WHERE EXISTS (SELECT 1 FROM reading_list_items i WHERE i.list_id = l.id AND i.manga_id = $1)
+		AND (l.is_public OR l.user_id = $2)`
+	var total int
+	if err := r.db.GetContext(ctx, &total, `SELECT COUNT(*) FROM reading_lists l`+where, mangaID, viewerID); err != nil {
+		return nil, 0, err
+	}
+	var rows []*model.ReadingList
+	err := r.db.SelectContext(ctx, &rows,
+		readingListSelect+where+` ORDER BY l.updated_at DESC, l.id DESC LIMIT $3 OFFSET $4`,
+		mangaID, viewerID, p.Limit, p.Offset)
+	return rows, total, err
+}
+
+func (r *ReadingListRepo) Update(ctx context.Context, l *model.ReadingList) error {
+	const q = `
+		UPDATE reading_lists SET name=:name, slug=:slug, description=:description, is_public=:is_public,
+		updated_at=:updated_at WHERE id=:id`
+	_, err := r.db.NamedExecContext(ctx, q, l)
+	return err
+}
+
+func (r *ReadingListRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM reading_lists WHERE id = $1`, id)
+	return err
+}
+
+func (r *ReadingListRepo) SlugExists(ctx context.Context, slug string) (bool, error) {
+	var exists bool
+	err := r.db.GetContext(ctx, &exists, `SELECT EXISTS(SELECT 1 FROM reading_lists WHERE slug = $1)`, slug)
+	return exists, err
+}
+
+func (r *ReadingListRepo) AddItem(ctx context.Context, listID, mangaID uuid.UUID) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Lock the list so concurrent appends don't take the same position.
+	res, err := tx.ExecContext(ctx,
+		`UPDATE reading_lists SET updated_at = $2 WHERE id = $1`, listID, time.Now())
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return apperror.ErrNotFound
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO reading_list_items (list_id, manga_id, position)
+		SELECT $1, $2, COALESCE(MAX(position), 0) + 1 FROM reading_list_items WHERE list_id = $1
+		ON CONFLICT (list_id, manga_id) DO NOTHING`, listID, mangaID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *ReadingListRepo) RemoveItem(ctx context.Context, listID, mangaID uuid.UUID) error {
+	res, err := r.db.ExecContext(ctx,
+		`DELETE FROM reading_list_items WHERE list_id = $1 AND manga_id = $2`, listID, mangaID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return apperror.ErrNotFound
+	}
+	_, err = r.db.ExecContext(ctx, `UPDATE reading_lists SET updated_at = $2 WHERE id = $1`, listID, time.Now())
+	return err
+}
+
+func (r *ReadingListRepo) ItemIDs(ctx context.Context, listID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.SelectContext(ctx, &ids,
+		`SELECT manga_id FROM reading_list_items WHERE list_id = $1 ORDER BY position, added_at`, listID)
+	return ids, err
+}
+
+func (r *ReadingListRepo) ReorderItems(ctx context.Context, listID uuid.UUID, mangaIDs []uuid.UUID) error {
+	strs := make([]string, len(mangaIDs))
+	for i, id := range mangaIDs {
+		strs[i] = id.String()
+	}
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE reading_list_items i SET position = o.n
+		FROM unnest($2::uuid[]) WITH ORDINALITY o(manga_id, n)
+		WHERE i.list_id = $1 AND i.manga_id = o.manga_id`, listID, pq.Array(strs))
+	return err
+}
+
+func (r *ReadingListRepo) ListItems(ctx context.Context, listID uuid.UUID, p pagination.Params) ([]*model.Manga, int, error) {
+	var total int
+	if err := r.db.GetContext(ctx, &total,
+		`SELECT COUNT(*) FROM reading_list_items WHERE list_id = $1`, listID); err != nil {
+		return nil, 0, err
+	}
+	var rows []*model.Manga
+	err := r.db.SelectContext(ctx, &rows, `
+		SELECT m.* FROM reading_list_items i
+		JOIN mangas m ON m.id = i.manga_id
+		WHERE i.list_id = $1
+		ORDER BY i.position, i.added_at
+		LIMIT $2 OFFSET $3`, listID, p.Limit, p.Offset)
+	return rows, total, err
+}
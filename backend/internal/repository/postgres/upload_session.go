@@ -0,0 +1,114 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/yumikokawaii/sherry-archive/internal/apperror"
+	"github.com/yumikokawaii/sherry-archive/internal/model"
+)
+
+type UploadSessionRepo struct{ db *sqlx.DB }
+
+func NewUploadSessionRepo(db *sqlx.DB) *UploadSessionRepo { return &UploadSessionRepo{db: db} }
+
+func (r *UploadSessionRepo) Create(ctx context.Context, s *model.UploadSession) error {
+	const q = `
+		INSERT INTO upload_sessions (id, owner_id, task_type, manga_id, chapter_id, apply_metadata, filename, size,
+			upload_offset, s3_key, s3_upload_id, parts, partial_size, direct, checksum, part_size, expires_at, created_at, updated_at)
+		VALUES (:id, :owner_id, :task_type, :manga_id, :chapter_id, :apply_metadata, :filename, :size,
+			:upload_offset, :s3_key, :s3_upload_id, :parts, :partial_size, :direct, :checksum, :part_size, :expires_at, :created_at, :updated_at)`
+	_, err := r.db.NamedExecContext(ctx, q, s)
+	return err
+}
+
+func (r *UploadSessionRepo) GetByID(ctx context.Context, id uuid.UUID) (*model.UploadSession, error) {
+	var s model.UploadSession
+	err := r.db.GetContext(ctx, &s, `SELECT * FROM upload_sessions WHERE id = $1`, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperror.ErrNotFound
+	}
+	return &s, err
+}
+
+func (r *UploadSessionRepo) Lock(ctx context.Context, id uuid.UUID, offset int64, until time.Time) (bool, error) {
+	now := time.Now()
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE upload_sessions SET locked_until = $1, updated_at = $2
+		WHERE id = $3 AND upload_offset = $4 AND task_id IS NULL AND (locked_until IS NULL OR locked_until < $2)`,
+		until, now, id, offset,
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (r *UploadSessionRepo) ExtendLock(ctx context.Context, id uuid.UUID, until time.Time) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE upload_sessions SET locked_until = $1, updated_at = $2 WHERE id = $3`,
+		until, time.Now(), id,
+	)
+	return err
+}
+
+func (r *UploadSessionRepo) Unlock(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE upload_sessions SET locked_until = NULL, updated_at = $1 WHERE id = $2`,
+		time.Now(), id,
+	)
+	return err
+}
+
+func (r *UploadSessionRepo) AddPart(ctx context.Context, id uuid.UUID, part model.UploadPart, offset int64) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE upload_sessions SET parts = parts || $1::jsonb, partial_size = 0, upload_offset = $2, updated_at = $3
+		WHERE id = $4`,
+		model.UploadParts{part}, offset, time.Now(), id,
+	)
+	return err
+}
+
+func (r *UploadSessionRepo) SavePartial(ctx context.Context, id uuid.UUID, partialSize, offset int64) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE upload_sessions SET partial_size = $1, upload_offset = $2, updated_at = $3 WHERE id = $4`,
+		partialSize, offset, time.Now(), id,
+	)
+	return err
+}
+
+func (r *UploadSessionRepo) MarkAssembled(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE upload_sessions SET s3_upload_id = '', partial_size = 0, updated_at = $1 WHERE id = $2`,
+		time.Now(), id,
+	)
+	return err
+}
+
+func (r *UploadSessionRepo) SetTask(ctx context.Context, id, taskID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE upload_sessions SET task_id = $1, updated_at = $2 WHERE id = $3`,
+		taskID, time.Now(), id,
+	)
+	return err
+}
+
+func (r *UploadSessionRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM upload_sessions WHERE id = $1`, id)
+	return err
+}
+
+func (r *UploadSessionRepo) ListExpired(ctx context.Context, before time.Time, limit int) ([]*model.UploadSession, error) {
+	var rows []*model.UploadSession
+	err := r.db.SelectContext(ctx, &rows, `
+		SELECT * FROM upload_sessions
+		WHERE expires_at < $1 AND (locked_until IS NULL OR locked_until < $1)
+		ORDER BY expires_at LIMIT $2`,
+		before, limit)
+	return rows, err
+}
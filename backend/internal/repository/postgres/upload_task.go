@@ -4,12 +4,17 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/yumikokawaii/sherry-archive/internal/apperror"
 	"github.com/yumikokawaii/sherry-archive/internal/model"
+	"github.com/yumikokawaii/sherry-archive/internal/repository"
+	"github.com/yumikokawaii/sherry-archive/pkg/pagination"
 )
 
 type UploadTaskRepo struct{ db *sqlx.DB }
@@ -18,8 +23,8 @@ func NewUploadTaskRepo(db *sqlx.DB) *UploadTaskRepo { return &UploadTaskRepo{db:
 
 func (r *UploadTaskRepo) Create(ctx context.Context, t *model.UploadTask) error {
 	const q = `
-		INSERT INTO upload_tasks (id, type, status, owner_id, manga_id, chapter_id, s3_key, error, created_at, updated_at)
-		VALUES (:id, :type, :status, :owner_id, :manga_id, :chapter_id, :s3_key, :error, :created_at, :updated_at)`
+		INSERT INTO upload_tasks (id, type, status, owner_id, manga_id, chapter_id, s3_key, error, apply_metadata, created_at, updated_at)
+		VALUES (:id, :type, :status, :owner_id, :manga_id, :chapter_id, :s3_key, :error, :apply_metadata, :created_at, :updated_at)`
 	_, err := r.db.NamedExecContext(ctx, q, t)
 	return err
 }
@@ -33,9 +38,37 @@ func (r *UploadTaskRepo) GetByID(ctx context.Context, id uuid.UUID) (*model.Uplo
 	return &t, err
 }
 
+func (r *UploadTaskRepo) ListByOwner(ctx context.Context, ownerID uuid.UUID, f repository.UploadTaskFilter, p pagination.Params) ([]*model.UploadTask, int, error) {
+	conds := []string{"owner_id = $1"}
+	args := []any{ownerID}
+	if f.Status != "" {
+		args = append(args, f.Status)
+		conds = append(conds, fmt.Sprintf("status = $%d", len(args)))
+	}
+	if f.Type != "" {
+		args = append(args, f.Type)
+		conds = append(conds, fmt.Sprintf("type = $%d", len(args)))
+	}
+	if f.MangaID != uuid.Nil {
+		args = append(args, f.MangaID)
+		conds = append(conds, fmt.Sprintf("manga_id = $%d", len(args)))
+	}
+	where := " WHERE " + strings.Join(conds, " AND ")
+
+	var total int
+	if err := r.db.GetContext(ctx, &total, `SELECT COUNT(*) FROM upload_tasks`+where, args...); err != nil {
+		return nil, 0, err
+	}
+	var rows []*model.UploadTask
+	err := r.db.SelectContext(ctx, &rows,
+		fmt.Sprintf(`SELECT * FROM upload_tasks%s ORDER BY created_at DESC, id DESC LIMIT $%d OFFSET $%d`, where, len(args)+1, len(args)+2),
+		append(args, p.Limit, p.Offset)...)
+	return rows, total, err
+}
+
 func (r *UploadTaskRepo) ClaimProcessing(ctx context.Context, id uuid.UUID) (bool, error) {
 	res, err := r.db.ExecContext(ctx,
-		`UPDATE upload_tasks SET status = $1, updated_at = $2 WHERE id = $3 AND status = $4`,
+		`UPDATE upload_tasks SET status = $1, attempts = attempts + 1, updated_at = $2 WHERE id = $3 AND status = $4`,
 		model.UploadTaskStatusProcessing, time.Now(), id, model.UploadTaskStatusPending,
 	)
 	if err != nil {
@@ -60,3 +93,103 @@ func (r *UploadTaskRepo) SetChapterAndDone(ctx context.Context, id uuid.UUID, ch
 	)
 	return err
 }
+
+func (r *UploadTaskRepo) SetResults(ctx context.Context, id uuid.UUID, results model.ChapterResults) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE upload_tasks SET results = $1, updated_at = $2 WHERE id = $3`,
+		results, time.Now(), id,
+	)
+	return err
+}
+
+func (r *UploadTaskRepo) SetMetadataReport(ctx context.Context, id uuid.UUID, report *model.MetadataReport) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE upload_tasks SET metadata_report = $1, updated_at = $2 WHERE id = $3`,
+		report, time.Now(), id,
+	)
+	return err
+}
+
+func (r *UploadTaskRepo) SetWarnings(ctx context.Context, id uuid.UUID, warnings []string) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE upload_tasks SET warnings = $1, updated_at = $2 WHERE id = $3`,
+		pq.StringArray(warnings), time.Now(), id,
+	)
+	return err
+}
+
+func (r *UploadTaskRepo) SetProgress(ctx context.Context, id uuid.UUID, processed, total int) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE upload_tasks SET processed = $1, total = $2, updated_at = $3 WHERE id = $4`,
+		processed, total, time.Now(), id,
+	)
+	return err
+}
+
+func (r *UploadTaskRepo) Touch(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE upload_tasks SET updated_at = $1 WHERE id = $2 AND status = $3`,
+		time.Now(), id, model.UploadTaskStatusProcessing,
+	)
+	return err
+}
+
+func (r *UploadTaskRepo) Cancel(ctx context.Context, id uuid.UUID) (bool, error) {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE upload_tasks SET status = $1, updated_at = $2 WHERE id = $3 AND status = $4`,
+		model.UploadTaskStatusCancelled, time.Now(), id, model.UploadTaskStatusPending,
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (r *UploadTaskRepo) FailPending(ctx context.Context, id uuid.UUID, errMsg string) (bool, error) {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE upload_tasks SET status = $1, error = $2, updated_at = $3 WHERE id = $4 AND status = $5`,
+		model.UploadTaskStatusFailed, errMsg, time.Now(), id, model.UploadTaskStatusPending,
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (r *UploadTaskRepo) Requeue(ctx context.Context, id uuid.UUID) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE upload_tasks SET status = $1, error = '',
+			results = COALESCE((SELECT jsonb_agg(r) FROM jsonb_array_elements(results) r WHERE r ? 'chapter_id'), '[]'),
+			warnings = '{}', metadata_report = NULL,
+			processed = 0, total = 0, attempts = 0, updated_at = $2
+		WHERE id = $3 AND status = $4`,
+		model.UploadTaskStatusPending, time.Now(), id, model.UploadTaskStatusFailed,
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (r *UploadTaskRepo) ListStuck(ctx context.Context, before time.Time, limit int) ([]*model.UploadTask, error) {
+	var rows []*model.UploadTask
+	err := r.db.SelectContext(ctx, &rows,
+		`SELECT * FROM upload_tasks WHERE status = $1 AND updated_at < $2 ORDER BY updated_at LIMIT $3`,
+		model.UploadTaskStatusProcessing, before, limit)
+	return rows, err
+}
+
+func (r *UploadTaskRepo) ReleaseStuck(ctx context.Context, id uuid.UUID, before time.Time, status model.UploadTaskStatus, errMsg string) (bool, error) {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE upload_tasks SET status = $1, error = $2, updated_at = $3 WHERE id = $4 AND status = $5 AND updated_at < $6`,
+		status, errMsg, time.Now(), id, model.UploadTaskStatusProcessing, before,
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
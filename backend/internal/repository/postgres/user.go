@@ -7,6 +7,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/yumikokawaii/sherry-archive/internal/apperror"
 	"github.com/yumikokawaii/sherry-archive/internal/model"
 )
@@ -50,10 +51,20 @@ func (r *UserRepo) GetByUsername(ctx context.Context, username string) (*model.U
 	return &u, err
 }
 
+func (r *UserRepo) ListByUsernames(ctx context.Context, usernames []string) ([]*model.User, error) {
+	var users []*model.User
+	if len(usernames) == 0 {
+		return users, nil
+	}
+	err := r.db.SelectContext(ctx, &users, `SELECT * FROM users WHERE username = ANY($1)`, pq.Array(usernames))
+	return users, err
+}
+
 func (r *UserRepo) Update(ctx context.Context, u *model.User) error {
 	const q = `
 		UPDATE users SET username=:username, email=:email, password_hash=:password_hash,
-		avatar_url=:avatar_url, bio=:bio, updated_at=:updated_at
+		avatar_url=:avatar_url, bio=:bio, preferred_language=:preferred_language, library_public=:library_public,
+		updated_at=:updated_at
 		WHERE id=:id`
 	_, err := r.db.NamedExecContext(ctx, q, u)
 	return err
@@ -0,0 +1,159 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/yumikokawaii/sherry-archive/internal/apperror"
+	"github.com/yumikokawaii/sherry-archive/internal/model"
+	"github.com/yumikokawaii/sherry-archive/pkg/pagination"
+)
+
+type WebhookRepo struct{ db *sqlx.DB }
+
+func NewWebhookRepo(db *sqlx.DB) *WebhookRepo { return &WebhookRepo{db: db} }
+
+func (r *WebhookRepo) Create(ctx context.Context, w *model.Webhook) error {
+	const q = `
+		INSERT INTO webhooks (id, user_id, url, secret, events, active, created_at, updated_at)
+		VALUES (:id, :user_id, :url, :secret, :events, :active, :created_at, :updated_at)`
+	_, err := r.db.NamedExecContext(ctx, q, w)
+	return err
+}
+
+func (r *WebhookRepo) GetByID(ctx context.Context, id uuid.UUID) (*model.Webhook, error) {
+	var w model.Webhook
+	err := r.db.GetContext(ctx, &w, `SELECT * FROM webhooks WHERE id = $1`, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperror.ErrNotFound
+	}
+	return &w, err
+}
+
+func (r *WebhookRepo) ListByUser(ctx context.Context, userID uuid.UUID) ([]*model.Webhook, error) {
+	var rows []*model.Webhook
+	err := r.db.SelectContext(ctx, &rows,
+		`SELECT * FROM webhooks WHERE user_id = $1 ORDER BY created_at, id`, userID)
+	return rows, err
+}
+
+func (r *WebhookRepo) CountByUser(ctx context.Context, userID uuid.UUID) (int, error) {
+	var n int
+	err := r.db.GetContext(ctx, &n, `SELECT COUNT(*) FROM webhooks WHERE user_id = $1`, userID)
+	return n, err
+}
+
+func (r *WebhookRepo) Update(ctx context.Context, w *model.Webhook) error {
+	const q = `
+		UPDATE webhooks SET url=:url, secret=:secret, events=:events, active=:active, updated_at=:updated_at
+		WHERE id=:id`
+	_, err := r.db.NamedExecContext(ctx, q, w)
+	return err
+}
+
+func (r *WebhookRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM webhooks WHERE id = $1`, id)
+	return err
+}
+
+func (r *WebhookRepo) ListSubscribed(ctx context.Context, event model.WebhookEvent, ownerID uuid.UUID) ([]*model.Webhook, error) {
+	var rows []*model.Webhook
+	err := r.db.SelectContext(ctx, &rows, `
+		SELECT * FROM webhooks
+		WHERE active AND events @> ARRAY[$1]::text[] AND ($2 = $3 OR user_id = $2)`,
+		string(event), ownerID, uuid.Nil)
+	return rows, err
+}
+
+func (r *WebhookRepo) CreateDeliveries(ctx context.Context, ds []*model.WebhookDelivery) error {
+	if len(ds) == 0 {
+		return nil
+	}
+	var ids, webhookIDs, eventIDs, events, payloads []string
+	var nextAttempts, created []time.Time
+	for _, d := range ds {
+		ids = append(ids, d.ID.String())
+		webhookIDs = append(webhookIDs, d.WebhookID.String())
+		eventIDs = append(eventIDs, d.EventID.String())
+		events = append(events, string(d.Event))
+		payloads = append(payloads, string(d.Payload))
+		nextAttempts = append(nextAttempts, d.NextAttemptAt)
+		created = append(created, d.CreatedAt)
+	}
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO webhook_deliveries (id, webhook_id, event_id, event, payload, status, next_attempt_at, created_at)
+		SELECT d.id::uuid, d.webhook_id::uuid, d.event_id::uuid, d.event, d.payload::jsonb, 'pending',
+			d.next_attempt_at, d.created_at
+		FROM unnest($1::text[], $2::text[], $3::text[], $4::text[], $5::text[], $6::timestamptz[], $7::timestamptz[])
+			AS d(id, webhook_id, event_id, event, payload, next_attempt_at, created_at)`,
+		pq.Array(ids), pq.Array(webhookIDs), pq.Array(eventIDs), pq.Array(events), pq.Array(payloads),
+		pq.Array(formatTimes(nextAttempts)), pq.Array(formatTimes(created)))
+	return err
+}
+
+// formatTimes renders times for a timestamptz[] parameter.
+func formatTimes(ts []time.Time) []string {
+	out := make([]string, len(ts))
+	for i, t := range ts {
+		out[i] = t.Format(time.RFC3339Nano)
+	}
+	return out
+}
+
+func (r *WebhookRepo) GetDelivery(ctx context.Context, id uuid.UUID) (*model.WebhookDelivery, error) {
+	var d model.WebhookDelivery
+	err := r.db.GetContext(ctx, &d, `SELECT * FROM webhook_deliveries WHERE id = $1`, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperror.ErrNotFound
+	}
+	return &d, err
+}
+
+func (r *WebhookRepo) ListDeliveries(ctx context.Context, webhookID uuid.UUID, p pagination.Params) ([]*model.WebhookDelivery, int, error) {
+	var total int
+	if err := r.db.GetContext(ctx, &total,
+		`SELECT COUNT(*) FROM webhook_deliveries WHERE webhook_id = $1`, webhookID); err != nil {
+		return nil, 0, err
+	}
+	var rows []*model.WebhookDelivery
+	err := r.db.SelectContext(ctx, &rows, `
+		SELECT * FROM webhook_deliveries WHERE webhook_id = $1
+		ORDER BY created_at DESC, id DESC LIMIT $2 OFFSET $3`, webhookID, p.Limit, p.Offset)
+	return rows, total, err
+}
+
+func (r *WebhookRepo) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*model.WebhookDeliveryJob, error) {
+	// As in NotificationJobRepo.Claim: SKIP LOCKED shares the queue between
+	// API instances and the lease keeps rows claimed after the commit.
+	now := time.Now()
+	var rows []*model.WebhookDeliveryJob
+	err := r.db.SelectContext(ctx, &rows, `
+		WITH due AS (
+			SELECT d.id FROM webhook_deliveries d
+			JOIN webhooks w ON w.id = d.webhook_id AND w.active
+			WHERE d.status = 'pending' AND d.next_attempt_at <= $1
+			ORDER BY d.next_attempt_at LIMIT $2
+			FOR UPDATE OF d SKIP LOCKED
+		), claimed AS (
+			UPDATE webhook_deliveries d SET attempts = d.attempts + 1, next_attempt_at = $3
+			FROM due WHERE d.id = due.id
+			RETURNING d.*
+		)
+		SELECT c.*, w.url, w.secret FROM claimed c JOIN webhooks w ON w.id = c.webhook_id`,
+		now, limit, now.Add(lease))
+	return rows, err
+}
+
+func (r *WebhookRepo) UpdateDelivery(ctx context.Context, d *model.WebhookDelivery) error {
+	const q = `
+		UPDATE webhook_deliveries SET status=:status, response_status=:response_status, last_error=:last_error,
+		next_attempt_at=:next_attempt_at, delivered_at=:delivered_at
+		WHERE id=:id`
+	_, err := r.db.NamedExecContext(ctx, q, d)
+	return err
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/yumikokawaii/sherry-archive/internal/apperror"
+	"github.com/yumikokawaii/sherry-archive/internal/model"
+)
+
+// Targets of a model.MetadataChange.
+const (
+	metadataTargetManga   = "manga"
+	metadataTargetChapter = "chapter"
+)
+
+// ApplyMetadata merges archive metadata into the manga and, when chapterID is
+// set, into that chapter. It is used by upload tasks created with apply_metadata.
+//
+// Manga author, artist and category are only filled when empty; a different
+// existing value is kept and reported as a conflict. Tags are added to the
+// existing ones. The chapter number and title are overwritten, unless the number
+// is already taken by another chapter in the same language.
+//
+// Failures are recorded as conflicts rather than returned, since the pages
+// themselves were imported successfully.
+func (s *PageService) ApplyMetadata(ctx context.Context, mangaID uuid.UUID, chapterID *uuid.UUID, meta *ZipMetadata) *model.MetadataReport {
+	report := &model.MetadataReport{Applied: []model.MetadataChange{}, Conflicts: []model.MetadataChange{}}
+	if meta == nil {
+		return report
+	}
+
+	manga, err := s.mangaRepo.GetByID(ctx, mangaID)
+	if err != nil {
+		report.Conflicts = append(report.Conflicts, model.MetadataChange{Target: metadataTargetManga, Field: "*", Reason: err.Error()})
+		return report
+	}
+	s.applyMangaMetadata(ctx, manga, meta, report)
+
+	if chapterID != nil {
+		s.applyChapterNumberAndTitle(ctx, manga, *chapterID, meta, report)
+	}
+	return report
+}
+
+func (s *PageService) applyMangaMetadata(ctx context.Context, m *model.Manga, meta *ZipMetadata, report *model.MetadataReport) {
+	var applied []model.MetadataChange
+	fill := func(field string, current *string, value string) {
+		value = strings.TrimSpace(value)
+		switch {
+		case value == "" || strings.EqualFold(*current, value):
+		case *current == "":
+			applied = append(applied, model.MetadataChange{Target: metadataTargetManga, Field: field, Old: "", New: value})
+			*current = value
+		default:
+			report.Conflicts = append(report.Conflicts, model.MetadataChange{
+				Target: metadataTargetManga, Field: field, Old: *current, New: value, Reason: "manga already has a different value",
+			})
+		}
+	}
+	fill("author", &m.Author, meta.Author)
+	fill("artist", &m.Artist, meta.Artist)
+	fill("category", &m.Category, meta.Category)
+
+	if tags := mergeTags(m.Tags, meta.Tags); len(tags) > len(m.Tags) {
+		applied = append(applied, model.MetadataChange{Target: metadataTargetManga, Field: "tags", Old: []string(m.Tags), New: tags})
+		m.Tags = pq.StringArray(tags)
+	}
+
+	if len(applied) == 0 {
+		return
+	}
+	m.UpdatedAt = time.Now()
+	if err := s.mangaRepo.Update(ctx, m); err != nil {
+		for _, c := range applied {
+			c.Reason = err.Error()
+			report.Conflicts = append(report.Conflicts, c)
+		}
+		return
+	}
+	report.Applied = append(report.Applied, applied...)
+	s.webhooks.PublishManga(ctx, model.WebhookMangaUpdated, m)
+}
+
+func (s *PageService) applyChapterNumberAndTitle(ctx context.Context, manga *model.Manga, chapterID uuid.UUID, meta *ZipMetadata, report *model.MetadataReport) {
+	ch, err := s.chapterRepo.GetByID(ctx, chapterID)
+	if err != nil {
+		report.Conflicts = append(report.Conflicts, model.MetadataChange{Target: metadataTargetChapter, Field: "*", Reason: err.Error()})
+		return
+	}
+
+	var applied []model.MetadataChange
+	if n := meta.ChapterNumber; n != nil && *n != ch.Number {
+		change := model.MetadataChange{Target: metadataTargetChapter, Field: "number", Old: ch.Number, New: *n}
+		switch existing, err := s.chapterRepo.GetByMangaAndNumber(ctx, ch.MangaID, *n, ch.Language); {
+		case manga.Type == model.TypeOneshot:
+			change.Reason = "oneshot chapters have no number"
+			report.Conflicts = append(report.Conflicts, change)
+		case err == nil && existing.ID != ch.ID:
+			change.Reason = "chapter number already exists"
+			report.Conflicts = append(report.Conflicts, change)
+		case err != nil && !errors.Is(err, apperror.ErrNotFound):
+			change.Reason = err.Error()
+			report.Conflicts = append(report.Conflicts, change)
+		default:
+			applied = append(applied, change)
+			ch.Number = *n
+		}
+	}
+	if title := strings.TrimSpace(meta.ChapterTitle); title != "" && title != ch.Title {
+		applied = append(applied, model.MetadataChange{Target: metadataTargetChapter, Field: "title", Old: ch.Title, New: title})
+		ch.Title = title
+	}
+
+	if len(applied) == 0 {
+		return
+	}
+	ch.UpdatedAt = time.Now()
+	if err := s.chapterRepo.Update(ctx, ch); err != nil {
+		// Most likely a concurrent upload claimed the number first.
+		for _, c := range applied {
+			c.Reason = err.Error()
+			report.Conflicts = append(report.Conflicts, c)
+		}
+		return
+	}
+	s.releaseCache.Invalidate(ctx)
+	report.Applied = append(report.Applied, applied...)
+}
+
+// mergeTags appends the tags not yet present (case-insensitively) to existing.
+func mergeTags(existing []string, add []string) []string {
+	seen := make(map[string]bool, len(existing)+len(add))
+	out := make([]string, 0, len(existing)+len(add))
+	for _, t := range existing {
+		seen[strings.ToLower(t)] = true
+		out = append(out, t)
+	}
+	for _, t := range add {
+		t = strings.TrimSpace(t)
+		if t == "" || seen[strings.ToLower(t)] {
+			continue
+		}
+		seen[strings.ToLower(t)] = true
+		out = append(out, t)
+	}
+	return out
+}
@@ -2,50 +2,230 @@ package service
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/yumikokawaii/sherry-archive/internal/apperror"
 	"github.com/yumikokawaii/sherry-archive/internal/model"
 	"github.com/yumikokawaii/sherry-archive/internal/repository"
 )
 
+// libraryDateLayout is the format of bookmark start and finish dates.
+const libraryDateLayout = "2006-01-02"
+
 type BookmarkService struct {
 	bookmarkRepo repository.BookmarkRepository
+	userRepo     repository.UserRepository
+	mangaRepo    repository.MangaRepository
+	chapterRepo  repository.ChapterRepository
+	readRepo     repository.ChapterReadRepository
 }
 
-func NewBookmarkService(bookmarkRepo repository.BookmarkRepository) *BookmarkService {
-	return &BookmarkService{bookmarkRepo: bookmarkRepo}
+func NewBookmarkService(
+	bookmarkRepo repository.BookmarkRepository,
+	userRepo repository.UserRepository,
+	mangaRepo repository.MangaRepository,
+	chapterRepo repository.ChapterRepository,
+	readRepo repository.ChapterReadRepository,
+) *BookmarkService {
+	return &BookmarkService{
+		bookmarkRepo: bookmarkRepo,
+		userRepo:     userRepo,
+		mangaRepo:    mangaRepo,
+		chapterRepo:  chapterRepo,
+		readRepo:     readRepo,
+	}
 }
 
+// UpsertBookmarkInput changes the fields that are set; nil leaves a field as is.
 type UpsertBookmarkInput struct {
-	ChapterID      uuid.UUID
-	LastPageNumber int
+	ChapterID      *uuid.UUID
+	LastPageNumber *int
+	Status         *model.BookmarkStatus
+	Score          *int    // 1–10; 0 clears it
+	StartedAt      *string // YYYY-MM-DD; "" clears it
+	FinishedAt     *string // YYYY-MM-DD; "" clears it
+	Notes          *string
 }
 
+// Upsert creates or updates the user's library entry for a manga. A chapter
+// position is also recorded in the chapter's read history. New entries start
+// as reading, or plan_to_read without a chapter; a planned entry moves to
+// reading once a chapter is set. Start and finish dates default to today when
+// the status becomes reading or completed.
 func (s *BookmarkService) Upsert(ctx context.Context, userID, mangaID uuid.UUID, in UpsertBookmarkInput) (*model.Bookmark, error) {
 	now := time.Now()
-	b := &model.Bookmark{
-		ID:             uuid.Must(uuid.NewV7()),
-		UserID:         userID,
-		MangaID:        mangaID,
-		ChapterID:      in.ChapterID,
-		LastPageNumber: in.LastPageNumber,
-		CreatedAt:      now,
-		UpdatedAt:      now,
+	b, err := s.bookmarkRepo.GetByUserAndManga(ctx, userID, mangaID)
+	switch {
+	case errors.Is(err, apperror.ErrNotFound):
+		if _, err := s.mangaRepo.GetByID(ctx, mangaID); err != nil {
+			return nil, err
+		}
+		b = &model.Bookmark{
+			ID:        uuid.Must(uuid.NewV7()),
+			UserID:    userID,
+			MangaID:   mangaID,
+			Status:    model.BookmarkPlanToRead,
+			CreatedAt: now,
+		}
+	case err != nil:
+		return nil, err
+	}
+	prevStatus := b.Status
+
+	var read *model.ChapterRead
+	if in.ChapterID != nil || in.LastPageNumber != nil {
+		chapterID := b.ChapterID
+		if in.ChapterID != nil {
+			chapterID = in.ChapterID
+		}
+		if chapterID == nil {
+			return nil, apperror.ErrBadRequest
+		}
+		ch, err := s.chapterRepo.GetByID(ctx, *chapterID)
+		if err != nil {
+			return nil, err
+		}
+		if ch.MangaID != mangaID {
+			return nil, apperror.ErrBadRequest
+		}
+		page := 1
+		if in.LastPageNumber != nil {
+			page = *in.LastPageNumber
+		} else if in.ChapterID == nil || (b.ChapterID != nil && *b.ChapterID == ch.ID) {
+			page = b.LastPageNumber
+		}
+		b.ChapterID, b.LastPageNumber = &ch.ID, page
+		if b.Status == model.BookmarkPlanToRead {
+			b.Status = model.BookmarkReading
+		}
+		read = readProgress(userID, ch, page, false, now)
+	}
+
+	if in.Status != nil {
+		if !in.Status.Valid() {
+			return nil, apperror.ErrBadRequest
+		}
+		b.Status = *in.Status
+	}
+	if in.Score != nil {
+		switch {
+		case *in.Score == 0:
+			b.Score = nil
+		case *in.Score >= 1 && *in.Score <= 10:
+			score := *in.Score
+			b.Score = &score
+		default:
+			return nil, apperror.ErrBadRequest
+		}
+	}
+	if b.StartedAt, err = applyLibraryDate(b.StartedAt, in.StartedAt); err != nil {
+		return nil, err
 	}
+	if b.FinishedAt, err = applyLibraryDate(b.FinishedAt, in.FinishedAt); err != nil {
+		return nil, err
+	}
+	if in.Notes != nil {
+		b.Notes = *in.Notes
+	}
+
+	today := now.Truncate(24 * time.Hour)
+	if b.Status != prevStatus {
+		if b.Status == model.BookmarkReading && b.StartedAt == nil {
+			b.StartedAt = &today
+		}
+		if b.Status == model.BookmarkCompleted && b.FinishedAt == nil {
+			b.FinishedAt = &today
+		}
+	}
+	if b.StartedAt != nil && b.FinishedAt != nil && b.FinishedAt.Before(*b.StartedAt) {
+		return nil, apperror.ErrBadRequest
+	}
+
+	b.UpdatedAt = now
 	if err := s.bookmarkRepo.Upsert(ctx, b); err != nil {
 		return nil, err
 	}
+	if read != nil {
+		if err := s.readRepo.Upsert(ctx, read); err != nil {
+			return nil, err
+		}
+	}
 	// Re-fetch to get the actual (potentially existing) record after upsert
 	return s.bookmarkRepo.GetByUserAndManga(ctx, userID, mangaID)
 }
 
+// applyLibraryDate returns cur changed by a YYYY-MM-DD input: nil keeps it,
+// "" clears it.
+func applyLibraryDate(cur *time.Time, in *string) (*time.Time, error) {
+	if in == nil {
+		return cur, nil
+	}
+	if *in == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(libraryDateLayout, *in)
+	if err != nil {
+		return nil, apperror.ErrBadRequest
+	}
+	return &t, nil
+}
+
 func (s *BookmarkService) Get(ctx context.Context, userID, mangaID uuid.UUID) (*model.Bookmark, error) {
 	return s.bookmarkRepo.GetByUserAndManga(ctx, userID, mangaID)
 }
 
-func (s *BookmarkService) List(ctx context.Context, userID uuid.UUID) ([]*model.Bookmark, error) {
-	return s.bookmarkRepo.ListByUser(ctx, userID)
+func (s *BookmarkService) List(ctx context.Context, userID uuid.UUID, f repository.BookmarkFilter) ([]*model.Bookmark, error) {
+	if f.Status != "" && !f.Status.Valid() {
+		return nil, apperror.ErrBadRequest
+	}
+	switch f.Sort {
+	case "", "updated", "added", "title", "score", "status":
+	default:
+		return nil, apperror.ErrBadRequest
+	}
+	return s.bookmarkRepo.ListByUser(ctx, userID, f)
+}
+
+// Library lists another user's library. It is forbidden unless the owner made
+// it public or is the viewer.
+func (s *BookmarkService) Library(ctx context.Context, ownerID, viewerID uuid.UUID, f repository.BookmarkFilter) ([]*model.Bookmark, error) {
+	owner, err := s.userRepo.GetByID(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	if !owner.LibraryPublic && owner.ID != viewerID {
+		return nil, apperror.ErrForbidden
+	}
+	return s.List(ctx, ownerID, f)
+}
+
+// Mangas returns the bookmarked manga by ID.
+func (s *BookmarkService) Mangas(ctx context.Context, bs []*model.Bookmark) (map[uuid.UUID]*model.Manga, error) {
+	ids := make([]uuid.UUID, len(bs))
+	for i, b := range bs {
+		ids[i] = b.MangaID
+	}
+	ms, err := s.mangaRepo.ListByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]*model.Manga, len(ms))
+	for _, m := range ms {
+		byID[m.ID] = m
+	}
+	return byID, nil
+}
+
+// UnreadCounts counts the published chapters of each manga the user hasn't
+// finished; manga with nothing unread are absent.
+func (s *BookmarkService) UnreadCounts(ctx context.Context, userID uuid.UUID, bs []*model.Bookmark) (map[uuid.UUID]int, error) {
+	ids := make([]uuid.UUID, len(bs))
+	for i, b := range bs {
+		ids[i] = b.MangaID
+	}
+	return s.readRepo.UnreadCounts(ctx, userID, ids)
 }
 
 func (s *BookmarkService) Delete(ctx context.Context, userID, mangaID uuid.UUID) error {
@@ -9,24 +9,46 @@ import (
 	"github.com/yumikokawaii/sherry-archive/internal/apperror"
 	"github.com/yumikokawaii/sherry-archive/internal/model"
 	"github.com/yumikokawaii/sherry-archive/internal/repository"
+	"github.com/yumikokawaii/sherry-archive/pkg/rediscache"
 )
 
 type ChapterService struct {
-	chapterRepo repository.ChapterRepository
-	mangaRepo   repository.MangaRepository
+	chapterRepo  repository.ChapterRepository
+	mangaRepo    repository.MangaRepository
+	userRepo     repository.UserRepository
+	pageRepo     repository.PageRepository
+	pageStore    *PageStore
+	readRepo     repository.ChapterReadRepository
+	releaseCache *rediscache.Cache
 }
 
+// releaseCache caches the latest releases feed (see ListLatest); nil disables caching.
 func NewChapterService(
 	chapterRepo repository.ChapterRepository,
 	mangaRepo repository.MangaRepository,
+	userRepo repository.UserRepository,
+	pageRepo repository.PageRepository,
+	pageStore *PageStore,
+	readRepo repository.ChapterReadRepository,
+	releaseCache *rediscache.Cache,
 ) *ChapterService {
-	return &ChapterService{chapterRepo: chapterRepo, mangaRepo: mangaRepo}
+	return &ChapterService{
+		chapterRepo:  chapterRepo,
+		mangaRepo:    mangaRepo,
+		userRepo:     userRepo,
+		pageRepo:     pageRepo,
+		pageStore:    pageStore,
+		readRepo:     readRepo,
+		releaseCache: releaseCache,
+	}
 }
 
 type CreateChapterInput struct {
-	MangaID uuid.UUID
-	Number  *float64
-	Title   string
+	MangaID  uuid.UUID
+	Number   *float64
+	Title    string
+	Volume   *int
+	Language string
 }
 
 func (s *ChapterService) Create(ctx context.Context, requesterID uuid.UUID, in CreateChapterInput) (*model.Chapter, error) {
@@ -38,18 +60,16 @@ func (s *ChapterService) Create(ctx context.Context, requesterID uuid.UUID, in C
 		return nil, apperror.ErrForbidden
 	}
 
+	language, ok := normalizeLanguage(in.Language)
+	if !ok {
+		return nil, apperror.ErrBadRequest
+	}
+
 	var number float64
 	title := in.Title
 
 	if manga.Type == model.TypeOneshot {
-		// Enforce max 1 chapter for oneshot
-		existing, err := s.chapterRepo.ListByManga(ctx, in.MangaID)
-		if err != nil {
-			return nil, err
-		}
-		if len(existing) > 0 {
-			return nil, apperror.ErrConflict
-		}
+		// Oneshots have a single chapter 0, once per language
 		number = 0
 		if title == "" {
 			title = "Oneshot"
@@ -59,10 +79,10 @@ func (s *ChapterService) Create(ctx context.Context, requesterID uuid.UUID, in C
 			return nil, apperror.ErrBadRequest
 		}
 		number = *in.Number
-		// Check duplicate chapter number
-		if existing, err := s.chapterRepo.GetByMangaAndNumber(ctx, in.MangaID, number); err == nil && existing != nil {
-			return nil, apperror.ErrConflict
-		}
+	}
+	// Check duplicate chapter number
+	if existing, err := s.chapterRepo.GetByMangaAndNumber(ctx, in.MangaID, number, language); err == nil && existing != nil {
+		return nil, apperror.ErrConflict
 	}
 
 	now := time.Now()
@@ -71,18 +91,23 @@ func (s *ChapterService) Create(ctx context.Context, requesterID uuid.UUID, in C
 		MangaID:   in.MangaID,
 		Number:    number,
 		Title:     title,
+		Volume:    in.Volume,
+		Language:  language,
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
 	if err := s.chapterRepo.Create(ctx, ch); err != nil {
 		return nil, err
 	}
+	s.releaseCache.Invalidate(ctx)
 	return ch, nil
 }
 
 type UpdateChapterInput struct {
-	Number *float64
-	Title  *string
+	Number   *float64
+	Title    *string
+	Volume   *int // 0 clears the volume
+	Language *string
 }
 
 func (s *ChapterService) Update(ctx context.Context, requesterID, chapterID uuid.UUID, in UpdateChapterInput) (*model.Chapter, error) {
@@ -98,20 +123,37 @@ func (s *ChapterService) Update(ctx context.Context, requesterID, chapterID uuid
 		return nil, apperror.ErrForbidden
 	}
 
-	if in.Number != nil && *in.Number != ch.Number {
-		if existing, err := s.chapterRepo.GetByMangaAndNumber(ctx, ch.MangaID, *in.Number); err == nil && existing != nil {
+	number, language := ch.Number, ch.Language
+	if in.Number != nil {
+		number = *in.Number
+	}
+	if in.Language != nil {
+		var ok bool
+		if language, ok = normalizeLanguage(*in.Language); !ok {
+			return nil, apperror.ErrBadRequest
+		}
+	}
+	if number != ch.Number || language != ch.Language {
+		if existing, err := s.chapterRepo.GetByMangaAndNumber(ctx, ch.MangaID, number, language); err == nil && existing != nil {
 			return nil, apperror.ErrConflict
 		}
-		ch.Number = *in.Number
+		ch.Number, ch.Language = number, language
 	}
 	if in.Title != nil {
 		ch.Title = *in.Title
 	}
+	if in.Volume != nil {
+		ch.Volume = in.Volume
+		if *in.Volume == 0 {
+			ch.Volume = nil
+		}
+	}
 	ch.UpdatedAt = time.Now()
 
 	if err := s.chapterRepo.Update(ctx, ch); err != nil {
 		return nil, err
 	}
+	s.releaseCache.Invalidate(ctx)
 	return ch, nil
 }
 
@@ -127,7 +169,16 @@ func (s *ChapterService) Delete(ctx context.Context, requesterID, chapterID uuid
 	if manga.OwnerID != requesterID {
 		return apperror.ErrForbidden
 	}
-	return s.chapterRepo.Delete(ctx, chapterID)
+	pages, err := s.pageRepo.GetByChapter(ctx, chapterID)
+	if err != nil {
+		return err
+	}
+	if err := s.chapterRepo.Delete(ctx, chapterID); err != nil {
+		return err
+	}
+	s.pageStore.ReleaseBestEffort(ctx, pages)
+	s.releaseCache.Invalidate(ctx)
+	return s.mangaRepo.RefreshLastChapterAt(ctx, ch.MangaID)
 }
 
 func (s *ChapterService) GetByID(ctx context.Context, id uuid.UUID) (*model.Chapter, error) {
@@ -136,10 +187,117 @@ func (s *ChapterService) GetByID(ctx context.Context, id uuid.UUID) (*model.Chap
 	return s.chapterRepo.GetByID(ctx, id)
 }
 
-func (s *ChapterService) ListByManga(ctx context.Context, mangaID uuid.UUID) ([]*model.Chapter, error) {
+// AllLanguages as ListChaptersInput.Language disables the language filter,
+// including the viewer's preferred language.
+const AllLanguages = "all"
+
+type ListChaptersInput struct {
+	Language string    // ?lang=; "" falls back to the viewer's preferred language
+	ViewerID uuid.UUID // uuid.Nil when anonymous
+}
+
+// ListByManga lists a manga's chapters, optionally in one language. Chapters
+// without a language are always included. When the language comes from the
+// viewer's preference and the manga has no chapter in it, all chapters are
+// returned rather than an unhelpful list of untagged ones.
+func (s *ChapterService) ListByManga(ctx context.Context, mangaID uuid.UUID, in ListChaptersInput) ([]*model.Chapter, error) {
 	// Verify manga exists
 	if _, err := s.mangaRepo.GetByID(ctx, mangaID); err != nil {
 		return nil, err
 	}
-	return s.chapterRepo.ListByManga(ctx, mangaID)
+	chapters, err := s.chapterRepo.ListByManga(ctx, mangaID)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.Language == AllLanguages {
+		return chapters, nil
+	}
+	language, ok := normalizeLanguage(in.Language)
+	if !ok {
+		return nil, apperror.ErrBadRequest
+	}
+	preferred := false
+	if language == "" && in.ViewerID != uuid.Nil {
+		if u, err := s.userRepo.GetByID(ctx, in.ViewerID); err == nil {
+			language, preferred = u.PreferredLanguage, true
+		}
+	}
+	if language == "" {
+		return chapters, nil
+	}
+
+	filtered := make([]*model.Chapter, 0, len(chapters))
+	found := false
+	for _, ch := range chapters {
+		switch ch.Language {
+		case language:
+			found = true
+			filtered = append(filtered, ch)
+		case "":
+			filtered = append(filtered, ch)
+		}
+	}
+	if preferred && !found {
+		return chapters, nil
+	}
+	return filtered, nil
+}
+
+// ChapterNeighbors are the chapters a reader moves to from the current one;
+// either is nil at the ends of the manga.
+type ChapterNeighbors struct {
+	Prev *model.Chapter
+	Next *model.Chapter
+}
+
+// Neighbors finds the previous and next published chapters (with pages) of ch
+// in ch's language; untagged chapters continue any language. For an untagged
+// chapter the viewer's preferred language is used, else the first language by
+// chapter order.
+func (s *ChapterService) Neighbors(ctx context.Context, ch *model.Chapter, viewerID uuid.UUID) (*ChapterNeighbors, error) {
+	ctx, sub := xray.BeginSubsegment(ctx, "chapter.Neighbors")
+	defer sub.Close(nil)
+
+	chapters, err := s.chapterRepo.ListByManga(ctx, ch.MangaID)
+	if err != nil {
+		return nil, err
+	}
+	language := ch.Language
+	if language == "" && viewerID != uuid.Nil {
+		if u, err := s.userRepo.GetByID(ctx, viewerID); err == nil {
+			language = u.PreferredLanguage
+		}
+	}
+	prev, next := chapterNeighbors(chapters, ch, language)
+	return &ChapterNeighbors{Prev: prev, Next: next}, nil
+}
+
+// chapterNeighbors picks from chapters (ordered by number, then language) the
+// closest published chapters before and after ch. Chapters sharing ch's number
+// are other editions of it, not neighbors. With an empty language, the first
+// chapter of each number wins.
+func chapterNeighbors(chapters []*model.Chapter, ch *model.Chapter, language string) (prev, next *model.Chapter) {
+	for _, c := range chapters {
+		if c.ID == ch.ID || c.PageCount == 0 || c.Number == ch.Number {
+			continue
+		}
+		if language != "" && c.Language != language && c.Language != "" {
+			continue
+		}
+		if c.Number < ch.Number {
+			if prev == nil || c.Number > prev.Number || preferLanguage(c, prev, language) {
+				prev = c
+			}
+		} else if next == nil || preferLanguage(c, next, language) {
+			next = c
+		}
+	}
+	return prev, next
+}
+
+// preferLanguage reports whether c should replace cur as the neighbor for the
+// same chapter number: a chapter in the wanted language beats an untagged one.
+func preferLanguage(c, cur *model.Chapter, language string) bool {
+	return c.Number == cur.Number && language != "" && c.Language == language && cur.Language != language
 }
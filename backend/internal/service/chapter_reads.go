@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yumikokawaii/sherry-archive/internal/apperror"
+	"github.com/yumikokawaii/sherry-archive/internal/model"
+)
+
+// readProgress is the progress of a user who reached page of ch. Reaching
+// the last page, or completed, finishes the chapter.
+func readProgress(userID uuid.UUID, ch *model.Chapter, page int, completed bool, now time.Time) *model.ChapterRead {
+	r := &model.ChapterRead{
+		UserID:         userID,
+		ChapterID:      ch.ID,
+		MangaID:        ch.MangaID,
+		LastPageNumber: page,
+		UpdatedAt:      now,
+	}
+	if ch.PageCount > 0 {
+		r.Progress = min(max(page*100/ch.PageCount, 0), 100)
+	}
+	if completed || (ch.PageCount > 0 && page >= ch.PageCount) {
+		r.Progress = 100
+		r.CompletedAt = &now
+	}
+	return r
+}
+
+type RecordReadInput struct {
+	PageNumber int  // last page reached
+	Completed  bool // mark finished regardless of PageNumber
+}
+
+// RecordRead records the user's progress through a chapter.
+func (s *ChapterService) RecordRead(ctx context.Context, userID, mangaID, chapterID uuid.UUID, in RecordReadInput) (*model.ChapterRead, error) {
+	ch, err := s.chapterRepo.GetByID(ctx, chapterID)
+	if err != nil {
+		return nil, err
+	}
+	if ch.MangaID != mangaID {
+		return nil, apperror.ErrNotFound
+	}
+	if in.PageNumber < 0 || (ch.PageCount > 0 && in.PageNumber > ch.PageCount) {
+		return nil, apperror.ErrBadRequest
+	}
+	r := readProgress(userID, ch, in.PageNumber, in.Completed, time.Now())
+	if err := s.readRepo.Upsert(ctx, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// UnmarkRead forgets the user's progress through a chapter.
+func (s *ChapterService) UnmarkRead(ctx context.Context, userID, mangaID, chapterID uuid.UUID) error {
+	ch, err := s.chapterRepo.GetByID(ctx, chapterID)
+	if err != nil {
+		return err
+	}
+	if ch.MangaID != mangaID {
+		return apperror.ErrNotFound
+	}
+	return s.readRepo.Delete(ctx, userID, chapterID)
+}
+
+type MarkReadUpToInput struct {
+	Number   float64 // chapters numbered up to and including this
+	Language string  // as ListChaptersInput.Language
+}
+
+// MarkReadUpTo marks the published chapters numbered up to in.Number read, among
+// the chapters ListByManga shows the user for in.Language. It returns how many
+// chapters were newly completed.
+func (s *ChapterService) MarkReadUpTo(ctx context.Context, userID, mangaID uuid.UUID, in MarkReadUpToInput) (int, error) {
+	chapters, err := s.ListByManga(ctx, mangaID, ListChaptersInput{Language: in.Language, ViewerID: userID})
+	if err != nil {
+		return 0, err
+	}
+	var ids []uuid.UUID
+	for _, ch := range chapters {
+		if ch.Number <= in.Number && ch.PageCount > 0 {
+			ids = append(ids, ch.ID)
+		}
+	}
+	return s.readRepo.MarkCompleted(ctx, userID, ids, time.Now())
+}
+
+// ReadStates returns the user's progress in a manga by chapter ID; unread
+// chapters are absent.
+func (s *ChapterService) ReadStates(ctx context.Context, userID, mangaID uuid.UUID) (map[uuid.UUID]*model.ChapterRead, error) {
+	reads, err := s.readRepo.ListByManga(ctx, userID, mangaID)
+	if err != nil {
+		return nil, err
+	}
+	states := make(map[uuid.UUID]*model.ChapterRead, len(reads))
+	for _, r := range reads {
+		states[r.ChapterID] = r
+	}
+	return states, nil
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"encoding/xml"
+	"strconv"
+	"strings"
+
+	"github.com/yumikokawaii/sherry-archive/internal/model"
+)
+
+// comicInfo is the ComicInfo.xml schema (Anansi Project v2.0) read by most CBZ
+// readers and library managers, as written into exports; only the fields we map
+// are declared. Uploads are parsed with the lenient comicInfoImport.
+type comicInfo struct {
+	XMLName     xml.Name        `xml:"ComicInfo"`
+	Title       string          `xml:"Title,omitempty"`
+	Series      string          `xml:"Series,omitempty"`
+	Number      string          `xml:"Number,omitempty"`
+	Count       int             `xml:"Count,omitempty"`
+	Volume      int             `xml:"Volume,omitempty"`
+	Summary     string          `xml:"Summary,omitempty"`
+	Writer      string          `xml:"Writer,omitempty"`
+	Penciller   string          `xml:"Penciller,omitempty"`
+	Genre       string          `xml:"Genre,omitempty"`
+	Tags        string          `xml:"Tags,omitempty"`
+	PageCount   int             `xml:"PageCount,omitempty"`
+	LanguageISO string          `xml:"LanguageISO,omitempty"`
+	Pages       []comicInfoPage `xml:"Pages>Page,omitempty"`
+}
+
+type comicInfoPage struct {
+	Image       int    `xml:"Image,attr"`
+	Type        string `xml:"Type,attr,omitempty"`
+	ImageWidth  int    `xml:"ImageWidth,attr,omitempty"`
+	ImageHeight int    `xml:"ImageHeight,attr,omitempty"`
+}
+
+// newComicInfo describes manga metadata. ch is nil for whole-manga exports.
+// The Manga element (reading direction) is left out: manga don't record one,
+// so readers apply their own default.
+func newComicInfo(m *model.Manga, ch *model.Chapter, chapterCount int) *comicInfo {
+	ci := &comicInfo{
+		Series:    m.Title,
+		Summary:   m.Description,
+		Writer:    m.Author,
+		Penciller: m.Artist,
+		Genre:     m.Category,
+		Tags:      strings.Join(m.Tags, ","),
+	}
+	if m.Type == model.TypeSeries && chapterCount > 0 {
+		ci.Count = chapterCount
+	}
+	if ch != nil {
+		ci.Title = ch.Title
+		ci.Number = formatChapterNumber(ch.Number)
+		ci.LanguageISO = ch.Language
+		if ch.Volume != nil {
+			ci.Volume = *ch.Volume
+		}
+	}
+	return ci
+}
+
+// addPage appends a page entry; the first page is marked as the front cover.
+func (ci *comicInfo) addPage(width, height int) {
+	p := comicInfoPage{Image: len(ci.Pages), ImageWidth: width, ImageHeight: height}
+	if p.Image == 0 {
+		p.Type = pageTypeFrontCover
+	}
+	ci.Pages = append(ci.Pages, p)
+	ci.PageCount = len(ci.Pages)
+}
+
+func (ci *comicInfo) marshal() ([]byte, error) {
+	out, err := xml.MarshalIndent(ci, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), out...), nil
+}
+
+// formatChapterNumber renders 12 as "12" and 12.5 as "12.5".
+func formatChapterNumber(n float64) string {
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,28 +13,36 @@ import (
 )
 
 type CommentService struct {
-	commentRepo repository.CommentRepository
-	mangaRepo   repository.MangaRepository
-	chapterRepo repository.ChapterRepository
+	commentRepo      repository.CommentRepository
+	mangaRepo        repository.MangaRepository
+	chapterRepo      repository.ChapterRepository
+	notificationJobs repository.NotificationJobRepository
 }
 
 func NewCommentService(
 	commentRepo repository.CommentRepository,
 	mangaRepo repository.MangaRepository,
 	chapterRepo repository.ChapterRepository,
+	notificationJobs repository.NotificationJobRepository,
 ) *CommentService {
-	return &CommentService{commentRepo: commentRepo, mangaRepo: mangaRepo, chapterRepo: chapterRepo}
+	return &CommentService{commentRepo: commentRepo, mangaRepo: mangaRepo, chapterRepo: chapterRepo, notificationJobs: notificationJobs}
 }
 
-func (s *CommentService) CreateMangaComment(ctx context.Context, userID, mangaID uuid.UUID, content string) (*model.CommentWithAuthor, error) {
+// CreateMangaComment posts a manga-level comment, optionally as a reply to
+// parentID. The reply and any @mentions are notified in the background.
+func (s *CommentService) CreateMangaComment(ctx context.Context, userID, mangaID uuid.UUID, content string, parentID *uuid.UUID) (*model.CommentWithAuthor, error) {
 	if _, err := s.mangaRepo.GetByID(ctx, mangaID); err != nil {
 		return nil, err
 	}
+	if err := s.checkParent(ctx, parentID, mangaID, nil); err != nil {
+		return nil, err
+	}
 
 	c := &model.Comment{
 		ID:        uuid.Must(uuid.NewV7()),
 		UserID:    userID,
 		MangaID:   mangaID,
+		ParentID:  parentID,
 		Content:   content,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
@@ -41,10 +50,12 @@ func (s *CommentService) CreateMangaComment(ctx context.Context, userID, mangaID
 	if err := s.commentRepo.Create(ctx, c); err != nil {
 		return nil, err
 	}
+	enqueueNotificationJob(ctx, s.notificationJobs, model.NotificationJobCommentCreated, c.ID)
 	return s.commentRepo.GetByID(ctx, c.ID)
 }
 
-func (s *CommentService) CreateChapterComment(ctx context.Context, userID, mangaID, chapterID uuid.UUID, content string) (*model.CommentWithAuthor, error) {
+// CreateChapterComment is CreateMangaComment for a chapter's thread.
+func (s *CommentService) CreateChapterComment(ctx context.Context, userID, mangaID, chapterID uuid.UUID, content string, parentID *uuid.UUID) (*model.CommentWithAuthor, error) {
 	ch, err := s.chapterRepo.GetByID(ctx, chapterID)
 	if err != nil {
 		return nil, err
@@ -52,12 +63,16 @@ func (s *CommentService) CreateChapterComment(ctx context.Context, userID, manga
 	if ch.MangaID != mangaID {
 		return nil, apperror.ErrNotFound
 	}
+	if err := s.checkParent(ctx, parentID, mangaID, &chapterID); err != nil {
+		return nil, err
+	}
 
 	c := &model.Comment{
 		ID:        uuid.Must(uuid.NewV7()),
 		UserID:    userID,
 		MangaID:   mangaID,
 		ChapterID: &chapterID,
+		ParentID:  parentID,
 		Content:   content,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
@@ -65,9 +80,31 @@ func (s *CommentService) CreateChapterComment(ctx context.Context, userID, manga
 	if err := s.commentRepo.Create(ctx, c); err != nil {
 		return nil, err
 	}
+	enqueueNotificationJob(ctx, s.notificationJobs, model.NotificationJobCommentCreated, c.ID)
 	return s.commentRepo.GetByID(ctx, c.ID)
 }
 
+// checkParent makes sure a reply answers a comment in the same thread: the
+// manga's own comments when chapterID is nil, else the chapter's.
+func (s *CommentService) checkParent(ctx context.Context, parentID *uuid.UUID, mangaID uuid.UUID, chapterID *uuid.UUID) error {
+	if parentID == nil {
+		return nil
+	}
+	parent, err := s.commentRepo.GetByID(ctx, *parentID)
+	if errors.Is(err, apperror.ErrNotFound) {
+		return apperror.ErrBadRequest
+	}
+	if err != nil {
+		return err
+	}
+	sameChapter := parent.ChapterID == nil && chapterID == nil ||
+		parent.ChapterID != nil && chapterID != nil && *parent.ChapterID == *chapterID
+	if parent.MangaID != mangaID || !sameChapter {
+		return apperror.ErrBadRequest
+	}
+	return nil
+}
+
 func (s *CommentService) Update(ctx context.Context, userID, commentID uuid.UUID, content string) (*model.CommentWithAuthor, error) {
 	c, err := s.commentRepo.GetByID(ctx, commentID)
 	if err != nil {
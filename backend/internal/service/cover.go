@@ -0,0 +1,176 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yumikokawaii/sherry-archive/internal/apperror"
+	"github.com/yumikokawaii/sherry-archive/internal/model"
+	"github.com/yumikokawaii/sherry-archive/internal/repository"
+	"github.com/yumikokawaii/sherry-archive/pkg/imageproc"
+	"github.com/yumikokawaii/sherry-archive/pkg/storage"
+	"go.uber.org/zap"
+)
+
+// coverKeyPrefix holds the cover images owned by their manga. Covers set before
+// cover variants existed may point at a page image instead, which is not deleted.
+const coverKeyPrefix = "covers/"
+
+// CoverService renders manga covers: the cropped source image is encoded to a
+// WebP variant per configured width (see imageproc.Processor.Cover), stored
+// under covers/<manga_id>/, and the previous cover is deleted.
+type CoverService struct {
+	mangaRepo   repository.MangaRepository
+	chapterRepo repository.ChapterRepository
+	pageRepo    repository.PageRepository
+	storage     *storage.Client
+	images      *imageproc.Processor
+	pageStore   *PageStore
+}
+
+func NewCoverService(
+	mangaRepo repository.MangaRepository,
+	chapterRepo repository.ChapterRepository,
+	pageRepo repository.PageRepository,
+	storage *storage.Client,
+	images *imageproc.Processor,
+	pageStore *PageStore,
+) *CoverService {
+	return &CoverService{
+		mangaRepo:   mangaRepo,
+		chapterRepo: chapterRepo,
+		pageRepo:    pageRepo,
+		storage:     storage,
+		images:      images,
+		pageStore:   pageStore,
+	}
+}
+
+// Upload sets the cover from an uploaded image, cropped to crop when set.
+func (s *CoverService) Upload(ctx context.Context, requesterID, mangaID uuid.UUID, src []byte, crop *imageproc.Crop) (*model.Manga, error) {
+	m, err := s.ownedManga(ctx, requesterID, mangaID)
+	if err != nil {
+		return nil, err
+	}
+	return m, s.replace(ctx, m, src, crop)
+}
+
+// FromPage sets the cover from a page of one of the manga's chapters, cropped
+// to crop when set.
+func (s *CoverService) FromPage(ctx context.Context, requesterID, mangaID, chapterID uuid.UUID, pageNumber int, crop *imageproc.Crop) (*model.Manga, error) {
+	m, err := s.ownedManga(ctx, requesterID, mangaID)
+	if err != nil {
+		return nil, err
+	}
+	ch, err := s.chapterRepo.GetByID(ctx, chapterID)
+	if err != nil {
+		return nil, err
+	}
+	if ch.MangaID != mangaID {
+		return nil, apperror.ErrNotFound
+	}
+	p, err := s.pageRepo.GetByChapterAndNumber(ctx, chapterID, pageNumber)
+	if err != nil {
+		return nil, err
+	}
+	src, err := s.readObject(ctx, p.ObjectKey)
+	if err != nil {
+		return nil, err
+	}
+	return m, s.replace(ctx, m, src, crop)
+}
+
+// SetDefault renders the cover from p when the manga has none yet (best-effort).
+func (s *CoverService) SetDefault(ctx context.Context, mangaID uuid.UUID, p *model.Page) {
+	m, err := s.mangaRepo.GetByID(ctx, mangaID)
+	if err != nil || m.CoverKey != "" {
+		return
+	}
+	src, err := s.readObject(ctx, p.ObjectKey)
+	if err == nil {
+		err = s.replace(ctx, m, src, nil)
+	}
+	if err != nil {
+		zap.L().Warn("cover: default from first page", zap.String("manga_id", mangaID.String()), zap.Error(err))
+	}
+}
+
+// DeleteObjects removes the stored cover of m (best-effort), once m is deleted
+// or has a new cover.
+func (s *CoverService) DeleteObjects(ctx context.Context, m *model.Manga) {
+	keys := []string{m.CoverKey}
+	for _, v := range m.CoverVariants {
+		keys = append(keys, v.Key)
+	}
+	for _, key := range keys {
+		switch {
+		case strings.HasPrefix(key, coverKeyPrefix):
+			_ = s.storage.DeleteObject(ctx, key)
+		case strings.HasPrefix(key, contentKeyPrefix):
+			// A default cover taken from a page before variants existed.
+			s.pageStore.ReleaseKey(ctx, key)
+		}
+	}
+}
+
+func (s *CoverService) ownedManga(ctx context.Context, requesterID, mangaID uuid.UUID) (*model.Manga, error) {
+	m, err := s.mangaRepo.GetByID(ctx, mangaID)
+	if err != nil {
+		return nil, err
+	}
+	if m.OwnerID != requesterID {
+		return nil, apperror.ErrForbidden
+	}
+	return m, nil
+}
+
+// replace renders and stores the variants of src, points m at them and deletes
+// the previous cover.
+func (s *CoverService) replace(ctx context.Context, m *model.Manga, src []byte, crop *imageproc.Crop) error {
+	rendered, err := s.images.Cover(ctx, src, crop)
+	switch {
+	case errors.Is(err, imageproc.ErrUnsupported):
+		return apperror.ErrInvalidMIME
+	case errors.Is(err, imageproc.ErrBadCrop):
+		return fmt.Errorf("%w: %v", apperror.ErrBadRequest, err)
+	case err != nil:
+		return err
+	}
+
+	old := *m
+	base := fmt.Sprintf("%s%s/%s", coverKeyPrefix, m.ID, uuid.Must(uuid.NewV7()))
+	variants := make(model.CoverVariants, len(rendered))
+	for i, v := range rendered {
+		variants[i] = model.CoverVariant{Width: v.Width, Key: fmt.Sprintf("%s_%d.webp", base, v.Width)}
+		if err := s.storage.PutObject(ctx, variants[i].Key, "image/webp", bytes.NewReader(v.Data), int64(len(v.Data))); err != nil {
+			s.DeleteObjects(ctx, &model.Manga{CoverVariants: variants[:i]})
+			return err
+		}
+	}
+
+	m.CoverKey = variants[len(variants)-1].Key
+	m.CoverVariants = variants
+	m.UpdatedAt = time.Now()
+	if err := s.mangaRepo.Update(ctx, m); err != nil {
+		s.DeleteObjects(ctx, &model.Manga{CoverVariants: variants})
+		*m = old
+		return err
+	}
+	s.DeleteObjects(ctx, &old)
+	return nil
+}
+
+func (s *CoverService) readObject(ctx context.Context, key string) ([]byte, error) {
+	body, err := s.storage.GetObject(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("get %s: %w", key, err)
+	}
+	defer body.Close()
+	return io.ReadAll(body)
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/yumikokawaii/sherry-archive/internal/apperror"
+	"github.com/yumikokawaii/sherry-archive/internal/model"
+	"github.com/yumikokawaii/sherry-archive/internal/repository"
+	"github.com/yumikokawaii/sherry-archive/pkg/imageproc"
+	"github.com/yumikokawaii/sherry-archive/pkg/phash"
+	"golang.org/x/sync/errgroup"
+)
+
+// DuplicatePolicy decides what happens when an imported chapter matches an
+// existing one. Percentages are of the imported chapter's pages; 0 disables.
+type DuplicatePolicy struct {
+	WarnPercent   int // add an upload warning
+	RejectPercent int // fail the import with ErrConflict
+	// MaxDistance is the Hamming distance up to which two pages of the same manga
+	// count as the same image. Across mangas hashes must be equal.
+	MaxDistance int
+}
+
+// chapterMatch is how many pages of an imported chapter match an existing chapter.
+type chapterMatch struct {
+	chapterID uuid.UUID
+	mangaID   uuid.UUID
+	matched   int
+}
+
+// fingerprintEntries hashes the archive images, bounded by the image workers.
+func (s *PageService) fingerprintEntries(ctx context.Context, entries []zipEntry) ([]imageproc.Fingerprint, error) {
+	fps := make([]imageproc.Fingerprint, len(entries))
+	eg, _ := errgroup.WithContext(ctx)
+	eg.SetLimit(s.images.Workers())
+	for i, e := range entries {
+		i, e := i, e
+		eg.Go(func() error {
+			rc, err := e.f.Open()
+			if err != nil {
+				return err
+			}
+			src, err := io.ReadAll(rc)
+			rc.Close()
+			if err != nil {
+				return err
+			}
+			fp, err := imageproc.Hash(src)
+			if err != nil {
+				return fmt.Errorf("%s: %w", e.f.Name, apperror.ErrInvalidMIME)
+			}
+			fps[i] = fp
+			return nil
+		})
+	}
+	return fps, eg.Wait()
+}
+
+// checkDuplicates compares an imported chapter with the library. chapterID is the
+// chapter being replaced (its own pages don't count), or uuid.Nil for a new one.
+// It returns ErrConflict at RejectPercent and a warning at WarnPercent.
+func (s *PageService) checkDuplicates(ctx context.Context, mangaID, chapterID uuid.UUID, fps []imageproc.Fingerprint) ([]string, error) {
+	if len(fps) == 0 || (s.duplicates.WarnPercent <= 0 && s.duplicates.RejectPercent <= 0) {
+		return nil, nil
+	}
+
+	hashes := make([]uint64, len(fps))
+	phashes := make([]int64, len(fps))
+	for i, fp := range fps {
+		hashes[i], phashes[i] = fp.PHash, int64(fp.PHash)
+	}
+	candidates, err := s.pageRepo.ListHashCandidates(ctx, mangaID, phashes)
+	if err != nil {
+		return nil, err
+	}
+	matches := matchChapters(hashes, candidates, mangaID, chapterID, s.duplicates.MaxDistance)
+	if len(matches) == 0 {
+		return nil, nil
+	}
+
+	best := matches[0]
+	percent := best.matched * 100 / len(fps)
+	describe := func() string {
+		return fmt.Sprintf("%d%% of the pages match chapter %s", percent, s.describeChapter(ctx, best.chapterID, mangaID))
+	}
+	switch {
+	case s.duplicates.RejectPercent > 0 && percent >= s.duplicates.RejectPercent:
+		return nil, fmt.Errorf("%w: duplicate chapter: %s", apperror.ErrConflict, describe())
+	case s.duplicates.WarnPercent > 0 && percent >= s.duplicates.WarnPercent:
+		return []string{"possible duplicate: " + describe()}, nil
+	}
+	return nil, nil
+}
+
+// describeChapter names a chapter for duplicate messages: its number, plus the
+// manga title when it belongs to another manga.
+func (s *PageService) describeChapter(ctx context.Context, chapterID, mangaID uuid.UUID) string {
+	ch, err := s.chapterRepo.GetByID(ctx, chapterID)
+	if err != nil {
+		return chapterID.String()
+	}
+	name := fmt.Sprintf("%g", ch.Number)
+	if ch.MangaID != mangaID {
+		if m, err := s.mangaRepo.GetByID(ctx, ch.MangaID); err == nil {
+			name += fmt.Sprintf(" of %q", m.Title)
+		}
+	}
+	return name
+}
+
+// matchChapters counts, per existing chapter, how many of hashes have a match in
+// it, best match first. Blank pages (hash 0) never match. Within mangaID hashes
+// match up to maxDistance; in other mangas they must be equal.
+func matchChapters(hashes []uint64, candidates []*model.PageHash, mangaID, excludeChapterID uuid.UUID, maxDistance int) []chapterMatch {
+	byChapter := make(map[uuid.UUID][]uint64)
+	mangas := make(map[uuid.UUID]uuid.UUID)
+	for _, c := range candidates {
+		if c.ChapterID == excludeChapterID {
+			continue
+		}
+		byChapter[c.ChapterID] = append(byChapter[c.ChapterID], uint64(c.PHash))
+		mangas[c.ChapterID] = c.MangaID
+	}
+
+	var matches []chapterMatch
+	for chapterID, existing := range byChapter {
+		distance := 0
+		if mangas[chapterID] == mangaID {
+			distance = maxDistance
+		}
+		m := chapterMatch{chapterID: chapterID, mangaID: mangas[chapterID]}
+		for _, h := range hashes {
+			if h == 0 {
+				continue
+			}
+			for _, e := range existing {
+				if phash.Distance(h, e) <= distance {
+					m.matched++
+					break
+				}
+			}
+		}
+		if m.matched > 0 {
+			matches = append(matches, m)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].matched != matches[j].matched {
+			return matches[i].matched > matches[j].matched
+		}
+		return matches[i].chapterID.String() < matches[j].chapterID.String()
+	})
+	return matches
+}
+
+// DuplicateService builds the admin report of near-duplicate chapters.
+type DuplicateService struct {
+	pageRepo repository.PageRepository
+	policy   DuplicatePolicy
+}
+
+func NewDuplicateService(pageRepo repository.PageRepository, policy DuplicatePolicy) *DuplicateService {
+	return &DuplicateService{pageRepo: pageRepo, policy: policy}
+}
+
+type DuplicateReportInput struct {
+	MangaID    *uuid.UUID // nil for the whole library
+	MinPercent int        // default: the policy's WarnPercent
+	Limit      int
+}
+
+// Report lists chapter pairs sharing at least MinPercent of the shorter chapter's pages.
+func (s *DuplicateService) Report(ctx context.Context, in DuplicateReportInput) ([]*model.DuplicateChapterPair, error) {
+	minPercent := in.MinPercent
+	if minPercent <= 0 {
+		minPercent = s.policy.WarnPercent
+	}
+	if minPercent > 100 {
+		return nil, apperror.ErrBadRequest
+	}
+	return s.pageRepo.ListDuplicateChapters(ctx, repository.DuplicateFilter{
+		MangaID:       in.MangaID,
+		MinSimilarity: float64(minPercent) / 100,
+		MaxDistance:   s.policy.MaxDistance,
+		Limit:         in.Limit,
+	})
+}
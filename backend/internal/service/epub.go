@@ -0,0 +1,205 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/yumikokawaii/sherry-archive/internal/model"
+)
+
+// Viewport used for pages whose dimensions are unknown (undecodable image).
+const (
+	epubDefaultWidth  = 800
+	epubDefaultHeight = 1200
+)
+
+const epubContainerXML = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`
+
+// epubPage is one image page already written to the archive.
+type epubPage struct {
+	id       string // "00001"
+	imageExt string
+	mime     string
+}
+
+// epubChapter marks where a chapter starts, for the table of contents.
+type epubChapter struct {
+	title     string
+	firstPage string
+}
+
+// writeMangaEPUB writes a fixed-layout EPUB 3 with one XHTML document per page,
+// each sized to its image, and a table of contents linking every chapter.
+func (s *ExportService) writeMangaEPUB(ctx context.Context, w io.Writer, manga *model.Manga, chapters []*model.Chapter) error {
+	zw := zip.NewWriter(w)
+
+	// The mimetype entry must come first and be stored uncompressed.
+	mw, err := zw.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(mw, epubContentType); err != nil {
+		return err
+	}
+	if err := writeZipString(zw, "META-INF/container.xml", epubContainerXML); err != nil {
+		return err
+	}
+
+	var pages []epubPage
+	var toc []epubChapter
+	for _, ch := range chapters {
+		chPages, err := s.pageRepo.GetByChapter(ctx, ch.ID)
+		if err != nil {
+			return err
+		}
+		for i, p := range chPages {
+			img, err := s.fetchPage(ctx, p)
+			if err != nil {
+				return err
+			}
+			page := epubPage{id: fmt.Sprintf("%05d", len(pages)+1), imageExt: img.ext, mime: img.mime}
+			if i == 0 {
+				toc = append(toc, epubChapter{title: epubChapterTitle(ch), firstPage: page.id})
+			}
+
+			fw, err := zw.CreateHeader(&zip.FileHeader{Name: "OEBPS/images/" + page.id + page.imageExt, Method: zip.Store})
+			if err != nil {
+				return err
+			}
+			if _, err := fw.Write(img.data); err != nil {
+				return err
+			}
+			width, height := img.width, img.height
+			if width == 0 || height == 0 {
+				width, height = epubDefaultWidth, epubDefaultHeight
+			}
+			if err := writeZipString(zw, "OEBPS/"+page.id+".xhtml", epubPageXHTML(manga.Title, page, width, height)); err != nil {
+				return err
+			}
+			pages = append(pages, page)
+		}
+	}
+
+	if err := writeZipString(zw, "OEBPS/nav.xhtml", epubNavXHTML(manga.Title, toc)); err != nil {
+		return err
+	}
+	if err := writeZipString(zw, "OEBPS/content.opf", epubPackageOPF(manga, pages)); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func epubChapterTitle(ch *model.Chapter) string {
+	title := "Chapter " + formatChapterNumber(ch.Number)
+	if ch.Title != "" {
+		title += ": " + ch.Title
+	}
+	if ch.Language != "" {
+		title += " [" + ch.Language + "]"
+	}
+	return title
+}
+
+func epubPageXHTML(title string, p epubPage, width, height int) string {
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops">
+<head>
+  <title>%s</title>
+  <meta name="viewport" content="width=%d, height=%d"/>
+  <style>html, body { margin: 0; padding: 0; } img { display: block; width: 100%%; height: 100%%; }</style>
+</head>
+<body>
+  <img src="images/%s%s" alt=""/>
+</body>
+</html>
+`, xmlEscape(title), width, height, p.id, p.imageExt)
+}
+
+func epubNavXHTML(title string, toc []epubChapter) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops">
+<head><title>%s</title></head>
+<body>
+  <nav epub:type="toc" id="toc">
+    <ol>
+`, xmlEscape(title))
+	for _, c := range toc {
+		fmt.Fprintf(&b, "      <li><a href=\"%s.xhtml\">%s</a></li>\n", c.firstPage, xmlEscape(c.title))
+	}
+	b.WriteString("    </ol>\n  </nav>\n</body>\n</html>\n")
+	return b.String()
+}
+
+func epubPackageOPF(m *model.Manga, pages []epubPage) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id" prefix="rendition: http://www.idpf.org/vocab/rendition/#">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+`)
+	fmt.Fprintf(&b, "    <dc:identifier id=\"book-id\">urn:uuid:%s</dc:identifier>\n", m.ID)
+	fmt.Fprintf(&b, "    <dc:title>%s</dc:title>\n", xmlEscape(m.Title))
+	b.WriteString("    <dc:language>und</dc:language>\n")
+	for _, creator := range []string{m.Author, m.Artist} {
+		if creator != "" {
+			fmt.Fprintf(&b, "    <dc:creator>%s</dc:creator>\n", xmlEscape(creator))
+		}
+	}
+	if m.Description != "" {
+		fmt.Fprintf(&b, "    <dc:description>%s</dc:description>\n", xmlEscape(m.Description))
+	}
+	for _, tag := range m.Tags {
+		fmt.Fprintf(&b, "    <dc:subject>%s</dc:subject>\n", xmlEscape(tag))
+	}
+	fmt.Fprintf(&b, "    <meta property=\"dcterms:modified\">%s</meta>\n", m.UpdatedAt.UTC().Format(time.RFC3339))
+	b.WriteString(`    <meta property="rendition:layout">pre-paginated</meta>
+    <meta property="rendition:spread">none</meta>
+    <meta name="cover" content="img-00001"/>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+`)
+	for i, p := range pages {
+		props := ""
+		if i == 0 {
+			props = ` properties="cover-image"`
+		}
+		fmt.Fprintf(&b, "    <item id=\"img-%s\" href=\"images/%s%s\" media-type=\"%s\"%s/>\n", p.id, p.id, p.imageExt, p.mime, props)
+		fmt.Fprintf(&b, "    <item id=\"page-%s\" href=\"%s.xhtml\" media-type=\"application/xhtml+xml\"/>\n", p.id, p.id)
+	}
+	// Manga reads right to left.
+	b.WriteString("  </manifest>\n  <spine page-progression-direction=\"rtl\">\n")
+	for _, p := range pages {
+		fmt.Fprintf(&b, "    <itemref idref=\"page-%s\"/>\n", p.id)
+	}
+	b.WriteString("  </spine>\n</package>\n")
+	return b.String()
+}
+
+func writeZipString(zw *zip.Writer, name, content string) error {
+	fw, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(fw, content)
+	return err
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,258 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/yumikokawaii/sherry-archive/internal/apperror"
+	"github.com/yumikokawaii/sherry-archive/internal/model"
+	"github.com/yumikokawaii/sherry-archive/internal/repository"
+	"github.com/yumikokawaii/sherry-archive/pkg/storage"
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	cbzContentType  = "application/vnd.comicbook+zip"
+	epubContentType = "application/epub+zip"
+)
+
+// imageExtensions maps sniffed content types to file extensions. Page object keys
+// always end in .webp, so the extension is taken from the bytes instead.
+var imageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+	"image/gif":  ".gif",
+}
+
+type ExportService struct {
+	pageRepo    repository.PageRepository
+	chapterRepo repository.ChapterRepository
+	mangaRepo   repository.MangaRepository
+	storage     *storage.Client
+}
+
+func NewExportService(
+	pageRepo repository.PageRepository,
+	chapterRepo repository.ChapterRepository,
+	mangaRepo repository.MangaRepository,
+	storage *storage.Client,
+) *ExportService {
+	return &ExportService{
+		pageRepo:    pageRepo,
+		chapterRepo: chapterRepo,
+		mangaRepo:   mangaRepo,
+		storage:     storage,
+	}
+}
+
+// ChapterExport is a chapter resolved and ready to be streamed as a CBZ.
+type ChapterExport struct {
+	Manga   *model.Manga
+	Chapter *model.Chapter
+	Pages   []*model.Page
+}
+
+// Filename is the suggested download name, e.g. "my-manga-ch012.5.cbz" or "my-manga-ch012.5-en.cbz".
+func (e *ChapterExport) Filename() string {
+	suffix := ""
+	if e.Chapter.Language != "" {
+		suffix = "-" + e.Chapter.Language
+	}
+	return fmt.Sprintf("%s-ch%s%s.cbz", e.Manga.Slug, padChapterNumber(e.Chapter.Number), suffix)
+}
+
+// GetChapterExport loads everything needed to export a chapter. Done before any
+// bytes are written so lookup errors can still be returned as JSON.
+func (s *ExportService) GetChapterExport(ctx context.Context, mangaID, chapterID uuid.UUID) (*ChapterExport, error) {
+	manga, err := s.mangaRepo.GetByID(ctx, mangaID)
+	if err != nil {
+		return nil, err
+	}
+	ch, err := s.chapterRepo.GetByID(ctx, chapterID)
+	if err != nil {
+		return nil, err
+	}
+	if ch.MangaID != mangaID {
+		return nil, apperror.ErrNotFound
+	}
+	pages, err := s.pageRepo.GetByChapter(ctx, chapterID)
+	if err != nil {
+		return nil, err
+	}
+	if len(pages) == 0 {
+		return nil, apperror.ErrNotFound
+	}
+	return &ChapterExport{Manga: manga, Chapter: ch, Pages: pages}, nil
+}
+
+// WriteChapterCBZ streams the chapter as a CBZ: pages 001.jpg, 002.jpg, … followed by ComicInfo.xml.
+func (s *ExportService) WriteChapterCBZ(ctx context.Context, w io.Writer, e *ChapterExport) error {
+	zw := zip.NewWriter(w)
+	info := newComicInfo(e.Manga, e.Chapter, 0)
+	if err := s.writeCBZPages(ctx, zw, "", e.Pages, info); err != nil {
+		return err
+	}
+	if err := writeComicInfo(zw, info); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// ExportManga writes the whole manga in the format of an export task type.
+// Returns the content type of the written archive.
+func (s *ExportService) ExportManga(ctx context.Context, taskType model.UploadTaskType, mangaID uuid.UUID, w io.Writer) (string, error) {
+	manga, err := s.mangaRepo.GetByID(ctx, mangaID)
+	if err != nil {
+		return "", err
+	}
+	chapters, err := s.publishedChapters(ctx, mangaID)
+	if err != nil {
+		return "", err
+	}
+	if len(chapters) == 0 {
+		return "", apperror.ErrNotFound
+	}
+
+	switch taskType {
+	case model.UploadTaskTypeExportCBZ:
+		return cbzContentType, s.writeMangaCBZ(ctx, w, manga, chapters)
+	case model.UploadTaskTypeExportEPUB:
+		return epubContentType, s.writeMangaEPUB(ctx, w, manga, chapters)
+	default:
+		return "", fmt.Errorf("unsupported export type: %s", taskType)
+	}
+}
+
+// writeMangaCBZ writes one CBZ with a folder per chapter and a series-level ComicInfo.xml.
+func (s *ExportService) writeMangaCBZ(ctx context.Context, w io.Writer, manga *model.Manga, chapters []*model.Chapter) error {
+	zw := zip.NewWriter(w)
+	info := newComicInfo(manga, nil, len(chapters))
+	for _, ch := range chapters {
+		pages, err := s.pageRepo.GetByChapter(ctx, ch.ID)
+		if err != nil {
+			return err
+		}
+		// Editions of the same chapter in different languages get their own folder.
+		dir := "Chapter " + padChapterNumber(ch.Number)
+		if ch.Language != "" {
+			dir += " [" + ch.Language + "]"
+		}
+		dir += "/"
+		if err := s.writeCBZPages(ctx, zw, dir, pages, info); err != nil {
+			return err
+		}
+	}
+	if err := writeComicInfo(zw, info); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func (s *ExportService) writeCBZPages(ctx context.Context, zw *zip.Writer, dir string, pages []*model.Page, info *comicInfo) error {
+	for _, p := range pages {
+		img, err := s.fetchPage(ctx, p)
+		if err != nil {
+			return err
+		}
+		// Images are already compressed; storing them keeps exports fast.
+		fw, err := zw.CreateHeader(&zip.FileHeader{
+			Name:   fmt.Sprintf("%s%03d%s", dir, p.Number, img.ext),
+			Method: zip.Store,
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := fw.Write(img.data); err != nil {
+			return err
+		}
+		info.addPage(img.width, img.height)
+	}
+	return nil
+}
+
+func writeComicInfo(zw *zip.Writer, info *comicInfo) error {
+	data, err := info.marshal()
+	if err != nil {
+		return err
+	}
+	fw, err := zw.Create("ComicInfo.xml")
+	if err != nil {
+		return err
+	}
+	_, err = fw.Write(data)
+	return err
+}
+
+// publishedChapters returns the manga's chapters that have pages, in reading order.
+func (s *ExportService) publishedChapters(ctx context.Context, mangaID uuid.UUID) ([]*model.Chapter, error) {
+	all, err := s.chapterRepo.ListByManga(ctx, mangaID)
+	if err != nil {
+		return nil, err
+	}
+	chapters := make([]*model.Chapter, 0, len(all))
+	for _, ch := range all {
+		if ch.PageCount > 0 {
+			chapters = append(chapters, ch)
+		}
+	}
+	return chapters, nil
+}
+
+// exportImage is a page image downloaded from storage.
+type exportImage struct {
+	data   []byte
+	mime   string
+	ext    string
+	width  int
+	height int
+}
+
+func (s *ExportService) fetchPage(ctx context.Context, p *model.Page) (*exportImage, error) {
+	body, err := s.storage.GetObject(ctx, p.ObjectKey)
+	if err != nil {
+		return nil, fmt.Errorf("get page %s: %w", p.ObjectKey, err)
+	}
+	defer body.Close()
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("read page %s: %w", p.ObjectKey, err)
+	}
+
+	img := &exportImage{data: data, width: p.Width, height: p.Height}
+	img.mime = http.DetectContentType(data)
+	ext, ok := imageExtensions[img.mime]
+	if !ok {
+		ext = strings.ToLower(path.Ext(p.ObjectKey))
+	}
+	img.ext = ext
+	if img.width == 0 || img.height == 0 {
+		if cfg, _, err := image.DecodeConfig(bytes.NewReader(data)); err == nil {
+			img.width, img.height = cfg.Width, cfg.Height
+		}
+	}
+	return img, nil
+}
+
+// padChapterNumber zero-pads the integer part so folders sort naturally: 7 → "007", 12.5 → "012.5".
+func padChapterNumber(n float64) string {
+	whole, frac, hasFrac := strings.Cut(formatChapterNumber(n), ".")
+	for len(whole) < 3 {
+		whole = "0" + whole
+	}
+	if hasFrac {
+		return whole + "." + frac
+	}
+	return whole
+}
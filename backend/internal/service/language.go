@@ -0,0 +1,19 @@
+package service
+
+import (
+	"regexp"
+	"strings"
+)
+
+// languagePattern accepts an ISO 639 code with optional subtags: "en", "vi", "pt-br", "zh-hant".
+var languagePattern = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})*$`)
+
+// normalizeLanguage lowercases a language tag and uses "-" as separator
+// ("EN" → "en", "pt_BR" → "pt-br"). "" is valid and means unspecified.
+func normalizeLanguage(code string) (string, bool) {
+	code = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "_", "-"))
+	if code == "" {
+		return "", true
+	}
+	return code, languagePattern.MatchString(code)
+}
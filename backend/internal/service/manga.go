@@ -14,12 +14,25 @@ import (
 	"github.com/yumikokawaii/sherry-archive/pkg/pagination"
 )
 
+// trendingSortDepth is how many top trending manga sort=trending ranks; the rest follow newest first.
+const trendingSortDepth = 500
+
+// TrendingRanker is implemented by analytics.Store.
+type TrendingRanker interface {
+	TrendingIDs(ctx context.Context, limit int) ([]uuid.UUID, error)
+}
+
 type MangaService struct {
 	mangaRepo repository.MangaRepository
+	pageRepo  repository.PageRepository
+	pageStore *PageStore
+	covers    *CoverService
+	trending  TrendingRanker
+	webhooks  *WebhookService
 }
 
-func NewMangaService(mangaRepo repository.MangaRepository) *MangaService {
-	return &MangaService{mangaRepo: mangaRepo}
+func NewMangaService(mangaRepo repository.MangaRepository, pageRepo repository.PageRepository, pageStore *PageStore, covers *CoverService, trending TrendingRanker, webhooks *WebhookService) *MangaService {
+	return &MangaService{mangaRepo: mangaRepo, pageRepo: pageRepo, pageStore: pageStore, covers: covers, trending: trending, webhooks: webhooks}
 }
 
 type CreateMangaInput struct {
@@ -59,6 +72,7 @@ func (s *MangaService) Create(ctx context.Context, in CreateMangaInput) (*model.
 	if err := s.mangaRepo.Create(ctx, m); err != nil {
 		return nil, err
 	}
+	s.webhooks.PublishManga(ctx, model.WebhookMangaCreated, m)
 	return m, nil
 }
 
@@ -116,6 +130,7 @@ func (s *MangaService) Update(ctx context.Context, requesterID, mangaID uuid.UUI
 	if err := s.mangaRepo.Update(ctx, m); err != nil {
 		return nil, err
 	}
+	s.webhooks.PublishManga(ctx, model.WebhookMangaUpdated, m)
 	return m, nil
 }
 
@@ -127,7 +142,18 @@ func (s *MangaService) Delete(ctx context.Context, requesterID, mangaID uuid.UUI
 	if m.OwnerID != requesterID {
 		return apperror.ErrForbidden
 	}
-	return s.mangaRepo.Delete(ctx, mangaID)
+	// Chapters and pages go with the manga; their images are released afterwards,
+	// keeping those other pages still use.
+	pages, err := s.pageRepo.ListByManga(ctx, mangaID)
+	if err != nil {
+		return err
+	}
+	if err := s.mangaRepo.Delete(ctx, mangaID); err != nil {
+		return err
+	}
+	s.pageStore.ReleaseBestEffort(ctx, pages)
+	s.covers.DeleteObjects(ctx, m)
+	return nil
 }
 
 func (s *MangaService) GetByID(ctx context.Context, id uuid.UUID) (*model.Manga, error) {
@@ -135,6 +161,13 @@ func (s *MangaService) GetByID(ctx context.Context, id uuid.UUID) (*model.Manga,
 }
 
 func (s *MangaService) List(ctx context.Context, filter repository.MangaFilter, p pagination.Params) ([]*model.Manga, int, error) {
+	if filter.Sort == "trending" && s.trending != nil {
+		ids, err := s.trending.TrendingIDs(ctx, trendingSortDepth)
+		if err != nil {
+			return nil, 0, err
+		}
+		filter.TrendingIDs = ids
+	}
 	return s.mangaRepo.List(ctx, filter, p)
 }
 
@@ -142,22 +175,6 @@ func (s *MangaService) ListByOwner(ctx context.Context, ownerID uuid.UUID, p pag
 	return s.mangaRepo.ListByOwner(ctx, ownerID, p)
 }
 
-func (s *MangaService) UpdateCover(ctx context.Context, requesterID, mangaID uuid.UUID, coverKey string) (*model.Manga, error) {
-	m, err := s.mangaRepo.GetByID(ctx, mangaID)
-	if err != nil {
-		return nil, err
-	}
-	if m.OwnerID != requesterID {
-		return nil, apperror.ErrForbidden
-	}
-	m.CoverKey = coverKey
-	m.UpdatedAt = time.Now()
-	if err := s.mangaRepo.Update(ctx, m); err != nil {
-		return nil, err
-	}
-	return m, nil
-}
-
 func (s *MangaService) uniqueSlug(ctx context.Context, title string) (string, error) {
 	base := pkgslug.Make(title)
 	slug := base
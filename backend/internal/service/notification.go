@@ -0,0 +1,296 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yumikokawaii/sherry-archive/internal/apperror"
+	"github.com/yumikokawaii/sherry-archive/internal/model"
+	"github.com/yumikokawaii/sherry-archive/internal/repository"
+	"github.com/yumikokawaii/sherry-archive/pkg/pagination"
+	"go.uber.org/zap"
+)
+
+const (
+	// notificationBatchSize bounds the jobs claimed per poll and the
+	// notifications inserted per statement.
+	notificationBatchSize = 100
+	// notificationJobLease is how long a claimed job stays invisible to other
+	// dispatchers; a worker that crashes mid-job loses it after this.
+	notificationJobLease = 5 * time.Minute
+	// maxNotificationJobAttempts is when a failing job is dropped.
+	maxNotificationJobAttempts = 5
+	// maxMentions caps the @mentions a comment notifies.
+	maxMentions = 10
+)
+
+type NotificationService struct {
+	notificationRepo repository.NotificationRepository
+	jobRepo          repository.NotificationJobRepository
+	userRepo         repository.UserRepository
+	mangaRepo        repository.MangaRepository
+	chapterRepo      repository.ChapterRepository
+	commentRepo      repository.CommentRepository
+	bookmarkRepo     repository.BookmarkRepository
+}
+
+func NewNotificationService(
+	notificationRepo repository.NotificationRepository,
+	jobRepo repository.NotificationJobRepository,
+	userRepo repository.UserRepository,
+	mangaRepo repository.MangaRepository,
+	chapterRepo repository.ChapterRepository,
+	commentRepo repository.CommentRepository,
+	bookmarkRepo repository.BookmarkRepository,
+) *NotificationService {
+	return &NotificationService{
+		notificationRepo: notificationRepo,
+		jobRepo:          jobRepo,
+		userRepo:         userRepo,
+		mangaRepo:        mangaRepo,
+		chapterRepo:      chapterRepo,
+		commentRepo:      commentRepo,
+		bookmarkRepo:     bookmarkRepo,
+	}
+}
+
+func (s *NotificationService) List(ctx context.Context, userID uuid.UUID, unreadOnly bool, p pagination.Params) ([]*model.NotificationWithContext, int, error) {
+	return s.notificationRepo.List(ctx, userID, unreadOnly, p)
+}
+
+func (s *NotificationService) UnreadCount(ctx context.Context, userID uuid.UUID) (int, error) {
+	return s.notificationRepo.CountUnread(ctx, userID)
+}
+
+func (s *NotificationService) MarkRead(ctx context.Context, userID, id uuid.UUID) error {
+	return s.notificationRepo.MarkRead(ctx, userID, id)
+}
+
+func (s *NotificationService) MarkAllRead(ctx context.Context, userID uuid.UUID) (int, error) {
+	return s.notificationRepo.MarkAllRead(ctx, userID)
+}
+
+func (s *NotificationService) Preferences(ctx context.Context, userID uuid.UUID) (map[model.NotificationType]bool, error) {
+	return s.notificationRepo.Preferences(ctx, userID)
+}
+
+// UpdatePreferences changes the given types and returns all preferences.
+func (s *NotificationService) UpdatePreferences(ctx context.Context, userID uuid.UUID, prefs map[model.NotificationType]bool) (map[model.NotificationType]bool, error) {
+	if err := s.notificationRepo.SetPreferences(ctx, userID, prefs); err != nil {
+		return nil, err
+	}
+	return s.notificationRepo.Preferences(ctx, userID)
+}
+
+// --- Fan-out ---
+
+// enqueueNotificationJob queues fan-out work for the dispatcher. Queuing is a
+// single insert, so callers never wait on fan-out; it is best-effort and a
+// failure is only logged.
+func enqueueNotificationJob(ctx context.Context, jobs repository.NotificationJobRepository, typ model.NotificationJobType, subjectID uuid.UUID) {
+	now := time.Now()
+	err := jobs.Enqueue(ctx, &model.NotificationJob{
+		ID:        uuid.Must(uuid.NewV7()),
+		Type:      typ,
+		SubjectID: subjectID,
+		RunAt:     now,
+		CreatedAt: now,
+	})
+	if err != nil {
+		zap.L().Warn("notifications: enqueue", zap.String("type", string(typ)),
+			zap.String("subject_id", subjectID.String()), zap.Error(err))
+	}
+}
+
+// StartDispatcher polls for due fan-out jobs until ctx is cancelled.
+// Call it as a goroutine from serve/server.go.
+func (s *NotificationService) StartDispatcher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		// Drain full batches right away; wait for the ticker otherwise.
+		for s.dispatch(ctx) == notificationBatchSize {
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// dispatch runs one batch of due jobs and returns how many it claimed.
+func (s *NotificationService) dispatch(ctx context.Context) int {
+	jobs, err := s.jobRepo.Claim(ctx, notificationBatchSize, notificationJobLease)
+	if err != nil {
+		if ctx.Err() == nil {
+			zap.L().Warn("notifications: claim jobs", zap.Error(err))
+		}
+		return 0
+	}
+	for _, j := range jobs {
+		s.runJob(ctx, j)
+	}
+	return len(jobs)
+}
+
+func (s *NotificationService) runJob(ctx context.Context, j *model.NotificationJob) {
+	var (
+		sent int
+		err  error
+	)
+	switch j.Type {
+	case model.NotificationJobChapterPublished:
+		sent, err = s.fanOutChapter(ctx, j.SubjectID)
+	case model.NotificationJobCommentCreated:
+		sent, err = s.fanOutComment(ctx, j.SubjectID)
+	}
+	// The subject was deleted before the job ran; there is nobody to tell.
+	if errors.Is(err, apperror.ErrNotFound) {
+		err = nil
+	}
+	log := zap.L().With(zap.String("job_id", j.ID.String()), zap.String("type", string(j.Type)),
+		zap.String("subject_id", j.SubjectID.String()))
+
+	if err == nil || j.Attempts >= maxNotificationJobAttempts {
+		if err != nil {
+			log.Error("notifications: job dropped", zap.Int("attempts", j.Attempts), zap.Error(err))
+		} else if sent > 0 {
+			log.Info("notifications: sent", zap.Int("count", sent))
+		}
+		if err := s.jobRepo.Delete(ctx, j.ID); err != nil {
+			log.Warn("notifications: delete job", zap.Error(err))
+		}
+		return
+	}
+	// Back off 30s, 2m, 4.5m, 8m between attempts.
+	retryAt := time.Now().Add(time.Duration(j.Attempts*j.Attempts) * 30 * time.Second)
+	log.Warn("notifications: job failed", zap.Int("attempts", j.Attempts), zap.Time("retry_at", retryAt), zap.Error(err))
+	if err := s.jobRepo.Retry(ctx, j.ID, err.Error(), retryAt); err != nil {
+		log.Warn("notifications: reschedule job", zap.Error(err))
+	}
+}
+
+// fanOutChapter notifies the manga's bookmarkers who read the chapter's
+// language. Chapters without pages aren't published yet and are skipped.
+func (s *NotificationService) fanOutChapter(ctx context.Context, chapterID uuid.UUID) (int, error) {
+	ch, err := s.chapterRepo.GetByID(ctx, chapterID)
+	if err != nil {
+		return 0, err
+	}
+	if ch.PageCount == 0 {
+		return 0, nil
+	}
+	manga, err := s.mangaRepo.GetByID(ctx, ch.MangaID)
+	if err != nil {
+		return 0, err
+	}
+	readers, err := s.bookmarkRepo.ListReaderIDs(ctx, ch.MangaID, ch.Language)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	var ns []*model.Notification
+	for _, userID := range readers {
+		if userID == manga.OwnerID {
+			continue
+		}
+		ns = append(ns, &model.Notification{
+			ID:        uuid.Must(uuid.NewV7()),
+			UserID:    userID,
+			Type:      model.NotificationNewChapter,
+			ActorID:   &manga.OwnerID,
+			MangaID:   ch.MangaID,
+			ChapterID: &ch.ID,
+			CreatedAt: now,
+		})
+	}
+	return s.createNotifications(ctx, ns)
+}
+
+// fanOutComment notifies the author of the comment replied to and the users
+// @mentioned. A user who is both only gets the reply.
+func (s *NotificationService) fanOutComment(ctx context.Context, commentID uuid.UUID) (int, error) {
+	c, err := s.commentRepo.GetByID(ctx, commentID)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	notified := map[uuid.UUID]bool{c.UserID: true}
+	var ns []*model.Notification
+	add := func(userID uuid.UUID, typ model.NotificationType) {
+		if notified[userID] {
+			return
+		}
+		notified[userID] = true
+		ns = append(ns, &model.Notification{
+			ID:        uuid.Must(uuid.NewV7()),
+			UserID:    userID,
+			Type:      typ,
+			ActorID:   &c.UserID,
+			MangaID:   c.MangaID,
+			ChapterID: c.ChapterID,
+			CommentID: &c.ID,
+			CreatedAt: now,
+		})
+	}
+
+	if c.ParentID != nil {
+		parent, err := s.commentRepo.GetByID(ctx, *c.ParentID)
+		switch {
+		case err == nil:
+			add(parent.UserID, model.NotificationCommentReply)
+		case !errors.Is(err, apperror.ErrNotFound):
+			return 0, err
+		}
+	}
+	if names := parseMentions(c.Content); len(names) > 0 {
+		users, err := s.userRepo.ListByUsernames(ctx, names)
+		if err != nil {
+			return 0, err
+		}
+		for _, u := range users {
+			add(u.ID, model.NotificationCommentMention)
+		}
+	}
+	return s.createNotifications(ctx, ns)
+}
+
+func (s *NotificationService) createNotifications(ctx context.Context, ns []*model.Notification) (int, error) {
+	sent := 0
+	for start := 0; start < len(ns); start += notificationBatchSize {
+		n, err := s.notificationRepo.CreateMany(ctx, ns[start:min(start+notificationBatchSize, len(ns))])
+		sent += n
+		if err != nil {
+			return sent, err
+		}
+	}
+	return sent, nil
+}
+
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@([\p{L}\p{N}_.\-]{3,30})`)
+
+// parseMentions returns the distinct usernames @mentioned in a comment, up to
+// maxMentions. Trailing dots and dashes are punctuation, not part of the name.
+func parseMentions(content string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, m := range mentionPattern.FindAllStringSubmatch(content, -1) {
+		name := strings.TrimRight(m[1], ".-")
+		if len(name) < 3 || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+		if len(names) == maxMentions {
+			break
+		}
+	}
+	return names
+}
@@ -1,14 +1,12 @@
 package service
 
 import (
-	"archive/zip"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
-	"path/filepath"
-	"sort"
-	"strings"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-xray-sdk-go/xray"
@@ -16,15 +14,33 @@ import (
 	"github.com/yumikokawaii/sherry-archive/internal/apperror"
 	"github.com/yumikokawaii/sherry-archive/internal/model"
 	"github.com/yumikokawaii/sherry-archive/internal/repository"
-	"github.com/yumikokawaii/sherry-archive/pkg/storage"
+	"github.com/yumikokawaii/sherry-archive/pkg/archive"
+	"github.com/yumikokawaii/sherry-archive/pkg/imageproc"
+	"github.com/yumikokawaii/sherry-archive/pkg/rediscache"
 	"github.com/yumikokawaii/sherry-archive/pkg/urlcache"
 	"golang.org/x/sync/errgroup"
 )
 
+// ZipUploadResult is the outcome of UploadZip. Warnings list skipped files and
+// ordering problems found in the archive (see imageEntries).
+type ZipUploadResult struct {
+	Pages    []*model.Page
+	Meta     *ZipMetadata
+	Warnings []string
+}
+
+// PageURLs are the signed URLs of one page. ThumbnailURL is empty for pages
+// stored before thumbnails existed.
+type PageURLs struct {
+	URL          string
+	ThumbnailURL string
+}
+
 type OneshotUploadResult struct {
-	Chapter *model.Chapter
-	Pages   []*model.Page
-	Meta    *ZipMetadata
+	Chapter  *model.Chapter
+	Pages    []*model.Page
+	Meta     *ZipMetadata
+	Warnings []string
 }
 
 var allowedExtensions = map[string]string{
@@ -35,26 +51,45 @@ var allowedExtensions = map[string]string{
 }
 
 type PageService struct {
-	pageRepo    repository.PageRepository
-	chapterRepo repository.ChapterRepository
-	mangaRepo   repository.MangaRepository
-	storage     *storage.Client
-	urlCache    *urlcache.URLCache
+	pageRepo     repository.PageRepository
+	chapterRepo  repository.ChapterRepository
+	mangaRepo    repository.MangaRepository
+	store        *PageStore
+	covers       *CoverService
+	urlCache     *urlcache.URLCache
+	releaseCache *rediscache.Cache
+	images       *imageproc.Processor
+	duplicates   DuplicatePolicy
+	// notificationJobs queues new chapter notifications.
+	notificationJobs repository.NotificationJobRepository
+	webhooks         *WebhookService
 }
 
 func NewPageService(
 	pageRepo repository.PageRepository,
 	chapterRepo repository.ChapterRepository,
 	mangaRepo repository.MangaRepository,
-	storage *storage.Client,
+	store *PageStore,
+	covers *CoverService,
 	urlCache *urlcache.URLCache,
+	releaseCache *rediscache.Cache,
+	images *imageproc.Processor,
+	duplicates DuplicatePolicy,
+	notificationJobs repository.NotificationJobRepository,
+	webhooks *WebhookService,
 ) *PageService {
 	return &PageService{
-		pageRepo:    pageRepo,
-		chapterRepo: chapterRepo,
-		mangaRepo:   mangaRepo,
-		storage:     storage,
-		urlCache:    urlCache,
+		pageRepo:         pageRepo,
+		chapterRepo:      chapterRepo,
+		mangaRepo:        mangaRepo,
+		store:            store,
+		covers:           covers,
+		urlCache:         urlCache,
+		releaseCache:     releaseCache,
+		images:           images,
+		duplicates:       duplicates,
+		notificationJobs: notificationJobs,
+		webhooks:         webhooks,
 	}
 }
 
@@ -82,116 +117,132 @@ func (s *PageService) UploadPages(ctx context.Context, requesterID, mangaID, cha
 			ID:        uuid.Must(uuid.NewV7()),
 			ChapterID: chapterID,
 			Number:    existing + i + 1,
-			ObjectKey: pageObjectKey(mangaID, chapterID),
 			CreatedAt: time.Now(),
 		}
 	}
 
-	return s.uploadAndPersist(ctx, chapterID, pages, files)
+	return s.uploadAndPersist(ctx, mangaID, chapterID, pages, files)
 }
 
-// UploadZip replaces all pages of a chapter from a zip archive.
-// Files inside the zip are sorted by filename to determine page order,
-// so naming them 001.jpg, 002.jpg, … gives a deterministic result.
-// An optional metadata.json at the ZIP root is parsed and returned as suggestions.
-func (s *PageService) UploadZip(ctx context.Context, requesterID, mangaID, chapterID uuid.UUID, r io.ReaderAt, size int64) ([]*model.Page, *ZipMetadata, error) {
+// ProgressFunc is told how many of an import's total pages are stored so far.
+// It is called once before the first page is stored and after every page, one
+// call at a time and with processed never decreasing.
+type ProgressFunc func(processed, total int)
+
+func (f ProgressFunc) report(processed, total int) {
+	if f != nil {
+		f(processed, total)
+	}
+}
+
+// UploadZip replaces all pages of a chapter from an archive: zip/cbz, rar/cbr,
+// 7z, tar(.gz) or a PDF whose pages are rasterized (format sniffed by pkg/archive).
+// Images are ordered by natural sort of their full path (2.jpg before 10.jpg,
+// folder by folder).
+// Optional metadata.json / ComicInfo.xml at the archive root are parsed and returned as
+// suggestions; a metadata.json "pages" manifest or ComicInfo page types adjust the
+// order (see orderEntries), and a ComicInfo FrontCover becomes the default manga cover.
+// progress (may be nil) follows the pages being stored.
+func (s *PageService) UploadZip(ctx context.Context, requesterID, mangaID, chapterID uuid.UUID, r io.ReaderAt, size int64, progress ProgressFunc) (*ZipUploadResult, error) {
 	if err := s.checkOwnership(ctx, requesterID, mangaID, chapterID); err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
-	zr, err := zip.NewReader(r, size)
+	a, err := archive.Open(ctx, r, size)
 	if err != nil {
-		return nil, nil, apperror.ErrBadRequest
+		return nil, apperror.ErrBadRequest
 	}
+	defer a.Close()
 
-	// Extract optional metadata (best-effort, errors ignored)
-	meta, _ := extractZipMetadata(r, size)
+	// Optional metadata (best-effort — malformed files are ignored)
+	meta := metadataInDir(a.Files, ".")
 
-	// Collect valid image entries, sort by filename
-	type zipEntry struct {
-		f    *zip.File
-		mime string
+	pages, warnings, err := s.replacePages(ctx, mangaID, chapterID, a.Files, meta, progress)
+	if err != nil {
+		return nil, err
 	}
-	var entries []zipEntry
-	for _, f := range zr.File {
-		if f.FileInfo().IsDir() {
-			continue
-		}
-		ext := strings.ToLower(filepath.Ext(f.Name))
-		mime, ok := allowedExtensions[ext]
-		if !ok {
-			continue
+	s.applyChapterMetadata(ctx, chapterID, meta)
+	return &ZipUploadResult{Pages: pages, Meta: meta, Warnings: warnings}, nil
+}
+
+// applyChapterMetadata fills the chapter's language and volume from the archive
+// metadata when they are not set yet (best-effort). The language is skipped if
+// the manga already has this chapter number in that language.
+func (s *PageService) applyChapterMetadata(ctx context.Context, chapterID uuid.UUID, meta *ZipMetadata) {
+	if meta == nil {
+		return
+	}
+	ch, err := s.chapterRepo.GetByID(ctx, chapterID)
+	if err != nil {
+		return
+	}
+	changed := false
+	if language := meta.chapterLanguage(); language != "" && ch.Language == "" {
+		if _, err := s.chapterRepo.GetByMangaAndNumber(ctx, ch.MangaID, ch.Number, language); errors.Is(err, apperror.ErrNotFound) {
+			ch.Language = language
+			changed = true
 		}
-		entries = append(entries, zipEntry{f: f, mime: mime})
 	}
-	if len(entries) == 0 {
-		return nil, nil, apperror.ErrBadRequest
+	if meta.Volume != nil && ch.Volume == nil {
+		ch.Volume = meta.Volume
+		changed = true
 	}
-	sort.Slice(entries, func(i, j int) bool {
-		return filepath.Base(entries[i].f.Name) < filepath.Base(entries[j].f.Name)
-	})
-
-	// Delete existing pages first (replace semantics)
-	existing, err := s.pageRepo.GetByChapter(ctx, chapterID)
-	if err != nil {
-		return nil, nil, err
+	if !changed {
+		return
 	}
-	for _, p := range existing {
-		_ = s.storage.DeleteObject(ctx, p.ObjectKey) // best-effort
-		_ = s.pageRepo.Delete(ctx, p.ID)
+	ch.UpdatedAt = time.Now()
+	if err := s.chapterRepo.Update(ctx, ch); err == nil {
+		s.releaseCache.Invalidate(ctx)
 	}
+}
 
-	// Build page records and upload in parallel
-	pages := make([]*model.Page, len(entries))
-	for i := range entries {
-		pages[i] = &model.Page{
-			ID:        uuid.Must(uuid.NewV7()),
-			ChapterID: chapterID,
-			Number:    i + 1,
-			ObjectKey: pageObjectKey(mangaID, chapterID),
-			CreatedAt: time.Now(),
-		}
+// replacePages swaps the chapter's pages for the archive images, returning the
+// archive warnings along with the new pages. The images are checked against the
+// library first (see checkDuplicates), so a rejected archive keeps the old pages.
+func (s *PageService) replacePages(ctx context.Context, mangaID, chapterID uuid.UUID, files []*archive.File, meta *ZipMetadata, progress ProgressFunc) ([]*model.Page, []string, error) {
+	entries, warnings := imageEntries(files)
+	entries, orderWarnings := orderEntries(entries, meta, ".")
+	warnings = append(warnings, orderWarnings...)
+	if len(entries) == 0 {
+		return nil, nil, apperror.ErrBadRequest
 	}
 
-	eg, egCtx := errgroup.WithContext(ctx)
-	for i, e := range entries {
-		i, e := i, e
-		eg.Go(func() error {
-			rc, err := e.f.Open()
-			if err != nil {
-				return err
-			}
-			defer rc.Close()
-			return s.storage.PutObject(egCtx, pages[i].ObjectKey, e.mime, rc, int64(e.f.UncompressedSize64))
-		})
+	fps, err := s.fingerprintEntries(ctx, entries)
+	if err != nil {
+		return nil, nil, err
 	}
-	if err := eg.Wait(); err != nil {
+	duplicateWarnings, err := s.checkDuplicates(ctx, mangaID, chapterID, fps)
+	if err != nil {
 		return nil, nil, err
 	}
+	warnings = append(warnings, duplicateWarnings...)
 
-	if err := s.pageRepo.CreateBatch(ctx, pages); err != nil {
+	// Delete existing pages first (replace semantics). Their images are released
+	// only after the new pages are stored, so unchanged images are reused.
+	existing, err := s.pageRepo.GetByChapter(ctx, chapterID)
+	if err != nil {
 		return nil, nil, err
 	}
-	if err := s.chapterRepo.UpdatePageCount(ctx, chapterID, len(pages)); err != nil {
-		return nil, nil, err
+	for _, p := range existing {
+		_ = s.pageRepo.Delete(ctx, p.ID)
 	}
 
-	// Auto-set cover from first page if manga has no cover yet (best-effort).
-	if len(pages) > 0 {
-		if m, err := s.mangaRepo.GetByID(ctx, mangaID); err == nil && m.CoverKey == "" {
-			m.CoverKey = pages[0].ObjectKey
-			m.UpdatedAt = time.Now()
-			_ = s.mangaRepo.Update(ctx, m)
-		}
+	pages, err := s.storeEntries(ctx, mangaID, chapterID, entries, fps, progress)
+	s.store.ReleaseBestEffort(ctx, existing)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	return pages, meta, nil
+	s.setDefaultCover(ctx, mangaID, pages)
+
+	return pages, warnings, nil
 }
 
-// UploadOneshotZip creates the oneshot chapter (if not yet existing) and uploads
-// pages from the ZIP in a single operation. The chapter title is taken from
-// metadata.json inside the ZIP when present.
-func (s *PageService) UploadOneshotZip(ctx context.Context, requesterID, mangaID uuid.UUID, r io.ReaderAt, size int64) (*OneshotUploadResult, error) {
+// UploadOneshotZip creates the oneshot chapter for the archive's language
+// (ErrConflict if that language already has one) and uploads pages from the
+// archive in a single operation. The chapter title is taken from metadata.json
+// or ComicInfo.xml inside the archive when present. progress is as for UploadZip.
+func (s *PageService) UploadOneshotZip(ctx context.Context, requesterID, mangaID uuid.UUID, r io.ReaderAt, size int64, progress ProgressFunc) (*OneshotUploadResult, error) {
 	manga, err := s.mangaRepo.GetByID(ctx, mangaID)
 	if err != nil {
 		return nil, err
@@ -203,19 +254,27 @@ func (s *PageService) UploadOneshotZip(ctx context.Context, requesterID, mangaID
 		return nil, apperror.ErrBadRequest
 	}
 
-	existing, err := s.chapterRepo.ListByManga(ctx, mangaID)
+	// Opened once: PDFs are rasterized on open.
+	a, err := archive.Open(ctx, r, size)
 	if err != nil {
-		return nil, err
-	}
-	if len(existing) > 0 {
-		return nil, apperror.ErrConflict
+		return nil, apperror.ErrBadRequest
 	}
+	defer a.Close()
 
-	meta, _ := extractZipMetadata(r, size)
+	meta := metadataInDir(a.Files, ".")
 
 	chapterTitle := "Oneshot"
-	if meta != nil && meta.ChapterTitle != "" {
-		chapterTitle = meta.ChapterTitle
+	var volume *int
+	if meta != nil {
+		if meta.ChapterTitle != "" {
+			chapterTitle = meta.ChapterTitle
+		}
+		volume = meta.Volume
+	}
+	// A oneshot has one chapter per language.
+	language := meta.chapterLanguage()
+	if existing, err := s.chapterRepo.GetByMangaAndNumber(ctx, mangaID, 0, language); err == nil && existing != nil {
+		return nil, apperror.ErrConflict
 	}
 
 	now := time.Now()
@@ -224,6 +283,8 @@ func (s *PageService) UploadOneshotZip(ctx context.Context, requesterID, mangaID
 		MangaID:   mangaID,
 		Number:    0,
 		Title:     chapterTitle,
+		Volume:    volume,
+		Language:  language,
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
@@ -231,19 +292,14 @@ func (s *PageService) UploadOneshotZip(ctx context.Context, requesterID, mangaID
 		return nil, err
 	}
 
-	pages, _, err := s.UploadZip(ctx, requesterID, mangaID, ch.ID, r, size)
+	// replacePages also sets the default cover from the first page.
+	pages, warnings, err := s.replacePages(ctx, mangaID, ch.ID, a.Files, meta, progress)
 	if err != nil {
+		_ = s.chapterRepo.Delete(ctx, ch.ID)
 		return nil, err
 	}
 
-	// Auto-set cover from the first page (best-effort — don't fail the upload if this goes wrong)
-	if len(pages) > 0 && manga.CoverKey == "" {
-		manga.CoverKey = pages[0].ObjectKey
-		manga.UpdatedAt = time.Now()
-		_ = s.mangaRepo.Update(ctx, manga)
-	}
-
-	return &OneshotUploadResult{Chapter: ch, Pages: pages, Meta: meta}, nil
+	return &OneshotUploadResult{Chapter: ch, Pages: pages, Meta: meta, Warnings: warnings}, nil
 }
 
 func (s *PageService) DeletePage(ctx context.Context, requesterID, mangaID, chapterID uuid.UUID, pageNumber int) error {
@@ -256,10 +312,10 @@ func (s *PageService) DeletePage(ctx context.Context, requesterID, mangaID, chap
 		return err
 	}
 
-	if err := s.storage.DeleteObject(ctx, page.ObjectKey); err != nil {
+	if err := s.pageRepo.Delete(ctx, page.ID); err != nil {
 		return err
 	}
-	if err := s.pageRepo.Delete(ctx, page.ID); err != nil {
+	if err := s.store.Release(ctx, []*model.Page{page}); err != nil {
 		return err
 	}
 
@@ -267,7 +323,7 @@ func (s *PageService) DeletePage(ctx context.Context, requesterID, mangaID, chap
 	if err != nil {
 		return err
 	}
-	return s.chapterRepo.UpdatePageCount(ctx, chapterID, count)
+	return s.updatePageCount(ctx, mangaID, chapterID, count)
 }
 
 func (s *PageService) ReorderPages(ctx context.Context, requesterID, mangaID, chapterID uuid.UUID, pageIDs []uuid.UUID) error {
@@ -277,7 +333,7 @@ func (s *PageService) ReorderPages(ctx context.Context, requesterID, mangaID, ch
 	return s.pageRepo.UpdateNumbers(ctx, chapterID, pageIDs)
 }
 
-func (s *PageService) GetPagesWithURLs(ctx context.Context, chapterID uuid.UUID) ([]*model.Page, []string, error) {
+func (s *PageService) GetPagesWithURLs(ctx context.Context, chapterID uuid.UUID) ([]*model.Page, []PageURLs, error) {
 	ctx, sub := xray.BeginSubsegment(ctx, "page.GetPagesWithURLs")
 	defer sub.Close(nil)
 
@@ -285,19 +341,67 @@ func (s *PageService) GetPagesWithURLs(ctx context.Context, chapterID uuid.UUID)
 	if err != nil {
 		return nil, nil, err
 	}
+	urls, err := s.resolvePageURLs(ctx, pages)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pages, urls, nil
+}
+
+// PrefetchPages returns the first n pages of a chapter with their URLs, for
+// readers warming the next chapter.
+func (s *PageService) PrefetchPages(ctx context.Context, chapterID uuid.UUID, n int) ([]*model.Page, []PageURLs, error) {
+	ctx, sub := xray.BeginSubsegment(ctx, "page.PrefetchPages")
+	defer sub.Close(nil)
 
-	keys := make([]string, len(pages))
+	pages, err := s.pageRepo.GetByChapter(ctx, chapterID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(pages) > n {
+		pages = pages[:n]
+	}
+	urls, err := s.resolvePageURLs(ctx, pages)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pages, urls, nil
+}
+
+// GetPages lists a chapter's pages without resolving URLs.
+func (s *PageService) GetPages(ctx context.Context, chapterID uuid.UUID) ([]*model.Page, error) {
+	return s.pageRepo.GetByChapter(ctx, chapterID)
+}
+
+func (s *PageService) resolvePageURLs(ctx context.Context, pages []*model.Page) ([]PageURLs, error) {
+	// Page and thumbnail keys are resolved in one batch: pages first, then the
+	// thumbnails that exist, in page order.
+	keys := make([]string, len(pages), 2*len(pages))
 	for i, p := range pages {
 		keys[i] = p.ObjectKey
 	}
+	for _, p := range pages {
+		if p.ThumbnailKey != "" {
+			keys = append(keys, p.ThumbnailKey)
+		}
+	}
 
 	_, urlSub := xray.BeginSubsegment(ctx, "page.ResolveURLs")
-	urls, err := s.urlCache.ResolveMany(ctx, keys)
+	resolved, err := s.urlCache.ResolveMany(ctx, keys)
 	urlSub.Close(nil)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
-	return pages, urls, nil
+
+	urls := make([]PageURLs, len(pages))
+	thumbs := resolved[len(pages):]
+	for i, p := range pages {
+		urls[i].URL = resolved[i]
+		if p.ThumbnailKey != "" {
+			urls[i].ThumbnailURL, thumbs = thumbs[0], thumbs[1:]
+		}
+	}
+	return urls, nil
 }
 
 // checkOwnership verifies the requester owns the manga and the chapter belongs to it.
@@ -319,19 +423,25 @@ func (s *PageService) checkOwnership(ctx context.Context, requesterID, mangaID,
 	return nil
 }
 
-func (s *PageService) uploadAndPersist(ctx context.Context, chapterID uuid.UUID, pages []*model.Page, files []UploadFile) ([]*model.Page, error) {
+func (s *PageService) uploadAndPersist(ctx context.Context, mangaID, chapterID uuid.UUID, pages []*model.Page, files []UploadFile) ([]*model.Page, error) {
 	eg, egCtx := errgroup.WithContext(ctx)
+	eg.SetLimit(s.images.Workers())
 	for i, f := range files {
 		i, f := i, f
 		eg.Go(func() error {
-			return s.storage.PutObject(egCtx, pages[i].ObjectKey, f.MIME, f.Content, f.Size)
+			src, err := io.ReadAll(f.Content)
+			if err != nil {
+				return err
+			}
+			fp, err := imageproc.Hash(src)
+			if err != nil {
+				return fmt.Errorf("%s: %w", f.Header.Filename, apperror.ErrInvalidMIME)
+			}
+			setFingerprint(pages[i], fp)
+			return s.storeImage(egCtx, pages[i], f.Header.Filename, src)
 		})
 	}
-	if err := eg.Wait(); err != nil {
-		return nil, err
-	}
-
-	if err := s.pageRepo.CreateBatch(ctx, pages); err != nil {
+	if err := s.persistPages(ctx, pages, eg.Wait()); err != nil {
 		return nil, err
 	}
 
@@ -339,13 +449,131 @@ func (s *PageService) uploadAndPersist(ctx context.Context, chapterID uuid.UUID,
 	if err != nil {
 		return nil, err
 	}
-	if err := s.chapterRepo.UpdatePageCount(ctx, chapterID, count); err != nil {
+	if err := s.updatePageCount(ctx, mangaID, chapterID, count); err != nil {
 		return nil, err
 	}
 
 	return pages, nil
 }
 
-func pageObjectKey(mangaID, chapterID uuid.UUID) string {
-	return fmt.Sprintf("mangas/%s/chapters/%s/%s.webp", mangaID, chapterID, uuid.Must(uuid.NewV7()))
+// storeEntries uploads entries in parallel as pages 1..n of the chapter and
+// persists them with their fingerprints (fps, as from fingerprintEntries),
+// reporting each stored page to progress.
+// The chapter is expected to have no pages yet.
+func (s *PageService) storeEntries(ctx context.Context, mangaID, chapterID uuid.UUID, entries []zipEntry, fps []imageproc.Fingerprint, progress ProgressFunc) ([]*model.Page, error) {
+	pages := make([]*model.Page, len(entries))
+	for i := range entries {
+		pages[i] = &model.Page{
+			ID:        uuid.Must(uuid.NewV7()),
+			ChapterID: chapterID,
+			Number:    i + 1,
+			CreatedAt: time.Now(),
+		}
+		setFingerprint(pages[i], fps[i])
+	}
+
+	var mu sync.Mutex
+	stored := 0
+	progress.report(0, len(entries))
+
+	eg, egCtx := errgroup.WithContext(ctx)
+	eg.SetLimit(s.images.Workers())
+	for i, e := range entries {
+		i, e := i, e
+		eg.Go(func() error {
+			rc, err := e.f.Open()
+			if err != nil {
+				return err
+			}
+			src, err := io.ReadAll(rc)
+			rc.Close()
+			if err != nil {
+				return err
+			}
+			if err := s.storeImage(egCtx, pages[i], e.f.Name, src); err != nil {
+				return err
+			}
+			mu.Lock()
+			stored++
+			progress.report(stored, len(entries))
+			mu.Unlock()
+			return nil
+		})
+	}
+	if err := s.persistPages(ctx, pages, eg.Wait()); err != nil {
+		return nil, err
+	}
+	if err := s.updatePageCount(ctx, mangaID, chapterID, len(pages)); err != nil {
+		return nil, err
+	}
+	return pages, nil
+}
+
+// updatePageCount stores the chapter's page count and refreshes the manga's
+// last_chapter_at and the latest releases feed, since a chapter counts as
+// published once it has pages. When this publishes the chapter, readers are
+// notified in the background and chapter.published webhooks are sent; adding
+// pages to a published chapter does neither again.
+func (s *PageService) updatePageCount(ctx context.Context, mangaID, chapterID uuid.UUID, count int) error {
+	ch, err := s.chapterRepo.GetByID(ctx, chapterID)
+	if err != nil {
+		return err
+	}
+	if err := s.chapterRepo.UpdatePageCount(ctx, chapterID, count); err != nil {
+		return err
+	}
+	s.releaseCache.Invalidate(ctx)
+	if err := s.mangaRepo.RefreshLastChapterAt(ctx, mangaID); err != nil {
+		return err
+	}
+	if ch.PageCount == 0 && count > 0 {
+		enqueueNotificationJob(ctx, s.notificationJobs, model.NotificationJobChapterPublished, chapterID)
+		ch.PageCount = count
+		s.webhooks.PublishChapter(ctx, ch)
+	}
+	return nil
+}
+
+// setDefaultCover renders the cover from the first page when the manga has none
+// yet (best-effort, see CoverService.SetDefault).
+func (s *PageService) setDefaultCover(ctx context.Context, mangaID uuid.UUID, pages []*model.Page) {
+	if len(pages) == 0 {
+		return
+	}
+	s.covers.SetDefault(ctx, mangaID, pages[0])
+}
+
+// persistPages saves pages whose images were stored, unless storing failed
+// (storeErr). On failure the stored images are released again.
+func (s *PageService) persistPages(ctx context.Context, pages []*model.Page, storeErr error) error {
+	err := storeErr
+	if err == nil {
+		err = s.pageRepo.CreateBatch(ctx, pages)
+	}
+	if err != nil {
+		s.store.ReleaseBestEffort(context.WithoutCancel(ctx), pages)
+	}
+	return err
+}
+
+// storeImage stores one page image through the PageStore and fills in the
+// page's keys and dimensions. p.SHA256 must be set. name identifies the image
+// in errors.
+func (s *PageService) storeImage(ctx context.Context, p *model.Page, name string, src []byte) error {
+	obj, err := s.store.Store(ctx, p.SHA256, src)
+	if errors.Is(err, imageproc.ErrUnsupported) {
+		return fmt.Errorf("%s: %w", name, apperror.ErrInvalidMIME)
+	}
+	if err != nil {
+		return err
+	}
+	p.ObjectKey, p.ThumbnailKey = obj.ObjectKey, obj.ThumbnailKey
+	p.Width, p.Height = obj.Width, obj.Height
+	return nil
+}
+
+// setFingerprint stores an image fingerprint on its page.
+func setFingerprint(p *model.Page, fp imageproc.Fingerprint) {
+	h := int64(fp.PHash)
+	p.SHA256, p.PHash = fp.SHA256, &h
 }
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yumikokawaii/sherry-archive/internal/apperror"
+	"github.com/yumikokawaii/sherry-archive/internal/model"
+	"github.com/yumikokawaii/sherry-archive/internal/repository"
+	"github.com/yumikokawaii/sherry-archive/pkg/imageproc"
+	"github.com/yumikokawaii/sherry-archive/pkg/storage"
+	"go.uber.org/zap"
+)
+
+// contentKeyPrefix holds the content-addressed page images. Pages stored before
+// content addressing keep per-page keys under mangas/ and own their objects.
+const contentKeyPrefix = "pages/"
+
+// PageStore keeps page images in S3 under keys derived from the SHA-256 of the
+// uploaded bytes, so identical pages (a re-uploaded chapter, the same image in
+// two chapters) are stored once. Each object is reference-counted in
+// page_objects and deleted with its last page.
+type PageStore struct {
+	objectRepo repository.PageObjectRepository
+	storage    *storage.Client
+	images     *imageproc.Processor
+}
+
+func NewPageStore(objectRepo repository.PageObjectRepository, storage *storage.Client, images *imageproc.Processor) *PageStore {
+	return &PageStore{objectRepo: objectRepo, storage: storage, images: images}
+}
+
+// Store references the stored image with the content sha256 (hex of src),
+// converting and uploading src (see imageproc.Process) if it isn't stored yet.
+// Every successful Store must be paired with a Release of the page.
+func (s *PageStore) Store(ctx context.Context, sha256 string, src []byte) (*model.PageObject, error) {
+	obj, err := s.objectRepo.Acquire(ctx, sha256)
+	if !errors.Is(err, apperror.ErrNotFound) {
+		return obj, err
+	}
+
+	img, err := s.images.Process(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+	// The keys are unique per upload, so images of a released object still
+	// being deleted never collide with a new upload of the same content.
+	base := contentKeyPrefix + sha256[:2] + "/" + sha256 + "-" + uuid.Must(uuid.NewV7()).String()
+	obj = &model.PageObject{
+		SHA256:       sha256,
+		ObjectKey:    base + img.Ext,
+		ThumbnailKey: base + "_thumb.webp",
+		Width:        img.Width,
+		Height:       img.Height,
+		CreatedAt:    time.Now(),
+	}
+	if err := s.storage.PutObject(ctx, obj.ObjectKey, img.MIME, bytes.NewReader(img.Data), int64(len(img.Data))); err != nil {
+		return nil, err
+	}
+	if err := s.storage.PutObject(ctx, obj.ThumbnailKey, "image/webp", bytes.NewReader(img.Thumbnail), int64(len(img.Thumbnail))); err != nil {
+		s.discard(ctx, sha256, obj.ObjectKey, "")
+		return nil, err
+	}
+	stored, err := s.objectRepo.Create(ctx, obj)
+	if err != nil {
+		// No page_objects row tracks the uploaded images, so nothing would release them.
+		s.discard(ctx, sha256, obj.ObjectKey, obj.ThumbnailKey)
+		return nil, err
+	}
+	if stored.ObjectKey != obj.ObjectKey {
+		// Stored concurrently under other keys; ours are unused.
+		s.discard(ctx, sha256, obj.ObjectKey, obj.ThumbnailKey)
+	}
+	return stored, nil
+}
+
+// discard deletes images Store uploaded but no page_objects row references.
+// It runs even when ctx is cancelled (often the reason Store failed); a
+// failure only leaves unused objects behind, so it is logged.
+func (s *PageStore) discard(ctx context.Context, sha256, objectKey, thumbnailKey string) {
+	if err := s.deleteObjects(context.WithoutCancel(ctx), objectKey, thumbnailKey); err != nil {
+		zap.L().Warn("page store: delete unused upload", zap.String("sha256", sha256), zap.Error(err))
+	}
+}
+
+// Release drops the pages' references to their images, deleting the images no
+// page uses anymore. Images of pages stored before content addressing are
+// deleted directly. Call it once the pages are deleted (or were never saved).
+func (s *PageStore) Release(ctx context.Context, pages []*model.Page) error {
+	var shas []string
+	var errs []error
+	for _, p := range pages {
+		switch {
+		case p.ObjectKey == "":
+			// Never stored.
+		case strings.HasPrefix(p.ObjectKey, contentKeyPrefix):
+			shas = append(shas, p.SHA256)
+		default:
+			errs = append(errs, s.deleteObjects(ctx, p.ObjectKey, p.ThumbnailKey))
+		}
+	}
+	errs = append(errs, s.objectRepo.Release(ctx, shas, func(o *model.PageObject) error {
+		return s.deleteObjects(ctx, o.ObjectKey, o.ThumbnailKey)
+	}))
+	return errors.Join(errs...)
+}
+
+// ReleaseKey drops a reference held through a page image key rather than a page
+// (a manga cover copied from a page). Keys outside the page store are ignored.
+func (s *PageStore) ReleaseKey(ctx context.Context, key string) {
+	if !strings.HasPrefix(key, contentKeyPrefix) {
+		return
+	}
+	// <sha256>.<ext>, or <sha256>-<id>.<ext> for keys unique per upload.
+	sha256, _, _ := strings.Cut(strings.TrimSuffix(path.Base(key), path.Ext(key)), "-")
+	s.ReleaseBestEffort(ctx, []*model.Page{{ObjectKey: key, SHA256: sha256}})
+}
+
+// ReleaseBestEffort is Release for cleanup paths where the pages are already
+// gone: failures only leave unused objects behind, so they are logged.
+func (s *PageStore) ReleaseBestEffort(ctx context.Context, pages []*model.Page) {
+	if err := s.Release(ctx, pages); err != nil {
+		zap.L().Warn("page store: release", zap.Int("pages", len(pages)), zap.Error(err))
+	}
+}
+
+func (s *PageStore) deleteObjects(ctx context.Context, objectKey, thumbnailKey string) error {
+	if thumbnailKey != "" {
+		if err := s.storage.DeleteObject(ctx, thumbnailKey); err != nil {
+			return fmt.Errorf("delete %s: %w", thumbnailKey, err)
+		}
+	}
+	if err := s.storage.DeleteObject(ctx, objectKey); err != nil {
+		return fmt.Errorf("delete %s: %w", objectKey, err)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yumikokawaii/sherry-archive/internal/apperror"
+	"github.com/yumikokawaii/sherry-archive/internal/model"
+	"github.com/yumikokawaii/sherry-archive/internal/repository"
+	"github.com/yumikokawaii/sherry-archive/internal/tracking"
+	"github.com/yumikokawaii/sherry-archive/pkg/pagination"
+)
+
+// EventTracker is implemented by tracking.Recorder.
+type EventTracker interface {
+	TrackUser(userID uuid.UUID, event string, properties map[string]any)
+}
+
+type RatingService struct {
+	ratingRepo repository.RatingRepository
+	mangaRepo  repository.MangaRepository
+	tracker    EventTracker
+}
+
+func NewRatingService(ratingRepo repository.RatingRepository, mangaRepo repository.MangaRepository, tracker EventTracker) *RatingService {
+	return &RatingService{ratingRepo: ratingRepo, mangaRepo: mangaRepo, tracker: tracker}
+}
+
+type RateInput struct {
+	Score  int    // 1–10
+	Review string // optional
+}
+
+// Rate sets the user's rating of a manga, replacing an earlier one. Owners
+// can't rate their own manga. Positive and negative scores are tracked as
+// interest events (see tracking.RatingEvent).
+func (s *RatingService) Rate(ctx context.Context, userID, mangaID uuid.UUID, in RateInput) (*model.Rating, error) {
+	if in.Score < 1 || in.Score > 10 {
+		return nil, apperror.ErrBadRequest
+	}
+	manga, err := s.mangaRepo.GetByID(ctx, mangaID)
+	if err != nil {
+		return nil, err
+	}
+	if manga.OwnerID == userID {
+		return nil, apperror.ErrForbidden
+	}
+
+	now := time.Now()
+	r := &model.Rating{
+		ID:        uuid.Must(uuid.NewV7()),
+		UserID:    userID,
+		MangaID:   mangaID,
+		Score:     in.Score,
+		Review:    strings.TrimSpace(in.Review),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.ratingRepo.Upsert(ctx, r); err != nil {
+		return nil, err
+	}
+	if event := tracking.RatingEvent(r.Score); event != "" {
+		s.tracker.TrackUser(userID, event, map[string]any{"manga_id": mangaID.String(), "score": r.Score})
+	}
+	return r, nil
+}
+
+func (s *RatingService) Get(ctx context.Context, userID, mangaID uuid.UUID) (*model.Rating, error) {
+	return s.ratingRepo.GetByUserAndManga(ctx, userID, mangaID)
+}
+
+func (s *RatingService) Delete(ctx context.Context, userID, mangaID uuid.UUID) error {
+	return s.ratingRepo.Delete(ctx, userID, mangaID)
+}
+
+// ListReviews lists the ratings of a manga that have a written review.
+func (s *RatingService) ListReviews(ctx context.Context, f repository.ReviewFilter, p pagination.Params) ([]*model.RatingWithAuthor, int, error) {
+	switch f.Sort {
+	case "", "helpful", "newest":
+	default:
+		return nil, 0, apperror.ErrBadRequest
+	}
+	if _, err := s.mangaRepo.GetByID(ctx, f.MangaID); err != nil {
+		return nil, 0, err
+	}
+	return s.ratingRepo.ListReviews(ctx, f, p)
+}
+
+// SetHelpful adds or removes the user's helpful vote on a review of the manga.
+// Authors can't vote on their own reviews.
+func (s *RatingService) SetHelpful(ctx context.Context, userID, mangaID, reviewID uuid.UUID, helpful bool) error {
+	r, err := s.ratingRepo.GetByID(ctx, reviewID)
+	if err != nil {
+		return err
+	}
+	if r.MangaID != mangaID || r.Review == "" {
+		return apperror.ErrNotFound
+	}
+	if r.UserID == userID {
+		return apperror.ErrForbidden
+	}
+	if helpful {
+		return s.ratingRepo.AddVote(ctx, reviewID, userID)
+	}
+	return s.ratingRepo.RemoveVote(ctx, reviewID, userID)
+}
@@ -0,0 +1,238 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yumikokawaii/sherry-archive/internal/apperror"
+	"github.com/yumikokawaii/sherry-archive/internal/model"
+	"github.com/yumikokawaii/sherry-archive/internal/repository"
+	"github.com/yumikokawaii/sherry-archive/pkg/pagination"
+	pkgslug "github.com/yumikokawaii/sherry-archive/pkg/slug"
+)
+
+// maxReadingListItems caps a single list; reorder requests carry every item.
+const maxReadingListItems = 1000
+
+type ReadingListService struct {
+	listRepo  repository.ReadingListRepository
+	mangaRepo repository.MangaRepository
+	tracker   EventTracker
+}
+
+func NewReadingListService(listRepo repository.ReadingListRepository, mangaRepo repository.MangaRepository, tracker EventTracker) *ReadingListService {
+	return &ReadingListService{listRepo: listRepo, mangaRepo: mangaRepo, tracker: tracker}
+}
+
+type CreateReadingListInput struct {
+	Name        string
+	Description string
+	IsPublic    bool
+}
+
+func (s *ReadingListService) Create(ctx context.Context, userID uuid.UUID, in CreateReadingListInput) (*model.ReadingList, error) {
+	name := strings.TrimSpace(in.Name)
+	if name == "" {
+		return nil, apperror.ErrBadRequest
+	}
+	id := uuid.Must(uuid.NewV7())
+	slug, err := s.uniqueSlug(ctx, name, id)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	l := &model.ReadingList{
+		ID:          id,
+		UserID:      userID,
+		Name:        name,
+		Slug:        slug,
+		Description: in.Description,
+		IsPublic:    in.IsPublic,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.listRepo.Create(ctx, l); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+type UpdateReadingListInput struct {
+	Name        *string
+	Description *string
+	IsPublic    *bool
+}
+
+func (s *ReadingListService) Update(ctx context.Context, userID, listID uuid.UUID, in UpdateReadingListInput) (*model.ReadingList, error) {
+	l, err := s.owned(ctx, userID, listID)
+	if err != nil {
+		return nil, err
+	}
+	if in.Name != nil {
+		name := strings.TrimSpace(*in.Name)
+		if name == "" {
+			return nil, apperror.ErrBadRequest
+		}
+		if pkgslug.Make(name) != pkgslug.Make(l.Name) {
+			slug, err := s.uniqueSlug(ctx, name, l.ID)
+			if err != nil {
+				return nil, err
+			}
+			l.Slug = slug
+		}
+		l.Name = name
+	}
+	if in.Description != nil {
+		l.Description = *in.Description
+	}
+	if in.IsPublic != nil {
+		l.IsPublic = *in.IsPublic
+	}
+	l.UpdatedAt = time.Now()
+	if err := s.listRepo.Update(ctx, l); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (s *ReadingListService) Delete(ctx context.Context, userID, listID uuid.UUID) error {
+	if _, err := s.owned(ctx, userID, listID); err != nil {
+		return err
+	}
+	return s.listRepo.Delete(ctx, listID)
+}
+
+// ListByUser lists a user's lists; private ones only when the viewer is the user.
+func (s *ReadingListService) ListByUser(ctx context.Context, userID, viewerID uuid.UUID) ([]*model.ReadingList, error) {
+	return s.listRepo.ListByUser(ctx, userID, userID != viewerID)
+}
+
+// GetBySlug returns a list with a page of its manga. Private lists are
+// reported as not found to anyone but their owner.
+func (s *ReadingListService) GetBySlug(ctx context.Context, slug string, viewerID uuid.UUID, p pagination.Params) (*model.ReadingList, []*model.Manga, int, error) {
+	l, err := s.listRepo.GetBySlug(ctx, slug)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	if !l.IsPublic && l.UserID != viewerID {
+		return nil, nil, 0, apperror.ErrNotFound
+	}
+	items, total, err := s.listRepo.ListItems(ctx, l.ID, p)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	return l, items, total, nil
+}
+
+// ListContainingManga lists the public lists holding a manga, plus the viewer's own.
+func (s *ReadingListService) ListContainingManga(ctx context.Context, mangaID, viewerID uuid.UUID, p pagination.Params) ([]*model.ReadingList, int, error) {
+	if _, err := s.mangaRepo.GetByID(ctx, mangaID); err != nil {
+		return nil, 0, err
+	}
+	return s.listRepo.ListContainingManga(ctx, mangaID, viewerID, p)
+}
+
+// AddItem adds a manga to the user's list and tracks it as a list_add
+// interest event.
+func (s *ReadingListService) AddItem(ctx context.Context, userID, listID, mangaID uuid.UUID) error {
+	l, err := s.owned(ctx, userID, listID)
+	if err != nil {
+		return err
+	}
+	if l.ItemCount >= maxReadingListItems {
+		return fmt.Errorf("%w: a list holds at most %d manga", apperror.ErrBadRequest, maxReadingListItems)
+	}
+	if _, err := s.mangaRepo.GetByID(ctx, mangaID); err != nil {
+		return err
+	}
+	if err := s.listRepo.AddItem(ctx, listID, mangaID); err != nil {
+		return err
+	}
+	s.tracker.TrackUser(userID, "list_add", listEventProperties(listID, mangaID))
+	return nil
+}
+
+// RemoveItem removes a manga from the user's list and tracks it as a
+// list_remove interest event.
+func (s *ReadingListService) RemoveItem(ctx context.Context, userID, listID, mangaID uuid.UUID) error {
+	if _, err := s.owned(ctx, userID, listID); err != nil {
+		return err
+	}
+	if err := s.listRepo.RemoveItem(ctx, listID, mangaID); err != nil {
+		return err
+	}
+	s.tracker.TrackUser(userID, "list_remove", listEventProperties(listID, mangaID))
+	return nil
+}
+
+func listEventProperties(listID, mangaID uuid.UUID) map[string]any {
+	return map[string]any{"manga_id": mangaID.String(), "list_id": listID.String()}
+}
+
+// ReorderItems sets the list order. mangaIDs must hold every item exactly once.
+func (s *ReadingListService) ReorderItems(ctx context.Context, userID, listID uuid.UUID, mangaIDs []uuid.UUID) error {
+	if _, err := s.owned(ctx, userID, listID); err != nil {
+		return err
+	}
+	current, err := s.listRepo.ItemIDs(ctx, listID)
+	if err != nil {
+		return err
+	}
+	if !samePermutation(current, mangaIDs) {
+		return fmt.Errorf("%w: manga_ids must list every item of the list once", apperror.ErrBadRequest)
+	}
+	return s.listRepo.ReorderItems(ctx, listID, mangaIDs)
+}
+
+func (s *ReadingListService) owned(ctx context.Context, userID, listID uuid.UUID) (*model.ReadingList, error) {
+	l, err := s.listRepo.GetByID(ctx, listID)
+	if err != nil {
+		return nil, err
+	}
+	if l.UserID != userID {
+		return nil, apperror.ErrForbidden
+	}
+	return l, nil
+}
+
+// uniqueSlug derives a list slug from its name and the random tail of its ID,
+// since list names ("Favourites") repeat across users far more than titles.
+func (s *ReadingListService) uniqueSlug(ctx context.Context, name string, id uuid.UUID) (string, error) {
+	base := pkgslug.Make(name)
+	if base == "" {
+		base = "list"
+	}
+	hex := strings.ReplaceAll(id.String(), "-", "")
+	base = pkgslug.Generate(base, hex[len(hex)-8:])
+	slug := base
+	for i := 1; ; i++ {
+		exists, err := s.listRepo.SlugExists(ctx, slug)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return slug, nil
+		}
+		slug = fmt.Sprintf("%s-%d", base, i)
+	}
+}
+
+func samePermutation(a, b []uuid.UUID) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	seen := make(map[uuid.UUID]int, len(a))
+	for _, id := range a {
+		seen[id]++
+	}
+	for _, id := range b {
+		if seen[id] == 0 {
+			return false
+		}
+		seen[id]--
+	}
+	return true
+}
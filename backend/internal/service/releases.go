@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yumikokawaii/sherry-archive/internal/apperror"
+	"github.com/yumikokawaii/sherry-archive/internal/model"
+	"github.com/yumikokawaii/sherry-archive/internal/repository"
+	"github.com/yumikokawaii/sherry-archive/pkg/rediscache"
+)
+
+// latestReleasesTTL bounds staleness for changes that don't invalidate the
+// feed, such as a manga being renamed or getting a new cover.
+const latestReleasesTTL = 5 * time.Minute
+
+type LatestReleasesInput struct {
+	Tags     []string
+	Category string
+	Language string // chapters in this language or with none; "" for all
+	Cursor   string // opaque, from a previous LatestReleases.NextCursor
+	Limit    int    // chapters per page
+}
+
+// ReleaseGroup is a manga with its chapters from one feed page, newest first.
+type ReleaseGroup struct {
+	Manga    *model.Manga
+	Chapters []*model.Chapter
+}
+
+type LatestReleases struct {
+	Groups     []ReleaseGroup
+	NextCursor string // empty on the last page
+}
+
+// ListLatest returns published chapters in reverse creation order, grouped by
+// manga in order of each manga's newest chapter on the page. A manga can show
+// up again on the next page if its chapters straddle the page boundary.
+func (s *ChapterService) ListLatest(ctx context.Context, in LatestReleasesInput) (*LatestReleases, error) {
+	language, ok := normalizeLanguage(in.Language)
+	if !ok {
+		return nil, apperror.ErrBadRequest
+	}
+	in.Language = language
+	filter := repository.LatestChapterFilter{Tags: in.Tags, Category: in.Category, Language: language}
+	if in.Cursor != "" {
+		t, id, err := decodeReleaseCursor(in.Cursor)
+		if err != nil {
+			return nil, apperror.ErrBadRequest
+		}
+		filter.BeforeTime, filter.BeforeID = t, id
+	}
+
+	return rediscache.Fetch(ctx, s.releaseCache, latestReleasesKey(in), latestReleasesTTL,
+		func(ctx context.Context) (*LatestReleases, error) {
+			return s.loadLatest(ctx, filter, in.Limit)
+		})
+}
+
+func (s *ChapterService) loadLatest(ctx context.Context, filter repository.LatestChapterFilter, limit int) (*LatestReleases, error) {
+	chapters, err := s.chapterRepo.ListLatest(ctx, filter, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var mangaIDs []uuid.UUID
+	groupIdx := make(map[uuid.UUID]int)
+	out := &LatestReleases{Groups: []ReleaseGroup{}}
+	for _, ch := range chapters {
+		i, ok := groupIdx[ch.MangaID]
+		if !ok {
+			i = len(out.Groups)
+			groupIdx[ch.MangaID] = i
+			mangaIDs = append(mangaIDs, ch.MangaID)
+			out.Groups = append(out.Groups, ReleaseGroup{})
+		}
+		out.Groups[i].Chapters = append(out.Groups[i].Chapters, ch)
+	}
+
+	mangas, err := s.mangaRepo.ListByIDs(ctx, mangaIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range mangas {
+		out.Groups[groupIdx[m.ID]].Manga = m
+	}
+	// Drop groups whose manga was deleted between the two queries.
+	out.Groups = slices.DeleteFunc(out.Groups, func(g ReleaseGroup) bool { return g.Manga == nil })
+
+	if len(chapters) == limit {
+		last := chapters[len(chapters)-1]
+		out.NextCursor = encodeReleaseCursor(last.CreatedAt, last.ID)
+	}
+	return out, nil
+}
+
+// latestReleasesKey identifies a feed page. Tags are ANDed, so their order doesn't matter.
+func latestReleasesKey(in LatestReleasesInput) string {
+	tags := slices.Clone(in.Tags)
+	slices.Sort(tags)
+	raw, _ := json.Marshal([]any{tags, in.Category, in.Language, in.Cursor, in.Limit})
+	sum := sha1.Sum(raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// Cursors encode the (created_at, id) of the last chapter on a page.
+func encodeReleaseCursor(t time.Time, id uuid.UUID) string {
+	raw := strconv.FormatInt(t.UnixMicro(), 10) + "_" + id.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeReleaseCursor(cursor string) (time.Time, uuid.UUID, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, uuid.Nil, err
+	}
+	micros, idStr, _ := strings.Cut(string(raw), "_")
+	us, err := strconv.ParseInt(micros, 10, 64)
+	if err != nil {
+		return time.Time{}, uuid.Nil, err
+	}
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return time.Time{}, uuid.Nil, err
+	}
+	return time.UnixMicro(us), id, nil
+}
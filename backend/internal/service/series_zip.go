@@ -0,0 +1,356 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yumikokawaii/sherry-archive/internal/apperror"
+	"github.com/yumikokawaii/sherry-archive/internal/model"
+	"github.com/yumikokawaii/sherry-archive/pkg/archive"
+	"github.com/yumikokawaii/sherry-archive/pkg/natsort"
+)
+
+// chapterFolderPattern pulls an optional volume, a chapter number and an optional title
+// out of folder names such as "12", "Chapter 12.5 - The Return", "Vol.02 Ch.013" or "c014_title".
+var chapterFolderPattern = regexp.MustCompile(
+	`(?i)^(?:vol(?:ume)?\.?\s*(\d+)\s*[-_.]?\s*)?(?:chapter|ch|c|episode|ep|#)?\.?\s*(\d+(?:\.\d+)?)(?:\s*[-–:_.]\s*|\s+|$)(.*)$`)
+
+// seriesChapter is one chapter found inside a series archive: either a
+// top-level folder or an inner archive (cbz, cbr, pdf, …) at the archive root.
+type seriesChapter struct {
+	folder   string
+	files    []*archive.File // files of the folder; nil for an inner archive until opened
+	source   *archive.File   // the inner archive, nil for a folder
+	dir      string          // directory of metadata.json / ComicInfo.xml within files
+	pages    int             // number of page images
+	meta     *ZipMetadata
+	number   float64
+	title    string
+	volume   *int
+	language string
+	ok       bool // number could be determined
+}
+
+// SeriesUploadResult is the outcome of UploadSeriesZip. Meta merges the manga-level
+// fields of all chapters' metadata, for ApplyMetadata (nil when there is none).
+// Warnings cover files outside any chapter; each chapter result has its own.
+type SeriesUploadResult struct {
+	Chapters model.ChapterResults
+	Meta     *ZipMetadata
+	Warnings []string
+}
+
+// UploadSeriesZip imports a whole series from one archive. Every top-level folder
+// (or inner archive at the root) becomes a chapter; its number and title come from
+// the folder's metadata.json or ComicInfo.xml when present, otherwise from the folder name.
+// Chapters are imported independently — a failing chapter is reported in the
+// returned results and does not abort the others. previous are the results of
+// an earlier attempt of the same task: chapters it imported, and which still
+// exist, are kept as they are rather than imported again. progress (may be nil)
+// counts the pages of all chapters; those of a skipped chapter count once it fails.
+func (s *PageService) UploadSeriesZip(ctx context.Context, requesterID, mangaID uuid.UUID, r io.ReaderAt, size int64, previous model.ChapterResults, progress ProgressFunc) (*SeriesUploadResult, error) {
+	manga, err := s.mangaRepo.GetByID(ctx, mangaID)
+	if err != nil {
+		return nil, err
+	}
+	if manga.OwnerID != requesterID {
+		return nil, apperror.ErrForbidden
+	}
+	if manga.Type != model.TypeSeries {
+		return nil, apperror.ErrBadRequest
+	}
+
+	a, err := archive.Open(ctx, r, size)
+	if err != nil {
+		return nil, apperror.ErrBadRequest
+	}
+	defer a.Close()
+
+	chapters, err := seriesChapters(ctx, a.Files)
+	if err != nil {
+		return nil, err
+	}
+	if len(chapters) == 0 {
+		return nil, apperror.ErrBadRequest
+	}
+
+	total := 0
+	for _, sc := range chapters {
+		total += sc.pages
+	}
+
+	imported := make(map[string]model.ChapterResult, len(previous))
+	for _, res := range previous {
+		if res.ChapterID != nil {
+			imported[res.Folder] = res
+		}
+	}
+
+	results := make(model.ChapterResults, 0, len(chapters))
+	var firstPages []*model.Page
+	done := 0
+	progress.report(done, total)
+	for _, sc := range chapters {
+		if res, ok := imported[sc.folder]; ok {
+			if _, err := s.chapterRepo.GetByID(ctx, *res.ChapterID); err == nil {
+				done += sc.pages
+				progress.report(done, total)
+				results = append(results, res)
+				continue
+			}
+		}
+		res := model.ChapterResult{Folder: sc.folder, Number: sc.number, Title: sc.title, Volume: sc.volume, Language: sc.language}
+		offset := done
+		chapterProgress := func(processed, _ int) { progress.report(offset+processed, total) }
+		pages, chapterID, warnings, err := s.importSeriesChapter(ctx, mangaID, sc, chapterProgress)
+		done += sc.pages
+		if err != nil {
+			// The pages of a skipped chapter count as processed.
+			progress.report(done, total)
+		}
+		res.Warnings = warnings
+		if err != nil {
+			res.Error = err.Error()
+		} else {
+			res.ChapterID = &chapterID
+			res.PageCount = len(pages)
+			if firstPages == nil {
+				firstPages = pages
+			}
+		}
+		results = append(results, res)
+	}
+
+	s.setDefaultCover(ctx, mangaID, firstPages)
+	return &SeriesUploadResult{
+		Chapters: results,
+		Meta:     seriesMetadata(chapters),
+		Warnings: skippedFileWarnings(ignoredSeriesFiles(a.Files)),
+	}, nil
+}
+
+// seriesMetadata merges the manga-level fields of every chapter's metadata, in
+// chapter order; tags are combined.
+func seriesMetadata(chapters []seriesChapter) *ZipMetadata {
+	var merged *ZipMetadata
+	for _, sc := range chapters {
+		if sc.meta == nil {
+			continue
+		}
+		if merged == nil {
+			merged = &ZipMetadata{}
+		}
+		tags := mergeTags(merged.Tags, sc.meta.Tags)
+		merged.fillFrom(&ZipMetadata{
+			Series:      sc.meta.Series,
+			Description: sc.meta.Description,
+			Author:      sc.meta.Author,
+			Artist:      sc.meta.Artist,
+			Category:    sc.meta.Category,
+		})
+		merged.Tags = tags
+	}
+	return merged
+}
+
+// entries lists the chapter's images in page order, with the archive warnings.
+func (sc seriesChapter) entries() ([]zipEntry, []string) {
+	entries, warnings := imageEntries(sc.files)
+	entries, orderWarnings := orderEntries(entries, sc.meta, sc.dir)
+	return entries, append(warnings, orderWarnings...)
+}
+
+// importSeriesChapter creates the chapter and stores its images as pages. An
+// inner archive is opened for the import and closed before it returns. The
+// archive and duplicate warnings are returned along with the pages.
+func (s *PageService) importSeriesChapter(ctx context.Context, mangaID uuid.UUID, sc seriesChapter, progress ProgressFunc) ([]*model.Page, uuid.UUID, []string, error) {
+	if sc.source != nil {
+		a, err := archive.OpenNested(ctx, sc.source)
+		if err != nil {
+			return nil, uuid.Nil, nil, err
+		}
+		defer a.Close()
+		sc.files = a.Files
+	}
+	entries, warnings := sc.entries()
+	if !sc.ok {
+		return nil, uuid.Nil, warnings, errors.New("cannot determine chapter number from folder name")
+	}
+	if len(entries) == 0 {
+		return nil, uuid.Nil, warnings, errors.New("no images found")
+	}
+	if existing, err := s.chapterRepo.GetByMangaAndNumber(ctx, mangaID, sc.number, sc.language); err == nil && existing != nil {
+		return nil, uuid.Nil, warnings, errors.New("chapter number already exists")
+	}
+	fps, err := s.fingerprintEntries(ctx, entries)
+	if err != nil {
+		return nil, uuid.Nil, warnings, err
+	}
+	duplicateWarnings, err := s.checkDuplicates(ctx, mangaID, uuid.Nil, fps)
+	if err != nil {
+		return nil, uuid.Nil, warnings, err
+	}
+	warnings = append(warnings, duplicateWarnings...)
+
+	now := time.Now()
+	ch := &model.Chapter{
+		ID:        uuid.Must(uuid.NewV7()),
+		MangaID:   mangaID,
+		Number:    sc.number,
+		Title:     sc.title,
+		Volume:    sc.volume,
+		Language:  sc.language,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.chapterRepo.Create(ctx, ch); err != nil {
+		return nil, uuid.Nil, warnings, err
+	}
+
+	pages, err := s.storeEntries(ctx, mangaID, ch.ID, entries, fps, progress)
+	if err != nil {
+		// Don't leave an empty chapter behind — a retry should be able to recreate it.
+		_ = s.chapterRepo.Delete(ctx, ch.ID)
+		return nil, uuid.Nil, warnings, err
+	}
+	return pages, ch.ID, warnings, nil
+}
+
+// seriesChapters groups archive files into chapters, ordered by chapter number.
+// A single wrapping folder ("My Series/Chapter 1/001.jpg") is looked through.
+// Inner archives are opened one at a time for their metadata and page count,
+// then closed; importSeriesChapter opens each again when its turn comes.
+func seriesChapters(ctx context.Context, files []*archive.File) ([]seriesChapter, error) {
+	prefix := commonRootFolder(files)
+
+	groups := make(map[string][]*archive.File)
+	var chapters []seriesChapter
+	for _, f := range files {
+		rel := strings.TrimPrefix(f.Name, prefix)
+		top, rest, nested := strings.Cut(rel, "/")
+		if isIgnoredArchivePath(top) {
+			continue
+		}
+		if nested && rest != "" {
+			groups[top] = append(groups[top], f)
+			continue
+		}
+		// Inner archives at the root are chapters of their own; other root
+		// files (metadata, stray images) are ignored.
+		if archive.DetectFile(f) == "" {
+			continue
+		}
+		a, err := archive.OpenNested(ctx, f)
+		if err != nil {
+			return nil, apperror.ErrBadRequest
+		}
+		sc := newSeriesChapter(strings.TrimSuffix(top, path.Ext(top)), a.Files, ".")
+		_ = a.Close()
+		sc.files, sc.source = nil, f
+		chapters = append(chapters, sc)
+	}
+	for folder, fs := range groups {
+		chapters = append(chapters, newSeriesChapter(folder, fs, path.Join(strings.TrimSuffix(prefix, "/"), folder)))
+	}
+
+	sort.SliceStable(chapters, func(i, j int) bool {
+		if chapters[i].ok != chapters[j].ok {
+			return chapters[i].ok
+		}
+		if chapters[i].number != chapters[j].number {
+			return chapters[i].number < chapters[j].number
+		}
+		return natsort.Less(chapters[i].folder, chapters[j].folder)
+	})
+	return chapters, nil
+}
+
+func newSeriesChapter(folder string, files []*archive.File, dir string) seriesChapter {
+	sc := seriesChapter{folder: folder, files: files, dir: dir}
+	sc.number, sc.title, sc.volume, sc.ok = parseChapterFolder(folder)
+	sc.meta = metadataInDir(files, dir)
+	entries, _ := sc.entries()
+	sc.pages = len(entries)
+	if sc.meta != nil {
+		if sc.meta.ChapterNumber != nil {
+			sc.number, sc.ok = *sc.meta.ChapterNumber, true
+		}
+		if sc.meta.ChapterTitle != "" {
+			sc.title = sc.meta.ChapterTitle
+		}
+		if sc.meta.Volume != nil {
+			sc.volume = sc.meta.Volume
+		}
+		sc.language = sc.meta.chapterLanguage()
+	}
+	return sc
+}
+
+// parseChapterFolder extracts the chapter number, the remaining title and the
+// volume, if any, from a folder name.
+func parseChapterFolder(name string) (float64, string, *int, bool) {
+	m := chapterFolderPattern.FindStringSubmatch(strings.TrimSpace(name))
+	if m == nil {
+		return 0, "", nil, false
+	}
+	n, err := strconv.ParseFloat(m[2], 64)
+	if err != nil {
+		return 0, "", nil, false
+	}
+	var volume *int
+	if v, err := strconv.Atoi(m[1]); err == nil {
+		volume = &v
+	}
+	return n, strings.TrimSpace(m[3]), volume, true
+}
+
+// commonRootFolder returns "name/" when every file lives under the same single
+// top-level folder and no page image sits directly in it (so it wraps chapters
+// rather than being one), otherwise "".
+func commonRootFolder(files []*archive.File) string {
+	root := ""
+	for _, f := range files {
+		top, rest, nested := strings.Cut(f.Name, "/")
+		if isIgnoredArchivePath(top) {
+			continue
+		}
+		if !nested || (root != "" && root != top) {
+			return ""
+		}
+		if _, isImage := allowedExtensions[strings.ToLower(path.Ext(rest))]; isImage && !strings.Contains(rest, "/") {
+			return ""
+		}
+		root = top
+	}
+	if root == "" {
+		return ""
+	}
+	return root + "/"
+}
+
+// ignoredSeriesFiles returns the files that seriesChapters skips as archiver noise.
+func ignoredSeriesFiles(files []*archive.File) []*archive.File {
+	prefix := commonRootFolder(files)
+	var ignored []*archive.File
+	for _, f := range files {
+		top, _, _ := strings.Cut(strings.TrimPrefix(f.Name, prefix), "/")
+		if isIgnoredArchivePath(top) {
+			ignored = append(ignored, f)
+		}
+	}
+	return ignored
+}
+
+// isIgnoredArchivePath reports whether a top-level entry is archiver noise
+// (macOS resource forks, hidden folders) rather than a chapter.
+func isIgnoredArchivePath(top string) bool {
+	return top == "__MACOSX" || strings.HasPrefix(top, ".")
+}
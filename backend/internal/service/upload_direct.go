@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yumikokawaii/sherry-archive/internal/apperror"
+	"github.com/yumikokawaii/sherry-archive/internal/model"
+	"github.com/yumikokawaii/sherry-archive/pkg/storage"
+	"go.uber.org/zap"
+)
+
+// directPartSize is the part size of direct uploads larger than one part,
+// which are sent as an S3 multipart upload; smaller ones are a single PUT.
+// S3 allows 10,000 parts, so direct uploads are capped at about 640 GB.
+const directPartSize = 64 << 20
+
+// DirectUpload tells the client where to PUT the archive: to URL, or for a
+// multipart upload each PartSize slice of it to its PartURLs entry (part 1
+// first). The URLs expire with the session.
+type DirectUpload struct {
+	Session  *model.UploadSession
+	URL      string
+	PartSize int64
+	PartURLs []string
+}
+
+// CreateDirect starts an upload the client sends straight to S3 through
+// presigned URLs, so the archive never passes through the API. The URLs work
+// against MinIO too: they point at the configured S3 endpoint.
+func (s *UploadSessionService) CreateDirect(ctx context.Context, requesterID uuid.UUID, in CreateUploadSessionInput) (*DirectUpload, error) {
+	if in.Checksum == "" {
+		return nil, apperror.ErrBadRequest
+	}
+	sess, err := s.newSession(ctx, requesterID, in)
+	if err != nil {
+		return nil, err
+	}
+	sess.Direct = true
+	d := &DirectUpload{Session: sess}
+
+	if sess.Size <= directPartSize {
+		u, err := s.storage.PresignedPutURL(ctx, sess.S3Key, s.ttl)
+		if err != nil {
+			return nil, err
+		}
+		d.URL = u.String()
+	} else {
+		if sess.S3UploadID, err = s.storage.CreateMultipartUpload(ctx, sess.S3Key, "application/octet-stream"); err != nil {
+			return nil, err
+		}
+		sess.PartSize = directPartSize
+		if d.PartURLs, err = s.partURLs(ctx, sess); err != nil {
+			_ = s.storage.AbortMultipartUpload(ctx, sess.S3Key, sess.S3UploadID) // best-effort
+			return nil, err
+		}
+		d.PartSize = sess.PartSize
+	}
+
+	if err := s.sessionRepo.Create(ctx, sess); err != nil {
+		if sess.S3UploadID != "" {
+			_ = s.storage.AbortMultipartUpload(ctx, sess.S3Key, sess.S3UploadID) // best-effort
+		}
+		return nil, err
+	}
+	return d, nil
+}
+
+func (s *UploadSessionService) partURLs(ctx context.Context, sess *model.UploadSession) ([]string, error) {
+	urls := make([]string, directPartCount(sess))
+	for i := range urls {
+		u, err := s.storage.PresignedUploadPartURL(ctx, sess.S3Key, sess.S3UploadID, int32(i+1), s.ttl)
+		if err != nil {
+			return nil, err
+		}
+		urls[i] = u.String()
+	}
+	return urls, nil
+}
+
+func directPartCount(sess *model.UploadSession) int {
+	return int((sess.Size + sess.PartSize - 1) / sess.PartSize)
+}
+
+// CompleteDirect checks that the client uploaded the whole archive, then
+// validates it and creates its upload task like the last tus chunk does. An
+// incomplete upload is a bad request the client can fix by uploading the
+// missing data; a wrong checksum or an invalid archive ends the session.
+// Completing a session that already has its task returns it unchanged.
+func (s *UploadSessionService) CompleteDirect(ctx context.Context, requesterID, id uuid.UUID) (*model.UploadSession, error) {
+	sess, err := s.Get(ctx, requesterID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !sess.Direct {
+		return nil, apperror.ErrBadRequest
+	}
+	if sess.TaskID.Valid {
+		return sess, nil
+	}
+	locked, err := s.sessionRepo.Lock(ctx, id, sess.Offset, time.Now().Add(uploadFinishLease))
+	if err != nil {
+		return nil, err
+	}
+	if !locked {
+		return nil, apperror.ErrConflict
+	}
+	ctx = context.WithoutCancel(ctx)
+	defer func() {
+		if err := s.sessionRepo.Unlock(ctx, id); err != nil {
+			zap.L().Warn("unlock upload session", zap.String("session_id", id.String()), zap.Error(err))
+		}
+	}()
+
+	if sess.S3UploadID != "" {
+		if sess.Parts, err = s.directParts(ctx, sess); err != nil {
+			return nil, err
+		}
+	} else {
+		size, err := s.storage.ObjectSize(ctx, sess.S3Key)
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			return nil, apperror.ErrBadRequest
+		}
+		if err != nil {
+			return nil, err
+		}
+		if size != sess.Size {
+			return nil, apperror.ErrBadRequest
+		}
+	}
+	if err := s.finish(ctx, sess); err != nil {
+		return nil, err
+	}
+	return sess, nil
+}
+
+// directParts lists the parts the client uploaded and checks they cover the
+// archive: every part present, each PartSize long except the last.
+func (s *UploadSessionService) directParts(ctx context.Context, sess *model.UploadSession) (model.UploadParts, error) {
+	stored, err := s.storage.ListParts(ctx, sess.S3Key, sess.S3UploadID)
+	if err != nil {
+		return nil, err
+	}
+	n := directPartCount(sess)
+	if len(stored) != n {
+		return nil, apperror.ErrBadRequest
+	}
+	parts := make(model.UploadParts, n)
+	for i, p := range stored {
+		want := sess.PartSize
+		if i == n-1 {
+			want = sess.Size - sess.PartSize*int64(n-1)
+		}
+		if p.Number != int32(i+1) || p.Size != want {
+			return nil, apperror.ErrBadRequest
+		}
+		parts[i] = model.UploadPart{Number: p.Number, ETag: p.ETag, Size: p.Size}
+	}
+	return parts, nil
+}
@@ -0,0 +1,419 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yumikokawaii/sherry-archive/internal/apperror"
+	"github.com/yumikokawaii/sherry-archive/internal/model"
+	"github.com/yumikokawaii/sherry-archive/internal/repository"
+	"github.com/yumikokawaii/sherry-archive/pkg/storage"
+	"go.uber.org/zap"
+)
+
+const (
+	// uploadPartSize is the size of the S3 parts received chunks are cut
+	// into; a chunk request buffers at most one part. S3 allows 10,000 parts,
+	// so uploads are capped at about 80 GB whatever upload.max_size says.
+	uploadPartSize = 8 << 20
+	// uploadLockLease is how long a chunk request holds its session between
+	// parts; a crashed API instance's lock expires after it.
+	uploadLockLease = 2 * time.Minute
+	// uploadFinishLease covers assembling, downloading and validating a
+	// complete upload.
+	uploadFinishLease = 30 * time.Minute
+	// uploadCleanupBatchSize bounds the expired sessions removed per run.
+	uploadCleanupBatchSize = 100
+)
+
+// UploadSessionService implements resumable (tus) archive uploads. Chunks are
+// streamed into an S3 multipart upload instead of being held in memory; once
+// the last byte arrives the archive is validated and an upload task is created
+// for it, as EnqueueZipUpload and friends do for single-request uploads.
+// Direct uploads (upload_direct.go) skip the API altogether for the bytes.
+type UploadSessionService struct {
+	sessionRepo repository.UploadSessionRepository
+	uploadTasks *UploadTaskService
+	storage     *storage.Client
+	maxSize     int64
+	ttl         time.Duration
+}
+
+// maxSize bounds the archive size; sessions expire ttl after they are created.
+func NewUploadSessionService(
+	sessionRepo repository.UploadSessionRepository,
+	uploadTasks *UploadTaskService,
+	storage *storage.Client,
+	maxSize int64,
+	ttl time.Duration,
+) *UploadSessionService {
+	return &UploadSessionService{
+		sessionRepo: sessionRepo,
+		uploadTasks: uploadTasks,
+		storage:     storage,
+		maxSize:     maxSize,
+		ttl:         ttl,
+	}
+}
+
+// MaxSize is the largest archive a session accepts, in bytes.
+func (s *UploadSessionService) MaxSize() int64 {
+	return s.maxSize
+}
+
+type CreateUploadSessionInput struct {
+	Type          model.UploadTaskType // zip, oneshot_zip or series_zip
+	MangaID       uuid.UUID
+	ChapterID     *uuid.UUID // zip only
+	ApplyMetadata bool
+	Filename      string
+	Size          int64
+	Checksum      string // hex SHA-256; required for direct uploads
+}
+
+// Create checks the upload target up front, so a client doesn't send a whole
+// archive only to be refused, and starts the multipart upload.
+func (s *UploadSessionService) Create(ctx context.Context, requesterID uuid.UUID, in CreateUploadSessionInput) (*model.UploadSession, error) {
+	sess, err := s.newSession(ctx, requesterID, in)
+	if err != nil {
+		return nil, err
+	}
+	if sess.S3UploadID, err = s.storage.CreateMultipartUpload(ctx, sess.S3Key, "application/octet-stream"); err != nil {
+		return nil, err
+	}
+	if err := s.sessionRepo.Create(ctx, sess); err != nil {
+		_ = s.storage.AbortMultipartUpload(ctx, sess.S3Key, sess.S3UploadID) // best-effort
+		return nil, err
+	}
+	return sess, nil
+}
+
+// newSession checks the input and the upload target and returns an unsaved session.
+func (s *UploadSessionService) newSession(ctx context.Context, requesterID uuid.UUID, in CreateUploadSessionInput) (*model.UploadSession, error) {
+	if in.Size <= 0 || in.Size > s.maxSize {
+		return nil, apperror.ErrBadRequest
+	}
+	if in.Type != model.UploadTaskTypeZip {
+		in.ChapterID = nil
+	}
+	if err := s.uploadTasks.checkTarget(ctx, in.Type, requesterID, in.MangaID, in.ChapterID); err != nil {
+		return nil, err
+	}
+
+	id := uuid.Must(uuid.NewV7())
+	now := time.Now()
+	sess := &model.UploadSession{
+		ID:            id,
+		OwnerID:       requesterID,
+		TaskType:      in.Type,
+		MangaID:       in.MangaID,
+		ApplyMetadata: in.ApplyMetadata,
+		Filename:      in.Filename,
+		Size:          in.Size,
+		Checksum:      strings.ToLower(in.Checksum),
+		// The worker detects the archive format from the content.
+		S3Key:     "uploads/" + id.String(),
+		ExpiresAt: now.Add(s.ttl),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if in.ChapterID != nil {
+		sess.ChapterID = uuid.NullUUID{UUID: *in.ChapterID, Valid: true}
+	}
+	return sess, nil
+}
+
+// Get returns the requester's session. Other users' and expired sessions are not found.
+func (s *UploadSessionService) Get(ctx context.Context, requesterID, id uuid.UUID) (*model.UploadSession, error) {
+	sess, err := s.sessionRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if sess.OwnerID != requesterID || time.Now().After(sess.ExpiresAt) {
+		return nil, apperror.ErrNotFound
+	}
+	return sess, nil
+}
+
+// Write appends a chunk read from r at offset, which must be the session's
+// current offset. Bytes beyond the declared size are ignored. If the client
+// goes away mid-chunk, what was received is kept and the client resumes from
+// the returned offset. The chunk that completes the upload also validates the
+// archive and creates the task (TaskID); an invalid archive ends the session
+// with ErrBadRequest.
+//
+// A session takes one chunk at a time: a stale offset, a concurrent chunk or a
+// completed session is a conflict.
+func (s *UploadSessionService) Write(ctx context.Context, requesterID, id uuid.UUID, offset int64, r io.Reader) (*model.UploadSession, error) {
+	sess, err := s.Get(ctx, requesterID, id)
+	if err != nil {
+		return nil, err
+	}
+	if sess.Direct {
+		return nil, apperror.ErrBadRequest
+	}
+	if sess.TaskID.Valid || sess.Offset != offset {
+		return nil, apperror.ErrConflict
+	}
+	locked, err := s.sessionRepo.Lock(ctx, id, offset, time.Now().Add(uploadLockLease))
+	if err != nil {
+		return nil, err
+	}
+	if !locked {
+		return nil, apperror.ErrConflict
+	}
+
+	// Keep storing after the client disconnects: received bytes are not lost.
+	ctx = context.WithoutCancel(ctx)
+	defer func() {
+		if err := s.sessionRepo.Unlock(ctx, id); err != nil {
+			zap.L().Warn("unlock upload session", zap.String("session_id", id.String()), zap.Error(err))
+		}
+	}()
+
+	if err := s.store(ctx, sess, r); err != nil {
+		return nil, err
+	}
+	if sess.Offset == sess.Size {
+		if err := s.finish(ctx, sess); err != nil {
+			return nil, err
+		}
+	}
+	return sess, nil
+}
+
+// Delete terminates the requester's session and discards what was uploaded.
+// Once the task exists only the session is removed; the archive is the task's.
+func (s *UploadSessionService) Delete(ctx context.Context, requesterID, id uuid.UUID) error {
+	sess, err := s.Get(ctx, requesterID, id)
+	if err != nil {
+		return err
+	}
+	if !sess.TaskID.Valid {
+		// Take the lock so no chunk is being stored meanwhile.
+		locked, err := s.sessionRepo.Lock(ctx, id, sess.Offset, time.Now().Add(uploadLockLease))
+		if err != nil {
+			return err
+		}
+		if !locked {
+			return apperror.ErrConflict
+		}
+	}
+	return s.discard(ctx, sess)
+}
+
+// store appends r to the session in uploadPartSize parts, starting with the
+// partial bytes left by the previous chunk. The tail shorter than a part is
+// saved as the partial object, unless it ends the upload.
+func (s *UploadSessionService) store(ctx context.Context, sess *model.UploadSession, r io.Reader) error {
+	buf := make([]byte, 0, uploadPartSize)
+	if sess.PartialSize > 0 {
+		var err error
+		if buf, err = s.readPartial(ctx, sess, buf); err != nil {
+			return err
+		}
+	}
+
+	log := zap.L().With(zap.String("session_id", sess.ID.String()))
+	body := io.LimitReader(r, sess.Size-sess.Offset)
+	offset := sess.Offset
+	for {
+		n, readErr := io.ReadFull(body, buf[len(buf):cap(buf)])
+		buf = buf[:len(buf)+n]
+		offset += int64(n)
+		if len(buf) == cap(buf) || (offset == sess.Size && len(buf) > 0) {
+			if err := s.storePart(ctx, sess, buf, offset); err != nil {
+				return err
+			}
+			buf = buf[:0]
+		}
+		if readErr != nil {
+			if !errors.Is(readErr, io.EOF) && !errors.Is(readErr, io.ErrUnexpectedEOF) {
+				log.Info("upload chunk interrupted", zap.Int64("offset", offset), zap.Error(readErr))
+			}
+			break
+		}
+	}
+
+	if len(buf) == 0 {
+		return nil
+	}
+	if err := s.storage.PutObject(ctx, sess.PartialKey(), "application/octet-stream", bytes.NewReader(buf), int64(len(buf))); err != nil {
+		return err
+	}
+	if err := s.sessionRepo.SavePartial(ctx, sess.ID, int64(len(buf)), offset); err != nil {
+		return err
+	}
+	sess.PartialSize, sess.Offset = int64(len(buf)), offset
+	return nil
+}
+
+// readPartial appends the session's partial bytes to buf. The object may hold
+// more than PartialSize if saving the offset failed after it was written.
+func (s *UploadSessionService) readPartial(ctx context.Context, sess *model.UploadSession, buf []byte) ([]byte, error) {
+	body, err := s.storage.GetObject(ctx, sess.PartialKey())
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	buf = buf[:sess.PartialSize]
+	if _, err := io.ReadFull(body, buf); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// storePart uploads buf as the session's next part; offset is the session's
+// offset once it is stored.
+func (s *UploadSessionService) storePart(ctx context.Context, sess *model.UploadSession, buf []byte, offset int64) error {
+	number := int32(len(sess.Parts) + 1)
+	etag, err := s.storage.UploadPart(ctx, sess.S3Key, sess.S3UploadID, number, bytes.NewReader(buf), int64(len(buf)))
+	if err != nil {
+		return err
+	}
+	part := model.UploadPart{Number: number, ETag: etag, Size: int64(len(buf))}
+	if err := s.sessionRepo.AddPart(ctx, sess.ID, part, offset); err != nil {
+		return err
+	}
+	sess.Parts = append(sess.Parts, part)
+	sess.PartialSize, sess.Offset = 0, offset
+	return s.sessionRepo.ExtendLock(ctx, sess.ID, time.Now().Add(uploadLockLease))
+}
+
+// finish assembles a complete upload, validates the archive and creates its
+// task. Each step is recorded, so a client retrying the last chunk (an empty
+// PATCH at the final offset) resumes where a failed attempt stopped.
+func (s *UploadSessionService) finish(ctx context.Context, sess *model.UploadSession) error {
+	if err := s.sessionRepo.ExtendLock(ctx, sess.ID, time.Now().Add(uploadFinishLease)); err != nil {
+		return err
+	}
+	if sess.S3UploadID != "" {
+		parts := make([]storage.CompletedPart, len(sess.Parts))
+		for i, p := range sess.Parts {
+			parts[i] = storage.CompletedPart{Number: p.Number, ETag: p.ETag}
+		}
+		if err := s.storage.CompleteMultipartUpload(ctx, sess.S3Key, sess.S3UploadID, parts); err != nil {
+			return err
+		}
+		if err := s.sessionRepo.MarkAssembled(ctx, sess.ID); err != nil {
+			return err
+		}
+		sess.S3UploadID, sess.PartialSize = "", 0
+		_ = s.storage.DeleteObject(ctx, sess.PartialKey()) // best-effort
+	}
+
+	if err := s.validate(ctx, sess); err != nil {
+		if errors.Is(err, apperror.ErrBadRequest) {
+			if err := s.discard(ctx, sess); err != nil {
+				zap.L().Warn("discard invalid upload", zap.String("session_id", sess.ID.String()), zap.Error(err))
+			}
+		}
+		return err
+	}
+
+	var chapterID *uuid.UUID
+	if sess.ChapterID.Valid {
+		chapterID = &sess.ChapterID.UUID
+	}
+	// The manga or chapter may have changed since the session was created.
+	if err := s.uploadTasks.checkTarget(ctx, sess.TaskType, sess.OwnerID, sess.MangaID, chapterID); err != nil {
+		return err
+	}
+	task, err := s.uploadTasks.createTask(ctx, sess.TaskType, sess.OwnerID, sess.MangaID, chapterID, sess.S3Key, sess.ApplyMetadata)
+	if err != nil {
+		return err
+	}
+	if err := s.sessionRepo.SetTask(ctx, sess.ID, task.ID); err != nil {
+		return err
+	}
+	sess.TaskID = uuid.NullUUID{UUID: task.ID, Valid: true}
+	return nil
+}
+
+// validate checks the assembled archive like a single-request upload, and
+// against the declared size and checksum. The archive is copied to a temp file
+// first: archive formats need random access.
+func (s *UploadSessionService) validate(ctx context.Context, sess *model.UploadSession) error {
+	body, err := s.storage.GetObject(ctx, sess.S3Key)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	tmp, err := os.CreateTemp("", "upload-session-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), body)
+	if err != nil {
+		return err
+	}
+	if size != sess.Size {
+		return apperror.ErrBadRequest
+	}
+	if sess.Checksum != "" && hex.EncodeToString(hash.Sum(nil)) != sess.Checksum {
+		return apperror.ErrBadRequest
+	}
+	return validateUpload(ctx, sess.TaskType, tmp, size)
+}
+
+// discard deletes the session along with the multipart upload or assembled
+// archive it still owns.
+func (s *UploadSessionService) discard(ctx context.Context, sess *model.UploadSession) error {
+	if !sess.TaskID.Valid {
+		if sess.S3UploadID != "" {
+			if err := s.storage.AbortMultipartUpload(ctx, sess.S3Key, sess.S3UploadID); err != nil {
+				return err
+			}
+		} else if err := s.storage.DeleteObject(ctx, sess.S3Key); err != nil {
+			return err
+		}
+		if err := s.storage.DeleteObject(ctx, sess.PartialKey()); err != nil {
+			return err
+		}
+	}
+	return s.sessionRepo.Delete(ctx, sess.ID)
+}
+
+// StartCleanup removes expired sessions and their unfinished uploads every
+// interval until ctx is cancelled.
+// Call it as a goroutine from serve/server.go.
+func (s *UploadSessionService) StartCleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		s.cleanup(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *UploadSessionService) cleanup(ctx context.Context) {
+	sessions, err := s.sessionRepo.ListExpired(ctx, time.Now(), uploadCleanupBatchSize)
+	if err != nil {
+		if ctx.Err() == nil {
+			zap.L().Warn("upload sessions: list expired", zap.Error(err))
+		}
+		return
+	}
+	for _, sess := range sessions {
+		if err := s.discard(ctx, sess); err != nil {
+			zap.L().Warn("upload sessions: discard expired", zap.String("session_id", sess.ID.String()), zap.Error(err))
+		}
+	}
+}
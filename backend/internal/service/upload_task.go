@@ -1,11 +1,11 @@
 package service
 
 import (
-	"archive/zip"
 	"bytes"
 	"context"
 	"fmt"
 	"io"
+	"path"
 	"path/filepath"
 	"strings"
 	"time"
@@ -14,24 +14,40 @@ import (
 	"github.com/yumikokawaii/sherry-archive/internal/apperror"
 	"github.com/yumikokawaii/sherry-archive/internal/model"
 	"github.com/yumikokawaii/sherry-archive/internal/repository"
+	"github.com/yumikokawaii/sherry-archive/pkg/archive"
+	"github.com/yumikokawaii/sherry-archive/pkg/pagination"
 	"github.com/yumikokawaii/sherry-archive/pkg/queue"
 	"github.com/yumikokawaii/sherry-archive/pkg/storage"
+	"github.com/yumikokawaii/sherry-archive/pkg/taskevents"
+	"go.uber.org/zap"
 )
 
+// progressSaveInterval throttles how often import progress is written to the
+// task row; live subscribers get every page through taskevents.
+const progressSaveInterval = 2 * time.Second
+
+// reapBatchSize bounds the stuck tasks released per reaper run.
+const reapBatchSize = 100
+
 type UploadTaskService struct {
 	uploadTaskRepo repository.UploadTaskRepository
 	mangaRepo      repository.MangaRepository
 	chapterRepo    repository.ChapterRepository
 	storage        *storage.Client
-	queue          *queue.Client
+	queue          queue.Client
+	events         *taskevents.Bus
+	webhooks       *WebhookService
 }
 
+// queue may be nil where tasks are only processed (the workers).
 func NewUploadTaskService(
 	uploadTaskRepo repository.UploadTaskRepository,
 	mangaRepo repository.MangaRepository,
 	chapterRepo repository.ChapterRepository,
 	storage *storage.Client,
-	queue *queue.Client,
+	queue queue.Client,
+	events *taskevents.Bus,
+	webhooks *WebhookService,
 ) *UploadTaskService {
 	return &UploadTaskService{
 		uploadTaskRepo: uploadTaskRepo,
@@ -39,78 +55,336 @@ func NewUploadTaskService(
 		chapterRepo:    chapterRepo,
 		storage:        storage,
 		queue:          queue,
+		events:         events,
+		webhooks:       webhooks,
 	}
 }
 
-// EnqueueZipUpload validates the zip, stages it to S3, and sends an SQS message.
-// Returns 202 immediately; Lambda processes asynchronously.
-func (s *UploadTaskService) EnqueueZipUpload(ctx context.Context, requesterID, mangaID, chapterID uuid.UUID, r io.Reader) (*model.UploadTask, error) {
+// EnqueueZipUpload validates the archive, stages it to S3, and queues it for the worker.
+// Returns 202 immediately; the worker processes asynchronously. With applyMetadata the
+// worker also merges the archive metadata into the manga and chapter (see PageService.ApplyMetadata).
+func (s *UploadTaskService) EnqueueZipUpload(ctx context.Context, requesterID, mangaID, chapterID uuid.UUID, r io.Reader, applyMetadata bool) (*model.UploadTask, error) {
+	return s.enqueueArchive(ctx, model.UploadTaskTypeZip, requesterID, mangaID, &chapterID, r, applyMetadata)
+}
+
+// EnqueueOneshotZipUpload validates the archive, stages it to S3, and queues it for the worker.
+// The chapter is created by the worker; chapter_id on the task starts as NULL.
+func (s *UploadTaskService) EnqueueOneshotZipUpload(ctx context.Context, requesterID, mangaID uuid.UUID, r io.Reader, applyMetadata bool) (*model.UploadTask, error) {
+	return s.enqueueArchive(ctx, model.UploadTaskTypeOneshotZip, requesterID, mangaID, nil, r, applyMetadata)
+}
+
+// EnqueueSeriesZipUpload validates the archive, stages it to S3, and queues it for the worker.
+// The worker creates one chapter per folder (or inner archive) and records per-chapter results on the task.
+func (s *UploadTaskService) EnqueueSeriesZipUpload(ctx context.Context, requesterID, mangaID uuid.UUID, r io.Reader, applyMetadata bool) (*model.UploadTask, error) {
+	return s.enqueueArchive(ctx, model.UploadTaskTypeSeriesZip, requesterID, mangaID, nil, r, applyMetadata)
+}
+
+func (s *UploadTaskService) enqueueArchive(ctx context.Context, taskType model.UploadTaskType, requesterID, mangaID uuid.UUID, chapterID *uuid.UUID, r io.Reader, applyMetadata bool) (*model.UploadTask, error) {
 	data, err := io.ReadAll(r)
 	if err != nil {
 		return nil, err
 	}
-	if err := validateZip(data); err != nil {
+	if err := validateUpload(ctx, taskType, bytes.NewReader(data), int64(len(data))); err != nil {
 		return nil, err
 	}
+	if err := s.checkTarget(ctx, taskType, requesterID, mangaID, chapterID); err != nil {
+		return nil, err
+	}
+
+	format := archive.Detect(bytes.NewReader(data))
+	s3Key := fmt.Sprintf("uploads/%s.%s", uuid.Must(uuid.NewV7()), format)
+	// bytes.NewReader is io.ReadSeeker — PutObject skips the extra io.ReadAll
+	if err := s.storage.PutObject(ctx, s3Key, format.ContentType(), bytes.NewReader(data), int64(len(data))); err != nil {
+		return nil, err
+	}
+	return s.createTask(ctx, taskType, requesterID, mangaID, chapterID, s3Key, applyMetadata)
+}
 
+// checkTarget verifies the requester owns the manga and that it takes uploads
+// of taskType: zip needs a chapter of the manga, oneshot_zip and series_zip a
+// manga of that type.
+func (s *UploadTaskService) checkTarget(ctx context.Context, taskType model.UploadTaskType, requesterID, mangaID uuid.UUID, chapterID *uuid.UUID) error {
 	manga, err := s.mangaRepo.GetByID(ctx, mangaID)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	if manga.OwnerID != requesterID {
-		return nil, apperror.ErrForbidden
+		return apperror.ErrForbidden
+	}
+	switch taskType {
+	case model.UploadTaskTypeZip:
+		if chapterID == nil {
+			return apperror.ErrBadRequest
+		}
+		ch, err := s.chapterRepo.GetByID(ctx, *chapterID)
+		if err != nil {
+			return err
+		}
+		if ch.MangaID != mangaID {
+			return apperror.ErrNotFound
+		}
+	case model.UploadTaskTypeOneshotZip:
+		if manga.Type != model.TypeOneshot {
+			return apperror.ErrBadRequest
+		}
+	case model.UploadTaskTypeSeriesZip:
+		if manga.Type != model.TypeSeries {
+			return apperror.ErrBadRequest
+		}
+	default:
+		return apperror.ErrBadRequest
 	}
-	ch, err := s.chapterRepo.GetByID(ctx, chapterID)
+	return nil
+}
+
+// EnqueueExport queues a whole-manga export. format is "cbz" or "epub"; the worker
+// writes the archive to the task's s3_key, which is served via DownloadURL once done.
+func (s *UploadTaskService) EnqueueExport(ctx context.Context, requesterID, mangaID uuid.UUID, format string) (*model.UploadTask, error) {
+	var taskType model.UploadTaskType
+	switch format {
+	case "cbz":
+		taskType = model.UploadTaskTypeExportCBZ
+	case "epub":
+		taskType = model.UploadTaskTypeExportEPUB
+	default:
+		return nil, apperror.ErrBadRequest
+	}
+
+	manga, err := s.mangaRepo.GetByID(ctx, mangaID)
 	if err != nil {
 		return nil, err
 	}
-	if ch.MangaID != mangaID {
+
+	now := time.Now()
+	task := &model.UploadTask{
+		ID:        uuid.Must(uuid.NewV7()),
+		Type:      taskType,
+		Status:    model.UploadTaskStatusPending,
+		OwnerID:   requesterID,
+		MangaID:   mangaID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	task.S3Key = fmt.Sprintf("exports/%s/%s.%s", task.ID, manga.Slug, format)
+
+	if err := s.uploadTaskRepo.Create(ctx, task); err != nil {
+		return nil, err
+	}
+	if err := s.dispatch(ctx, task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// GetTask returns the task if requesterID owns it. Other users' tasks are
+// reported as missing.
+func (s *UploadTaskService) GetTask(ctx context.Context, requesterID, id uuid.UUID) (*model.UploadTask, error) {
+	task, err := s.uploadTaskRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if task.OwnerID != requesterID {
 		return nil, apperror.ErrNotFound
 	}
+	return task, nil
+}
 
-	return s.enqueue(ctx, model.UploadTaskTypeZip, requesterID, mangaID, &chapterID, data)
+func (s *UploadTaskService) List(ctx context.Context, ownerID uuid.UUID, f repository.UploadTaskFilter, p pagination.Params) ([]*model.UploadTask, int, error) {
+	return s.uploadTaskRepo.ListByOwner(ctx, ownerID, f, p)
 }
 
-// EnqueueOneshotZipUpload validates the zip, stages it to S3, and sends an SQS message.
-// The chapter is created by Lambda; chapter_id on the task starts as NULL.
-func (s *UploadTaskService) EnqueueOneshotZipUpload(ctx context.Context, requesterID, mangaID uuid.UUID, r io.Reader) (*model.UploadTask, error) {
-	data, err := io.ReadAll(r)
+// Cancel stops a pending task before a worker claims it and drops its staged
+// upload. Tasks in any other status are a conflict.
+func (s *UploadTaskService) Cancel(ctx context.Context, requesterID, id uuid.UUID) (*model.UploadTask, error) {
+	task, err := s.GetTask(ctx, requesterID, id)
 	if err != nil {
 		return nil, err
 	}
-	if err := validateZip(data); err != nil {
+	ok, err := s.uploadTaskRepo.Cancel(ctx, id)
+	if err != nil {
 		return nil, err
 	}
+	if !ok {
+		return nil, apperror.ErrConflict
+	}
+	if !task.Type.IsExport() {
+		_ = s.storage.DeleteObject(ctx, task.S3Key) // best-effort
+	}
+	return s.reload(ctx, id)
+}
 
-	manga, err := s.mangaRepo.GetByID(ctx, mangaID)
+// Retry queues a failed task again. Imports reuse the staged upload, which the
+// worker only deletes once a task is done; a series_zip retry skips the chapters
+// already imported. Tasks in any other status are a conflict.
+func (s *UploadTaskService) Retry(ctx context.Context, requesterID, id uuid.UUID) (*model.UploadTask, error) {
+	if _, err := s.GetTask(ctx, requesterID, id); err != nil {
+		return nil, err
+	}
+	ok, err := s.uploadTaskRepo.Requeue(ctx, id)
 	if err != nil {
 		return nil, err
 	}
-	if manga.OwnerID != requesterID {
-		return nil, apperror.ErrForbidden
+	if !ok {
+		return nil, apperror.ErrConflict
 	}
-	if manga.Type != model.TypeOneshot {
-		return nil, apperror.ErrBadRequest
+	task, err := s.reload(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.dispatch(ctx, task); err != nil {
+		_ = s.uploadTaskRepo.UpdateStatus(ctx, id, model.UploadTaskStatusFailed, "enqueue: "+err.Error())
+		return nil, err
 	}
+	return task, nil
+}
+
+// reload reads a task after a status change and publishes it.
+func (s *UploadTaskService) reload(ctx context.Context, id uuid.UUID) (*model.UploadTask, error) {
+	task, err := s.uploadTaskRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	s.PublishStatus(ctx, task)
+	return task, nil
+}
+
+// Watch subscribes to the task's live events, then loads it. Subscribing first
+// means no event after the returned snapshot is missed; the caller must close
+// the subscription.
+func (s *UploadTaskService) Watch(ctx context.Context, requesterID, id uuid.UUID) (*model.UploadTask, *taskevents.Subscription, error) {
+	sub, err := s.events.Subscribe(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	task, err := s.GetTask(ctx, requesterID, id)
+	if err != nil {
+		sub.Close()
+		return nil, nil, err
+	}
+	return task, sub, nil
+}
+
+// PublishStatus tells the task's live subscribers about its stored state and,
+// once it is done or failed, sends the upload_task webhook. It is called after
+// every status change (best-effort).
+func (s *UploadTaskService) PublishStatus(ctx context.Context, task *model.UploadTask) {
+	s.publish(ctx, task.ID, taskevents.Event{
+		Type:      taskevents.TypeStatus,
+		Status:    string(task.Status),
+		Processed: task.Processed,
+		Total:     task.Total,
+		Error:     task.Error,
+	})
+	s.webhooks.PublishUploadTask(ctx, task)
+}
+
+// StartReaper releases tasks left in processing by a worker that died (a
+// crashed or timed out Lambda never finishes its claim) until ctx is
+// cancelled. Every interval, tasks not updated for stuckAfter are queued again,
+// or failed once they were claimed maxAttempts times.
+// Call it as a goroutine from serve/server.go.
+func (s *UploadTaskService) StartReaper(ctx context.Context, interval, stuckAfter time.Duration, maxAttempts int) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		s.reap(ctx, stuckAfter, maxAttempts)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
 
-	return s.enqueue(ctx, model.UploadTaskTypeOneshotZip, requesterID, mangaID, nil, data)
+func (s *UploadTaskService) reap(ctx context.Context, stuckAfter time.Duration, maxAttempts int) {
+	before := time.Now().Add(-stuckAfter)
+	tasks, err := s.uploadTaskRepo.ListStuck(ctx, before, reapBatchSize)
+	if err != nil {
+		if ctx.Err() == nil {
+			zap.L().Warn("reaper: list stuck tasks", zap.Error(err))
+		}
+		return
+	}
+	for _, t := range tasks {
+		log := zap.L().With(zap.String("task_id", t.ID.String()), zap.Int("attempts", t.Attempts))
+		status, errMsg := model.UploadTaskStatusPending, ""
+		if t.Attempts >= maxAttempts {
+			status, errMsg = model.UploadTaskStatusFailed, fmt.Sprintf("worker stopped responding %d times", t.Attempts)
+		}
+		ok, err := s.uploadTaskRepo.ReleaseStuck(ctx, t.ID, before, status, errMsg)
+		if err != nil {
+			log.Warn("reaper: release task", zap.Error(err))
+			continue
+		}
+		if !ok {
+			continue // the worker came back or another instance released it
+		}
+		if status == model.UploadTaskStatusPending {
+			log.Warn("reaper: task stuck in processing, queued again")
+			if err := s.dispatch(ctx, t); err != nil {
+				log.Error("reaper: enqueue task", zap.Error(err))
+				_ = s.uploadTaskRepo.UpdateStatus(ctx, t.ID, model.UploadTaskStatusFailed, "enqueue: "+err.Error())
+			}
+		} else {
+			log.Warn("reaper: task stuck in processing, failed")
+		}
+		_, _ = s.reload(ctx, t.ID)
+	}
 }
 
-func (s *UploadTaskService) GetTask(ctx context.Context, id uuid.UUID) (*model.UploadTask, error) {
-	return s.uploadTaskRepo.GetByID(ctx, id)
+// Progress returns the ProgressFunc of the task's import. Every call is
+// published to live subscribers; the task row is updated at most every
+// progressSaveInterval, and always once all pages are stored.
+func (s *UploadTaskService) Progress(ctx context.Context, id uuid.UUID) ProgressFunc {
+	var saved time.Time
+	return func(processed, total int) {
+		s.publish(ctx, id, taskevents.Event{
+			Type:      taskevents.TypeProgress,
+			Status:    string(model.UploadTaskStatusProcessing),
+			Processed: processed,
+			Total:     total,
+		})
+		if processed < total && time.Since(saved) < progressSaveInterval {
+			return
+		}
+		saved = time.Now()
+		if err := s.uploadTaskRepo.SetProgress(ctx, id, processed, total); err != nil {
+			zap.L().Warn("save task progress", zap.String("task_id", id.String()), zap.Error(err))
+		}
+	}
 }
 
-func (s *UploadTaskService) enqueue(ctx context.Context, taskType model.UploadTaskType, ownerID, mangaID uuid.UUID, chapterID *uuid.UUID, data []byte) (*model.UploadTask, error) {
+func (s *UploadTaskService) publish(ctx context.Context, id uuid.UUID, e taskevents.Event) {
+	if err := s.events.Publish(ctx, id, e); err != nil {
+		zap.L().Warn("publish task event", zap.String("task_id", id.String()), zap.String("type", e.Type), zap.Error(err))
+	}
+}
+
+// DownloadURL returns a presigned URL for a finished export, or "" for any other task.
+func (s *UploadTaskService) DownloadURL(ctx context.Context, task *model.UploadTask) (string, error) {
+	if !task.Type.IsExport() || task.Status != model.UploadTaskStatusDone {
+		return "", nil
+	}
+	u, err := s.storage.PresignedDownloadURL(ctx, task.S3Key, path.Base(task.S3Key))
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+// createTask records a pending task for the archive staged at s3Key and sends it to the worker queue.
+func (s *UploadTaskService) createTask(ctx context.Context, taskType model.UploadTaskType, ownerID, mangaID uuid.UUID, chapterID *uuid.UUID, s3Key string, applyMetadata bool) (*model.UploadTask, error) {
 	now := time.Now()
 	task := &model.UploadTask{
-		ID:        uuid.Must(uuid.NewV7()),
-		Type:      taskType,
-		Status:    model.UploadTaskStatusPending,
-		OwnerID:   ownerID,
-		MangaID:   mangaID,
-		S3Key:     fmt.Sprintf("uploads/%s.zip", uuid.Must(uuid.NewV7())),
-		CreatedAt: now,
-		UpdatedAt: now,
+		ID:            uuid.Must(uuid.NewV7()),
+		Type:          taskType,
+		Status:        model.UploadTaskStatusPending,
+		OwnerID:       ownerID,
+		MangaID:       mangaID,
+		S3Key:         s3Key,
+		ApplyMetadata: applyMetadata,
+		CreatedAt:     now,
+		UpdatedAt:     now,
 	}
 	if chapterID != nil {
 		task.ChapterID = uuid.NullUUID{UUID: *chapterID, Valid: true}
@@ -119,39 +393,73 @@ func (s *UploadTaskService) enqueue(ctx context.Context, taskType model.UploadTa
 	if err := s.uploadTaskRepo.Create(ctx, task); err != nil {
 		return nil, err
 	}
-
-	// bytes.NewReader is io.ReadSeeker — PutObject skips the extra io.ReadAll
-	if err := s.storage.PutObject(ctx, task.S3Key, "application/zip", bytes.NewReader(data), int64(len(data))); err != nil {
+	if err := s.dispatch(ctx, task); err != nil {
 		return nil, err
 	}
+	return task, nil
+}
 
+// dispatch sends a created task to the worker queue.
+func (s *UploadTaskService) dispatch(ctx context.Context, task *model.UploadTask) error {
 	msg := queue.UploadMessage{
-		TaskID:    task.ID,
-		Type:      string(task.Type),
-		S3Key:     task.S3Key,
-		MangaID:   mangaID,
-		OwnerID:   ownerID,
-		ChapterID: chapterID,
+		TaskID:        task.ID,
+		Type:          string(task.Type),
+		S3Key:         task.S3Key,
+		MangaID:       task.MangaID,
+		OwnerID:       task.OwnerID,
+		ApplyMetadata: task.ApplyMetadata,
 	}
-	if err := s.queue.Enqueue(ctx, msg); err != nil {
-		return nil, err
+	if task.ChapterID.Valid {
+		msg.ChapterID = &task.ChapterID.UUID
 	}
+	return s.queue.Enqueue(ctx, msg)
+}
 
-	return task, nil
+// validateUpload checks an archive uploaded for a task of taskType.
+func validateUpload(ctx context.Context, taskType model.UploadTaskType, r io.ReaderAt, size int64) error {
+	if taskType == model.UploadTaskTypeSeriesZip {
+		return validateSeriesArchive(ctx, r, size)
+	}
+	return validateArchive(ctx, r, size)
+}
+
+// validateArchive checks r is a supported archive with at least one image.
+// PDFs are only sniffed here — their pages are rasterized by the worker.
+func validateArchive(ctx context.Context, r io.ReaderAt, size int64) error {
+	return validateArchiveFiles(ctx, r, size, true, func(f *archive.File) bool {
+		_, ok := allowedExtensions[strings.ToLower(filepath.Ext(f.Name))]
+		return ok
+	})
+}
+
+// validateSeriesArchive is like validateArchive but also accepts inner archives,
+// which series uploads may use instead of one folder per chapter. A bare PDF has
+// no chapter structure and is rejected.
+func validateSeriesArchive(ctx context.Context, r io.ReaderAt, size int64) error {
+	return validateArchiveFiles(ctx, r, size, false, func(f *archive.File) bool {
+		_, ok := allowedExtensions[strings.ToLower(filepath.Ext(f.Name))]
+		return ok || archive.DetectFile(f) != ""
+	})
 }
 
-// validateZip checks the bytes are a parseable zip with at least one image entry.
-func validateZip(data []byte) error {
-	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+func validateArchiveFiles(ctx context.Context, r io.ReaderAt, size int64, allowPDF bool, accept func(f *archive.File) bool) error {
+	switch archive.Detect(r) {
+	case "":
+		return apperror.ErrBadRequest
+	case archive.PDF:
+		if allowPDF {
+			return nil
+		}
+		return apperror.ErrBadRequest
+	}
+
+	a, err := archive.Open(ctx, r, size)
 	if err != nil {
 		return apperror.ErrBadRequest
 	}
-	for _, f := range zr.File {
-		if f.FileInfo().IsDir() {
-			continue
-		}
-		ext := strings.ToLower(filepath.Ext(f.Name))
-		if _, ok := allowedExtensions[ext]; ok {
+	defer a.Close()
+	for _, f := range a.Files {
+		if accept(f) {
 			return nil
 		}
 	}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/yumikokawaii/sherry-archive/internal/apperror"
 	"github.com/yumikokawaii/sherry-archive/internal/model"
 	"github.com/yumikokawaii/sherry-archive/internal/repository"
 )
@@ -18,8 +19,10 @@ func NewUserService(userRepo repository.UserRepository) *UserService {
 }
 
 type UpdateUserInput struct {
-	Bio      *string
-	Username *string
+	Bio               *string
+	Username          *string
+	PreferredLanguage *string // "" clears it
+	LibraryPublic     *bool
 }
 
 func (s *UserService) GetByID(ctx context.Context, id uuid.UUID) (*model.User, error) {
@@ -37,6 +40,16 @@ func (s *UserService) Update(ctx context.Context, userID uuid.UUID, in UpdateUse
 	if in.Username != nil {
 		u.Username = *in.Username
 	}
+	if in.PreferredLanguage != nil {
+		language, ok := normalizeLanguage(*in.PreferredLanguage)
+		if !ok {
+			return nil, apperror.ErrBadRequest
+		}
+		u.PreferredLanguage = language
+	}
+	if in.LibraryPublic != nil {
+		u.LibraryPublic = *in.LibraryPublic
+	}
 	u.UpdatedAt = time.Now()
 	if err := s.userRepo.Update(ctx, u); err != nil {
 		return nil, err
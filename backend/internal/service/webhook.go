@@ -0,0 +1,447 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yumikokawaii/sherry-archive/internal/apperror"
+	"github.com/yumikokawaii/sherry-archive/internal/model"
+	"github.com/yumikokawaii/sherry-archive/internal/repository"
+	"github.com/yumikokawaii/sherry-archive/pkg/pagination"
+	"github.com/yumikokawaii/sherry-archive/pkg/webhook"
+	"go.uber.org/zap"
+)
+
+const (
+	maxWebhooksPerUser = 10
+	// webhookBatchSize bounds the deliveries claimed per poll.
+	webhookBatchSize = 50
+	// webhookWorkers bounds the requests in flight at once.
+	webhookWorkers = 8
+	// maxWebhookAttempts is when a delivery is marked failed. With
+	// webhook.Backoff the last retry is about 4h after the first attempt.
+	maxWebhookAttempts = 10
+	// maxWebhookErrorLen caps the error stored on a delivery.
+	maxWebhookErrorLen = 500
+)
+
+type WebhookService struct {
+	webhookRepo repository.WebhookRepository
+	client      *webhook.Client
+	timeout     time.Duration
+}
+
+// client sends deliveries and may be nil where only Publish is used (the
+// upload Lambda). timeout bounds one attempt.
+func NewWebhookService(webhookRepo repository.WebhookRepository, client *webhook.Client, timeout time.Duration) *WebhookService {
+	return &WebhookService{webhookRepo: webhookRepo, client: client, timeout: timeout}
+}
+
+type CreateWebhookInput struct {
+	URL    string
+	Secret string // generated when empty
+	Events []model.WebhookEvent
+}
+
+func (s *WebhookService) Create(ctx context.Context, userID uuid.UUID, in CreateWebhookInput) (*model.Webhook, error) {
+	if err := validateWebhook(in.URL, in.Events); err != nil {
+		return nil, err
+	}
+	n, err := s.webhookRepo.CountByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if n >= maxWebhooksPerUser {
+		return nil, apperror.ErrConflict
+	}
+	secret := in.Secret
+	if secret == "" {
+		secret = newWebhookSecret()
+	}
+	now := time.Now()
+	w := &model.Webhook{
+		ID:        uuid.Must(uuid.NewV7()),
+		UserID:    userID,
+		URL:       in.URL,
+		Secret:    secret,
+		Events:    webhookEventStrings(in.Events),
+		Active:    true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.webhookRepo.Create(ctx, w); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+type UpdateWebhookInput struct {
+	URL    *string
+	Secret *string              // "" generates a new one
+	Events []model.WebhookEvent // nil leaves them
+	Active *bool
+}
+
+func (s *WebhookService) Update(ctx context.Context, userID, webhookID uuid.UUID, in UpdateWebhookInput) (*model.Webhook, error) {
+	w, err := s.owned(ctx, userID, webhookID)
+	if err != nil {
+		return nil, err
+	}
+	if in.URL != nil {
+		w.URL = *in.URL
+	}
+	if in.Events != nil {
+		w.Events = webhookEventStrings(in.Events)
+	}
+	if err := validateWebhook(w.URL, webhookEvents(w.Events)); err != nil {
+		return nil, err
+	}
+	if in.Secret != nil {
+		w.Secret = *in.Secret
+		if w.Secret == "" {
+			w.Secret = newWebhookSecret()
+		}
+	}
+	if in.Active != nil {
+		w.Active = *in.Active
+	}
+	w.UpdatedAt = time.Now()
+	if err := s.webhookRepo.Update(ctx, w); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (s *WebhookService) Delete(ctx context.Context, userID, webhookID uuid.UUID) error {
+	if _, err := s.owned(ctx, userID, webhookID); err != nil {
+		return err
+	}
+	return s.webhookRepo.Delete(ctx, webhookID)
+}
+
+func (s *WebhookService) List(ctx context.Context, userID uuid.UUID) ([]*model.Webhook, error) {
+	return s.webhookRepo.ListByUser(ctx, userID)
+}
+
+func (s *WebhookService) ListDeliveries(ctx context.Context, userID, webhookID uuid.UUID, p pagination.Params) ([]*model.WebhookDelivery, int, error) {
+	if _, err := s.owned(ctx, userID, webhookID); err != nil {
+		return nil, 0, err
+	}
+	return s.webhookRepo.ListDeliveries(ctx, webhookID, p)
+}
+
+// Ping queues a ping delivery to test the endpoint, even if the webhook is
+// paused; it is sent once the webhook is active.
+func (s *WebhookService) Ping(ctx context.Context, userID, webhookID uuid.UUID) (*model.WebhookDelivery, error) {
+	w, err := s.owned(ctx, userID, webhookID)
+	if err != nil {
+		return nil, err
+	}
+	ds, err := s.deliveries([]*model.Webhook{w}, model.WebhookPing, map[string]string{"webhook_id": w.ID.String()})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.webhookRepo.CreateDeliveries(ctx, ds); err != nil {
+		return nil, err
+	}
+	return s.webhookRepo.GetDelivery(ctx, ds[0].ID)
+}
+
+// owned returns the webhook if userID owns it. Other users' webhooks are
+// reported as missing.
+func (s *WebhookService) owned(ctx context.Context, userID, webhookID uuid.UUID) (*model.Webhook, error) {
+	w, err := s.webhookRepo.GetByID(ctx, webhookID)
+	if err != nil {
+		return nil, err
+	}
+	if w.UserID != userID {
+		return nil, apperror.ErrNotFound
+	}
+	return w, nil
+}
+
+func validateWebhook(rawURL string, events []model.WebhookEvent) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" || u.User != nil {
+		return apperror.ErrBadRequest
+	}
+	if len(events) == 0 {
+		return apperror.ErrBadRequest
+	}
+	for _, e := range events {
+		if !e.Valid() {
+			return apperror.ErrBadRequest
+		}
+	}
+	return nil
+}
+
+func newWebhookSecret() string {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func webhookEventStrings(events []model.WebhookEvent) []string {
+	seen := make(map[model.WebhookEvent]bool)
+	out := make([]string, 0, len(events))
+	for _, e := range events {
+		if !seen[e] {
+			seen[e] = true
+			out = append(out, string(e))
+		}
+	}
+	return out
+}
+
+func webhookEvents(events []string) []model.WebhookEvent {
+	out := make([]model.WebhookEvent, len(events))
+	for i, e := range events {
+		out[i] = model.WebhookEvent(e)
+	}
+	return out
+}
+
+// --- Publishing ---
+
+// webhookEnvelope is the body of every delivery.
+type webhookEnvelope struct {
+	ID        uuid.UUID          `json:"id"` // the event; the same in every webhook's delivery
+	Event     model.WebhookEvent `json:"event"`
+	CreatedAt time.Time          `json:"created_at"`
+	Data      any                `json:"data"`
+}
+
+type webhookManga struct {
+	ID       uuid.UUID         `json:"id"`
+	OwnerID  uuid.UUID         `json:"owner_id"`
+	Title    string            `json:"title"`
+	Slug     string            `json:"slug"`
+	Status   model.MangaStatus `json:"status"`
+	Type     model.MangaType   `json:"type"`
+	Tags     []string          `json:"tags"`
+	Author   string            `json:"author"`
+	Artist   string            `json:"artist"`
+	Category string            `json:"category"`
+}
+
+type webhookChapter struct {
+	ID        uuid.UUID `json:"id"`
+	MangaID   uuid.UUID `json:"manga_id"`
+	Number    float64   `json:"number"`
+	Title     string    `json:"title"`
+	Volume    *int      `json:"volume"`
+	Language  string    `json:"language"`
+	PageCount int       `json:"page_count"`
+}
+
+type webhookUploadTask struct {
+	ID        uuid.UUID              `json:"id"`
+	Type      model.UploadTaskType   `json:"type"`
+	Status    model.UploadTaskStatus `json:"status"`
+	MangaID   uuid.UUID              `json:"manga_id"`
+	ChapterID *uuid.UUID             `json:"chapter_id"`
+	Error     string                 `json:"error,omitempty"`
+}
+
+// PublishManga sends manga.created or manga.updated.
+func (s *WebhookService) PublishManga(ctx context.Context, event model.WebhookEvent, m *model.Manga) {
+	s.publish(ctx, event, uuid.Nil, webhookManga{
+		ID:       m.ID,
+		OwnerID:  m.OwnerID,
+		Title:    m.Title,
+		Slug:     m.Slug,
+		Status:   m.Status,
+		Type:     m.Type,
+		Tags:     m.Tags,
+		Author:   m.Author,
+		Artist:   m.Artist,
+		Category: m.Category,
+	})
+}
+
+// PublishChapter sends chapter.published.
+func (s *WebhookService) PublishChapter(ctx context.Context, ch *model.Chapter) {
+	s.publish(ctx, model.WebhookChapterPublished, uuid.Nil, webhookChapter{
+		ID:        ch.ID,
+		MangaID:   ch.MangaID,
+		Number:    ch.Number,
+		Title:     ch.Title,
+		Volume:    ch.Volume,
+		Language:  ch.Language,
+		PageCount: ch.PageCount,
+	})
+}
+
+// PublishUploadTask sends upload_task.done or upload_task.failed to the task
+// owner's webhooks; tasks in other states send nothing.
+func (s *WebhookService) PublishUploadTask(ctx context.Context, t *model.UploadTask) {
+	event := model.WebhookUploadTaskDone
+	switch t.Status {
+	case model.UploadTaskStatusDone:
+	case model.UploadTaskStatusFailed:
+		event = model.WebhookUploadTaskFailed
+	default:
+		return
+	}
+	data := webhookUploadTask{ID: t.ID, Type: t.Type, Status: t.Status, MangaID: t.MangaID, Error: t.Error}
+	if t.ChapterID.Valid {
+		data.ChapterID = &t.ChapterID.UUID
+	}
+	s.publish(ctx, event, t.OwnerID, data)
+}
+
+// publish queues a delivery of the event for every subscribed webhook; only
+// ownerID's for private events. It is best-effort: the caller's work is done
+// and a failure is only logged.
+func (s *WebhookService) publish(ctx context.Context, event model.WebhookEvent, ownerID uuid.UUID, data any) {
+	if !event.Private() {
+		ownerID = uuid.Nil
+	}
+	log := zap.L().With(zap.String("event", string(event)))
+	hooks, err := s.webhookRepo.ListSubscribed(ctx, event, ownerID)
+	if err != nil {
+		log.Warn("webhooks: list subscribers", zap.Error(err))
+		return
+	}
+	if len(hooks) == 0 {
+		return
+	}
+	ds, err := s.deliveries(hooks, event, data)
+	if err == nil {
+		err = s.webhookRepo.CreateDeliveries(ctx, ds)
+	}
+	if err != nil {
+		log.Warn("webhooks: queue deliveries", zap.Int("webhooks", len(hooks)), zap.Error(err))
+	}
+}
+
+// deliveries builds one pending delivery of the event per webhook.
+func (s *WebhookService) deliveries(hooks []*model.Webhook, event model.WebhookEvent, data any) ([]*model.WebhookDelivery, error) {
+	now := time.Now()
+	eventID := uuid.Must(uuid.NewV7())
+	payload, err := json.Marshal(webhookEnvelope{ID: eventID, Event: event, CreatedAt: now, Data: data})
+	if err != nil {
+		return nil, err
+	}
+	ds := make([]*model.WebhookDelivery, len(hooks))
+	for i, w := range hooks {
+		ds[i] = &model.WebhookDelivery{
+			ID:            uuid.Must(uuid.NewV7()),
+			WebhookID:     w.ID,
+			EventID:       eventID,
+			Event:         event,
+			Payload:       payload,
+			Status:        model.WebhookDeliveryPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+		}
+	}
+	return ds, nil
+}
+
+// --- Delivery ---
+
+// StartDispatcher sends due deliveries until ctx is cancelled.
+// Call it as a goroutine from serve/server.go.
+func (s *WebhookService) StartDispatcher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		// Drain full batches right away; wait for the ticker otherwise.
+		for s.dispatch(ctx) == webhookBatchSize {
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// dispatch sends one batch of due deliveries and returns how many it claimed.
+func (s *WebhookService) dispatch(ctx context.Context) int {
+	// The lease must outlast the slowest batch: every worker sending its
+	// share of the batch one timeout after another.
+	lease := s.timeout*(webhookBatchSize/webhookWorkers+1) + time.Minute
+	jobs, err := s.webhookRepo.ClaimDeliveries(ctx, webhookBatchSize, lease)
+	if err != nil {
+		if ctx.Err() == nil {
+			zap.L().Warn("webhooks: claim deliveries", zap.Error(err))
+		}
+		return 0
+	}
+
+	sem := make(chan struct{}, webhookWorkers)
+	var wg sync.WaitGroup
+	for _, j := range jobs {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			s.deliver(ctx, j)
+		}()
+	}
+	wg.Wait()
+	return len(jobs)
+}
+
+// deliver makes one attempt and records its outcome.
+func (s *WebhookService) deliver(ctx context.Context, j *model.WebhookDeliveryJob) {
+	reqCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	status, err := s.client.Send(reqCtx, webhook.Request{
+		URL:        j.URL,
+		Secret:     j.Secret,
+		Event:      string(j.Event),
+		DeliveryID: j.ID.String(),
+		Body:       j.Payload,
+	})
+	cancel()
+	if ctx.Err() != nil {
+		// Shutting down: leave the claim to expire and retry the attempt later.
+		return
+	}
+
+	d := &j.WebhookDelivery
+	d.ResponseStatus = nil
+	if status != 0 {
+		d.ResponseStatus = &status
+	}
+	now := time.Now()
+	switch {
+	case err == nil:
+		d.Status, d.LastError, d.DeliveredAt = model.WebhookDeliverySucceeded, "", &now
+	case d.Attempts >= maxWebhookAttempts || errors.Is(err, webhook.ErrPrivateAddress):
+		d.Status, d.LastError = model.WebhookDeliveryFailed, truncateError(err)
+	default:
+		d.LastError = truncateError(err)
+		d.NextAttemptAt = now.Add(webhook.Backoff(d.Attempts))
+	}
+
+	log := zap.L().With(zap.String("delivery_id", d.ID.String()), zap.String("webhook_id", d.WebhookID.String()),
+		zap.String("event", string(d.Event)), zap.Int("attempt", d.Attempts))
+	switch d.Status {
+	case model.WebhookDeliveryFailed:
+		log.Warn("webhooks: delivery failed", zap.Error(err))
+	case model.WebhookDeliveryPending:
+		log.Info("webhooks: delivery will be retried", zap.Time("next_attempt_at", d.NextAttemptAt), zap.Error(err))
+	}
+	if err := s.webhookRepo.UpdateDelivery(ctx, d); err != nil {
+		log.Warn("webhooks: save delivery", zap.Error(err))
+	}
+}
+
+func truncateError(err error) string {
+	msg := err.Error()
+	if len(msg) > maxWebhookErrorLen {
+		msg = msg[:maxWebhookErrorLen]
+	}
+	return msg
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/yumikokawaii/sherry-archive/pkg/archive"
+	"github.com/yumikokawaii/sherry-archive/pkg/natsort"
+)
+
+// zipEntry is an image file inside an uploaded archive.
+type zipEntry struct {
+	f *archive.File
+}
+
+// imageEntries filters files down to supported images, in natural order of their
+// full path: 2.jpg before 10.jpg, and folder by folder, so images with the same
+// name in different folders keep a deterministic order. macOS resource forks
+// and hidden files are skipped.
+//
+// The returned warnings describe skipped files and names that make the page
+// order ambiguous; they are meant to be shown to the uploader.
+func imageEntries(files []*archive.File) ([]zipEntry, []string) {
+	var entries []zipEntry
+	for _, f := range files {
+		if isResourceFork(f.Name) || isHiddenPath(f.Name) {
+			continue
+		}
+		if _, ok := allowedExtensions[strings.ToLower(path.Ext(f.Name))]; ok {
+			entries = append(entries, zipEntry{f: f})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return natsort.Less(entries[i].f.Name, entries[j].f.Name)
+	})
+	return entries, append(skippedFileWarnings(files), duplicateNameWarnings(entries)...)
+}
+
+// skippedFileWarnings reports the macOS resource forks and hidden images among
+// files, which are never imported. Other hidden files (.DS_Store, …) are not
+// worth mentioning.
+func skippedFileWarnings(files []*archive.File) []string {
+	var warnings []string
+	resourceForks := 0
+	for _, f := range files {
+		switch {
+		case isResourceFork(f.Name):
+			resourceForks++
+		case isHiddenPath(f.Name):
+			if _, isImage := allowedExtensions[strings.ToLower(path.Ext(f.Name))]; isImage {
+				warnings = append(warnings, fmt.Sprintf("%s: skipped hidden file", f.Name))
+			}
+		}
+	}
+	if resourceForks > 0 {
+		warnings = append(warnings, fmt.Sprintf("skipped %d macOS resource fork file(s) (__MACOSX/, ._*)", resourceForks))
+	}
+	return warnings
+}
+
+// orderEntries applies the archive metadata to the image order: a metadata.json
+// "pages" manifest when present, ComicInfo.xml page types otherwise.
+// dir is the directory holding the metadata file; manifest paths are relative to it.
+func orderEntries(entries []zipEntry, meta *ZipMetadata, dir string) ([]zipEntry, []string) {
+	if meta != nil && len(meta.Pages) > 0 {
+		return applyManifest(entries, meta.Pages, dir)
+	}
+	return applyPageHints(entries, meta), nil
+}
+
+// applyManifest orders entries as listed in the manifest. Listed files that are
+// missing are reported; images the manifest leaves out are appended in natural
+// order and reported too, so a stale manifest never drops pages silently.
+func applyManifest(entries []zipEntry, manifest []string, dir string) ([]zipEntry, []string) {
+	byName := make(map[string]int, len(entries))
+	for i, e := range entries {
+		byName[e.f.Name] = i
+	}
+
+	var warnings []string
+	used := make([]bool, len(entries))
+	out := make([]zipEntry, 0, len(entries))
+	for _, name := range manifest {
+		full := path.Join(dir, name)
+		i, ok := byName[full]
+		switch {
+		case !ok:
+			warnings = append(warnings, fmt.Sprintf("%s: listed in the manifest but not found", full))
+		case used[i]:
+			warnings = append(warnings, fmt.Sprintf("%s: listed in the manifest more than once", full))
+		default:
+			used[i] = true
+			out = append(out, entries[i])
+		}
+	}
+
+	unlisted := 0
+	for i, e := range entries {
+		if !used[i] {
+			out = append(out, e)
+			unlisted++
+		}
+	}
+	if unlisted > 0 {
+		warnings = append(warnings, fmt.Sprintf("%d image(s) not in the manifest were appended at the end", unlisted))
+	}
+	return out, warnings
+}
+
+// duplicateNameWarnings reports file names that appear in more than one folder.
+// entries must be sorted, so that the folders are listed in page order.
+func duplicateNameWarnings(entries []zipEntry) []string {
+	dirs := make(map[string][]string)
+	var names []string
+	for _, e := range entries {
+		base := path.Base(e.f.Name)
+		if dirs[base] == nil {
+			names = append(names, base)
+		}
+		dirs[base] = append(dirs[base], path.Dir(e.f.Name))
+	}
+
+	var warnings []string
+	for _, base := range names {
+		if len(dirs[base]) > 1 {
+			warnings = append(warnings, fmt.Sprintf("%s: found in several folders (%s); pages follow the folder order",
+				base, strings.Join(dirs[base], ", ")))
+		}
+	}
+	return warnings
+}
+
+// isResourceFork reports whether name is macOS archiver metadata: anything
+// under __MACOSX/ or an AppleDouble "._" file.
+func isResourceFork(name string) bool {
+	for _, part := range strings.Split(name, "/") {
+		if part == "__MACOSX" || strings.HasPrefix(part, "._") {
+			return true
+		}
+	}
+	return false
+}
+
+// isHiddenPath reports whether the file or one of its folders is hidden (dot-prefixed).
+func isHiddenPath(name string) bool {
+	for _, part := range strings.Split(name, "/") {
+		if strings.HasPrefix(part, ".") {
+			return true
+		}
+	}
+	return false
+}
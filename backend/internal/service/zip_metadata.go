@@ -1,54 +1,247 @@
 package service
 
 import (
-	"archive/zip"
 	"encoding/json"
-	"io"
-	"path/filepath"
+	"encoding/xml"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/yumikokawaii/sherry-archive/pkg/archive"
 )
 
-// ZipMetadata holds optional fields parsed from a metadata.json file at the ZIP root.
+// ZipMetadata holds optional fields parsed from the archive's metadata files:
+// our own metadata.json and/or a ComicInfo.xml, as written by most CBZ tools.
+// When both exist, metadata.json wins field by field and ComicInfo.xml fills the gaps.
 type ZipMetadata struct {
-	ChapterNumber *float64 `json:"chapter_number"`
-	ChapterTitle  string   `json:"chapter_title"`
-	Author        string   `json:"author"`
-	Artist        string   `json:"artist"`
-	Tags          []string `json:"tags"`
-	Category      string   `json:"category"`
-	Language      string   `json:"language"`
-}
-
-// extractZipMetadata looks for a metadata.json at the ZIP root and parses it.
-// Returns nil (not an error) when the file is absent.
-func extractZipMetadata(r io.ReaderAt, size int64) (*ZipMetadata, error) {
-	zr, err := zip.NewReader(r, size)
-	if err != nil {
-		return nil, nil
-	}
+	ChapterNumber    *float64 `json:"chapter_number"`
+	ChapterTitle     string   `json:"chapter_title"`
+	Volume           *int     `json:"volume"`
+	Series           string   `json:"series"`
+	Description      string   `json:"description"`
+	Author           string   `json:"author"`
+	Artist           string   `json:"artist"`
+	Tags             []string `json:"tags"`
+	Category         string   `json:"category"`
+	Language         string   `json:"language"`
+	ReadingDirection string   `json:"reading_direction"` // "rtl" or "ltr"
+	// Pages is an optional page order manifest: image paths relative to the
+	// folder holding metadata.json. It takes precedence over PageHints.
+	Pages []string `json:"pages"`
+
+	// PageHints come from ComicInfo.xml <Pages> only. Image indexes refer to
+	// the archive's images in natural path order (see imageEntries).
+	PageHints []PageHint `json:"-"`
+}
+
+// PageHint is the ComicInfo.xml type of one image, e.g. FrontCover or Deleted.
+type PageHint struct {
+	Image int
+	Type  string
+}
 
-	for _, f := range zr.File {
-		if f.FileInfo().IsDir() {
+// ComicInfo.xml page types that affect ingestion.
+const (
+	pageTypeFrontCover = "FrontCover"
+	pageTypeBackCover  = "BackCover"
+	pageTypeDeleted    = "Deleted"
+)
+
+// metadataInDir parses the metadata files located directly inside dir ("." for the
+// archive root). Returns nil when both are absent or malformed.
+func metadataInDir(files []*archive.File, dir string) *ZipMetadata {
+	var native, comic *ZipMetadata
+	for _, f := range files {
+		if path.Dir(f.Name) != dir {
 			continue
 		}
-		// Only consider files directly at the root (no directory component)
-		if filepath.Dir(f.Name) != "." {
-			continue
+		switch base := path.Base(f.Name); {
+		case base == "metadata.json":
+			native = parseMetadataJSON(f)
+		case strings.EqualFold(base, "ComicInfo.xml"):
+			comic = parseComicInfo(f)
 		}
-		if filepath.Base(f.Name) != "metadata.json" {
-			continue
+	}
+
+	switch {
+	case native == nil:
+		return comic
+	case comic != nil:
+		native.fillFrom(comic)
+	}
+	return native
+}
+
+func parseMetadataJSON(f *archive.File) *ZipMetadata {
+	rc, err := f.Open()
+	if err != nil {
+		return nil
+	}
+	defer rc.Close()
+
+	var meta ZipMetadata
+	if err := json.NewDecoder(rc).Decode(&meta); err != nil {
+		return nil
+	}
+	return &meta
+}
+
+// comicInfoImport is comicInfo as read from uploads. Numbers are kept as text:
+// real files have empty (<Volume></Volume>, <Count/>) or non-integer values,
+// which would fail the whole decode.
+type comicInfoImport struct {
+	Title       string `xml:"Title"`
+	Series      string `xml:"Series"`
+	Number      string `xml:"Number"`
+	Volume      string `xml:"Volume"`
+	Summary     string `xml:"Summary"`
+	Writer      string `xml:"Writer"`
+	Penciller   string `xml:"Penciller"`
+	Genre       string `xml:"Genre"`
+	Tags        string `xml:"Tags"`
+	LanguageISO string `xml:"LanguageISO"`
+	Manga       string `xml:"Manga"`
+	Pages       []struct {
+		Image string `xml:"Image,attr"`
+		Type  string `xml:"Type,attr"`
+	} `xml:"Pages>Page"`
+}
+
+func parseComicInfo(f *archive.File) *ZipMetadata {
+	rc, err := f.Open()
+	if err != nil {
+		return nil
+	}
+	defer rc.Close()
+
+	var ci comicInfoImport
+	if err := xml.NewDecoder(rc).Decode(&ci); err != nil {
+		return nil
+	}
+
+	meta := &ZipMetadata{
+		ChapterTitle: strings.TrimSpace(ci.Title),
+		Series:       strings.TrimSpace(ci.Series),
+		Description:  strings.TrimSpace(ci.Summary),
+		Author:       firstListItem(ci.Writer),
+		Artist:       firstListItem(ci.Penciller),
+		Language:     strings.TrimSpace(ci.LanguageISO),
+	}
+	if n, err := strconv.ParseFloat(strings.TrimSpace(ci.Number), 64); err == nil {
+		meta.ChapterNumber = &n
+	}
+	// ComicInfo uses -1 for "no volume".
+	if v, err := strconv.Atoi(strings.TrimSpace(ci.Volume)); err == nil && v > 0 {
+		meta.Volume = &v
+	}
+	// Category is a single value; any further genres are kept as tags.
+	genres := splitList(ci.Genre)
+	if len(genres) > 0 {
+		meta.Category = genres[0]
+		meta.Tags = append(meta.Tags, genres[1:]...)
+	}
+	meta.Tags = append(meta.Tags, splitList(ci.Tags)...)
+	switch ci.Manga {
+	case "YesAndRightToLeft":
+		meta.ReadingDirection = "rtl"
+	case "No":
+		meta.ReadingDirection = "ltr"
+	}
+	for _, p := range ci.Pages {
+		image, err := strconv.Atoi(strings.TrimSpace(p.Image))
+		if err == nil && p.Type != "" {
+			meta.PageHints = append(meta.PageHints, PageHint{Image: image, Type: p.Type})
+		}
+	}
+	return meta
+}
+
+// fillFrom copies every field of other that m leaves empty.
+func (m *ZipMetadata) fillFrom(other *ZipMetadata) {
+	if m.ChapterNumber == nil {
+		m.ChapterNumber = other.ChapterNumber
+	}
+	if m.Volume == nil {
+		m.Volume = other.Volume
+	}
+	fill := func(dst *string, src string) {
+		if *dst == "" {
+			*dst = src
 		}
+	}
+	fill(&m.ChapterTitle, other.ChapterTitle)
+	fill(&m.Series, other.Series)
+	fill(&m.Description, other.Description)
+	fill(&m.Author, other.Author)
+	fill(&m.Artist, other.Artist)
+	fill(&m.Category, other.Category)
+	fill(&m.Language, other.Language)
+	fill(&m.ReadingDirection, other.ReadingDirection)
+	if len(m.Tags) == 0 {
+		m.Tags = other.Tags
+	}
+	if len(m.Pages) == 0 {
+		m.Pages = other.Pages
+	}
+	m.PageHints = other.PageHints
+}
+
+// chapterLanguage is the normalized metadata language, or "" when absent or not a valid code.
+func (m *ZipMetadata) chapterLanguage() string {
+	if m == nil {
+		return ""
+	}
+	language, ok := normalizeLanguage(m.Language)
+	if !ok {
+		return ""
+	}
+	return language
+}
+
+// applyPageHints drops pages marked Deleted, moves the FrontCover to the front
+// and the BackCover to the end. entries must be in imageEntries order, which is
+// what ComicInfo.xml image indexes refer to.
+func applyPageHints(entries []zipEntry, meta *ZipMetadata) []zipEntry {
+	if meta == nil || len(meta.PageHints) == 0 {
+		return entries
+	}
+	types := make(map[int]string, len(meta.PageHints))
+	for _, h := range meta.PageHints {
+		types[h.Image] = h.Type
+	}
 
-		rc, err := f.Open()
-		if err != nil {
-			return nil, nil
+	var front, back, body []zipEntry
+	for i, e := range entries {
+		switch types[i] {
+		case pageTypeDeleted:
+		case pageTypeFrontCover:
+			front = append(front, e)
+		case pageTypeBackCover:
+			back = append(back, e)
+		default:
+			body = append(body, e)
 		}
-		defer rc.Close()
+	}
+	out := make([]zipEntry, 0, len(entries))
+	out = append(out, front...)
+	out = append(out, body...)
+	return append(out, back...)
+}
 
-		var meta ZipMetadata
-		if err := json.NewDecoder(rc).Decode(&meta); err != nil {
-			return nil, nil
+// splitList splits a ComicInfo comma-separated list, dropping empty items.
+func splitList(s string) []string {
+	var out []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
 		}
-		return &meta, nil
 	}
-	return nil, nil
+	return out
+}
+
+func firstListItem(s string) string {
+	if items := splitList(s); len(items) > 0 {
+		return items[0]
+	}
+	return ""
 }
@@ -0,0 +1,60 @@
+package tracking
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yumikokawaii/sherry-archive/internal/metrics"
+)
+
+// Recorder stores events the API emits itself for actions it handles on a
+// signed-in user's behalf (ratings, reading lists), the way Ingest stores
+// client events: persisted, then enriched, off the request path.
+type Recorder struct {
+	store    Store
+	enricher Enricher // optional, nil = disabled
+}
+
+func NewRecorder(store Store, enricher Enricher) *Recorder {
+	return &Recorder{store: store, enricher: enricher}
+}
+
+// TrackUser records event for userID. Server events have no device; the
+// user's ID stands in for it, so the aggregation job credits the user's own
+// interest profile.
+func (r *Recorder) TrackUser(userID uuid.UUID, event string, properties map[string]any) {
+	props, _ := json.Marshal(properties)
+	rows := []EventRow{{
+		DeviceID:   userID,
+		UserID:     &userID,
+		Event:      event,
+		Properties: props,
+		CreatedAt:  time.Now(),
+	}}
+	metrics.RecordTrackingEvent(event)
+
+	// Fresh context: the request that triggered the event may end first.
+	go func() {
+		ctx := context.Background()
+		_ = r.store.Insert(ctx, rows)
+		if r.enricher != nil {
+			r.enricher.ProcessEvents(ctx, rows)
+		}
+	}()
+}
+
+// RatingEvent names the interest event for a rating score: rating_positive
+// for 7–10, rating_negative for 1–4, and "" for neutral scores, which send
+// nothing.
+func RatingEvent(score int) string {
+	switch {
+	case score >= 7:
+		return "rating_positive"
+	case score <= 4:
+		return "rating_negative"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,113 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/yumikokawaii/sherry-archive/pkg/queue"
+	"go.uber.org/zap"
+)
+
+// receiveRetryDelay is how long Consume waits after a failed receive.
+const receiveRetryDelay = 5 * time.Second
+
+// Options tune Consume.
+type Options struct {
+	// Concurrency is how many tasks run at once.
+	Concurrency int
+	// Visibility hides a received message from other workers; it is extended
+	// every half visibility while the task runs.
+	Visibility time.Duration
+}
+
+// Consume receives messages from q and hands them to p until ctx is cancelled,
+// then waits for the running tasks. Handled messages are acked; failed ones are
+// nacked for a later retry or the dead-letter state.
+func Consume(ctx context.Context, q queue.Client, p *Processor, opts Options) {
+	slots := make(chan struct{}, opts.Concurrency)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		// Hold a slot before receiving, so messages never wait hidden in the worker.
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+		free := cap(slots) - len(slots) + 1
+
+		deliveries, err := q.Receive(ctx, free, opts.Visibility)
+		if err != nil {
+			<-slots
+			if ctx.Err() != nil {
+				return
+			}
+			zap.L().Error("worker: receive", zap.Error(err))
+			select {
+			case <-time.After(receiveRetryDelay):
+			case <-ctx.Done():
+				return
+			}
+			continue
+		}
+		if len(deliveries) == 0 {
+			<-slots
+			continue
+		}
+
+		for i, d := range deliveries {
+			if i > 0 {
+				slots <- struct{}{}
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-slots }()
+				// Running tasks finish on shutdown rather than being abandoned half imported.
+				handle(context.WithoutCancel(ctx), q, p, d, opts.Visibility)
+			}()
+		}
+	}
+}
+
+func handle(ctx context.Context, q queue.Client, p *Processor, d *queue.Delivery, visibility time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(visibility / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := q.Extend(ctx, d, visibility); err != nil {
+					zap.L().Warn("worker: extend visibility", zap.Error(err))
+				}
+			}
+		}
+	}()
+
+	var msg queue.UploadMessage
+	err := json.Unmarshal(d.Body, &msg)
+	if err != nil {
+		err = fmt.Errorf("unmarshal message: %w", err)
+	} else {
+		err = p.Handle(ctx, msg)
+	}
+	close(done)
+
+	if err != nil {
+		zap.L().Warn("worker: message failed", zap.Int("attempts", d.Attempts), zap.Error(err))
+		if err := q.Nack(ctx, d, err.Error()); err != nil {
+			zap.L().Error("worker: nack", zap.Error(err))
+		}
+		return
+	}
+	if err := q.Ack(ctx, d); err != nil {
+		zap.L().Error("worker: ack", zap.Error(err))
+	}
+}
@@ -0,0 +1,358 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/redis/go-redis/v9"
+	"github.com/yumikokawaii/sherry-archive/internal/apperror"
+	"github.com/yumikokawaii/sherry-archive/internal/config"
+	"github.com/yumikokawaii/sherry-archive/internal/model"
+	"github.com/yumikokawaii/sherry-archive/internal/repository"
+	"github.com/yumikokawaii/sherry-archive/internal/repository/postgres"
+	"github.com/yumikokawaii/sherry-archive/internal/service"
+	"github.com/yumikokawaii/sherry-archive/pkg/archive"
+	"github.com/yumikokawaii/sherry-archive/pkg/imageproc"
+	"github.com/yumikokawaii/sherry-archive/pkg/queue"
+	"github.com/yumikokawaii/sherry-archive/pkg/rediscache"
+	"github.com/yumikokawaii/sherry-archive/pkg/storage"
+	"github.com/yumikokawaii/sherry-archive/pkg/taskevents"
+	"go.uber.org/zap"
+)
+
+// heartbeatInterval is how often a running task is touched; keep it well below
+// upload.stuck_after.
+const heartbeatInterval = time.Minute
+
+// Processor runs queued upload and export tasks. It is shared by the upload
+// Lambda and the worker subcommand.
+type Processor struct {
+	pageSvc        *service.PageService
+	exportSvc      *service.ExportService
+	uploadTaskRepo repository.UploadTaskRepository
+	uploadTaskSvc  *service.UploadTaskService
+	storage        *storage.Client
+	maxAttempts    int
+}
+
+// NewProcessor wires the services a task needs. Redis invalidates the latest
+// releases feed when an import adds pages and carries live task progress to
+// the API.
+func NewProcessor(cfg *config.Application, db *sqlx.DB, sc *storage.Client, rdb *redis.Client) *Processor {
+	releaseCache := rediscache.New(rdb, "releases")
+
+	archive.ConfigurePDF(cfg.Upload.PDFRasterizer, cfg.Upload.PDFDPI)
+	images := newImageProcessor(cfg)
+
+	pageRepo := postgres.NewPageRepo(db)
+	chapterRepo := postgres.NewChapterRepo(db)
+	mangaRepo := postgres.NewMangaRepo(db)
+
+	pageStore := service.NewPageStore(postgres.NewPageObjectRepo(db), sc, images)
+	coverSvc := service.NewCoverService(mangaRepo, chapterRepo, pageRepo, sc, images, pageStore)
+
+	// Workers only queue webhook deliveries; the API dispatcher sends them.
+	webhookSvc := service.NewWebhookService(postgres.NewWebhookRepo(db), nil, 0)
+
+	// urlCache is nil — workers only call the zip upload methods, which don't use it.
+	// Published chapters are queued for notification; the API dispatcher fans them out.
+	pageSvc := service.NewPageService(pageRepo, chapterRepo, mangaRepo, pageStore, coverSvc, nil, releaseCache, images, service.DuplicatePolicy{
+		WarnPercent:   cfg.Duplicates.WarnPercent,
+		RejectPercent: cfg.Duplicates.RejectPercent,
+		MaxDistance:   cfg.Duplicates.MaxDistance,
+	}, postgres.NewNotificationJobRepo(db), webhookSvc)
+	uploadTaskRepo := postgres.NewUploadTaskRepo(db)
+	return &Processor{
+		pageSvc:        pageSvc,
+		exportSvc:      service.NewExportService(pageRepo, chapterRepo, mangaRepo, sc),
+		uploadTaskRepo: uploadTaskRepo,
+		uploadTaskSvc:  service.NewUploadTaskService(uploadTaskRepo, mangaRepo, chapterRepo, sc, nil, taskevents.New(rdb), webhookSvc),
+		storage:        sc,
+		maxAttempts:    cfg.Upload.MaxAttempts,
+	}
+}
+
+// Handle claims and runs the task of msg. A task that fails with a transient
+// error goes back to pending and the error is returned, so the queue retries
+// the message. It is marked failed instead, and nil returned, when the error
+// is permanent (a bad archive, a missing manga) or the task was claimed
+// maxAttempts times. Messages for a task that is no longer pending are skipped.
+func (p *Processor) Handle(ctx context.Context, msg queue.UploadMessage) error {
+	zap.L().Info("task received", zap.String("task_id", msg.TaskID.String()), zap.String("type", msg.Type), zap.String("manga_id", msg.MangaID.String()))
+
+	// Atomically claim the task (pending → processing).
+	// Returns false if another worker already claimed it or it's already done — skip silently.
+	claimed, err := p.uploadTaskRepo.ClaimProcessing(ctx, msg.TaskID)
+	if err != nil {
+		return fmt.Errorf("claim task: %w", err)
+	}
+	if !claimed {
+		zap.L().Info("task already claimed or done, skipping", zap.String("task_id", msg.TaskID.String()))
+		return nil
+	}
+
+	zap.L().Info("task claimed, processing", zap.String("task_id", msg.TaskID.String()))
+	p.publishTask(ctx, msg.TaskID)
+	stop := p.heartbeat(ctx, msg.TaskID)
+	err = p.process(ctx, msg)
+	stop()
+	if err != nil {
+		if p.retryable(ctx, msg.TaskID, err) {
+			zap.L().Warn("task failed, will retry", zap.String("task_id", msg.TaskID.String()), zap.Error(err))
+			_ = p.uploadTaskRepo.UpdateStatus(ctx, msg.TaskID, model.UploadTaskStatusPending, err.Error())
+			p.publishTask(ctx, msg.TaskID)
+			return err
+		}
+		zap.L().Error("task failed", zap.String("task_id", msg.TaskID.String()), zap.Error(err))
+		_ = p.uploadTaskRepo.UpdateStatus(ctx, msg.TaskID, model.UploadTaskStatusFailed, err.Error())
+		p.publishTask(ctx, msg.TaskID)
+		return nil
+	}
+	zap.L().Info("task done", zap.String("task_id", msg.TaskID.String()))
+	p.publishTask(ctx, msg.TaskID)
+	return nil
+}
+
+// DeadLettered fails the task of a message the queue gave up on. A task that
+// failed transiently was left pending for the redelivery, which will never
+// come. Tasks in any other state are left alone.
+func (p *Processor) DeadLettered(ctx context.Context, msg queue.UploadMessage, reason string) {
+	failed, err := p.uploadTaskRepo.FailPending(ctx, msg.TaskID, "message dead-lettered: "+reason)
+	if err != nil {
+		zap.L().Warn("fail dead-lettered task", zap.String("task_id", msg.TaskID.String()), zap.Error(err))
+		return
+	}
+	if failed {
+		zap.L().Error("task failed, message dead-lettered", zap.String("task_id", msg.TaskID.String()), zap.String("reason", reason))
+		p.publishTask(ctx, msg.TaskID)
+	}
+}
+
+// heartbeat touches the task every heartbeatInterval until stop is called, so
+// the API's reaper doesn't release a task that runs long without progress
+// updates (an export, a large archive being downloaded).
+func (p *Processor) heartbeat(ctx context.Context, taskID uuid.UUID) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(heartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := p.uploadTaskRepo.Touch(ctx, taskID); err != nil {
+					zap.L().Warn("task heartbeat", zap.String("task_id", taskID.String()), zap.Error(err))
+				}
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// retryable reports whether a task that failed with err should be attempted
+// again. apperror sentinels mean the archive or its target is invalid, which
+// no retry fixes.
+func (p *Processor) retryable(ctx context.Context, taskID uuid.UUID, err error) bool {
+	if apperror.HTTPStatus(err) != http.StatusInternalServerError {
+		return false
+	}
+	task, getErr := p.uploadTaskRepo.GetByID(ctx, taskID)
+	if getErr != nil {
+		// Leave the decision to the queue; the reaper fails the task if it
+		// stays stuck.
+		return true
+	}
+	return task.Attempts < p.maxAttempts
+}
+
+// publishTask tells live subscribers about a status change and, for finished
+// tasks, sends the upload_task.done or upload_task.failed webhook (see
+// UploadTaskService.PublishStatus). It uses the task as stored, so events carry
+// its final chapter, progress and error.
+func (p *Processor) publishTask(ctx context.Context, taskID uuid.UUID) {
+	task, err := p.uploadTaskRepo.GetByID(ctx, taskID)
+	if err != nil {
+		zap.L().Warn("publish task", zap.String("task_id", taskID.String()), zap.Error(err))
+		return
+	}
+	p.uploadTaskSvc.PublishStatus(ctx, task)
+}
+
+func (p *Processor) process(ctx context.Context, msg queue.UploadMessage) error {
+	if model.UploadTaskType(msg.Type).IsExport() {
+		return p.processExport(ctx, msg)
+	}
+
+	zap.L().Info("downloading staged archive", zap.String("task_id", msg.TaskID.String()), zap.String("s3_key", msg.S3Key))
+	body, err := p.storage.GetObject(ctx, msg.S3Key)
+	if err != nil {
+		return fmt.Errorf("get staged archive: %w", err)
+	}
+	defer body.Close()
+
+	tmp, err := os.CreateTemp("", "upload-*")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	size, err := io.Copy(tmp, body)
+	if err != nil {
+		return fmt.Errorf("write temp file: %w", err)
+	}
+	zap.L().Info("archive downloaded", zap.String("task_id", msg.TaskID.String()), zap.Int64("size_bytes", size))
+
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	switch model.UploadTaskType(msg.Type) {
+	case model.UploadTaskTypeZip:
+		if msg.ChapterID == nil {
+			return fmt.Errorf("chapter_id required for zip task: %w", apperror.ErrBadRequest)
+		}
+		zap.L().Info("processing zip", zap.String("task_id", msg.TaskID.String()), zap.String("chapter_id", msg.ChapterID.String()))
+		result, err := p.pageSvc.UploadZip(ctx, msg.OwnerID, msg.MangaID, *msg.ChapterID, tmp, size, p.uploadTaskSvc.Progress(ctx, msg.TaskID))
+		if err != nil {
+			return err
+		}
+		p.saveWarnings(ctx, msg, result.Warnings)
+		p.applyMetadata(ctx, msg, msg.ChapterID, result.Meta)
+		_ = p.uploadTaskRepo.UpdateStatus(ctx, msg.TaskID, model.UploadTaskStatusDone, "")
+
+	case model.UploadTaskTypeOneshotZip:
+		zap.L().Info("processing oneshot zip", zap.String("task_id", msg.TaskID.String()), zap.String("manga_id", msg.MangaID.String()))
+		result, err := p.pageSvc.UploadOneshotZip(ctx, msg.OwnerID, msg.MangaID, tmp, size, p.uploadTaskSvc.Progress(ctx, msg.TaskID))
+		if err != nil {
+			return err
+		}
+		zap.L().Info("oneshot chapter created", zap.String("task_id", msg.TaskID.String()), zap.String("chapter_id", result.Chapter.ID.String()))
+		p.saveWarnings(ctx, msg, result.Warnings)
+		// The oneshot chapter already took its title from the metadata.
+		p.applyMetadata(ctx, msg, nil, result.Meta)
+		_ = p.uploadTaskRepo.SetChapterAndDone(ctx, msg.TaskID, result.Chapter.ID)
+
+	case model.UploadTaskTypeSeriesZip:
+		zap.L().Info("processing series zip", zap.String("task_id", msg.TaskID.String()), zap.String("manga_id", msg.MangaID.String()))
+		// A retried task keeps the chapters its earlier attempts imported.
+		task, err := p.uploadTaskRepo.GetByID(ctx, msg.TaskID)
+		if err != nil {
+			return fmt.Errorf("get task: %w", err)
+		}
+		result, err := p.pageSvc.UploadSeriesZip(ctx, msg.OwnerID, msg.MangaID, tmp, size, task.Results, p.uploadTaskSvc.Progress(ctx, msg.TaskID))
+		if err != nil {
+			return err
+		}
+		if err := p.uploadTaskRepo.SetResults(ctx, msg.TaskID, result.Chapters); err != nil {
+			return fmt.Errorf("save results: %w", err)
+		}
+		p.saveWarnings(ctx, msg, result.Warnings)
+		imported, err := seriesOutcome(result.Chapters)
+		zap.L().Info("series chapters imported", zap.String("task_id", msg.TaskID.String()), zap.Int("imported", imported), zap.Int("total", len(result.Chapters)))
+		if err != nil {
+			return err
+		}
+		p.applyMetadata(ctx, msg, nil, result.Meta)
+		_ = p.uploadTaskRepo.UpdateStatus(ctx, msg.TaskID, model.UploadTaskStatusDone, "")
+
+	default:
+		return fmt.Errorf("unknown task type %s: %w", msg.Type, apperror.ErrBadRequest)
+	}
+
+	zap.L().Info("deleting staged archive", zap.String("task_id", msg.TaskID.String()))
+	_ = p.storage.DeleteObject(ctx, msg.S3Key)
+	return nil
+}
+
+// seriesOutcome counts the chapters a series task imported. When none could
+// be, the archive is at fault and the error is permanent: another attempt
+// would fail the same chapters again.
+func seriesOutcome(results model.ChapterResults) (int, error) {
+	imported := 0
+	for _, r := range results {
+		if r.Error == "" {
+			imported++
+		}
+	}
+	if imported == 0 {
+		return 0, fmt.Errorf("%w: no chapters imported", apperror.ErrBadRequest)
+	}
+	return imported, nil
+}
+
+// saveWarnings records archive warnings (skipped files, ambiguous page order) on the task.
+func (p *Processor) saveWarnings(ctx context.Context, msg queue.UploadMessage, warnings []string) {
+	if len(warnings) == 0 {
+		return
+	}
+	zap.L().Info("archive warnings", zap.String("task_id", msg.TaskID.String()), zap.Strings("warnings", warnings))
+	if err := p.uploadTaskRepo.SetWarnings(ctx, msg.TaskID, warnings); err != nil {
+		zap.L().Warn("save warnings", zap.String("task_id", msg.TaskID.String()), zap.Error(err))
+	}
+}
+
+// applyMetadata merges the archive metadata into the manga (and chapter) for
+// tasks created with apply_metadata, and records the report on the task.
+func (p *Processor) applyMetadata(ctx context.Context, msg queue.UploadMessage, chapterID *uuid.UUID, meta *service.ZipMetadata) {
+	if !msg.ApplyMetadata {
+		return
+	}
+	report := p.pageSvc.ApplyMetadata(ctx, msg.MangaID, chapterID, meta)
+	zap.L().Info("metadata applied", zap.String("task_id", msg.TaskID.String()),
+		zap.Int("applied", len(report.Applied)), zap.Int("conflicts", len(report.Conflicts)))
+	if err := p.uploadTaskRepo.SetMetadataReport(ctx, msg.TaskID, report); err != nil {
+		zap.L().Warn("save metadata report", zap.String("task_id", msg.TaskID.String()), zap.Error(err))
+	}
+}
+
+// processExport builds the archive in a temp file and uploads it to the task's
+// s3_key. Nothing is staged for exports, so there is nothing to clean up.
+func (p *Processor) processExport(ctx context.Context, msg queue.UploadMessage) error {
+	zap.L().Info("processing export", zap.String("task_id", msg.TaskID.String()), zap.String("type", msg.Type), zap.String("manga_id", msg.MangaID.String()))
+
+	tmp, err := os.CreateTemp("", "export-*")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	contentType, err := p.exportSvc.ExportManga(ctx, model.UploadTaskType(msg.Type), msg.MangaID, tmp)
+	if err != nil {
+		return err
+	}
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	zap.L().Info("uploading export", zap.String("task_id", msg.TaskID.String()), zap.String("s3_key", msg.S3Key), zap.Int64("size_bytes", size))
+	if err := p.storage.UploadObject(ctx, msg.S3Key, contentType, tmp, size); err != nil {
+		return fmt.Errorf("upload export: %w", err)
+	}
+	_ = p.uploadTaskRepo.UpdateStatus(ctx, msg.TaskID, model.UploadTaskStatusDone, "")
+	return nil
+}
+
+func newImageProcessor(cfg *config.Application) *imageproc.Processor {
+	return imageproc.New(imageproc.Config{
+		Encoder:          cfg.Image.Encoder,
+		Quality:          cfg.Image.Quality,
+		ThumbnailWidth:   cfg.Image.ThumbnailWidth,
+		ThumbnailQuality: cfg.Image.ThumbnailQuality,
+		Workers:          cfg.Image.Workers,
+		CoverWidths:      cfg.Image.CoverWidthList(),
+		CoverQuality:     cfg.Image.CoverQuality,
+	})
+}
@@ -0,0 +1,48 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/yumikokawaii/sherry-archive/internal/apperror"
+	"github.com/yumikokawaii/sherry-archive/internal/model"
+	"github.com/yumikokawaii/sherry-archive/internal/repository"
+)
+
+// taskRepo serves GetByID from a fixed task; retryable needs nothing else.
+type taskRepo struct {
+	repository.UploadTaskRepository
+	task *model.UploadTask
+}
+
+func (r taskRepo) GetByID(context.Context, uuid.UUID) (*model.UploadTask, error) {
+	return r.task, nil
+}
+
+func TestSeriesOutcome(t *testing.T) {
+	chapterID := uuid.New()
+	p := &Processor{uploadTaskRepo: taskRepo{task: &model.UploadTask{Attempts: 1}}, maxAttempts: 3}
+
+	failed := model.ChapterResults{
+		{Folder: "Chapter 1", Error: "no images found"},
+		{Folder: "Chapter 2", Error: "chapter number already exists"},
+	}
+	imported, err := seriesOutcome(failed)
+	if imported != 0 || !errors.Is(err, apperror.ErrBadRequest) {
+		t.Fatalf("seriesOutcome(all failed) = %d, %v; want 0, ErrBadRequest", imported, err)
+	}
+	if p.retryable(context.Background(), uuid.New(), err) {
+		t.Errorf("retryable(%v) = true; a series with no importable chapter must fail on the first attempt", err)
+	}
+
+	partial := append(failed, model.ChapterResult{Folder: "Chapter 3", ChapterID: &chapterID, PageCount: 12})
+	if imported, err := seriesOutcome(partial); imported != 1 || err != nil {
+		t.Errorf("seriesOutcome(one imported) = %d, %v; want 1, nil", imported, err)
+	}
+
+	if transient := errors.New("get staged archive: timeout"); !p.retryable(context.Background(), uuid.New(), transient) {
+		t.Errorf("retryable(%v) = false, want true below max attempts", transient)
+	}
+}
@@ -0,0 +1,94 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+
+	"github.com/yumikokawaii/sherry-archive/internal/config"
+	"github.com/yumikokawaii/sherry-archive/pkg/archive"
+	"github.com/yumikokawaii/sherry-archive/pkg/imageproc"
+)
+
+// SelfCheck processes a generated page and renders a generated PDF the way an
+// import does, with the configured tools, so a deployment missing the external
+// binaries the processor runs fails before it takes tasks. It needs no
+// database or storage.
+func SelfCheck(ctx context.Context, cfg *config.Application) error {
+	images := newImageProcessor(cfg)
+
+	img := image.NewGray(image.Rect(0, 0, 400, 600))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(i)
+	}
+	var page bytes.Buffer
+	if err := png.Encode(&page, img); err != nil {
+		return err
+	}
+	if err := checkProcess(ctx, images, page.Bytes()); err != nil {
+		return fmt.Errorf("selfcheck: process page: %w", err)
+	}
+
+	archive.ConfigurePDF(cfg.Upload.PDFRasterizer, cfg.Upload.PDFDPI)
+	pdf := samplePDF()
+	a, err := archive.Open(ctx, bytes.NewReader(pdf), int64(len(pdf)))
+	if err != nil {
+		return fmt.Errorf("selfcheck: render pdf: %w", err)
+	}
+	defer a.Close()
+	if len(a.Files) != 1 {
+		return fmt.Errorf("selfcheck: render pdf: %d pages, want 1", len(a.Files))
+	}
+	rc, err := a.Files[0].Open()
+	if err != nil {
+		return fmt.Errorf("selfcheck: render pdf: %w", err)
+	}
+	rendered, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil {
+		return fmt.Errorf("selfcheck: render pdf: %w", err)
+	}
+	if err := checkProcess(ctx, images, rendered); err != nil {
+		return fmt.Errorf("selfcheck: process pdf page: %w", err)
+	}
+	return nil
+}
+
+func checkProcess(ctx context.Context, images *imageproc.Processor, src []byte) error {
+	res, err := images.Process(ctx, src)
+	if err != nil {
+		return err
+	}
+	for _, out := range [][]byte{res.Data, res.Thumbnail} {
+		if _, _, err := image.DecodeConfig(bytes.NewReader(out)); err != nil {
+			return fmt.Errorf("undecodable output: %w", err)
+		}
+	}
+	return nil
+}
+
+// samplePDF is a one-page blank PDF with a valid cross-reference table.
+func samplePDF() []byte {
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 200 300] >>",
+	}
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, o := range objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, o)
+	}
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return b.Bytes()
+}
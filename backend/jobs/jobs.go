@@ -367,6 +367,10 @@ var interestPointsMap = map[string]float64{
 	"comment_post":     4,
 	"bookmark_add":     8,
 	"bookmark_remove":  -3,
+	"list_add":         6,
+	"list_remove":      -2,
+	"rating_positive":  10,
+	"rating_negative":  -5,
 }
 
 var trendingPointsMap = map[string]float64{
@@ -0,0 +1,8 @@
+ALTER TABLE upload_tasks DROP COLUMN IF EXISTS results;
+
+-- Postgres cannot drop a single enum value, so rebuild the type without it.
+DELETE FROM upload_tasks WHERE type = 'series_zip';
+ALTER TYPE upload_task_type RENAME TO upload_task_type_old;
+CREATE TYPE upload_task_type AS ENUM ('zip', 'oneshot_zip');
+ALTER TABLE upload_tasks ALTER COLUMN type TYPE upload_task_type USING type::text::upload_task_type;
+DROP TYPE upload_task_type_old;
@@ -0,0 +1,5 @@
+ALTER TYPE upload_task_type ADD VALUE IF NOT EXISTS 'series_zip';
+
+-- Per-chapter outcome of a series_zip import, e.g.
+-- [{"folder": "Chapter 1", "number": 1, "chapter_id": "...", "page_count": 24}]
+ALTER TABLE upload_tasks ADD COLUMN results JSONB NOT NULL DEFAULT '[]';
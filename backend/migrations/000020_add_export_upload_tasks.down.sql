@@ -0,0 +1,6 @@
+-- Postgres cannot drop a single enum value, so rebuild the type without them.
+DELETE FROM upload_tasks WHERE type IN ('export_cbz', 'export_epub');
+ALTER TYPE upload_task_type RENAME TO upload_task_type_old;
+CREATE TYPE upload_task_type AS ENUM ('zip', 'oneshot_zip', 'series_zip');
+ALTER TABLE upload_tasks ALTER COLUMN type TYPE upload_task_type USING type::text::upload_task_type;
+DROP TYPE upload_task_type_old;
@@ -0,0 +1,4 @@
+-- Exports reuse the upload task machinery: s3_key holds the output object
+-- (exports/<task_id>/<slug>.cbz|.epub) instead of a staged upload.
+ALTER TYPE upload_task_type ADD VALUE IF NOT EXISTS 'export_cbz';
+ALTER TYPE upload_task_type ADD VALUE IF NOT EXISTS 'export_epub';
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_mangas_last_chapter_at;
+ALTER TABLE mangas DROP COLUMN IF EXISTS last_chapter_at;
@@ -0,0 +1,13 @@
+-- Time the newest published chapter (page_count > 0) was created; drives sort=updated.
+ALTER TABLE mangas ADD COLUMN last_chapter_at TIMESTAMPTZ;
+
+UPDATE mangas m SET last_chapter_at = c.latest
+FROM (
+    SELECT manga_id, MAX(created_at) AS latest
+    FROM chapters
+    WHERE page_count > 0
+    GROUP BY manga_id
+) c
+WHERE c.manga_id = m.id;
+
+CREATE INDEX idx_mangas_last_chapter_at ON mangas (last_chapter_at DESC NULLS LAST);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_chapters_latest;
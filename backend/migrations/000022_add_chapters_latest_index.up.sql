@@ -0,0 +1,2 @@
+-- Latest releases feed: published chapters, newest first, keyset-paginated on (created_at, id).
+CREATE INDEX idx_chapters_latest ON chapters (created_at DESC, id DESC) WHERE page_count > 0;
@@ -0,0 +1,8 @@
+ALTER TABLE users DROP COLUMN IF EXISTS preferred_language;
+
+-- Fails if a manga has the same chapter number in several languages; remove the extra editions first.
+ALTER TABLE chapters DROP CONSTRAINT IF EXISTS uq_chapter_manga_number_language;
+ALTER TABLE chapters ADD CONSTRAINT uq_chapter_manga_number UNIQUE (manga_id, number);
+
+ALTER TABLE chapters DROP COLUMN IF EXISTS language;
+ALTER TABLE chapters DROP COLUMN IF EXISTS volume;
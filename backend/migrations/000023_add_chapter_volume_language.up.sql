@@ -0,0 +1,8 @@
+-- Chapters can exist once per language: '' means the language is unspecified.
+ALTER TABLE chapters ADD COLUMN volume INT;
+ALTER TABLE chapters ADD COLUMN language TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE chapters DROP CONSTRAINT uq_chapter_manga_number;
+ALTER TABLE chapters ADD CONSTRAINT uq_chapter_manga_number_language UNIQUE (manga_id, number, language);
+
+ALTER TABLE users ADD COLUMN preferred_language TEXT NOT NULL DEFAULT '';
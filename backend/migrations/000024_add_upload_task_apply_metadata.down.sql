@@ -0,0 +1,2 @@
+ALTER TABLE upload_tasks DROP COLUMN IF EXISTS metadata_report;
+ALTER TABLE upload_tasks DROP COLUMN IF EXISTS apply_metadata;
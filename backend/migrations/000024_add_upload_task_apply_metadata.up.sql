@@ -0,0 +1,6 @@
+-- Opt-in: the worker merges the archive's metadata.json / ComicInfo.xml into the
+-- manga and chapter, and records what it changed and what conflicted, e.g.
+-- {"applied": [{"target": "manga", "field": "author", "old": "", "new": "Oda"}],
+--  "conflicts": [{"target": "chapter", "field": "number", "old": 3, "new": 2, "reason": "..."}]}
+ALTER TABLE upload_tasks ADD COLUMN apply_metadata BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE upload_tasks ADD COLUMN metadata_report JSONB;
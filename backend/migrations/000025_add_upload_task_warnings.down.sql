@@ -0,0 +1 @@
+ALTER TABLE upload_tasks DROP COLUMN IF EXISTS warnings;
@@ -0,0 +1,3 @@
+-- Archive problems found during import that did not fail the task: skipped
+-- macOS resource forks and hidden files, duplicate file names, manifest mismatches.
+ALTER TABLE upload_tasks ADD COLUMN warnings TEXT[] NOT NULL DEFAULT '{}';
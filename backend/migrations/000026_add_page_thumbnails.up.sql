@@ -0,0 +1,3 @@
+-- Pages are now transcoded to WebP on ingestion, with a small thumbnail for
+-- chapter scrubbers. Existing pages keep an empty key and have no thumbnail.
+ALTER TABLE pages ADD COLUMN thumbnail_key TEXT NOT NULL DEFAULT '';
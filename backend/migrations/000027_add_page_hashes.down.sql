@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_pages_phash;
+ALTER TABLE pages DROP COLUMN IF EXISTS phash;
+ALTER TABLE pages DROP COLUMN IF EXISTS sha256;
@@ -0,0 +1,8 @@
+-- Content fingerprints for duplicate detection: SHA-256 of the uploaded image
+-- bytes, and a 64-bit dHash perceptual hash (stored as its BIGINT bit pattern)
+-- that survives re-encoding and resizing. Pages stored earlier have none.
+ALTER TABLE pages ADD COLUMN sha256 TEXT NOT NULL DEFAULT '';
+ALTER TABLE pages ADD COLUMN phash BIGINT;
+
+-- Blank pages hash to 0 and would match each other everywhere.
+CREATE INDEX idx_pages_phash ON pages(phash) WHERE phash IS NOT NULL AND phash <> 0;
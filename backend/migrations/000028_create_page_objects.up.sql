@@ -0,0 +1,13 @@
+-- Page images are stored once per content under a key derived from the SHA-256
+-- of the uploaded bytes. ref_count is the number of pages using the object; the
+-- S3 objects are deleted when it drops to zero. Pages stored earlier keep their
+-- own per-page keys and are not tracked here.
+CREATE TABLE page_objects (
+    sha256        TEXT        PRIMARY KEY,
+    object_key    TEXT        NOT NULL,
+    thumbnail_key TEXT        NOT NULL,
+    width         INT         NOT NULL DEFAULT 0,
+    height        INT         NOT NULL DEFAULT 0,
+    ref_count     INT         NOT NULL DEFAULT 0,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
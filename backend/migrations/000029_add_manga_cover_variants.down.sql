@@ -0,0 +1 @@
+ALTER TABLE mangas DROP COLUMN IF EXISTS cover_variants;
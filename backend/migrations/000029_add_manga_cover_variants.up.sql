@@ -0,0 +1,4 @@
+-- Resized WebP renditions of the cover, [{"width": 160, "key": "..."}, ...]
+-- narrowest first. cover_key points at the largest one; covers stored earlier
+-- have none and keep their original image in cover_key.
+ALTER TABLE mangas ADD COLUMN cover_variants JSONB NOT NULL DEFAULT '[]';
@@ -0,0 +1,3 @@
+ALTER TABLE mangas DROP COLUMN IF EXISTS rating_count, DROP COLUMN IF EXISTS rating_avg;
+DROP TABLE IF EXISTS review_votes;
+DROP TABLE IF EXISTS ratings;
@@ -0,0 +1,28 @@
+-- One 1–10 score per user per manga, with an optional written review.
+-- helpful_count caches the review's rows in review_votes.
+CREATE TABLE ratings (
+    id            UUID        PRIMARY KEY,
+    user_id       UUID        NOT NULL REFERENCES users(id)  ON DELETE CASCADE,
+    manga_id      UUID        NOT NULL REFERENCES mangas(id) ON DELETE CASCADE,
+    score         SMALLINT    NOT NULL CHECK (score BETWEEN 1 AND 10),
+    review        TEXT        NOT NULL DEFAULT '' CHECK (char_length(review) <= 10000),
+    helpful_count INT         NOT NULL DEFAULT 0,
+    created_at    TIMESTAMPTZ NOT NULL,
+    updated_at    TIMESTAMPTZ NOT NULL,
+    UNIQUE (manga_id, user_id)
+);
+
+CREATE INDEX idx_ratings_user    ON ratings (user_id);
+CREATE INDEX idx_ratings_reviews ON ratings (manga_id, helpful_count DESC, created_at DESC) WHERE review <> '';
+
+CREATE TABLE review_votes (
+    rating_id  UUID        NOT NULL REFERENCES ratings(id) ON DELETE CASCADE,
+    user_id    UUID        NOT NULL REFERENCES users(id)   ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (rating_id, user_id)
+);
+
+-- Kept in step with ratings by the rating repository.
+ALTER TABLE mangas
+    ADD COLUMN rating_avg   DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN rating_count INT              NOT NULL DEFAULT 0;
@@ -0,0 +1,23 @@
+-- Named, ordered collections of manga. Private lists are visible to their owner only.
+CREATE TABLE reading_lists (
+    id          UUID        PRIMARY KEY,
+    user_id     UUID        NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name        TEXT        NOT NULL CHECK (char_length(name) BETWEEN 1 AND 100),
+    slug        TEXT        NOT NULL UNIQUE,
+    description TEXT        NOT NULL DEFAULT '',
+    is_public   BOOLEAN     NOT NULL DEFAULT FALSE,
+    created_at  TIMESTAMPTZ NOT NULL,
+    updated_at  TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_reading_lists_user ON reading_lists (user_id, created_at);
+
+CREATE TABLE reading_list_items (
+    list_id  UUID        NOT NULL REFERENCES reading_lists(id) ON DELETE CASCADE,
+    manga_id UUID        NOT NULL REFERENCES mangas(id)        ON DELETE CASCADE,
+    position INT         NOT NULL,
+    added_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (list_id, manga_id)
+);
+
+CREATE INDEX idx_reading_list_items_manga ON reading_list_items (manga_id);
@@ -0,0 +1,14 @@
+-- Per-chapter reading progress. progress only grows (0–100) and completed_at is
+-- kept once set, so re-reading a chapter from the start doesn't unread it.
+CREATE TABLE chapter_reads (
+    user_id          UUID        NOT NULL REFERENCES users(id)    ON DELETE CASCADE,
+    chapter_id       UUID        NOT NULL REFERENCES chapters(id) ON DELETE CASCADE,
+    manga_id         UUID        NOT NULL REFERENCES mangas(id)   ON DELETE CASCADE,
+    progress         SMALLINT    NOT NULL DEFAULT 0 CHECK (progress BETWEEN 0 AND 100),
+    last_page_number INT         NOT NULL DEFAULT 0,
+    completed_at     TIMESTAMPTZ,
+    updated_at       TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (user_id, chapter_id)
+);
+
+CREATE INDEX idx_chapter_reads_manga ON chapter_reads (user_id, manga_id);
@@ -0,0 +1,11 @@
+ALTER TABLE users DROP COLUMN IF EXISTS library_public;
+DROP INDEX IF EXISTS idx_bookmarks_user_status;
+DELETE FROM bookmarks WHERE chapter_id IS NULL;
+ALTER TABLE bookmarks
+    DROP COLUMN notes,
+    DROP COLUMN finished_at,
+    DROP COLUMN started_at,
+    DROP COLUMN score,
+    DROP COLUMN status,
+    ALTER COLUMN last_page_number SET DEFAULT 1,
+    ALTER COLUMN chapter_id SET NOT NULL;
@@ -0,0 +1,17 @@
+-- Bookmarks become library entries. A planned manga has no chapter yet, so
+-- chapter_id is optional; score is the user's own 1–10 score and notes
+-- are never shown to others.
+ALTER TABLE bookmarks
+    ALTER COLUMN chapter_id DROP NOT NULL,
+    ALTER COLUMN last_page_number SET DEFAULT 0,
+    ADD COLUMN status      TEXT     NOT NULL DEFAULT 'reading'
+        CHECK (status IN ('reading', 'plan_to_read', 'completed', 'dropped', 'on_hold')),
+    ADD COLUMN score       SMALLINT CHECK (score BETWEEN 1 AND 10),
+    ADD COLUMN started_at  DATE,
+    ADD COLUMN finished_at DATE,
+    ADD COLUMN notes       TEXT     NOT NULL DEFAULT '';
+
+CREATE INDEX idx_bookmarks_user_status ON bookmarks (user_id, status);
+
+-- Whether GET /users/:id/library is visible to others.
+ALTER TABLE users ADD COLUMN library_public BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS notification_jobs;
+DROP TABLE IF EXISTS notification_preferences;
+DROP TABLE IF EXISTS notifications;
+ALTER TABLE comments DROP COLUMN IF EXISTS parent_id;
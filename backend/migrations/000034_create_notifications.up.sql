@@ -0,0 +1,42 @@
+-- A comment may answer another comment in the same thread.
+ALTER TABLE comments ADD COLUMN parent_id UUID REFERENCES comments(id) ON DELETE SET NULL;
+
+CREATE TABLE notifications (
+    id         UUID        PRIMARY KEY,
+    user_id    UUID        NOT NULL REFERENCES users(id)    ON DELETE CASCADE,
+    type       TEXT        NOT NULL CHECK (type IN ('new_chapter', 'comment_reply', 'comment_mention')),
+    actor_id   UUID                 REFERENCES users(id)    ON DELETE SET NULL,
+    manga_id   UUID        NOT NULL REFERENCES mangas(id)   ON DELETE CASCADE,
+    chapter_id UUID                 REFERENCES chapters(id) ON DELETE CASCADE,
+    comment_id UUID                 REFERENCES comments(id) ON DELETE CASCADE,
+    read_at    TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+-- One notification per recipient and subject, so a retried fan-out job or a
+-- re-uploaded chapter never notifies twice.
+CREATE UNIQUE INDEX idx_notifications_subject ON notifications (user_id, type, COALESCE(comment_id, chapter_id));
+CREATE INDEX idx_notifications_user   ON notifications (user_id, created_at DESC);
+CREATE INDEX idx_notifications_unread ON notifications (user_id) WHERE read_at IS NULL;
+
+-- Only opt-outs are interesting; a missing row means the type is enabled.
+CREATE TABLE notification_preferences (
+    user_id UUID    NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type    TEXT    NOT NULL,
+    enabled BOOLEAN NOT NULL,
+    PRIMARY KEY (user_id, type)
+);
+
+-- Fan-out work queued by the API and the upload Lambda, and run by the API's
+-- dispatcher. Workers claim due rows with FOR UPDATE SKIP LOCKED.
+CREATE TABLE notification_jobs (
+    id         UUID        PRIMARY KEY,
+    type       TEXT        NOT NULL CHECK (type IN ('chapter_published', 'comment_created')),
+    subject_id UUID        NOT NULL,
+    attempts   INT         NOT NULL DEFAULT 0,
+    last_error TEXT        NOT NULL DEFAULT '',
+    run_at     TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_notification_jobs_run_at ON notification_jobs (run_at);
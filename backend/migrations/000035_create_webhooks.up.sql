@@ -0,0 +1,33 @@
+CREATE TABLE webhooks (
+    id         UUID        PRIMARY KEY,
+    user_id    UUID        NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    url        TEXT        NOT NULL,
+    secret     TEXT        NOT NULL,
+    events     TEXT[]      NOT NULL,
+    active     BOOLEAN     NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_webhooks_user   ON webhooks (user_id);
+CREATE INDEX idx_webhooks_events ON webhooks USING GIN (events) WHERE active;
+
+-- One row per webhook and event; it is also the retry queue. The dispatcher
+-- claims due pending rows with FOR UPDATE SKIP LOCKED.
+CREATE TABLE webhook_deliveries (
+    id              UUID        PRIMARY KEY,
+    webhook_id      UUID        NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+    event_id        UUID        NOT NULL,  -- shared by every delivery of one event
+    event           TEXT        NOT NULL,
+    payload         JSONB       NOT NULL,
+    status          TEXT        NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'succeeded', 'failed')),
+    attempts        INT         NOT NULL DEFAULT 0,
+    response_status INT,
+    last_error      TEXT        NOT NULL DEFAULT '',
+    next_attempt_at TIMESTAMPTZ NOT NULL,
+    delivered_at    TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_webhook_deliveries_webhook ON webhook_deliveries (webhook_id, created_at DESC);
+CREATE INDEX idx_webhook_deliveries_due     ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
@@ -0,0 +1,3 @@
+ALTER TABLE upload_tasks
+    DROP COLUMN IF EXISTS total,
+    DROP COLUMN IF EXISTS processed;
@@ -0,0 +1,5 @@
+-- Pages stored so far and pages in the archive, updated by the worker while it
+-- imports (series_zip: across all chapters). Both stay 0 for exports.
+ALTER TABLE upload_tasks
+    ADD COLUMN processed INT NOT NULL DEFAULT 0,
+    ADD COLUMN total     INT NOT NULL DEFAULT 0;
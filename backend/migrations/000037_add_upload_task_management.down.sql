@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS idx_upload_tasks_stuck;
+DROP INDEX IF EXISTS idx_upload_tasks_owner;
+ALTER TABLE upload_tasks DROP COLUMN IF EXISTS attempts;
+
+-- Postgres cannot drop a single enum value, so rebuild the type without it.
+UPDATE upload_tasks SET status = 'failed', error = 'cancelled' WHERE status = 'cancelled';
+ALTER TABLE upload_tasks ALTER COLUMN status DROP DEFAULT;
+ALTER TYPE upload_task_status RENAME TO upload_task_status_old;
+CREATE TYPE upload_task_status AS ENUM ('pending', 'processing', 'done', 'failed');
+ALTER TABLE upload_tasks ALTER COLUMN status TYPE upload_task_status USING status::text::upload_task_status;
+ALTER TABLE upload_tasks ALTER COLUMN status SET DEFAULT 'pending';
+DROP TYPE upload_task_status_old;
@@ -0,0 +1,9 @@
+-- A pending task can be cancelled by its owner before a worker claims it.
+ALTER TYPE upload_task_status ADD VALUE IF NOT EXISTS 'cancelled';
+
+-- How often a worker claimed the task. The reaper re-queues tasks stuck in
+-- processing until they reach UPLOAD__MAX_ATTEMPTS, then fails them.
+ALTER TABLE upload_tasks ADD COLUMN attempts INT NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_upload_tasks_owner ON upload_tasks (owner_id, created_at DESC);
+CREATE INDEX idx_upload_tasks_stuck ON upload_tasks (updated_at) WHERE status = 'processing';
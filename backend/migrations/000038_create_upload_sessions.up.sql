@@ -0,0 +1,25 @@
+-- Resumable (tus) archive uploads. Chunks are streamed into an S3 multipart
+-- upload; parts holds the completed parts and a tail shorter than one part is
+-- kept in the <s3_key>.partial object until the next chunk arrives.
+CREATE TABLE upload_sessions (
+    id             UUID             NOT NULL PRIMARY KEY,
+    owner_id       UUID             NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    task_type      upload_task_type NOT NULL,
+    manga_id       UUID             NOT NULL REFERENCES mangas(id) ON DELETE CASCADE,
+    chapter_id     UUID             REFERENCES chapters(id) ON DELETE CASCADE,
+    apply_metadata BOOLEAN          NOT NULL DEFAULT FALSE,
+    filename       TEXT             NOT NULL DEFAULT '',
+    size           BIGINT           NOT NULL,
+    upload_offset  BIGINT           NOT NULL DEFAULT 0,
+    s3_key         TEXT             NOT NULL,
+    s3_upload_id   TEXT             NOT NULL DEFAULT '', -- '' once the multipart upload is completed
+    parts          JSONB            NOT NULL DEFAULT '[]',
+    partial_size   BIGINT           NOT NULL DEFAULT 0,
+    task_id        UUID             REFERENCES upload_tasks(id) ON DELETE SET NULL,
+    locked_until   TIMESTAMPTZ,     -- held by the request writing a chunk
+    expires_at     TIMESTAMPTZ      NOT NULL,
+    created_at     TIMESTAMPTZ      NOT NULL DEFAULT NOW(),
+    updated_at     TIMESTAMPTZ      NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_upload_sessions_expires ON upload_sessions (expires_at);
@@ -0,0 +1,4 @@
+ALTER TABLE upload_sessions
+    DROP COLUMN IF EXISTS part_size,
+    DROP COLUMN IF EXISTS checksum,
+    DROP COLUMN IF EXISTS direct;
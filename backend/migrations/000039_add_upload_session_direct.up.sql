@@ -0,0 +1,7 @@
+-- Direct uploads: the client PUTs the archive to presigned S3 URLs (one
+-- object, or the parts of a multipart upload of part_size bytes) and the API
+-- only verifies the result against the declared size and SHA-256 checksum.
+ALTER TABLE upload_sessions
+    ADD COLUMN direct    BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN checksum  TEXT    NOT NULL DEFAULT '',
+    ADD COLUMN part_size BIGINT  NOT NULL DEFAULT 0;
@@ -0,0 +1,16 @@
+-- Upload task queue for deployments without SQS (queue.backend = postgres).
+-- Workers claim visible rows with FOR UPDATE SKIP LOCKED; visible_at hides a
+-- claimed message until its visibility timeout. Messages received too often
+-- are kept as 'dead' for inspection instead of being retried.
+CREATE TABLE queue_messages (
+    id         UUID        PRIMARY KEY,
+    body       JSONB       NOT NULL,
+    status     TEXT        NOT NULL DEFAULT 'ready' CHECK (status IN ('ready', 'dead')),
+    attempts   INT         NOT NULL DEFAULT 0,
+    last_error TEXT        NOT NULL DEFAULT '',
+    visible_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_queue_messages_visible ON queue_messages (visible_at) WHERE status = 'ready';
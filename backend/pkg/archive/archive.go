@@ -0,0 +1,271 @@
+// Package archive opens every upload format we accept — zip/cbz, rar/cbr, 7z,
+// tar(.gz) and PDF — behind a single file listing. The format is chosen by
+// sniffing the content, never by the file extension.
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/bodgit/sevenzip"
+	"github.com/nwaples/rardecode/v2"
+)
+
+type Format string
+
+const (
+	Zip      Format = "zip"
+	RAR      Format = "rar"
+	SevenZip Format = "7z"
+	Tar      Format = "tar"
+	TarGz    Format = "tar.gz"
+	PDF      Format = "pdf"
+)
+
+// Limits on what one archive may expand to, so a small upload can't exhaust
+// the worker's disk or time (a zip bomb, a PDF of thousands of pages).
+const (
+	maxFiles         = 10000
+	maxExtractedSize = 8 << 30
+	maxPDFPages      = 2000
+	pdfTimeout       = 10 * time.Minute
+)
+
+var (
+	// ErrUnsupported is returned by Open when the content matches no known format.
+	ErrUnsupported = errors.New("archive: unsupported format")
+	// ErrTooLarge is returned by Open for archives over the file count or
+	// extracted size limit, and PDFs over the page limit.
+	ErrTooLarge = errors.New("archive: too many files or too large")
+)
+
+// ContentType is the MIME type used when storing an archive of this format.
+func (f Format) ContentType() string {
+	switch f {
+	case Zip:
+		return "application/zip"
+	case RAR:
+		return "application/vnd.rar"
+	case SevenZip:
+		return "application/x-7z-compressed"
+	case Tar:
+		return "application/x-tar"
+	case TarGz:
+		return "application/gzip"
+	case PDF:
+		return "application/pdf"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// File is a regular file inside an archive. Name is a cleaned, slash-separated
+// path relative to the archive root.
+type File struct {
+	Name string
+	Size int64
+	open func() (io.ReadCloser, error)
+}
+
+func (f *File) Open() (io.ReadCloser, error) { return f.open() }
+
+// Archive is an opened archive. Close releases any temporary files.
+type Archive struct {
+	Format Format
+	Files  []*File // regular files only, in archive order
+
+	cleanup func() error
+}
+
+func (a *Archive) Close() error {
+	if a.cleanup == nil {
+		return nil
+	}
+	return a.cleanup()
+}
+
+var signatures = []struct {
+	format Format
+	offset int
+	magic  []byte
+}{
+	{Zip, 0, []byte("PK\x03\x04")},
+	{Zip, 0, []byte("PK\x05\x06")}, // empty archive
+	{RAR, 0, []byte("Rar!\x1a\x07")},
+	{SevenZip, 0, []byte("7z\xbc\xaf\x27\x1c")},
+	{PDF, 0, []byte("%PDF-")},
+	{TarGz, 0, []byte("\x1f\x8b")},
+	{Tar, 257, []byte("ustar")},
+}
+
+// Detect sniffs the format from the first bytes of r. Returns "" when unknown.
+func Detect(r io.ReaderAt) Format {
+	head := make([]byte, 512)
+	n, _ := r.ReadAt(head, 0)
+	head = head[:n]
+	for _, s := range signatures {
+		if len(head) >= s.offset+len(s.magic) && bytes.Equal(head[s.offset:s.offset+len(s.magic)], s.magic) {
+			return s.format
+		}
+	}
+	return ""
+}
+
+// DetectFile sniffs the format of a file inside an archive (e.g. a nested chapter archive).
+func DetectFile(f *File) Format {
+	rc, err := f.Open()
+	if err != nil {
+		return ""
+	}
+	defer rc.Close()
+	head, _ := io.ReadAll(io.LimitReader(rc, 512))
+	return Detect(bytes.NewReader(head))
+}
+
+// Open sniffs r and lists its files. Zip archives are read in place; the
+// other formats are extracted to a temporary directory, so Close must be called.
+// ctx bounds PDF rendering.
+func Open(ctx context.Context, r io.ReaderAt, size int64) (*Archive, error) {
+	switch Detect(r) {
+	case Zip:
+		return openZip(r, size)
+	case RAR:
+		return openRAR(r, size)
+	case SevenZip:
+		return open7z(r, size)
+	case Tar:
+		return openTar(io.NewSectionReader(r, 0, size), Tar)
+	case TarGz:
+		gz, err := gzip.NewReader(io.NewSectionReader(r, 0, size))
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		return openTar(gz, TarGz)
+	case PDF:
+		return openPDF(ctx, r, size)
+	default:
+		return nil, ErrUnsupported
+	}
+}
+
+func openZip(r io.ReaderAt, size int64) (*Archive, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	a := &Archive{Format: Zip}
+	var total uint64
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		name, ok := cleanName(f.Name)
+		if !ok {
+			continue
+		}
+		total += f.UncompressedSize64
+		if len(a.Files) >= maxFiles || total > maxExtractedSize {
+			return nil, ErrTooLarge
+		}
+		a.Files = append(a.Files, &File{Name: name, Size: int64(f.UncompressedSize64), open: f.Open})
+	}
+	return a, nil
+}
+
+func openRAR(r io.ReaderAt, size int64) (*Archive, error) {
+	rr, err := rardecode.NewReader(io.NewSectionReader(r, 0, size))
+	if err != nil {
+		return nil, err
+	}
+	sp, err := newSpool()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		h, err := rr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			sp.remove()
+			return nil, err
+		}
+		if h.IsDir {
+			continue
+		}
+		if err := sp.add(h.Name, rr); err != nil {
+			sp.remove()
+			return nil, err
+		}
+	}
+	return sp.archive(RAR), nil
+}
+
+// open7z extracts in archive order: solid 7z blocks are cheap to read
+// sequentially but expensive to seek into, so files are not opened lazily.
+func open7z(r io.ReaderAt, size int64) (*Archive, error) {
+	zr, err := sevenzip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	sp, err := newSpool()
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		if err := sp.addFunc(f.Name, f.Open); err != nil {
+			sp.remove()
+			return nil, err
+		}
+	}
+	return sp.archive(SevenZip), nil
+}
+
+func openTar(r io.Reader, format Format) (*Archive, error) {
+	tr := tar.NewReader(r)
+	sp, err := newSpool()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			sp.remove()
+			return nil, err
+		}
+		if h.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err := sp.add(h.Name, tr); err != nil {
+			sp.remove()
+			return nil, err
+		}
+	}
+	return sp.archive(format), nil
+}
+
+// cleanName normalises an entry name to a relative slash path ("./a\b.jpg" → "a/b.jpg").
+// ".." segments cannot climb above the archive root.
+func cleanName(name string) (string, bool) {
+	name = path.Clean("/" + strings.ReplaceAll(name, "\\", "/"))
+	name = strings.TrimPrefix(name, "/")
+	if name == "" || name == "." {
+		return "", false
+	}
+	return name, true
+}
@@ -0,0 +1,193 @@
+package archive_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/yumikokawaii/sherry-archive/pkg/archive"
+)
+
+var pages = map[string]string{
+	"./Chapter 1/001.jpg": "one",
+	"Chapter 1/002.jpg":   "two",
+}
+
+func zipBytes(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range []string{"./Chapter 1/001.jpg", "Chapter 1/002.jpg"} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.WriteString(w, pages[name])
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func tarBytes(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	tw.WriteHeader(&tar.Header{Name: "Chapter 1/", Typeflag: tar.TypeDir, Mode: 0o755})
+	for _, name := range []string{"./Chapter 1/001.jpg", "Chapter 1/002.jpg"} {
+		body := pages[name]
+		if err := tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(body))}); err != nil {
+			t.Fatal(err)
+		}
+		io.WriteString(tw, body)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func gzipBytes(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	gw.Write(data)
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want archive.Format
+	}{
+		{"zip", zipBytes(t), archive.Zip},
+		{"tar", tarBytes(t), archive.Tar},
+		{"tar.gz", gzipBytes(t, tarBytes(t)), archive.TarGz},
+		{"rar5", []byte("Rar!\x1a\x07\x01\x00rest"), archive.RAR},
+		{"7z", []byte("7z\xbc\xaf\x27\x1crest"), archive.SevenZip},
+		{"pdf", []byte("%PDF-1.7\n"), archive.PDF},
+		{"jpeg", []byte("\xff\xd8\xff\xe0"), ""},
+		{"empty", nil, ""},
+	}
+	for _, tt := range tests {
+		if got := archive.Detect(bytes.NewReader(tt.data)); got != tt.want {
+			t.Errorf("Detect(%s) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestOpen(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want archive.Format
+	}{
+		{"zip", zipBytes(t), archive.Zip},
+		{"tar", tarBytes(t), archive.Tar},
+		{"tar.gz", gzipBytes(t, tarBytes(t)), archive.TarGz},
+	}
+	for _, tt := range tests {
+		a, err := archive.Open(context.Background(), bytes.NewReader(tt.data), int64(len(tt.data)))
+		if err != nil {
+			t.Fatalf("Open(%s): %v", tt.name, err)
+		}
+		if a.Format != tt.want {
+			t.Errorf("Open(%s).Format = %q, want %q", tt.name, a.Format, tt.want)
+		}
+		if len(a.Files) != 2 {
+			t.Fatalf("Open(%s) listed %d files, want 2", tt.name, len(a.Files))
+		}
+		for i, want := range []struct{ name, body string }{{"Chapter 1/001.jpg", "one"}, {"Chapter 1/002.jpg", "two"}} {
+			f := a.Files[i]
+			if f.Name != want.name {
+				t.Errorf("Open(%s) file %d = %q, want %q", tt.name, i, f.Name, want.name)
+			}
+			rc, err := f.Open()
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, _ := io.ReadAll(rc)
+			rc.Close()
+			if string(body) != want.body {
+				t.Errorf("Open(%s) %s = %q, want %q", tt.name, f.Name, body, want.body)
+			}
+		}
+		if err := a.Close(); err != nil {
+			t.Errorf("Close(%s): %v", tt.name, err)
+		}
+	}
+}
+
+func TestOpenUnsupported(t *testing.T) {
+	data := []byte("not an archive")
+	if _, err := archive.Open(context.Background(), bytes.NewReader(data), int64(len(data))); err != archive.ErrUnsupported {
+		t.Errorf("Open(text) error = %v, want ErrUnsupported", err)
+	}
+}
+
+func TestOpenTooManyFiles(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for i := range 10001 {
+		if _, err := zw.Create(fmt.Sprintf("%05d.jpg", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	if _, err := archive.Open(context.Background(), bytes.NewReader(data), int64(len(data))); !errors.Is(err, archive.ErrTooLarge) {
+		t.Errorf("Open(10001 files) error = %v, want ErrTooLarge", err)
+	}
+}
+
+func TestOpenNested(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create("Chapter 1.cbt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write(tarBytes(t))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	outer, err := archive.Open(context.Background(), bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer outer.Close()
+
+	a, err := archive.OpenNested(context.Background(), outer.Files[0])
+	if err != nil {
+		t.Fatalf("OpenNested: %v", err)
+	}
+	if a.Format != archive.Tar || len(a.Files) != 2 {
+		t.Fatalf("OpenNested = %s with %d files, want tar with 2", a.Format, len(a.Files))
+	}
+	rc, err := a.Files[1].Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(rc)
+	rc.Close()
+	if string(body) != "two" {
+		t.Errorf("OpenNested %s = %q, want %q", a.Files[1].Name, body, "two")
+	}
+	if err := a.Close(); err != nil {
+		t.Errorf("Close: %v", err)
+	}
+}
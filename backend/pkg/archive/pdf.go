@@ -0,0 +1,107 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+// PDF pages are rendered to JPEG by poppler's pdftoppm, which must be installed
+// wherever uploads are processed. Override with ConfigurePDF.
+var (
+	pdfRasterizer = "pdftoppm"
+	pdfDPI        = 150
+)
+
+// ConfigurePDF sets the rasterizer binary and render resolution used for PDF uploads.
+// Zero values keep the defaults.
+func ConfigurePDF(rasterizer string, dpi int) {
+	if rasterizer != "" {
+		pdfRasterizer = rasterizer
+	}
+	if dpi > 0 {
+		pdfDPI = dpi
+	}
+}
+
+// openPDF rasterizes every page into 0001.jpg, 0002.jpg, … so a PDF behaves
+// like an archive of page images. Rendering is stopped when ctx is done or
+// after pdfTimeout.
+func openPDF(ctx context.Context, r io.ReaderAt, size int64) (*Archive, error) {
+	sp, err := newSpool()
+	if err != nil {
+		return nil, err
+	}
+	src := filepath.Join(sp.dir, "source.pdf")
+	if err := writeFile(src, io.NewSectionReader(r, 0, size)); err != nil {
+		sp.remove()
+		return nil, err
+	}
+
+	outDir := filepath.Join(sp.dir, "pages")
+	if err := os.Mkdir(outDir, 0o700); err != nil {
+		sp.remove()
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, pdfTimeout)
+	defer cancel()
+	// One page past the limit is rendered to tell a PDF at the limit from a longer one.
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, pdfRasterizer, "-jpeg", "-r", strconv.Itoa(pdfDPI), "-l", strconv.Itoa(maxPDFPages+1),
+		src, filepath.Join(outDir, "page"))
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		sp.remove()
+		return nil, fmt.Errorf("archive: rasterize pdf: %w: %s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	_ = os.Remove(src)
+
+	// pdftoppm zero-pads page numbers to the width of the page count, so a
+	// lexical sort is page order.
+	rendered, err := os.ReadDir(outDir)
+	if err != nil {
+		sp.remove()
+		return nil, err
+	}
+	if len(rendered) > maxPDFPages {
+		sp.remove()
+		return nil, ErrTooLarge
+	}
+	sort.Slice(rendered, func(i, j int) bool { return rendered[i].Name() < rendered[j].Name() })
+	for i, e := range rendered {
+		p := filepath.Join(outDir, e.Name())
+		info, err := e.Info()
+		if err != nil {
+			sp.remove()
+			return nil, err
+		}
+		if sp.size += info.Size(); sp.size > maxExtractedSize {
+			sp.remove()
+			return nil, ErrTooLarge
+		}
+		sp.files = append(sp.files, &File{
+			Name: fmt.Sprintf("%04d.jpg", i+1),
+			Size: info.Size(),
+			open: func() (io.ReadCloser, error) { return os.Open(p) },
+		})
+	}
+	return sp.archive(PDF), nil
+}
+
+func writeFile(name string, r io.Reader) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,119 @@
+package archive
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// spool extracts sequential-access archives (rar, 7z, tar) to a temporary
+// directory so their files can be reopened in any order and in parallel.
+type spool struct {
+	dir   string
+	files []*File
+	size  int64 // bytes extracted so far
+}
+
+func newSpool() (*spool, error) {
+	dir, err := os.MkdirTemp("", "archive-*")
+	if err != nil {
+		return nil, err
+	}
+	return &spool{dir: dir}, nil
+}
+
+// add copies r to disk as the next file. Files are stored under their index,
+// never under the archive-supplied name.
+func (s *spool) add(name string, r io.Reader) error {
+	name, ok := cleanName(name)
+	if !ok {
+		return nil
+	}
+	if len(s.files) >= maxFiles {
+		return ErrTooLarge
+	}
+	p := s.path(len(s.files))
+	out, err := os.Create(p)
+	if err != nil {
+		return err
+	}
+	n, err := io.Copy(out, io.LimitReader(r, maxExtractedSize-s.size+1))
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	if s.size += n; s.size > maxExtractedSize {
+		return ErrTooLarge
+	}
+	s.files = append(s.files, &File{
+		Name: name,
+		Size: n,
+		open: func() (io.ReadCloser, error) { return os.Open(p) },
+	})
+	return nil
+}
+
+func (s *spool) addFunc(name string, open func() (io.ReadCloser, error)) error {
+	rc, err := open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return s.add(name, rc)
+}
+
+func (s *spool) path(i int) string { return filepath.Join(s.dir, strconv.Itoa(i)) }
+
+func (s *spool) remove() { _ = os.RemoveAll(s.dir) }
+
+func (s *spool) archive(format Format) *Archive {
+	return &Archive{
+		Format:  format,
+		Files:   s.files,
+		cleanup: func() error { return os.RemoveAll(s.dir) },
+	}
+}
+
+// OpenNested opens f, an archive inside another one (a chapter cbz in a series
+// archive). f is copied to a temporary file rather than into memory, within the
+// same size limit as extracted files; Close removes it.
+func OpenNested(ctx context.Context, f *File) (*Archive, error) {
+	sp, err := newSpool()
+	if err != nil {
+		return nil, err
+	}
+	if err := sp.addFunc(f.Name, f.open); err != nil {
+		sp.remove()
+		return nil, err
+	}
+	if len(sp.files) == 0 {
+		sp.remove()
+		return nil, ErrUnsupported
+	}
+	src, err := os.Open(sp.path(0))
+	if err != nil {
+		sp.remove()
+		return nil, err
+	}
+	a, err := Open(ctx, src, sp.size)
+	if err != nil {
+		src.Close()
+		sp.remove()
+		return nil, err
+	}
+	cleanup := a.cleanup
+	a.cleanup = func() error {
+		var err error
+		if cleanup != nil {
+			err = cleanup()
+		}
+		src.Close()
+		sp.remove()
+		return err
+	}
+	return a, nil
+}
@@ -0,0 +1,228 @@
+// Package imageproc prepares uploaded page images for storage: it reads their
+// dimensions, re-encodes them to WebP and renders a small thumbnail. Encoding is
+// done by libwebp's cwebp, which must be installed wherever pages are ingested
+// (the API for direct uploads, the worker for archives).
+package imageproc
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"os/exec"
+	"sort"
+	"strconv"
+
+	"github.com/yumikokawaii/sherry-archive/pkg/phash"
+	_ "golang.org/x/image/webp"
+)
+
+// maxWebPDimension is the largest width or height a WebP image can have.
+// Taller images (long webtoon strips) are kept in their original format.
+const maxWebPDimension = 16383
+
+var (
+	// ErrUnsupported is returned when the bytes are not a decodable image.
+	ErrUnsupported = errors.New("imageproc: unsupported image")
+	// ErrBadCrop is returned for a crop rectangle outside the image.
+	ErrBadCrop = errors.New("imageproc: crop outside the image")
+)
+
+type Config struct {
+	// Encoder is the cwebp binary. Default: "cwebp" (from PATH).
+	Encoder string
+	// Quality is the WebP quality for pages, 0–100. Default: 80.
+	Quality int
+	// ThumbnailWidth is the thumbnail width in pixels; the height keeps the aspect ratio. Default: 160.
+	ThumbnailWidth int
+	// ThumbnailQuality is the WebP quality for thumbnails. Default: 50.
+	ThumbnailQuality int
+	// Workers bounds how many images of one upload are processed concurrently. Default: 4.
+	Workers int
+	// CoverWidths are the widths of the cover variants, in pixels. Default: 160, 320, 640.
+	CoverWidths []int
+	// CoverQuality is the WebP quality for covers. Default: 80.
+	CoverQuality int
+}
+
+type Processor struct {
+	cfg Config
+}
+
+// New returns a Processor; zero Config fields keep their defaults.
+func New(cfg Config) *Processor {
+	if cfg.Encoder == "" {
+		cfg.Encoder = "cwebp"
+	}
+	if cfg.Quality <= 0 || cfg.Quality > 100 {
+		cfg.Quality = 80
+	}
+	if cfg.ThumbnailWidth <= 0 {
+		cfg.ThumbnailWidth = 160
+	}
+	if cfg.ThumbnailQuality <= 0 || cfg.ThumbnailQuality > 100 {
+		cfg.ThumbnailQuality = 50
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = 4
+	}
+	if len(cfg.CoverWidths) == 0 {
+		cfg.CoverWidths = []int{160, 320, 640}
+	}
+	if cfg.CoverQuality <= 0 || cfg.CoverQuality > 100 {
+		cfg.CoverQuality = 80
+	}
+	return &Processor{cfg: cfg}
+}
+
+// Workers is the configured concurrency for processing one upload.
+func (p *Processor) Workers() int {
+	return p.cfg.Workers
+}
+
+// Result is a processed page. Data is WebP unless the image is too large for
+// WebP, in which case it is the original bytes; MIME and Ext describe Data.
+// The thumbnail is always WebP.
+type Result struct {
+	Width     int
+	Height    int
+	Data      []byte
+	MIME      string
+	Ext       string
+	Thumbnail []byte
+}
+
+// Process decodes src (JPEG, PNG or WebP) and encodes the page and its thumbnail.
+func (p *Processor) Process(ctx context.Context, src []byte) (*Result, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(src))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupported, err)
+	}
+	res := &Result{Width: cfg.Width, Height: cfg.Height}
+
+	if cfg.Width > maxWebPDimension || cfg.Height > maxWebPDimension {
+		res.Data, res.MIME, res.Ext = src, "image/"+format, "."+format
+		if format == "jpeg" {
+			res.Ext = ".jpg"
+		}
+	} else {
+		if res.Data, err = p.encode(ctx, src, p.cfg.Quality, 0, 0, nil); err != nil {
+			return nil, err
+		}
+		res.MIME, res.Ext = "image/webp", ".webp"
+	}
+
+	width, height := 0, 0
+	if cfg.Width > p.cfg.ThumbnailWidth {
+		width = p.cfg.ThumbnailWidth
+	}
+	thumbHeight := cfg.Height
+	if width > 0 {
+		thumbHeight = cfg.Height * width / cfg.Width
+	}
+	// Long strips would still be too tall for WebP at the thumbnail width.
+	if thumbHeight > maxWebPDimension {
+		width, height = 0, maxWebPDimension
+	}
+	if res.Thumbnail, err = p.encode(ctx, src, p.cfg.ThumbnailQuality, width, height, nil); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Crop is a rectangle of the source image, in pixels from its top-left corner.
+type Crop struct {
+	X, Y          int
+	Width, Height int
+}
+
+// Variant is an encoded WebP rendition Width pixels wide.
+type Variant struct {
+	Width int
+	Data  []byte
+}
+
+// Cover crops src (the whole image when crop is nil) and encodes a WebP variant
+// per configured cover width, narrowest first. Images are never upscaled: widths
+// beyond the crop collapse into one variant at the crop's own width.
+func (p *Processor) Cover(ctx context.Context, src []byte, crop *Crop) ([]Variant, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(src))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupported, err)
+	}
+	full := image.Rect(0, 0, cfg.Width, cfg.Height)
+	if crop != nil {
+		r := image.Rect(crop.X, crop.Y, crop.X+crop.Width, crop.Y+crop.Height)
+		if crop.Width <= 0 || crop.Height <= 0 || !r.In(full) {
+			return nil, fmt.Errorf("%w: %v not in %v", ErrBadCrop, r, full)
+		}
+		full = r
+	}
+
+	widths := append([]int(nil), p.cfg.CoverWidths...)
+	sort.Ints(widths)
+	var variants []Variant
+	for _, w := range widths {
+		if w > full.Dx() {
+			w = full.Dx()
+		}
+		if len(variants) > 0 && variants[len(variants)-1].Width >= w {
+			continue
+		}
+		resize := w
+		if w == full.Dx() {
+			resize = 0
+		}
+		data, err := p.encode(ctx, src, p.cfg.CoverQuality, resize, 0, crop)
+		if err != nil {
+			return nil, err
+		}
+		variants = append(variants, Variant{Width: w, Data: data})
+	}
+	return variants, nil
+}
+
+// encode runs cwebp over src, cropping it first when crop is set and scaling to
+// width or height (keeping the aspect ratio) when one is non-zero.
+func (p *Processor) encode(ctx context.Context, src []byte, quality, width, height int, crop *Crop) ([]byte, error) {
+	args := []string{"-quiet", "-metadata", "none", "-q", strconv.Itoa(quality)}
+	if crop != nil {
+		args = append(args, "-crop", strconv.Itoa(crop.X), strconv.Itoa(crop.Y), strconv.Itoa(crop.Width), strconv.Itoa(crop.Height))
+	}
+	if width > 0 || height > 0 {
+		args = append(args, "-resize", strconv.Itoa(width), strconv.Itoa(height))
+	}
+	args = append(args, "-o", "-", "--", "-")
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.cfg.Encoder, args...)
+	cmd.Stdin = bytes.NewReader(src)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("imageproc: encode webp: %w: %s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// Fingerprint identifies an image's content: SHA256 (hex) of the uploaded bytes
+// for exact copies, PHash (see phash.DHash) for re-encoded or resized ones.
+type Fingerprint struct {
+	SHA256 string
+	PHash  uint64
+}
+
+// Hash decodes src and fingerprints it.
+func Hash(src []byte) (Fingerprint, error) {
+	img, _, err := image.Decode(bytes.NewReader(src))
+	if err != nil {
+		return Fingerprint{}, fmt.Errorf("%w: %v", ErrUnsupported, err)
+	}
+	sum := sha256.Sum256(src)
+	return Fingerprint{SHA256: hex.EncodeToString(sum[:]), PHash: phash.DHash(img)}, nil
+}
@@ -0,0 +1,72 @@
+package imageproc_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yumikokawaii/sherry-archive/pkg/imageproc"
+)
+
+// pngHeader returns the signature and IHDR chunk of a w×h PNG: enough for
+// image.DecodeConfig, without allocating the pixels.
+func pngHeader(w, h int) []byte {
+	var ihdr bytes.Buffer
+	ihdr.WriteString("IHDR")
+	binary.Write(&ihdr, binary.BigEndian, uint32(w))
+	binary.Write(&ihdr, binary.BigEndian, uint32(h))
+	ihdr.Write([]byte{8, 0, 0, 0, 0}) // 8-bit grayscale
+
+	var b bytes.Buffer
+	b.WriteString("\x89PNG\r\n\x1a\n")
+	binary.Write(&b, binary.BigEndian, uint32(ihdr.Len()-4))
+	b.Write(ihdr.Bytes())
+	binary.Write(&b, binary.BigEndian, crc32.ChecksumIEEE(ihdr.Bytes()))
+	return b.Bytes()
+}
+
+// fakeEncoder is a cwebp stand-in that outputs its arguments.
+func fakeEncoder(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "cwebp")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\necho \"$@\"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestProcessThumbnailSize(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		resize        string // cwebp -resize arguments; "" for none
+	}{
+		{"page", 1200, 1800, "-resize 160 0"},
+		{"narrow page", 100, 1800, ""},
+		{"long strip", 800, 40000, "-resize 160 0"},
+		{"very long strip", 800, 100000, "-resize 0 16383"},
+		{"narrow long strip", 100, 20000, "-resize 0 16383"},
+	}
+	p := imageproc.New(imageproc.Config{Encoder: fakeEncoder(t), ThumbnailWidth: 160})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := p.Process(context.Background(), pngHeader(tt.width, tt.height))
+			if err != nil {
+				t.Fatal(err)
+			}
+			args := string(res.Thumbnail)
+			if tt.resize == "" {
+				if strings.Contains(args, "-resize") {
+					t.Errorf("thumbnail args %q, want no -resize", args)
+				}
+			} else if !strings.Contains(args, tt.resize) {
+				t.Errorf("thumbnail args %q, want %q", args, tt.resize)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+// Package natsort orders strings the way people expect file names to sort:
+// runs of digits compare by numeric value, so "2.jpg" comes before "10.jpg".
+package natsort
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Compare returns -1, 0 or +1 depending on whether a sorts before, equal to or
+// after b. Letters compare case-insensitively, and '/' sorts before any other
+// character so that paths are ordered directory by directory. Strings that only
+// differ in case or leading zeros fall back to a byte-wise comparison, which
+// keeps the order total.
+func Compare(a, b string) int {
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if isDigit(a[i]) && isDigit(b[j]) {
+			ei, ej := digitsEnd(a, i), digitsEnd(b, j)
+			if c := compareNumbers(a[i:ei], b[j:ej]); c != 0 {
+				return c
+			}
+			i, j = ei, ej
+			continue
+		}
+		ra, sa := utf8.DecodeRuneInString(a[i:])
+		rb, sb := utf8.DecodeRuneInString(b[j:])
+		if c := compareRunes(ra, rb); c != 0 {
+			return c
+		}
+		i, j = i+sa, j+sb
+	}
+	switch {
+	case i == len(a) && j < len(b):
+		return -1
+	case i < len(a) && j == len(b):
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// Less reports whether a sorts before b, for use with sort.Slice.
+func Less(a, b string) bool {
+	return Compare(a, b) < 0
+}
+
+func isDigit(c byte) bool { return '0' <= c && c <= '9' }
+
+func digitsEnd(s string, i int) int {
+	for i < len(s) && isDigit(s[i]) {
+		i++
+	}
+	return i
+}
+
+// compareNumbers compares two digit runs by value, without parsing them, so
+// arbitrarily long runs work.
+func compareNumbers(x, y string) int {
+	x, y = strings.TrimLeft(x, "0"), strings.TrimLeft(y, "0")
+	if len(x) != len(y) {
+		if len(x) < len(y) {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(x, y)
+}
+
+func compareRunes(a, b rune) int {
+	switch {
+	case a == b:
+		return 0
+	case a == '/':
+		return -1
+	case b == '/':
+		return 1
+	}
+	la, lb := unicode.ToLower(a), unicode.ToLower(b)
+	switch {
+	case la < lb:
+		return -1
+	case la > lb:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,44 @@
+package natsort_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/yumikokawaii/sherry-archive/pkg/natsort"
+)
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"2.jpg", "10.jpg", -1},
+		{"10.jpg", "2.jpg", 1},
+		{"page2.png", "page10.png", -1},
+		{"002.jpg", "2.jpg", -1}, // equal value: byte-wise tiebreak
+		{"Page1.jpg", "page2.jpg", -1},
+		{"a.jpg", "a.jpg", 0},
+		{"ch1/10.jpg", "ch1/9.jpg", 1},
+		{"ch1/001.jpg", "ch1 extra/001.jpg", -1},
+		{"ch2/001.jpg", "ch10/001.jpg", -1},
+		{"1.5.jpg", "1.10.jpg", -1},
+		{"img", "img1", -1},
+		{"99999999999999999999999.jpg", "100000000000000000000000.jpg", -1},
+	}
+
+	for _, tt := range tests {
+		if got := natsort.Compare(tt.a, tt.b); got != tt.expected {
+			t.Errorf("Compare(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.expected)
+		}
+	}
+}
+
+func TestSort(t *testing.T) {
+	names := []string{"10.jpg", "b/1.jpg", "1.jpg", "a/2.jpg", "2.jpg", "a/10.jpg", "a/1.jpg"}
+	expected := []string{"1.jpg", "2.jpg", "10.jpg", "a/1.jpg", "a/2.jpg", "a/10.jpg", "b/1.jpg"}
+
+	slices.SortFunc(names, natsort.Compare)
+	if !slices.Equal(names, expected) {
+		t.Errorf("sorted = %v, want %v", names, expected)
+	}
+}
@@ -0,0 +1,66 @@
+// Package phash computes dHash perceptual hashes: 64-bit fingerprints that stay
+// equal, or within a few bits, when an image is re-encoded, resized or slightly
+// recompressed — unlike a cryptographic hash of its bytes.
+package phash
+
+import (
+	"image"
+	"image/color"
+	"math/bits"
+)
+
+const (
+	gridW = 9 // 8 horizontal gradients per row
+	gridH = 8
+	// samples is the number of pixels read per grid cell along each axis, so
+	// large pages are hashed at a fixed cost.
+	samples = 16
+)
+
+// DHash returns the difference hash of img: the image is reduced to a 9×8
+// grayscale grid and each bit records whether a cell is brighter than its right
+// neighbour. A blank (uniform) image hashes to 0.
+func DHash(img image.Image) uint64 {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w == 0 || h == 0 {
+		return 0
+	}
+
+	var lum [gridH][gridW]float64
+	for cy := 0; cy < gridH; cy++ {
+		for cx := 0; cx < gridW; cx++ {
+			var sum float64
+			for sy := 0; sy < samples; sy++ {
+				y := b.Min.Y + (2*(cy*samples+sy)+1)*h/(2*gridH*samples)
+				for sx := 0; sx < samples; sx++ {
+					x := b.Min.X + (2*(cx*samples+sx)+1)*w/(2*gridW*samples)
+					sum += luminance(img.At(x, y))
+				}
+			}
+			lum[cy][cx] = sum
+		}
+	}
+
+	var hash uint64
+	for y := 0; y < gridH; y++ {
+		for x := 0; x < gridW-1; x++ {
+			hash <<= 1
+			if lum[y][x] > lum[y][x+1] {
+				hash |= 1
+			}
+		}
+	}
+	return hash
+}
+
+// Distance is the Hamming distance between two hashes: 0 for the same picture,
+// typically ≤ 5 for re-encodes of it, and around 32 for unrelated images.
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+func luminance(c color.Color) float64 {
+	r, g, b, _ := c.RGBA()
+	return 0.299*float64(r) + 0.587*float64(g) + 0.114*float64(b)
+}
@@ -0,0 +1,68 @@
+package phash_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/yumikokawaii/sherry-archive/pkg/phash"
+	"golang.org/x/image/draw"
+)
+
+// gradient draws a diagonal gradient; flip mirrors it horizontally.
+func gradient(w, h int, flip bool) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			v := (x*255/w + y*255/h) / 2
+			if flip {
+				v = ((w-1-x)*255/w + y*255/h) / 2
+			}
+			img.SetGray(x, y, color.Gray{Y: uint8(v)})
+		}
+	}
+	return img
+}
+
+func TestDHashStableUnderResize(t *testing.T) {
+	src := gradient(900, 1200, false)
+	small := image.NewGray(image.Rect(0, 0, 300, 400))
+	draw.BiLinear.Scale(small, small.Bounds(), src, src.Bounds(), draw.Src, nil)
+
+	if d := phash.Distance(phash.DHash(src), phash.DHash(small)); d > 4 {
+		t.Errorf("distance after resize = %d, want <= 4", d)
+	}
+}
+
+func TestDHashDiffersForDifferentImages(t *testing.T) {
+	a := phash.DHash(gradient(800, 800, false))
+	b := phash.DHash(gradient(800, 800, true))
+	if d := phash.Distance(a, b); d < 20 {
+		t.Errorf("distance between mirrored images = %d, want >= 20", d)
+	}
+}
+
+func TestDHashBlank(t *testing.T) {
+	blank := image.NewGray(image.Rect(0, 0, 100, 100))
+	if got := phash.DHash(blank); got != 0 {
+		t.Errorf("DHash(blank) = %#x, want 0", got)
+	}
+}
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a, b     uint64
+		expected int
+	}{
+		{0, 0, 0},
+		{0, 1, 1},
+		{0xff, 0x0f, 4},
+		{0, ^uint64(0), 64},
+	}
+
+	for _, tt := range tests {
+		if got := phash.Distance(tt.a, tt.b); got != tt.expected {
+			t.Errorf("Distance(%#x, %#x) = %d, want %d", tt.a, tt.b, got, tt.expected)
+		}
+	}
+}
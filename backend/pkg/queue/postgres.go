@@ -0,0 +1,142 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// receiveWait is how long Receive polls an empty queue before returning, like
+// an SQS long poll.
+const receiveWait = 20 * time.Second
+
+// DeadFunc is told about a message moved to the dead state, and why.
+type DeadFunc func(ctx context.Context, msg UploadMessage, reason string)
+
+// Postgres keeps the queue in the queue_messages table, so the worker
+// subcommand needs no AWS. A message received maxReceives times without being
+// acked is moved to the dead state, where it stays for inspection, and passed
+// to onDead (may be nil).
+type Postgres struct {
+	db           *sqlx.DB
+	maxReceives  int
+	pollInterval time.Duration
+	onDead       DeadFunc
+}
+
+func NewPostgres(db *sqlx.DB, maxReceives int, pollInterval time.Duration, onDead DeadFunc) *Postgres {
+	return &Postgres{db: db, maxReceives: maxReceives, pollInterval: pollInterval, onDead: onDead}
+}
+
+func (q *Postgres) Enqueue(ctx context.Context, msg UploadMessage) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	_, err = q.db.ExecContext(ctx, `
+		INSERT INTO queue_messages (id, body, visible_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $3, $3)`, uuid.Must(uuid.NewV7()), body, now)
+	return err
+}
+
+// Receive polls every pollInterval until a message is visible, for up to 20 seconds.
+func (q *Postgres) Receive(ctx context.Context, max int, visibility time.Duration) ([]*Delivery, error) {
+	deadline := time.Now().Add(receiveWait)
+	for {
+		deliveries, err := q.claim(ctx, max, visibility)
+		if err != nil || len(deliveries) > 0 || time.Now().After(deadline) {
+			return deliveries, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(q.pollInterval):
+		}
+	}
+}
+
+func (q *Postgres) claim(ctx context.Context, max int, visibility time.Duration) ([]*Delivery, error) {
+	now := time.Now()
+	// Messages whose visibility ran out maxReceives times were never acked or
+	// nacked: the worker died on them every time.
+	const timedOut = "visibility timeout exceeded"
+	var dead [][]byte
+	if err := q.db.SelectContext(ctx, &dead, `
+		UPDATE queue_messages SET status = 'dead', last_error = $3, updated_at = $1
+		WHERE status = 'ready' AND visible_at <= $1 AND attempts >= $2
+		RETURNING body`, now, q.maxReceives, timedOut); err != nil {
+		return nil, err
+	}
+	for _, body := range dead {
+		q.deadLettered(ctx, body, timedOut)
+	}
+
+	// SKIP LOCKED lets several workers poll the table without taking the same
+	// messages; visible_at keeps them hidden after the statement commits.
+	var rows []struct {
+		ID       uuid.UUID `db:"id"`
+		Body     []byte    `db:"body"`
+		Attempts int       `db:"attempts"`
+	}
+	err := q.db.SelectContext(ctx, &rows, `
+		UPDATE queue_messages m SET attempts = m.attempts + 1, visible_at = $3, updated_at = $1
+		FROM (
+			SELECT id FROM queue_messages WHERE status = 'ready' AND visible_at <= $1
+			ORDER BY visible_at LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		) due
+		WHERE m.id = due.id
+		RETURNING m.id, m.body, m.attempts`, now, max, now.Add(visibility))
+	if err != nil {
+		return nil, err
+	}
+	deliveries := make([]*Delivery, len(rows))
+	for i, r := range rows {
+		deliveries[i] = &Delivery{Body: r.Body, Attempts: r.Attempts, handle: r.ID.String()}
+	}
+	return deliveries, nil
+}
+
+func (q *Postgres) Extend(ctx context.Context, d *Delivery, visibility time.Duration) error {
+	now := time.Now()
+	_, err := q.db.ExecContext(ctx,
+		`UPDATE queue_messages SET visible_at = $2, updated_at = $3 WHERE id = $1`, d.handle, now.Add(visibility), now)
+	return err
+}
+
+func (q *Postgres) Ack(ctx context.Context, d *Delivery) error {
+	_, err := q.db.ExecContext(ctx, `DELETE FROM queue_messages WHERE id = $1`, d.handle)
+	return err
+}
+
+func (q *Postgres) Nack(ctx context.Context, d *Delivery, reason string) error {
+	now := time.Now()
+	status := "ready"
+	if d.Attempts >= q.maxReceives {
+		status = "dead"
+	}
+	if _, err := q.db.ExecContext(ctx, `
+		UPDATE queue_messages SET status = $2, last_error = $3, visible_at = $4, updated_at = $5
+		WHERE id = $1`, d.handle, status, reason, now.Add(retryDelay(d.Attempts)), now); err != nil {
+		return err
+	}
+	if status == "dead" {
+		q.deadLettered(ctx, d.Body, reason)
+	}
+	return nil
+}
+
+func (q *Postgres) deadLettered(ctx context.Context, body []byte, reason string) {
+	if q.onDead == nil {
+		return
+	}
+	var msg UploadMessage
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return
+	}
+	q.onDead(ctx, msg, reason)
+}
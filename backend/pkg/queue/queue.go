@@ -0,0 +1,63 @@
+package queue
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Backends selectable with queue.backend.
+const (
+	BackendSQS      = "sqs"
+	BackendPostgres = "postgres"
+)
+
+// Client carries upload and export tasks from the API to the workers: the
+// upload Lambda (SQS) or the worker subcommand (SQS or Postgres).
+type Client interface {
+	// Enqueue sends msg to the queue.
+	Enqueue(ctx context.Context, msg UploadMessage) error
+	// Receive waits up to a few seconds for at most max messages. Received
+	// messages are hidden from other consumers for visibility, after which they
+	// are delivered again unless acked.
+	Receive(ctx context.Context, max int, visibility time.Duration) ([]*Delivery, error)
+	// Extend hides a received message for another visibility from now.
+	Extend(ctx context.Context, d *Delivery, visibility time.Duration) error
+	// Ack removes a processed message.
+	Ack(ctx context.Context, d *Delivery) error
+	// Nack returns a message for a later retry, or dead-letters it once it was
+	// received too often. reason is recorded where the backend supports it.
+	Nack(ctx context.Context, d *Delivery, reason string) error
+}
+
+// UploadMessage is the queue payload for async zip processing and exports.
+// Shared between the server (enqueue) and the workers (consume).
+type UploadMessage struct {
+	TaskID    uuid.UUID  `json:"task_id"`
+	Type      string     `json:"type"`
+	S3Key     string     `json:"s3_key"` // staged zip, or the output key for exports
+	MangaID   uuid.UUID  `json:"manga_id"`
+	ChapterID *uuid.UUID `json:"chapter_id,omitempty"` // nil for oneshot_zip and series_zip
+	OwnerID   uuid.UUID  `json:"owner_id"`
+	// ApplyMetadata merges the archive metadata into the manga and chapter (upload tasks only).
+	ApplyMetadata bool `json:"apply_metadata,omitempty"`
+}
+
+// Delivery is a received message.
+type Delivery struct {
+	Body []byte
+	// Attempts is how often the message was received, this time included.
+	Attempts int
+	handle   string // SQS receipt handle or queue_messages id
+}
+
+// retryDelay is how long a nacked message stays hidden: 30s doubling per
+// attempt, at most 15 minutes.
+func retryDelay(attempts int) time.Duration {
+	d := 30 * time.Second
+	for i := 1; i < attempts && d < 15*time.Minute; i++ {
+		d *= 2
+	}
+	return min(d, 15*time.Minute)
+}
@@ -4,48 +4,92 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
-	"github.com/google/uuid"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
 )
 
-type Client struct {
+// SQS is the queue backend used in AWS. Dead-lettering is left to the
+// queue's redrive policy: Nack only delays the next delivery.
+type SQS struct {
 	sqs      *sqs.Client
 	queueURL string
 }
 
-func NewClient(ctx context.Context, region, queueURL string) (*Client, error) {
+func NewSQS(ctx context.Context, region, queueURL string) (*SQS, error) {
 	cfg, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithRegion(region))
 	if err != nil {
 		return nil, fmt.Errorf("load aws config: %w", err)
 	}
-	return &Client{
+	return &SQS{
 		sqs:      sqs.NewFromConfig(cfg),
 		queueURL: queueURL,
 	}, nil
 }
 
-// UploadMessage is the SQS payload for async zip processing.
-// Shared between the server (enqueue) and Lambda (consume).
-type UploadMessage struct {
-	TaskID    uuid.UUID  `json:"task_id"`
-	Type      string     `json:"type"`
-	S3Key     string     `json:"s3_key"`
-	MangaID   uuid.UUID  `json:"manga_id"`
-	ChapterID *uuid.UUID `json:"chapter_id,omitempty"` // nil for oneshot_zip
-	OwnerID   uuid.UUID  `json:"owner_id"`
-}
-
-func (c *Client) Enqueue(ctx context.Context, msg UploadMessage) error {
+func (q *SQS) Enqueue(ctx context.Context, msg UploadMessage) error {
 	body, err := json.Marshal(msg)
 	if err != nil {
 		return err
 	}
-	_, err = c.sqs.SendMessage(ctx, &sqs.SendMessageInput{
-		QueueUrl:    aws.String(c.queueURL),
+	_, err = q.sqs.SendMessage(ctx, &sqs.SendMessageInput{
+		QueueUrl:    aws.String(q.queueURL),
 		MessageBody: aws.String(string(body)),
 	})
 	return err
 }
+
+// Receive long-polls for up to 20 seconds. SQS returns at most 10 messages.
+func (q *SQS) Receive(ctx context.Context, max int, visibility time.Duration) ([]*Delivery, error) {
+	out, err := q.sqs.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
+		QueueUrl:            aws.String(q.queueURL),
+		MaxNumberOfMessages: int32(min(max, 10)),
+		VisibilityTimeout:   int32(visibility / time.Second),
+		WaitTimeSeconds:     20,
+		MessageSystemAttributeNames: []types.MessageSystemAttributeName{
+			types.MessageSystemAttributeNameApproximateReceiveCount,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	deliveries := make([]*Delivery, len(out.Messages))
+	for i, m := range out.Messages {
+		attempts, _ := strconv.Atoi(m.Attributes[string(types.MessageSystemAttributeNameApproximateReceiveCount)])
+		deliveries[i] = &Delivery{
+			Body:     []byte(aws.ToString(m.Body)),
+			Attempts: attempts,
+			handle:   aws.ToString(m.ReceiptHandle),
+		}
+	}
+	return deliveries, nil
+}
+
+func (q *SQS) Extend(ctx context.Context, d *Delivery, visibility time.Duration) error {
+	return q.changeVisibility(ctx, d, visibility)
+}
+
+func (q *SQS) Ack(ctx context.Context, d *Delivery) error {
+	_, err := q.sqs.DeleteMessage(ctx, &sqs.DeleteMessageInput{
+		QueueUrl:      aws.String(q.queueURL),
+		ReceiptHandle: aws.String(d.handle),
+	})
+	return err
+}
+
+func (q *SQS) Nack(ctx context.Context, d *Delivery, reason string) error {
+	return q.changeVisibility(ctx, d, retryDelay(d.Attempts))
+}
+
+func (q *SQS) changeVisibility(ctx context.Context, d *Delivery, visibility time.Duration) error {
+	_, err := q.sqs.ChangeMessageVisibility(ctx, &sqs.ChangeMessageVisibilityInput{
+		QueueUrl:          aws.String(q.queueURL),
+		ReceiptHandle:     aws.String(d.handle),
+		VisibilityTimeout: int32(visibility / time.Second),
+	})
+	return err
+}
@@ -0,0 +1,66 @@
+package rediscache
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Cache is a JSON cache-aside for one namespace of keys. Instead of deleting
+// keys on invalidation, a version counter is bumped: every key embeds the
+// version current when it was read, so old entries stop being served and
+// simply expire.
+//
+// Redis errors are treated as cache misses so a request never fails due to
+// cache unavailability. A nil *Cache is valid and caches nothing.
+type Cache struct {
+	rdb       *redis.Client
+	namespace string
+}
+
+func New(rdb *redis.Client, namespace string) *Cache {
+	return &Cache{rdb: rdb, namespace: namespace}
+}
+
+// Fetch returns the cached value for key, or calls load and caches its result
+// for ttl. The version is read before load runs, so a result computed while an
+// invalidation happens is stored under the old version and never served.
+func Fetch[T any](ctx context.Context, c *Cache, key string, ttl time.Duration, load func(context.Context) (T, error)) (T, error) {
+	if c == nil {
+		return load(ctx)
+	}
+
+	cacheKey := c.versionedKey(ctx, key)
+	var v T
+	if data, err := c.rdb.Get(ctx, cacheKey).Bytes(); err == nil && json.Unmarshal(data, &v) == nil {
+		return v, nil
+	}
+
+	v, err := load(ctx)
+	if err != nil {
+		return v, err
+	}
+	if data, err := json.Marshal(v); err == nil {
+		c.rdb.Set(ctx, cacheKey, data, ttl) // best-effort
+	}
+	return v, nil
+}
+
+// Invalidate drops every key in the namespace by bumping its version.
+func (c *Cache) Invalidate(ctx context.Context) {
+	if c == nil {
+		return
+	}
+	c.rdb.Incr(ctx, c.namespace+":version")
+}
+
+func (c *Cache) versionedKey(ctx context.Context, key string) string {
+	v, err := c.rdb.Get(ctx, c.namespace+":version").Int64()
+	if err != nil {
+		v = 0
+	}
+	return c.namespace + ":v" + strconv.FormatInt(v, 10) + ":" + key
+}
@@ -3,6 +3,8 @@ package storage
 import (
 	"bytes"
 	"context"
+	"errors"
+	"fmt"
 	"io"
 	"net/url"
 	"time"
@@ -10,10 +12,14 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 )
 
 const opTimeout = 10 * time.Second
 
+// ErrObjectNotFound is returned by ObjectSize for a missing object.
+var ErrObjectNotFound = errors.New("storage: object not found")
+
 type Client struct {
 	s3client      *s3.Client
 	presignClient *s3.PresignClient
@@ -74,6 +80,19 @@ func (c *Client) PutObject(ctx context.Context, objectKey, contentType string, r
 	return err
 }
 
+// UploadObject is PutObject for large objects such as exports.
+// No internal timeout is applied — use a context deadline if needed.
+func (c *Client) UploadObject(ctx context.Context, objectKey, contentType string, r io.ReadSeeker, size int64) error {
+	_, err := c.s3client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:        aws.String(c.bucket),
+		Key:           aws.String(objectKey),
+		Body:          r,
+		ContentType:   aws.String(contentType),
+		ContentLength: aws.Int64(size),
+	})
+	return err
+}
+
 // GetObject downloads an object from S3 and returns its body.
 // The caller is responsible for closing the returned ReadCloser.
 // No internal timeout is applied — use a context deadline if needed.
@@ -88,6 +107,24 @@ func (c *Client) GetObject(ctx context.Context, objectKey string) (io.ReadCloser
 	return out.Body, nil
 }
 
+// ObjectSize returns the size of an object, or ErrObjectNotFound.
+func (c *Client) ObjectSize(ctx context.Context, objectKey string) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+	out, err := c.s3client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(c.bucket),
+		Key:    aws.String(objectKey),
+	})
+	var missing *types.NotFound
+	if errors.As(err, &missing) {
+		return 0, ErrObjectNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	return aws.ToInt64(out.ContentLength), nil
+}
+
 func (c *Client) DeleteObject(ctx context.Context, objectKey string) error {
 	ctx, cancel := context.WithTimeout(ctx, opTimeout)
 	defer cancel()
@@ -112,3 +149,38 @@ func (c *Client) PresignedGetURL(ctx context.Context, objectKey string) (*url.UR
 	}
 	return url.Parse(req.URL)
 }
+
+// PresignedPutURL returns a URL a client can PUT objectKey to until expiry,
+// without credentials. With a custom endpoint (MinIO) the URL points there.
+func (c *Client) PresignedPutURL(ctx context.Context, objectKey string, expiry time.Duration) (*url.URL, error) {
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+	req, err := c.presignClient.PresignPutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(c.bucket),
+		Key:    aws.String(objectKey),
+	}, func(o *s3.PresignOptions) {
+		o.Expires = expiry
+	})
+	if err != nil {
+		return nil, err
+	}
+	return url.Parse(req.URL)
+}
+
+// PresignedDownloadURL is PresignedGetURL with a Content-Disposition override so
+// browsers save the object as filename instead of displaying it.
+func (c *Client) PresignedDownloadURL(ctx context.Context, objectKey, filename string) (*url.URL, error) {
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+	req, err := c.presignClient.PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket:                     aws.String(c.bucket),
+		Key:                        aws.String(objectKey),
+		ResponseContentDisposition: aws.String(fmt.Sprintf("attachment; filename=%q", filename)),
+	}, func(o *s3.PresignOptions) {
+		o.Expires = c.presignExpiry
+	})
+	if err != nil {
+		return nil, err
+	}
+	return url.Parse(req.URL)
+}
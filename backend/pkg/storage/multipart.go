@@ -0,0 +1,139 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// partTimeout bounds one UploadPart call; parts are a few MB.
+const partTimeout = time.Minute
+
+// CompletedPart identifies an uploaded part when completing a multipart upload.
+type CompletedPart struct {
+	Number int32
+	ETag   string
+}
+
+// CreateMultipartUpload starts a multipart upload to objectKey and returns its upload ID.
+func (c *Client) CreateMultipartUpload(ctx context.Context, objectKey, contentType string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+	out, err := c.s3client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:      aws.String(c.bucket),
+		Key:         aws.String(objectKey),
+		ContentType: aws.String(contentType),
+	})
+	if err != nil {
+		return "", err
+	}
+	return aws.ToString(out.UploadId), nil
+}
+
+// UploadPart stores part number (1-based) of a multipart upload and returns its
+// ETag. Every part but the last must be at least 5 MB.
+func (c *Client) UploadPart(ctx context.Context, objectKey, uploadID string, number int32, r io.ReadSeeker, size int64) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, partTimeout)
+	defer cancel()
+	out, err := c.s3client.UploadPart(ctx, &s3.UploadPartInput{
+		Bucket:        aws.String(c.bucket),
+		Key:           aws.String(objectKey),
+		UploadId:      aws.String(uploadID),
+		PartNumber:    aws.Int32(number),
+		Body:          r,
+		ContentLength: aws.Int64(size),
+	})
+	if err != nil {
+		return "", err
+	}
+	return aws.ToString(out.ETag), nil
+}
+
+// PresignedUploadPartURL returns a URL a client can PUT part number of a
+// multipart upload to until expiry, without credentials.
+func (c *Client) PresignedUploadPartURL(ctx context.Context, objectKey, uploadID string, number int32, expiry time.Duration) (*url.URL, error) {
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+	req, err := c.presignClient.PresignUploadPart(ctx, &s3.UploadPartInput{
+		Bucket:     aws.String(c.bucket),
+		Key:        aws.String(objectKey),
+		UploadId:   aws.String(uploadID),
+		PartNumber: aws.Int32(number),
+	}, func(o *s3.PresignOptions) {
+		o.Expires = expiry
+	})
+	if err != nil {
+		return nil, err
+	}
+	return url.Parse(req.URL)
+}
+
+// UploadedPart is a part stored in a multipart upload.
+type UploadedPart struct {
+	Number int32
+	ETag   string
+	Size   int64
+}
+
+// ListParts returns the parts stored so far in a multipart upload, by number.
+func (c *Client) ListParts(ctx context.Context, objectKey, uploadID string) ([]UploadedPart, error) {
+	var parts []UploadedPart
+	p := s3.NewListPartsPaginator(c.s3client, &s3.ListPartsInput{
+		Bucket:   aws.String(c.bucket),
+		Key:      aws.String(objectKey),
+		UploadId: aws.String(uploadID),
+	})
+	for p.HasMorePages() {
+		out, err := p.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, part := range out.Parts {
+			parts = append(parts, UploadedPart{
+				Number: aws.ToInt32(part.PartNumber),
+				ETag:   aws.ToString(part.ETag),
+				Size:   aws.ToInt64(part.Size),
+			})
+		}
+	}
+	return parts, nil
+}
+
+// CompleteMultipartUpload assembles the parts, in order, into the object.
+// S3 may take a while for large objects, so no internal timeout is applied.
+func (c *Client) CompleteMultipartUpload(ctx context.Context, objectKey, uploadID string, parts []CompletedPart) error {
+	completed := make([]types.CompletedPart, len(parts))
+	for i, p := range parts {
+		completed[i] = types.CompletedPart{PartNumber: aws.Int32(p.Number), ETag: aws.String(p.ETag)}
+	}
+	_, err := c.s3client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(c.bucket),
+		Key:             aws.String(objectKey),
+		UploadId:        aws.String(uploadID),
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: completed},
+	})
+	return err
+}
+
+// AbortMultipartUpload discards a multipart upload and its stored parts.
+// Aborting an upload that no longer exists is not an error.
+func (c *Client) AbortMultipartUpload(ctx context.Context, objectKey, uploadID string) error {
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+	_, err := c.s3client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(c.bucket),
+		Key:      aws.String(objectKey),
+		UploadId: aws.String(uploadID),
+	})
+	var missing *types.NoSuchUpload
+	if errors.As(err, &missing) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,111 @@
+// Package taskevents carries live upload task updates from the worker to the
+// API over Redis pub/sub, one channel per task.
+//
+// Pub/sub is fire-and-forget: a subscriber only receives events published while
+// it is subscribed, and events are lost while Redis is unreachable. Readers
+// should subscribe before loading the stored task and treat events as hints on
+// top of it.
+package taskevents
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// Event types.
+const (
+	TypeStatus   = "status"   // the task changed status
+	TypeProgress = "progress" // another page was stored
+)
+
+// Event is a snapshot of a task's status and progress.
+type Event struct {
+	Type      string `json:"type"`
+	Status    string `json:"status"`
+	Processed int    `json:"processed"`
+	Total     int    `json:"total"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Bus publishes and subscribes to task events. A nil *Bus is valid: it
+// publishes nothing and subscriptions never receive an event.
+type Bus struct {
+	rdb *redis.Client
+}
+
+func New(rdb *redis.Client) *Bus {
+	return &Bus{rdb: rdb}
+}
+
+// Publish sends e to the task's subscribers.
+func (b *Bus) Publish(ctx context.Context, taskID uuid.UUID, e Event) error {
+	if b == nil {
+		return nil
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return b.rdb.Publish(ctx, channel(taskID), data).Err()
+}
+
+// Subscription receives the events of one task until it is closed.
+type Subscription struct {
+	ps   *redis.PubSub
+	c    chan Event
+	done chan struct{}
+}
+
+// Subscribe starts listening to the task's events; it returns once Redis has
+// confirmed the subscription, so no later event is missed.
+func (b *Bus) Subscribe(ctx context.Context, taskID uuid.UUID) (*Subscription, error) {
+	s := &Subscription{c: make(chan Event), done: make(chan struct{})}
+	if b == nil {
+		return s, nil
+	}
+	s.ps = b.rdb.Subscribe(ctx, channel(taskID))
+	if _, err := s.ps.Receive(ctx); err != nil {
+		s.ps.Close()
+		return nil, err
+	}
+	go s.forward()
+	return s, nil
+}
+
+// Events returns the channel events are delivered on. It is closed when the
+// subscription is closed or the Redis connection is lost for good.
+func (s *Subscription) Events() <-chan Event {
+	return s.c
+}
+
+// Close stops the subscription.
+func (s *Subscription) Close() error {
+	close(s.done)
+	if s.ps == nil {
+		close(s.c)
+		return nil
+	}
+	return s.ps.Close()
+}
+
+func (s *Subscription) forward() {
+	defer close(s.c)
+	for msg := range s.ps.Channel() {
+		var e Event
+		if json.Unmarshal([]byte(msg.Payload), &e) != nil {
+			continue
+		}
+		select {
+		case s.c <- e:
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func channel(taskID uuid.UUID) string {
+	return "upload_task:" + taskID.String() + ":events"
+}
@@ -0,0 +1,59 @@
+// Package tus holds the protocol details of tus 1.0 resumable uploads
+// (https://tus.io/protocols/resumable-upload): header names and the
+// Upload-Metadata encoding.
+package tus
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+// Version is the only protocol version supported.
+const Version = "1.0.0"
+
+// Extensions lists the supported protocol extensions, for the Tus-Extension header.
+const Extensions = "creation,termination,expiration"
+
+// ContentType is the Content-Type a PATCH request must carry.
+const ContentType = "application/offset+octet-stream"
+
+const (
+	HeaderResumable = "Tus-Resumable"
+	HeaderVersion   = "Tus-Version"
+	HeaderExtension = "Tus-Extension"
+	HeaderMaxSize   = "Tus-Max-Size"
+	HeaderOffset    = "Upload-Offset"
+	HeaderLength    = "Upload-Length"
+	HeaderMetadata  = "Upload-Metadata"
+	HeaderExpires   = "Upload-Expires"
+	// HeaderDeferLength announces an upload of unknown length, which is not supported.
+	HeaderDeferLength = "Upload-Defer-Length"
+)
+
+var ErrBadMetadata = errors.New("tus: malformed Upload-Metadata")
+
+// ParseMetadata decodes an Upload-Metadata header: comma-separated pairs of a
+// key and an optional base64 value, separated by a space. Keys without a
+// value map to "".
+func ParseMetadata(header string) (map[string]string, error) {
+	md := map[string]string{}
+	if strings.TrimSpace(header) == "" {
+		return md, nil
+	}
+	for _, pair := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" {
+			return nil, ErrBadMetadata
+		}
+		if _, dup := md[key]; dup {
+			return nil, ErrBadMetadata
+		}
+		decoded, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, ErrBadMetadata
+		}
+		md[key] = string(decoded)
+	}
+	return md, nil
+}
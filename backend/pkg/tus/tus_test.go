@@ -0,0 +1,33 @@
+package tus_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/yumikokawaii/sherry-archive/pkg/tus"
+)
+
+func TestParseMetadata(t *testing.T) {
+	tests := []struct {
+		header string
+		want   map[string]string
+		ok     bool
+	}{
+		{"", map[string]string{}, true},
+		{"type emlw", map[string]string{"type": "zip"}, true},
+		{"filename dm9sIDEuY2J6, apply_metadata", map[string]string{"filename": "vol 1.cbz", "apply_metadata": ""}, true},
+		{"type emlw,type emlw", nil, false},
+		{"type not-base64!", nil, false},
+		{"type emlw,", nil, false},
+	}
+	for _, tt := range tests {
+		got, err := tus.ParseMetadata(tt.header)
+		if (err == nil) != tt.ok {
+			t.Errorf("ParseMetadata(%q) error = %v, want ok=%v", tt.header, err, tt.ok)
+			continue
+		}
+		if tt.ok && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseMetadata(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package webhook_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/yumikokawaii/sherry-archive/pkg/webhook"
+)
+
+func TestSignVerify(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{"event":"manga.created"}`)
+	header := webhook.Sign("secret", now, body)
+
+	tests := []struct {
+		name   string
+		secret string
+		header string
+		body   []byte
+		now    time.Time
+		ok     bool
+	}{
+		{"valid", "secret", header, body, now, true},
+		{"within tolerance", "secret", header, body, now.Add(4 * time.Minute), true},
+		{"wrong secret", "other", header, body, now, false},
+		{"tampered body", "secret", header, []byte(`{"event":"manga.deleted"}`), now, false},
+		{"too old", "secret", header, body, now.Add(6 * time.Minute), false},
+		{"malformed", "secret", "v1=abc", body, now, false},
+	}
+	for _, tt := range tests {
+		err := webhook.Verify(tt.secret, tt.header, tt.body, 5*time.Minute, tt.now)
+		if (err == nil) != tt.ok {
+			t.Errorf("%s: Verify() = %v, want ok=%v", tt.name, err, tt.ok)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempt  int
+		expected time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{4, 4 * time.Minute},
+		{10, 512 * 30 * time.Second},
+		{11, 6 * time.Hour},
+		{100, 6 * time.Hour},
+	}
+	for _, tt := range tests {
+		if got := webhook.Backoff(tt.attempt); got != tt.expected {
+			t.Errorf("Backoff(%d) = %v, want %v", tt.attempt, got, tt.expected)
+		}
+	}
+}
+
+func TestClientSend(t *testing.T) {
+	body := []byte(`{"event":"chapter.published"}`)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ := io.ReadAll(r.Body)
+		if r.Header.Get(webhook.EventHeader) != "chapter.published" || r.Header.Get(webhook.DeliveryHeader) != "d1" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if err := webhook.Verify("secret", r.Header.Get(webhook.SignatureHeader), got, time.Minute, time.Now()); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	c := webhook.NewClient(5*time.Second, true)
+	status, err := c.Send(context.Background(), webhook.Request{
+		URL: srv.URL, Secret: "secret", Event: "chapter.published", DeliveryID: "d1", Body: body,
+	})
+	if err != nil || status != http.StatusNoContent {
+		t.Fatalf("Send() = %d, %v, want 204, nil", status, err)
+	}
+
+	status, err = c.Send(context.Background(), webhook.Request{
+		URL: srv.URL, Secret: "wrong", Event: "chapter.published", DeliveryID: "d1", Body: body,
+	})
+	if err == nil || status != http.StatusUnauthorized {
+		t.Fatalf("Send() with wrong secret = %d, %v, want 401 and an error", status, err)
+	}
+}
+
+func TestClientRefusesPrivateAddresses(t *testing.T) {
+	hit := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hit = true
+	}))
+	defer srv.Close()
+
+	c := webhook.NewClient(5*time.Second, false)
+	status, err := c.Send(context.Background(), webhook.Request{URL: srv.URL, Secret: "s", Body: []byte("{}")})
+	if !errors.Is(err, webhook.ErrPrivateAddress) || status != 0 || hit {
+		t.Fatalf("Send() = %d, %v (hit=%v), want ErrPrivateAddress", status, err, hit)
+	}
+}
+
+func TestClientDoesNotFollowRedirects(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/elsewhere", http.StatusFound)
+	}))
+	defer srv.Close()
+
+	c := webhook.NewClient(5*time.Second, true)
+	if status, err := c.Send(context.Background(), webhook.Request{URL: srv.URL, Body: []byte("{}")}); err == nil || status != http.StatusFound {
+		t.Fatalf("Send() = %d, %v, want 302 and an error", status, err)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 	"github.com/redis/go-redis/v9"
 	"github.com/spf13/cobra"
@@ -21,10 +22,13 @@ import (
 	"github.com/yumikokawaii/sherry-archive/internal/service"
 	"github.com/yumikokawaii/sherry-archive/internal/tracking"
 	"github.com/yumikokawaii/sherry-archive/internal/tracing"
-	"github.com/yumikokawaii/sherry-archive/pkg/queue"
+	"github.com/yumikokawaii/sherry-archive/pkg/imageproc"
+	"github.com/yumikokawaii/sherry-archive/pkg/rediscache"
 	"github.com/yumikokawaii/sherry-archive/pkg/storage"
+	"github.com/yumikokawaii/sherry-archive/pkg/taskevents"
 	"github.com/yumikokawaii/sherry-archive/pkg/token"
 	"github.com/yumikokawaii/sherry-archive/pkg/urlcache"
+	"github.com/yumikokawaii/sherry-archive/pkg/webhook"
 	"github.com/yumikokawaii/sherry-archive/pkg/xrayhook"
 	"go.uber.org/zap"
 )
@@ -57,6 +61,30 @@ func Server(cmd *cobra.Command, args []string) {
 	if err != nil {
 		zap.L().Fatal("invalid analytics.decay_interval", zap.String("value", cfg.Analytics.DecayInterval), zap.Error(err))
 	}
+	notificationPollInterval, err := time.ParseDuration(cfg.Notifications.PollInterval)
+	if err != nil {
+		zap.L().Fatal("invalid notifications.poll_interval", zap.String("value", cfg.Notifications.PollInterval), zap.Error(err))
+	}
+	webhookPollInterval, err := time.ParseDuration(cfg.Webhooks.PollInterval)
+	if err != nil {
+		zap.L().Fatal("invalid webhooks.poll_interval", zap.String("value", cfg.Webhooks.PollInterval), zap.Error(err))
+	}
+	webhookTimeout, err := time.ParseDuration(cfg.Webhooks.Timeout)
+	if err != nil {
+		zap.L().Fatal("invalid webhooks.timeout", zap.String("value", cfg.Webhooks.Timeout), zap.Error(err))
+	}
+	taskStuckAfter, err := time.ParseDuration(cfg.Upload.StuckAfter)
+	if err != nil {
+		zap.L().Fatal("invalid upload.stuck_after", zap.String("value", cfg.Upload.StuckAfter), zap.Error(err))
+	}
+	taskReapInterval, err := time.ParseDuration(cfg.Upload.ReapInterval)
+	if err != nil {
+		zap.L().Fatal("invalid upload.reap_interval", zap.String("value", cfg.Upload.ReapInterval), zap.Error(err))
+	}
+	uploadSessionTTL, err := time.ParseDuration(cfg.Upload.SessionTTL)
+	if err != nil {
+		zap.L().Fatal("invalid upload.session_ttl", zap.String("value", cfg.Upload.SessionTTL), zap.Error(err))
+	}
 
 	// Database — use X-Ray instrumented connection when tracing is enabled.
 	var db *sqlx.DB
@@ -105,21 +133,27 @@ func Server(cmd *cobra.Command, args []string) {
 		refreshExpiry,
 	)
 
-	// SQS
-	sqsClient, err := queue.NewClient(context.Background(), cfg.S3.Region, cfg.SQS.QueueURL)
-	if err != nil {
-		zap.L().Fatal("sqs", zap.Error(err))
-	}
+	// Task queue — SQS, or Postgres for the worker subcommand
+	// The API only enqueues; dead letters are handled by the worker.
+	taskQueue := newQueue(cfg, db, nil)
 
 	// Repositories
 	userRepo := postgres.NewUserRepo(db)
 	mangaRepo := postgres.NewMangaRepo(db)
 	chapterRepo := postgres.NewChapterRepo(db)
 	pageRepo := postgres.NewPageRepo(db)
+	pageObjectRepo := postgres.NewPageObjectRepo(db)
 	bookmarkRepo := postgres.NewBookmarkRepo(db)
 	commentRepo := postgres.NewCommentRepo(db)
+	ratingRepo := postgres.NewRatingRepo(db)
+	readingListRepo := postgres.NewReadingListRepo(db)
+	chapterReadRepo := postgres.NewChapterReadRepo(db)
+	notificationRepo := postgres.NewNotificationRepo(db)
+	notificationJobRepo := postgres.NewNotificationJobRepo(db)
+	webhookRepo := postgres.NewWebhookRepo(db)
 	refreshTokenRepo := postgres.NewRefreshTokenRepo(db)
 	uploadTaskRepo := postgres.NewUploadTaskRepo(db)
+	uploadSessionRepo := postgres.NewUploadSessionRepo(db)
 	deviceMappingRepo := postgres.NewDeviceUserMappingRepo(db)
 	seenMangaRepo := postgres.NewSeenMangaRepo(db)
 	userInterestRepo := postgres.NewUserInterestRepo(db)
@@ -139,6 +173,16 @@ func Server(cmd *cobra.Command, args []string) {
 		signer = cfSigner
 	}
 	urlCache := urlcache.New(signer, rdb, presignExpiry)
+	releaseCache := rediscache.New(rdb, "releases")
+	images := imageproc.New(imageproc.Config{
+		Encoder:          cfg.Image.Encoder,
+		Quality:          cfg.Image.Quality,
+		ThumbnailWidth:   cfg.Image.ThumbnailWidth,
+		ThumbnailQuality: cfg.Image.ThumbnailQuality,
+		Workers:          cfg.Image.Workers,
+		CoverWidths:      cfg.Image.CoverWidthList(),
+		CoverQuality:     cfg.Image.CoverQuality,
+	})
 
 	// Analytics — real-time trending + suggestions via Redis
 	stopTags := make(map[string]struct{})
@@ -149,32 +193,68 @@ func Server(cmd *cobra.Command, args []string) {
 			}
 		}
 	}
+	adminIDs := make(map[uuid.UUID]struct{})
+	for _, v := range strings.Split(cfg.Admin.UserIDs, ",") {
+		if v = strings.TrimSpace(v); v == "" {
+			continue
+		}
+		id, err := uuid.Parse(v)
+		if err != nil {
+			zap.L().Fatal("admin.user_ids", zap.String("id", v), zap.Error(err))
+		}
+		adminIDs[id] = struct{}{}
+	}
+	duplicatePolicy := service.DuplicatePolicy{
+		WarnPercent:   cfg.Duplicates.WarnPercent,
+		RejectPercent: cfg.Duplicates.RejectPercent,
+		MaxDistance:   cfg.Duplicates.MaxDistance,
+	}
+
 	analyticsStore := analytics.NewStore(rdb, db, seenMangaRepo, cfg.Analytics.ContributionCap, decayInterval, stopTags)
+	trackingStore := tracking.NewPostgresStore(db)
+	// Events the API emits itself (ratings, reading lists) take the same path as client events.
+	eventRecorder := tracking.NewRecorder(trackingStore, analyticsStore)
 
 	// Services
 	authSvc := service.NewAuthService(userRepo, refreshTokenRepo, deviceMappingRepo, seenMangaRepo, userInterestRepo, analyticsStore, tokenMgr)
 	userSvc := service.NewUserService(userRepo)
-	mangaSvc := service.NewMangaService(mangaRepo)
-	chapterSvc := service.NewChapterService(chapterRepo, mangaRepo)
-	pageSvc := service.NewPageService(pageRepo, chapterRepo, mangaRepo, storageClient, urlCache)
-	bookmarkSvc := service.NewBookmarkService(bookmarkRepo)
-	commentSvc := service.NewCommentService(commentRepo, mangaRepo, chapterRepo)
-	uploadTaskSvc := service.NewUploadTaskService(uploadTaskRepo, mangaRepo, chapterRepo, storageClient, sqsClient)
+	pageStore := service.NewPageStore(pageObjectRepo, storageClient, images)
+	coverSvc := service.NewCoverService(mangaRepo, chapterRepo, pageRepo, storageClient, images, pageStore)
+	webhookSvc := service.NewWebhookService(webhookRepo, webhook.NewClient(webhookTimeout, cfg.Webhooks.AllowPrivate), webhookTimeout)
+	mangaSvc := service.NewMangaService(mangaRepo, pageRepo, pageStore, coverSvc, analyticsStore, webhookSvc)
+	chapterSvc := service.NewChapterService(chapterRepo, mangaRepo, userRepo, pageRepo, pageStore, chapterReadRepo, releaseCache)
+	pageSvc := service.NewPageService(pageRepo, chapterRepo, mangaRepo, pageStore, coverSvc, urlCache, releaseCache, images, duplicatePolicy, notificationJobRepo, webhookSvc)
+	bookmarkSvc := service.NewBookmarkService(bookmarkRepo, userRepo, mangaRepo, chapterRepo, chapterReadRepo)
+	commentSvc := service.NewCommentService(commentRepo, mangaRepo, chapterRepo, notificationJobRepo)
+	ratingSvc := service.NewRatingService(ratingRepo, mangaRepo, eventRecorder)
+	readingListSvc := service.NewReadingListService(readingListRepo, mangaRepo, eventRecorder)
+	uploadTaskSvc := service.NewUploadTaskService(uploadTaskRepo, mangaRepo, chapterRepo, storageClient, taskQueue, taskevents.New(rdb), webhookSvc)
+	uploadSessionSvc := service.NewUploadSessionService(uploadSessionRepo, uploadTaskSvc, storageClient, cfg.Upload.MaxSize, uploadSessionTTL)
+	exportSvc := service.NewExportService(pageRepo, chapterRepo, mangaRepo, storageClient)
+	duplicateSvc := service.NewDuplicateService(pageRepo, duplicatePolicy)
+	notificationSvc := service.NewNotificationService(notificationRepo, notificationJobRepo, userRepo, mangaRepo, chapterRepo, commentRepo, bookmarkRepo)
 
 	// Handlers
 	handlers := handler.Handlers{
-		Auth:       handler.NewAuthHandler(authSvc),
-		Manga:      handler.NewMangaHandler(mangaSvc, storageClient, urlCache),
-		Chapter:    handler.NewChapterHandler(chapterSvc, pageSvc),
-		Page:       handler.NewPageHandler(pageSvc, uploadTaskSvc),
-		Bookmark:   handler.NewBookmarkHandler(bookmarkSvc),
-		User:       handler.NewUserHandler(userSvc, storageClient),
-		Comment:    handler.NewCommentHandler(commentSvc),
-		UploadTask: handler.NewUploadTaskHandler(uploadTaskSvc),
-		Sitemap:    handler.NewSitemapHandler(mangaRepo, chapterRepo),
+		Auth:         handler.NewAuthHandler(authSvc),
+		Manga:        handler.NewMangaHandler(mangaSvc, coverSvc, urlCache),
+		Chapter:      handler.NewChapterHandler(chapterSvc, pageSvc, urlCache),
+		Page:         handler.NewPageHandler(pageSvc, uploadTaskSvc),
+		Bookmark:     handler.NewBookmarkHandler(bookmarkSvc, urlCache),
+		Notification: handler.NewNotificationHandler(notificationSvc),
+		Webhook:      handler.NewWebhookHandler(webhookSvc),
+		User:         handler.NewUserHandler(userSvc, storageClient),
+		Comment:      handler.NewCommentHandler(commentSvc),
+		Rating:       handler.NewRatingHandler(ratingSvc),
+		ReadingList:  handler.NewReadingListHandler(readingListSvc, urlCache),
+		UploadTask:   handler.NewUploadTaskHandler(uploadTaskSvc),
+		Upload:       handler.NewUploadSessionHandler(uploadSessionSvc),
+		Export:       handler.NewExportHandler(exportSvc, uploadTaskSvc),
+		Sitemap:      handler.NewSitemapHandler(mangaRepo, chapterRepo),
+		Admin:        handler.NewAdminHandler(duplicateSvc),
 	}
 
-	r := handler.SetupRouter(handlers, tokenMgr)
+	r := handler.SetupRouter(handlers, tokenMgr, adminIDs)
 
 	// Background context cancelled on shutdown
 	bgCtx, bgCancel := context.WithCancel(context.Background())
@@ -182,9 +262,12 @@ func Server(cmd *cobra.Command, args []string) {
 
 	analytics.NewHandler(analyticsStore, urlCache).Mount(r)
 	go analyticsStore.StartDecay(bgCtx)
+	go notificationSvc.StartDispatcher(bgCtx, notificationPollInterval)
+	go webhookSvc.StartDispatcher(bgCtx, webhookPollInterval)
+	go uploadTaskSvc.StartReaper(bgCtx, taskReapInterval, taskStuckAfter, cfg.Upload.MaxAttempts)
+	go uploadSessionSvc.StartCleanup(bgCtx, taskReapInterval)
 
 	// Tracking — mounted independently; enriched by analytics store
-	trackingStore := tracking.NewPostgresStore(db)
 	tracking.NewHandler(trackingStore, tokenMgr, analyticsStore).Mount(r)
 
 	// Metrics — push to CloudWatch every 60s; gated by metrics.enabled config
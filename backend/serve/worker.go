@@ -0,0 +1,99 @@
+package serve
+
+import (
+	"context"
+	"crypto/tls"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/cobra"
+	"github.com/yumikokawaii/sherry-archive/internal/config"
+	"github.com/yumikokawaii/sherry-archive/internal/repository/postgres"
+	"github.com/yumikokawaii/sherry-archive/internal/worker"
+	"github.com/yumikokawaii/sherry-archive/pkg/queue"
+	"github.com/yumikokawaii/sherry-archive/pkg/storage"
+	"go.uber.org/zap"
+)
+
+// Worker processes queued upload and export tasks in-process, the same way
+// the upload Lambda does, from SQS or the Postgres queue.
+func Worker(cmd *cobra.Command, args []string) {
+	cfg, err := config.Load()
+	if err != nil {
+		zap.L().Fatal("config", zap.Error(err))
+	}
+
+	visibility, err := time.ParseDuration(cfg.Worker.VisibilityTimeout)
+	if err != nil {
+		zap.L().Fatal("invalid worker.visibility_timeout", zap.String("value", cfg.Worker.VisibilityTimeout), zap.Error(err))
+	}
+	// Consume extends visibility every half timeout, and SQS counts it in
+	// whole seconds.
+	if visibility < time.Second {
+		zap.L().Fatal("invalid worker.visibility_timeout, must be at least 1s", zap.String("value", cfg.Worker.VisibilityTimeout))
+	}
+	if cfg.Worker.Concurrency < 1 {
+		zap.L().Fatal("invalid worker.concurrency", zap.Int("value", cfg.Worker.Concurrency))
+	}
+
+	db, err := postgres.Connect(cfg.DB.DSN())
+	if err != nil {
+		zap.L().Fatal("db connect", zap.Error(err))
+	}
+	defer db.Close()
+
+	// Presigned URLs are never handed out by the worker.
+	storageClient, err := storage.NewClient(context.Background(), cfg.S3.Region, cfg.S3.Bucket, cfg.S3.Endpoint, time.Hour)
+	if err != nil {
+		zap.L().Fatal("s3", zap.Error(err))
+	}
+
+	redisOpts := &redis.Options{
+		Addr:     cfg.Redis.Addr,
+		Password: cfg.Redis.Password,
+		DB:       cfg.Redis.DB,
+	}
+	if cfg.Redis.TLS {
+		redisOpts.TLSConfig = &tls.Config{}
+	}
+	rdb := redis.NewClient(redisOpts)
+	defer rdb.Close()
+
+	processor := worker.NewProcessor(cfg, db, storageClient, rdb)
+	taskQueue := newQueue(cfg, db, processor.DeadLettered)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	zap.L().Info("worker starting", zap.String("queue", cfg.Queue.Backend), zap.Int("concurrency", cfg.Worker.Concurrency))
+	worker.Consume(ctx, taskQueue, processor, worker.Options{
+		Concurrency: cfg.Worker.Concurrency,
+		Visibility:  visibility,
+	})
+	zap.L().Info("worker stopped")
+}
+
+// newQueue returns the task queue selected by queue.backend. onDead (may be
+// nil) is told about Postgres messages that are dead-lettered.
+func newQueue(cfg *config.Application, db *sqlx.DB, onDead queue.DeadFunc) queue.Client {
+	switch cfg.Queue.Backend {
+	case queue.BackendSQS:
+		q, err := queue.NewSQS(context.Background(), cfg.S3.Region, cfg.SQS.QueueURL)
+		if err != nil {
+			zap.L().Fatal("sqs", zap.Error(err))
+		}
+		return q
+	case queue.BackendPostgres:
+		pollInterval, err := time.ParseDuration(cfg.Queue.PollInterval)
+		if err != nil {
+			zap.L().Fatal("invalid queue.poll_interval", zap.String("value", cfg.Queue.PollInterval), zap.Error(err))
+		}
+		return queue.NewPostgres(db, cfg.Queue.MaxReceives, pollInterval, onDead)
+	default:
+		zap.L().Fatal("invalid queue.backend", zap.String("value", cfg.Queue.Backend))
+		return nil
+	}
+}